@@ -0,0 +1,231 @@
+// Package client is a Go client for the Weather API server. It wraps the
+// JSON endpoints in typed methods, retries transient failures, and reports API
+// errors as *APIError:
+//
+//	c, err := client.New("https://weather.example.com", client.WithAPIKey(key))
+//	weather, err := c.GetWeather(ctx, "10001", &client.RequestOptions{Units: client.Metric})
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Retry defaults, overridable with WithRetries
+const (
+	defaultRetries  = 2
+	defaultBackoff  = 200 * time.Millisecond
+	maxBackoff      = 5 * time.Second
+	defaultTimeout  = 30 * time.Second
+	maxErrorBodyLen = 64 << 10
+)
+
+// Unit systems for RequestOptions.Units
+const (
+	Imperial = "imperial"
+	Metric   = "metric"
+	Standard = "standard"
+)
+
+// Client calls a Weather API server. It's safe for concurrent use.
+type Client struct {
+	baseURL     *url.URL
+	httpClient  *http.Client
+	apiKey      string
+	bearerToken string
+	userAgent   string
+	retries     int           // retries after the first attempt
+	backoff     time.Duration // delay before the first retry, doubled for each one after
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sends requests with the given HTTP client instead of one
+// with a 30 second timeout
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+// WithAPIKey authenticates requests with an API key, sent as X-API-Key
+func WithAPIKey(key string) Option {
+	return func(c *Client) { c.apiKey = key }
+}
+
+// WithBearerToken authenticates requests with a JWT, sent as a bearer token
+func WithBearerToken(token string) Option {
+	return func(c *Client) { c.bearerToken = token }
+}
+
+// WithUserAgent sets the User-Agent of requests
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) { c.userAgent = userAgent }
+}
+
+// WithRetries sets how many times a failed request is retried (0 to never
+// retry), and the delay before the first retry, doubled for each one after
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries = retries
+		c.backoff = backoff
+	}
+}
+
+// New returns a client for the server at baseURL, e.g.
+// "https://weather.example.com". Include a path prefix such as "/api/v1" to
+// call the versioned endpoints.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid base URL %q: must be http or https", baseURL)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		userAgent:  "go-container-test-client",
+		retries:    defaultRetries,
+		backoff:    defaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// RequestOptions are the settings every lookup takes. Empty fields use the
+// caller's saved preferences on the server, then its defaults.
+type RequestOptions struct {
+	Country  string // of the postal code, e.g. CA (default: US)
+	Units    string // Imperial, Metric, or Standard
+	Lang     string // e.g. es or zh-CN
+	Provider string // one of the server's ENABLED_PROVIDERS
+}
+
+// query returns the options as query parameters, added to params
+func (o *RequestOptions) query(params url.Values) url.Values {
+	if o == nil {
+		return params
+	}
+	for name, value := range map[string]string{"country": o.Country, "units": o.Units, "lang": o.Lang, "provider": o.Provider} {
+		if value != "" {
+			params.Set(name, value)
+		}
+	}
+	return params
+}
+
+// get calls a GET endpoint and decodes its JSON response into v
+func (c *Client) get(ctx context.Context, path string, params url.Values, v interface{}) error {
+	return c.do(ctx, http.MethodGet, path, params, nil, v)
+}
+
+// do calls an endpoint and decodes its JSON response into v. Every endpoint
+// the client calls only reads, so any of them can be retried: after network
+// errors, 429 Too Many Requests, and 5xx responses other than 501 Not
+// Implemented. Retries wait for the response's Retry-After when it's given,
+// or a jittered exponential backoff otherwise, and stop when ctx is done.
+func (c *Client) do(ctx context.Context, method, path string, params url.Values, body interface{}, v interface{}) error {
+	u := *c.baseURL
+	u.Path += path
+	u.RawQuery = params.Encode()
+
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	for retry := 0; ; retry++ {
+		resp, err := c.send(ctx, method, u.String(), payload)
+		if err == nil && resp.StatusCode < 300 {
+			defer resp.Body.Close()
+			if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+				return fmt.Errorf("failed to decode %s %s response: %w", method, path, err)
+			}
+			return nil
+		}
+		if err == nil {
+			err = newAPIError(resp)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if retry >= c.retries || !retryable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(c.delay(retry, err))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// send sends one attempt of a request
+func (c *Client) send(ctx context.Context, method, url string, payload []byte) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+	if c.bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.bearerToken)
+	}
+	return c.httpClient.Do(req)
+}
+
+// delay returns the wait before the given retry (0 for the first): the failed
+// response's Retry-After, up to the longest backoff, or else a random
+// duration between half and all of the exponential backoff
+func (c *Client) delay(retry int, err error) time.Duration {
+	if apiErr, ok := err.(*APIError); ok && apiErr.RetryAfter > 0 {
+		return min(apiErr.RetryAfter, maxBackoff)
+	}
+	backoff := c.backoff << retry
+	if backoff <= 0 || backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	half := backoff / 2
+	return half + rand.N(half+1)
+}
+
+// retryable reports whether a failed attempt is likely transient
+func retryable(err error) bool {
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		return true // the request didn't get a response
+	}
+	return apiErr.StatusCode == http.StatusTooManyRequests ||
+		(apiErr.StatusCode >= http.StatusInternalServerError && apiErr.StatusCode != http.StatusNotImplemented)
+}
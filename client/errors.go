@@ -0,0 +1,74 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Kinds of failed lookup, for errors.Is on an *APIError. They match the
+// statuses the server responds to failed lookups with.
+var (
+	ErrInvalidZip          = errors.New("invalid zip code")     // 400 Bad Request
+	ErrUnauthorized        = errors.New("unauthorized")         // 401 Unauthorized or 403 Forbidden
+	ErrNotFound            = errors.New("not found")            // 404 Not Found
+	ErrRateLimited         = errors.New("rate limited")         // 429 Too Many Requests
+	ErrUpstreamUnavailable = errors.New("upstream unavailable") // 503 Service Unavailable
+)
+
+// APIError is an error response from the server
+type APIError struct {
+	StatusCode int
+	Message    string        // the response's "error"
+	Code       string        // the response's "code", e.g. unknown_zip_code, if any
+	RetryAfter time.Duration // from the Retry-After header, if any
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("weather API returned status: %d", e.StatusCode)
+	}
+	return fmt.Sprintf("weather API returned status %d: %s", e.StatusCode, e.Message)
+}
+
+// Is classifies the error by its status
+func (e *APIError) Is(target error) bool {
+	switch e.StatusCode {
+	case http.StatusBadRequest:
+		return target == ErrInvalidZip
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return target == ErrUnauthorized
+	case http.StatusNotFound:
+		return target == ErrNotFound
+	case http.StatusTooManyRequests:
+		return target == ErrRateLimited
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return target == ErrUpstreamUnavailable
+	}
+	return false
+}
+
+// newAPIError reads an error response, closing its body
+func newAPIError(resp *http.Response) *APIError {
+	defer resp.Body.Close()
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+
+	var body struct {
+		Error string `json:"error"`
+		Code  string `json:"code"`
+	}
+	if json.NewDecoder(io.LimitReader(resp.Body, maxErrorBodyLen)).Decode(&body) == nil {
+		apiErr.Message = body.Error
+		apiErr.Code = body.Code
+	}
+	// Drain what's left so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBodyLen))
+	return apiErr
+}
@@ -0,0 +1,283 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// WeatherResponse is the current weather, from GET /weather
+type WeatherResponse struct {
+	ZipCode       string  `json:"zip_code,omitempty"`
+	Location      string  `json:"location"`
+	Units         string  `json:"units"`
+	Temperature   float64 `json:"temperature"`
+	FeelsLike     float64 `json:"feels_like"`
+	DewPoint      float64 `json:"dew_point"`
+	Description   string  `json:"description"`
+	Icon          string  `json:"icon"`
+	IconURL       string  `json:"icon_url"`
+	Humidity      int     `json:"humidity"`
+	Pressure      int     `json:"pressure"`
+	Visibility    int     `json:"visibility"`
+	CloudCover    int     `json:"cloud_cover"`
+	WindSpeed     float64 `json:"wind_speed"`
+	WindDirection int     `json:"wind_direction"`
+	WindGust      float64 `json:"wind_gust"`
+	ObservedAt    string  `json:"observed_at"`
+	LocalTime     string  `json:"local_time"`
+	Cache         string  `json:"cache,omitempty"`
+	Stale         bool    `json:"stale,omitempty"`
+	Source        string  `json:"source,omitempty"`
+}
+
+// BatchWeatherResult is the outcome of one lookup in a batch: its weather, or
+// why it failed
+type BatchWeatherResult struct {
+	ZipCode string           `json:"zip_code"`
+	Weather *WeatherResponse `json:"weather,omitempty"`
+	Error   string           `json:"error,omitempty"`
+}
+
+// BatchWeatherResponse is the results of a batch lookup, in request order,
+// from POST /weather/batch
+type BatchWeatherResponse struct {
+	Results []BatchWeatherResult `json:"results"`
+}
+
+// ForecastResponse is the 5-day forecast, from GET /forecast
+type ForecastResponse struct {
+	ZipCode  string          `json:"zip_code"`
+	Location string          `json:"location"`
+	Units    string          `json:"units"`
+	Days     []DailyForecast `json:"days"`
+}
+
+// DailyForecast summarizes the forecast for a single day
+type DailyForecast struct {
+	Date                string  `json:"date"`
+	High                float64 `json:"high"`
+	Low                 float64 `json:"low"`
+	Description         string  `json:"description"`
+	Icon                string  `json:"icon"`
+	IconURL             string  `json:"icon_url"`
+	PrecipitationChance int     `json:"precipitation_chance"`
+}
+
+// HourlyForecastResponse is the hour-by-hour forecast, from GET /forecast/hourly
+type HourlyForecastResponse struct {
+	ZipCode       string           `json:"zip_code"`
+	Location      string           `json:"location"`
+	Units         string           `json:"units"`
+	IntervalHours int              `json:"interval_hours"`
+	Hours         []HourlyForecast `json:"hours"`
+}
+
+// HourlyForecast is the forecast for a single forecast period
+type HourlyForecast struct {
+	Time                string  `json:"time"`
+	Temperature         float64 `json:"temperature"`
+	Description         string  `json:"description"`
+	Icon                string  `json:"icon"`
+	IconURL             string  `json:"icon_url"`
+	PrecipitationChance int     `json:"precipitation_chance"`
+	Precipitation       float64 `json:"precipitation"`
+	WindSpeed           float64 `json:"wind_speed"`
+	WindDirection       int     `json:"wind_direction"`
+}
+
+// HistoryResponse is the observed weather for a past date, from GET /history
+type HistoryResponse struct {
+	ZipCode       string  `json:"zip_code"`
+	Location      string  `json:"location"`
+	Date          string  `json:"date"`
+	Units         string  `json:"units"`
+	High          float64 `json:"high"`
+	Low           float64 `json:"low"`
+	Humidity      int     `json:"humidity"`
+	Precipitation float64 `json:"precipitation"`
+	WindSpeed     float64 `json:"wind_speed"`
+}
+
+// AirQualityResponse is the air quality, from GET /air-quality. Pollutant
+// concentrations are in μg/m³.
+type AirQualityResponse struct {
+	ZipCode  string  `json:"zip_code"`
+	Location string  `json:"location"`
+	AQI      int     `json:"aqi"`
+	Level    string  `json:"level"`
+	PM25     float64 `json:"pm2_5"`
+	PM10     float64 `json:"pm10"`
+	O3       float64 `json:"o3"`
+	NO2      float64 `json:"no2"`
+}
+
+// UVResponse is the UV index, from GET /uv
+type UVResponse struct {
+	ZipCode  string  `json:"zip_code"`
+	Location string  `json:"location"`
+	UVIndex  float64 `json:"uv_index"`
+	Risk     string  `json:"risk"`
+}
+
+// AstronomyResponse is today's sun and moon, from GET /astronomy
+type AstronomyResponse struct {
+	ZipCode          string  `json:"zip_code"`
+	Location         string  `json:"location"`
+	Sunrise          string  `json:"sunrise"`
+	Sunset           string  `json:"sunset"`
+	DayLength        string  `json:"day_length"`
+	DayLengthSeconds int64   `json:"day_length_seconds"`
+	MoonPhase        string  `json:"moon_phase"`
+	MoonIllumination float64 `json:"moon_illumination"`
+}
+
+// ZipCodeResponse is a normalized zip code and where it is, from GET /zip-code
+type ZipCodeResponse struct {
+	ZipCode string  `json:"zip_code"`
+	Plus4   string  `json:"plus4,omitempty"`
+	City    string  `json:"city"`
+	State   string  `json:"state"`
+	Country string  `json:"country"`
+	Lat     float64 `json:"lat"`
+	Lon     float64 `json:"lon"`
+}
+
+// VersionResponse is the build of the server, from GET /version
+type VersionResponse struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+	Modified  bool   `json:"modified,omitempty"`
+}
+
+// zipCodeQuery returns the query parameters of a lookup by zip code
+func zipCodeQuery(zipCode string, opts *RequestOptions) url.Values {
+	return opts.query(url.Values{"zip_code": {zipCode}})
+}
+
+// GetWeather returns the current weather at a zip code
+func (c *Client) GetWeather(ctx context.Context, zipCode string, opts *RequestOptions) (*WeatherResponse, error) {
+	var weather WeatherResponse
+	if err := c.get(ctx, "/weather", zipCodeQuery(zipCode, opts), &weather); err != nil {
+		return nil, err
+	}
+	return &weather, nil
+}
+
+// GetWeatherByCity returns the current weather in a city, given as "City" or
+// "City,ST"
+func (c *Client) GetWeatherByCity(ctx context.Context, city string, opts *RequestOptions) (*WeatherResponse, error) {
+	var weather WeatherResponse
+	if err := c.get(ctx, "/weather", opts.query(url.Values{"city": {city}}), &weather); err != nil {
+		return nil, err
+	}
+	return &weather, nil
+}
+
+// GetWeatherByCoordinates returns the current weather at a latitude and longitude
+func (c *Client) GetWeatherByCoordinates(ctx context.Context, lat, lon float64, opts *RequestOptions) (*WeatherResponse, error) {
+	params := url.Values{
+		"lat": {strconv.FormatFloat(lat, 'f', -1, 64)},
+		"lon": {strconv.FormatFloat(lon, 'f', -1, 64)},
+	}
+	var weather WeatherResponse
+	if err := c.get(ctx, "/weather", opts.query(params), &weather); err != nil {
+		return nil, err
+	}
+	return &weather, nil
+}
+
+// GetWeatherBatch returns the current weather at up to 50 zip codes. A zip
+// code that fails doesn't fail the batch; its result has the error instead.
+func (c *Client) GetWeatherBatch(ctx context.Context, zipCodes []string, opts *RequestOptions) (*BatchWeatherResponse, error) {
+	var batch BatchWeatherResponse
+	if err := c.do(ctx, http.MethodPost, "/weather/batch", opts.query(url.Values{}), zipCodes, &batch); err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// GetForecast returns the 5-day forecast at a zip code
+func (c *Client) GetForecast(ctx context.Context, zipCode string, opts *RequestOptions) (*ForecastResponse, error) {
+	var forecast ForecastResponse
+	if err := c.get(ctx, "/forecast", zipCodeQuery(zipCode, opts), &forecast); err != nil {
+		return nil, err
+	}
+	return &forecast, nil
+}
+
+// GetHourlyForecast returns the forecast at a zip code for the next hours
+// (at most 120), or the server's default of 24 when hours is 0
+func (c *Client) GetHourlyForecast(ctx context.Context, zipCode string, hours int, opts *RequestOptions) (*HourlyForecastResponse, error) {
+	params := zipCodeQuery(zipCode, opts)
+	if hours > 0 {
+		params.Set("hours", strconv.Itoa(hours))
+	}
+	var forecast HourlyForecastResponse
+	if err := c.get(ctx, "/forecast/hourly", params, &forecast); err != nil {
+		return nil, err
+	}
+	return &forecast, nil
+}
+
+// GetHistory returns the observed weather at a zip code on a past date
+func (c *Client) GetHistory(ctx context.Context, zipCode string, date time.Time, opts *RequestOptions) (*HistoryResponse, error) {
+	params := zipCodeQuery(zipCode, opts)
+	params.Set("date", date.Format(time.DateOnly))
+	var history HistoryResponse
+	if err := c.get(ctx, "/history", params, &history); err != nil {
+		return nil, err
+	}
+	return &history, nil
+}
+
+// GetAirQuality returns the air quality at a zip code. Only opts.Country applies.
+func (c *Client) GetAirQuality(ctx context.Context, zipCode string, opts *RequestOptions) (*AirQualityResponse, error) {
+	var airQuality AirQualityResponse
+	if err := c.get(ctx, "/air-quality", zipCodeQuery(zipCode, opts), &airQuality); err != nil {
+		return nil, err
+	}
+	return &airQuality, nil
+}
+
+// GetUV returns the UV index at a zip code. Only opts.Country applies.
+func (c *Client) GetUV(ctx context.Context, zipCode string, opts *RequestOptions) (*UVResponse, error) {
+	var uv UVResponse
+	if err := c.get(ctx, "/uv", zipCodeQuery(zipCode, opts), &uv); err != nil {
+		return nil, err
+	}
+	return &uv, nil
+}
+
+// GetAstronomy returns today's sunrise, sunset, and moon phase at a zip code.
+// Only opts.Country applies.
+func (c *Client) GetAstronomy(ctx context.Context, zipCode string, opts *RequestOptions) (*AstronomyResponse, error) {
+	var astronomy AstronomyResponse
+	if err := c.get(ctx, "/astronomy", zipCodeQuery(zipCode, opts), &astronomy); err != nil {
+		return nil, err
+	}
+	return &astronomy, nil
+}
+
+// LookupZipCode normalizes a US zip code, e.g. "10001-1234", and returns its
+// city, state, and coordinates
+func (c *Client) LookupZipCode(ctx context.Context, zipCode string) (*ZipCodeResponse, error) {
+	var zip ZipCodeResponse
+	if err := c.get(ctx, "/zip-code", url.Values{"zip_code": {zipCode}}, &zip); err != nil {
+		return nil, err
+	}
+	return &zip, nil
+}
+
+// GetVersion returns the build of the server
+func (c *Client) GetVersion(ctx context.Context) (*VersionResponse, error) {
+	var version VersionResponse
+	if err := c.get(ctx, "/version", nil, &version); err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"math"
+	"net/http"
+)
+
+// CacheInvalidationResponse reports how many cache entries were removed
+type CacheInvalidationResponse struct {
+	Deleted int `json:"deleted"`
+}
+
+// requireAdmin only lets requests through that carry the ADMIN_TOKEN bearer
+// token or a JWT granting the admin scope, or that came over a connection with
+// a client certificate signed by ADMIN_CLIENT_CA_FILE. Admin endpoints are
+// disabled when none of these are set up.
+func (s *Server) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := s.config()
+		if c.AdminToken == "" && c.JWT == nil && c.AdminClientCAs == nil {
+			writeError(w, http.StatusForbidden, "admin endpoints are disabled: ADMIN_TOKEN is not set")
+			return
+		}
+		if c.AdminClientCAs != nil && r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		provided, ok := bearerToken(r)
+		if ok && c.AdminToken != "" && subtle.ConstantTimeCompare([]byte(provided), []byte(c.AdminToken)) == 1 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if ok && c.JWT != nil {
+			if claims, err := c.JWT.verify(r.Context(), s.jwksClient, provided); err == nil {
+				if !claims.hasScope(scopeAdmin) {
+					w.Header().Set("WWW-Authenticate", `Bearer realm="admin", error="insufficient_scope", scope="`+scopeAdmin+`"`)
+					writeError(w, http.StatusForbidden, "bearer token lacks the "+scopeAdmin+" scope")
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+		writeError(w, http.StatusUnauthorized, "a valid admin token is required")
+	})
+}
+
+// CacheStatsResponse represents the cache statistics we'll return
+type CacheStatsResponse struct {
+	Backend         string  `json:"backend"`
+	TTLSeconds      int     `json:"ttl_seconds"`
+	StaleTTLSeconds int     `json:"stale_ttl_seconds"`
+	Hits            int64   `json:"hits"`
+	Misses          int64   `json:"misses"`
+	HitRatio        float64 `json:"hit_ratio"`
+	Entries         int     `json:"entries"`
+	Evictions       int64   `json:"evictions"`
+	MemoryBytes     int64   `json:"memory_bytes"`
+}
+
+// Cache statistics handler using Chi
+func (s *Server) cacheStatsHandler(w http.ResponseWriter, r *http.Request) {
+	stats := s.weatherCache.Stats()
+
+	// Hit ratio is 0 until the cache has been used
+	var hitRatio float64
+	if lookups := stats.Hits + stats.Misses; lookups > 0 {
+		hitRatio = math.Round(float64(stats.Hits)/float64(lookups)*1000) / 1000
+	}
+
+	c := s.config()
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(CacheStatsResponse{
+		Backend:         c.CacheBackend,
+		TTLSeconds:      int(c.CacheTTL.Seconds()),
+		StaleTTLSeconds: int(c.CacheStaleTTL.Seconds()),
+		Hits:            stats.Hits,
+		Misses:          stats.Misses,
+		HitRatio:        hitRatio,
+		Entries:         stats.Entries,
+		Evictions:       stats.Evictions,
+		MemoryBytes:     stats.MemoryBytes,
+	})
+}
+
+// Cache invalidation handler using Chi
+func (s *Server) cacheInvalidationHandler(w http.ResponseWriter, r *http.Request) {
+	// Flush everything when all=true is given
+	if r.URL.Query().Get("all") == "true" {
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(CacheInvalidationResponse{Deleted: s.weatherCache.DeletePrefix("")})
+		return
+	}
+
+	// Otherwise remove every cached variant (units, language) of one location
+	loc, ok := s.readZipCode(w, r)
+	if !ok {
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(CacheInvalidationResponse{Deleted: s.weatherCache.DeletePrefix(cacheKeyPrefix(loc))})
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// OpenWeatherMap air quality index levels (1-5)
+var airQualityLevels = map[int]string{
+	1: "Good",
+	2: "Fair",
+	3: "Moderate",
+	4: "Poor",
+	5: "Very Poor",
+}
+
+// AirQualityResponse represents the air quality data we'll return.
+// Pollutant concentrations are in μg/m³.
+type AirQualityResponse struct {
+	ZipCode  string  `json:"zip_code"`
+	Location string  `json:"location"`
+	AQI      int     `json:"aqi"`
+	Level    string  `json:"level"`
+	PM25     float64 `json:"pm2_5"`
+	PM10     float64 `json:"pm10"`
+	O3       float64 `json:"o3"`
+	NO2      float64 `json:"no2"`
+}
+
+// OpenWeatherMap Air Pollution API response structure (simplified)
+type OpenWeatherAirPollutionAPIResponse struct {
+	List []struct {
+		Main struct {
+			AQI int `json:"aqi"`
+		} `json:"main"`
+		Components struct {
+			PM25 float64 `json:"pm2_5"`
+			PM10 float64 `json:"pm10"`
+			O3   float64 `json:"o3"`
+			NO2  float64 `json:"no2"`
+		} `json:"components"`
+	} `json:"list"`
+}
+
+func (s *Server) getAirQuality(ctx context.Context, loc Location) (*AirQualityResponse, error) {
+	// Get API key from environment variable
+	apiKey := s.config().OpenWeatherAPIKey
+	if s.config().MockMode {
+		// In mock mode, return mock data instead of calling the API
+		return &AirQualityResponse{
+			ZipCode:  loc.ZipCode,
+			Location: s.mockName(loc),
+			AQI:      2,
+			Level:    airQualityLevels[2],
+			PM25:     8.4,
+			PM10:     14.9,
+			O3:       61.2,
+			NO2:      12.7,
+		}, nil
+	}
+
+	// The air pollution API only accepts coordinates
+	coords, err := s.geocodeZipCode(ctx, loc, apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Add("lat", fmt.Sprintf("%f", coords.Lat))
+	params.Add("lon", fmt.Sprintf("%f", coords.Lon))
+	params.Add("appid", apiKey)
+
+	var apiResp OpenWeatherAirPollutionAPIResponse
+	if err := s.fetchOpenWeather(ctx, "/data/2.5/air_pollution", params, &apiResp); err != nil {
+		return nil, err
+	}
+
+	if len(apiResp.List) == 0 {
+		return nil, withKind(ErrNotFound, fmt.Errorf("no air quality data available for zip code %s", loc.ZipCode))
+	}
+	current := apiResp.List[0]
+
+	return &AirQualityResponse{
+		ZipCode:  loc.ZipCode,
+		Location: coords.Name,
+		AQI:      current.Main.AQI,
+		Level:    airQualityLevels[current.Main.AQI],
+		PM25:     current.Components.PM25,
+		PM10:     current.Components.PM10,
+		O3:       current.Components.O3,
+		NO2:      current.Components.NO2,
+	}, nil
+}
+
+// Air quality handler using Chi
+func (s *Server) airQualityHandler(w http.ResponseWriter, r *http.Request) {
+	// Get zip code and country from query parameters
+	loc, ok := s.zipCodeFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	// Get air quality data
+	airQuality, err := s.getAirQuality(r.Context(), loc)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	// Return air quality data as JSON
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(airQuality)
+}
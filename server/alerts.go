@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+)
+
+// NWS active alerts API response structure (simplified)
+type NWSAlertsAPIResponse struct {
+	Features []struct {
+		Properties struct {
+			ID        string `json:"id"`
+			Event     string `json:"event"`
+			Headline  string `json:"headline"`
+			Severity  string `json:"severity"`
+			Urgency   string `json:"urgency"`
+			AreaDesc  string `json:"areaDesc"`
+			Effective string `json:"effective"`
+			Expires   string `json:"expires"`
+		} `json:"properties"`
+	} `json:"features"`
+}
+
+// SevereAlert is a severe or extreme weather alert in effect at a location
+type SevereAlert struct {
+	ID        string `json:"id"`
+	Event     string `json:"event"`
+	Headline  string `json:"headline"`
+	Severity  string `json:"severity"`
+	Urgency   string `json:"urgency"`
+	Area      string `json:"area"`
+	Effective string `json:"effective"`
+	Expires   string `json:"expires"`
+}
+
+// Alert severities that count as severe
+var severeAlertSeverities = []string{"Severe", "Extreme"}
+
+// getSevereAlerts returns the severe and extreme alerts the National Weather
+// Service has in effect at a US zip code. Demo data has none.
+func (s *Server) getSevereAlerts(ctx context.Context, loc Location) ([]SevereAlert, error) {
+	if s.config().MockMode {
+		return nil, nil
+	}
+
+	coords, err := s.nwsCoordinates(ctx, loc)
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	params.Set("point", fmt.Sprintf("%.4f,%.4f", coords.Lat, coords.Lon))
+	var apiResp NWSAlertsAPIResponse
+	if err := s.fetchNWS(ctx, nwsBaseURL+"/alerts/active?"+params.Encode(), &apiResp); err != nil {
+		return nil, err
+	}
+
+	var alerts []SevereAlert
+	for _, feature := range apiResp.Features {
+		p := feature.Properties
+		if !slices.Contains(severeAlertSeverities, p.Severity) {
+			continue
+		}
+		alerts = append(alerts, SevereAlert{
+			ID:        p.ID,
+			Event:     p.Event,
+			Headline:  p.Headline,
+			Severity:  p.Severity,
+			Urgency:   p.Urgency,
+			Area:      p.AreaDesc,
+			Effective: p.Effective,
+			Expires:   p.Expires,
+		})
+	}
+	return alerts, nil
+}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// How long per-location request counts are kept, and the most locations
+// /admin/analytics/top-locations returns
+const (
+	locationRequestRetention = 7 * 24 * time.Hour
+	defaultTopLocations      = 10
+	maxTopLocations          = 100
+)
+
+// locationKey identifies a requested zip code
+type locationKey struct {
+	zipCode, country string
+}
+
+// locationRequestTracker counts the requests for each zip code by UTC hour
+type locationRequestTracker struct {
+	mu    sync.Mutex
+	hours map[time.Time]map[locationKey]int64
+}
+
+// record counts a request for a location; only zip codes are counted
+func (t *locationRequestTracker) record(loc Location) {
+	if loc.ZipCode == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	hour := time.Now().UTC().Truncate(time.Hour)
+	counts, ok := t.hours[hour]
+	if !ok {
+		counts = map[locationKey]int64{}
+		t.hours[hour] = counts
+		t.prune()
+	}
+	counts[locationKey{loc.ZipCode, loc.country()}]++
+}
+
+// prune drops the hours past locationRequestRetention. The caller holds mu.
+func (t *locationRequestTracker) prune() {
+	oldest := time.Now().UTC().Add(-locationRequestRetention).Truncate(time.Hour)
+	for hour := range t.hours {
+		if hour.Before(oldest) {
+			delete(t.hours, hour)
+		}
+	}
+}
+
+// since returns each location's requests in the hours starting at or after from
+func (t *locationRequestTracker) since(from time.Time) map[locationKey]int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	totals := map[locationKey]int64{}
+	for hour, counts := range t.hours {
+		if hour.Before(from) {
+			continue
+		}
+		for key, count := range counts {
+			totals[key] += count
+		}
+	}
+	return totals
+}
+
+// LocationRequests reports how often a zip code was requested
+type LocationRequests struct {
+	ZipCode  string `json:"zip_code"`
+	Country  string `json:"country"`
+	Requests int64  `json:"requests"`
+	Warmed   bool   `json:"warmed"`
+}
+
+// TopLocationsResponse represents the most requested locations we'll return from /admin/analytics/top-locations
+type TopLocationsResponse struct {
+	Window        string             `json:"window"`
+	From          string             `json:"from"`
+	TotalRequests int64              `json:"total_requests"`
+	Locations     []LocationRequests `json:"locations"`
+}
+
+// Top locations handler using Chi
+func (s *Server) topLocationsHandler(w http.ResponseWriter, r *http.Request) {
+	window := 24 * time.Hour
+	if value := r.URL.Query().Get("window"); value != "" {
+		parsed, err := parseWindowDuration(value)
+		if err != nil || parsed < time.Hour || parsed > locationRequestRetention {
+			writeError(w, http.StatusBadRequest, "window must be a duration between 1h and 7d, such as 24h or 7d")
+			return
+		}
+		window = parsed
+	}
+
+	limit := defaultTopLocations
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxTopLocations {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxTopLocations))
+			return
+		}
+		limit = parsed
+	}
+
+	// Counts are kept by the hour, so the window covers whole hours including the current one
+	from := time.Now().UTC().Add(time.Hour - window).Truncate(time.Hour)
+	response := TopLocationsResponse{
+		Window:    formatWindowDuration(window),
+		From:      from.Format(time.RFC3339),
+		Locations: []LocationRequests{},
+	}
+	warmed := s.config().CacheWarmZipCodes
+	for key, count := range s.locationRequests.since(from) {
+		response.TotalRequests += count
+		response.Locations = append(response.Locations, LocationRequests{
+			ZipCode:  key.zipCode,
+			Country:  key.country,
+			Requests: count,
+			// Cache warming only covers US zip codes
+			Warmed: key.country == defaultCountry && slices.Contains(warmed, key.zipCode),
+		})
+	}
+	sort.Slice(response.Locations, func(i, j int) bool {
+		a, b := response.Locations[i], response.Locations[j]
+		if a.Requests != b.Requests {
+			return a.Requests > b.Requests
+		}
+		if a.ZipCode != b.ZipCode {
+			return a.ZipCode < b.ZipCode
+		}
+		return a.Country < b.Country
+	})
+	if len(response.Locations) > limit {
+		response.Locations = response.Locations[:limit]
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
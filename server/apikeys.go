@@ -0,0 +1,339 @@
+package server
+
+import (
+	"cmp"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// Header clients send their API key in
+const apiKeyHeader = "X-API-Key"
+
+// Shortest API key accepted, so that keys can't be guessed
+const minAPIKeyLength = 16
+
+// apiKey is a client API key, and where it was configured: "env" for
+// API_KEYS, "file" for API_KEYS_FILE, or "store" for the store
+type apiKey struct {
+	apiClient
+	source string
+}
+
+// apiKeyEntry is one key in an API_KEYS_FILE. The file may hold the key itself
+// or its hex-encoded SHA-256 hash.
+type apiKeyEntry struct {
+	Name   string `yaml:"name" json:"name"`
+	Tier   string `yaml:"tier,omitempty" json:"tier,omitempty"`
+	Key    string `yaml:"key,omitempty" json:"key,omitempty"`
+	SHA256 string `yaml:"sha256,omitempty" json:"sha256,omitempty"`
+}
+
+// hashAPIKey returns the hex-encoded SHA-256 hash keys are looked up by, so
+// that the plain keys aren't kept in memory
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// hash returns the hash an entry's key is looked up by
+func (e apiKeyEntry) hash() string {
+	if e.Key != "" {
+		return hashAPIKey(e.Key)
+	}
+	return strings.ToLower(e.SHA256)
+}
+
+// apiKeyID identifies a key by the start of its hash, in /admin/keys
+func apiKeyID(hash string) string {
+	return hash[:min(len(hash), 12)]
+}
+
+// loadAPIKeys reads the client API keys from API_KEYS, a comma-separated list
+// of name:key or name:tier:key entries, and from the API_KEYS_FILE at path, a
+// JSON or YAML list of entries. The keys are returned by hash; none means
+// authentication is off.
+func loadAPIKeys(env *environment, path string) (map[string]apiKey, []string) {
+	keys := map[string]apiKey{}
+	var problems []string
+	add := func(source string, entry apiKeyEntry) {
+		hash := entry.hash()
+		switch {
+		case entry.Name == "":
+			problems = append(problems, fmt.Sprintf("invalid %s entry: name must not be empty", source))
+			return
+		case entry.Key != "" && entry.SHA256 != "":
+			problems = append(problems, fmt.Sprintf("invalid %s entry %q: set key or sha256, not both", source, entry.Name))
+			return
+		case entry.Key != "":
+			if len(entry.Key) < minAPIKeyLength {
+				problems = append(problems, fmt.Sprintf("invalid %s entry %q: key must be at least %d characters", source, entry.Name, minAPIKeyLength))
+				return
+			}
+		default:
+			if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != sha256.Size {
+				problems = append(problems, fmt.Sprintf("invalid %s entry %q: sha256 must be a hex-encoded SHA-256 hash of the key", source, entry.Name))
+				return
+			}
+		}
+		if existing, ok := keys[hash]; ok {
+			problems = append(problems, fmt.Sprintf("invalid %s entry %q: same key as %q", source, entry.Name, existing.Name))
+			return
+		}
+		if entry.Tier == "" {
+			entry.Tier = defaultTier
+		}
+		key := apiKey{apiClient: apiClient{Name: entry.Name, Tier: entry.Tier}, source: "env"}
+		if source == "API_KEYS_FILE" {
+			key.source = "file"
+		}
+		keys[hash] = key
+	}
+
+	for _, item := range listFromEnv(env, "API_KEYS") {
+		fields := strings.Split(item, ":")
+		switch len(fields) {
+		case 2:
+			add("API_KEYS", apiKeyEntry{Name: fields[0], Key: fields[1]})
+		case 3:
+			add("API_KEYS", apiKeyEntry{Name: fields[0], Tier: fields[1], Key: fields[2]})
+		default:
+			problems = append(problems, "invalid API_KEYS entry: must be name:key or name:tier:key")
+		}
+	}
+
+	if path != "" {
+		entries, err := readAPIKeysFile(path)
+		if err != nil {
+			return keys, append(problems, err.Error())
+		}
+		for _, entry := range entries {
+			add("API_KEYS_FILE", entry)
+		}
+	}
+	return keys, problems
+}
+
+// readAPIKeysFile reads the entries in an API_KEYS_FILE
+func readAPIKeysFile(path string) ([]apiKeyEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API_KEYS_FILE: %v", err)
+	}
+	var entries []apiKeyEntry
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid API_KEYS_FILE %s: %v", path, err)
+	}
+	return entries, nil
+}
+
+// loadStoredAPIKeys reads the API keys kept in the store, if it keeps any
+func (s *Server) loadStoredAPIKeys() error {
+	keys := map[string]apiKey{}
+	if store, ok := s.dataStore.(apiKeyStore); ok {
+		entries, err := store.APIKeys()
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			keys[entry.hash()] = apiKey{apiClient: apiClient{Name: entry.Name, Tier: cmp.Or(entry.Tier, defaultTier)}, source: "store"}
+		}
+	}
+	s.storedAPIKeys.Store(&keys)
+	return nil
+}
+
+// storedKeys returns the API keys kept in the store, by hash
+func (s *Server) storedKeys() map[string]apiKey {
+	if keys := s.storedAPIKeys.Load(); keys != nil {
+		return *keys
+	}
+	return nil
+}
+
+// hasAPIKeys reports whether any client API key is configured or stored,
+// which turns API key authentication on
+func (s *Server) hasAPIKeys(c *Config) bool {
+	return len(c.APIKeys) > 0 || len(s.storedKeys()) > 0
+}
+
+// findAPIKey looks up a client API key by hash. Configured keys take
+// precedence over stored ones.
+func (s *Server) findAPIKey(c *Config, hash string) (apiKey, bool) {
+	if key, ok := c.APIKeys[hash]; ok {
+		return key, true
+	}
+	key, ok := s.storedKeys()[hash]
+	return key, ok
+}
+
+// updateAPIKeysFile applies change to the entries in API_KEYS_FILE, writes them
+// back, and reloads the configuration so the change takes effect. JSON files
+// are written as JSON and others as YAML; comments in the file are not kept.
+func (s *Server) updateAPIKeysFile(path string, change func([]apiKeyEntry) []apiKeyEntry) error {
+	s.apiKeysFileMu.Lock()
+	defer s.apiKeysFileMu.Unlock()
+
+	entries, err := readAPIKeysFile(path)
+	if err != nil {
+		return err
+	}
+	entries = change(entries)
+
+	var data []byte
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		data, err = json.MarshalIndent(entries, "", "  ")
+	} else {
+		data, err = yaml.Marshal(entries)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode API keys: %v", err)
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("failed to write API_KEYS_FILE: %v", err)
+	}
+
+	ignored, err := s.reloadConfig()
+	logReload(ignored, err)
+	return err
+}
+
+// APIKeyResponse describes a client API key. The key itself is only returned
+// when it is created.
+type APIKeyResponse struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Tier   string `json:"tier"`
+	Source string `json:"source"`
+	Key    string `json:"key,omitempty"`
+}
+
+// APIKeyRequest describes a key to create with POST /admin/keys
+type APIKeyRequest struct {
+	Name string `json:"name"`
+	Tier string `json:"tier"`
+}
+
+// API key list handler using Chi
+func (s *Server) apiKeysHandler(w http.ResponseWriter, r *http.Request) {
+	keys := []APIKeyResponse{}
+	for _, configured := range []map[string]apiKey{s.config().APIKeys, s.storedKeys()} {
+		for hash, key := range configured {
+			keys = append(keys, APIKeyResponse{ID: apiKeyID(hash), Name: key.Name, Tier: key.Tier, Source: key.source})
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Name != keys[j].Name {
+			return keys[i].Name < keys[j].Name
+		}
+		return keys[i].ID < keys[j].ID
+	})
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(keys)
+}
+
+// API key creation handler using Chi. The new key is added by its hash to
+// API_KEYS_FILE, or else to the store, so it is only ever shown in this response.
+func (s *Server) createAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	path := s.config().APIKeysFile
+	store, inStore := s.dataStore.(apiKeyStore)
+	if path == "" && !inStore {
+		writeError(w, http.StatusConflict, "managing API keys requires API_KEYS_FILE or STORE_BACKEND=sqlite or postgres")
+		return
+	}
+
+	var body APIKeyRequest
+	r.Body = http.MaxBytesReader(w, r.Body, 1024)
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Name == "" {
+		writeError(w, http.StatusBadRequest, `request body must be a JSON object such as {"name": "mobile-app", "tier": "pro"}`)
+		return
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to generate API key")
+		return
+	}
+	key := hex.EncodeToString(secret)
+	hash := hashAPIKey(key)
+
+	entry := apiKeyEntry{Name: body.Name, Tier: body.Tier, SHA256: hash}
+	source := "file"
+	var err error
+	if path != "" {
+		err = s.updateAPIKeysFile(path, func(entries []apiKeyEntry) []apiKeyEntry {
+			return append(entries, entry)
+		})
+	} else {
+		source = "store"
+		if err = store.AddAPIKey(entry); err == nil {
+			err = s.loadStoredAPIKeys()
+		}
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	infof("Created API key %s for %s", apiKeyID(hash), body.Name)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(APIKeyResponse{ID: apiKeyID(hash), Name: body.Name, Tier: cmp.Or(body.Tier, defaultTier), Source: source, Key: key})
+}
+
+// API key revocation handler using Chi. Keys set in API_KEYS have to be
+// removed there.
+func (s *Server) deleteAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	c := s.config()
+	id := chi.URLParam(r, "id")
+	var revoked *APIKeyResponse
+	var revokedHash string
+	for _, configured := range []map[string]apiKey{c.APIKeys, s.storedKeys()} {
+		for hash, key := range configured {
+			if apiKeyID(hash) == id {
+				revoked = &APIKeyResponse{ID: id, Name: key.Name, Tier: key.Tier, Source: key.source}
+				revokedHash = hash
+			}
+		}
+	}
+	switch {
+	case revoked == nil:
+		writeError(w, http.StatusNotFound, "no API key with id "+id)
+		return
+	case revoked.Source == "env":
+		writeError(w, http.StatusConflict, "API key "+id+" is set in API_KEYS; remove it there and reload")
+		return
+	}
+
+	var err error
+	if revoked.Source == "store" {
+		if _, err = s.dataStore.(apiKeyStore).DeleteAPIKey(revokedHash); err == nil {
+			err = s.loadStoredAPIKeys()
+		}
+	} else {
+		err = s.updateAPIKeysFile(c.APIKeysFile, func(entries []apiKeyEntry) []apiKeyEntry {
+			return slices.DeleteFunc(entries, func(entry apiKeyEntry) bool {
+				return apiKeyID(entry.hash()) == id
+			})
+		})
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	infof("Revoked API key %s of %s", id, revoked.Name)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(revoked)
+}
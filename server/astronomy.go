@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+)
+
+// Average length of a lunar cycle, in days
+const synodicMonth = 29.530588853
+
+// A known new moon (2000-01-06 18:14 UTC), used as the reference for moon phases
+var referenceNewMoon = time.Date(2000, time.January, 6, 18, 14, 0, 0, time.UTC)
+
+// Moon phase names, in order, each covering an eighth of the lunar cycle
+var moonPhases = []string{
+	"New Moon",
+	"Waxing Crescent",
+	"First Quarter",
+	"Waxing Gibbous",
+	"Full Moon",
+	"Waning Gibbous",
+	"Last Quarter",
+	"Waning Crescent",
+}
+
+// AstronomyResponse represents the sun and moon data we'll return
+type AstronomyResponse struct {
+	ZipCode          string  `json:"zip_code"`
+	Location         string  `json:"location"`
+	Sunrise          string  `json:"sunrise"`
+	Sunset           string  `json:"sunset"`
+	DayLength        string  `json:"day_length"`
+	DayLengthSeconds int64   `json:"day_length_seconds"`
+	MoonPhase        string  `json:"moon_phase"`
+	MoonIllumination float64 `json:"moon_illumination"`
+}
+
+// moonPhase returns the phase name and illuminated fraction (percent) of the moon at t
+func moonPhase(t time.Time) (string, float64) {
+	// Position within the current lunar cycle, from 0 (new) to 1 (next new)
+	age := math.Mod(t.Sub(referenceNewMoon).Hours()/24, synodicMonth)
+	if age < 0 {
+		age += synodicMonth
+	}
+	cycle := age / synodicMonth
+
+	// Shift by half a phase so each name is centered on its exact moment
+	index := int(math.Floor(cycle*8+0.5)) % len(moonPhases)
+	illumination := (1 - math.Cos(2*math.Pi*cycle)) / 2
+
+	return moonPhases[index], math.Round(illumination*1000) / 10
+}
+
+// newAstronomyResponse builds the response from sunrise/sunset times in the location's time zone
+func newAstronomyResponse(zipCode, location string, sunrise, sunset time.Time) *AstronomyResponse {
+	dayLength := sunset.Sub(sunrise)
+	phase, illumination := moonPhase(time.Now())
+
+	return &AstronomyResponse{
+		ZipCode:          zipCode,
+		Location:         location,
+		Sunrise:          sunrise.Format(time.RFC3339),
+		Sunset:           sunset.Format(time.RFC3339),
+		DayLength:        fmt.Sprintf("%dh %02dm", int(dayLength.Hours()), int(dayLength.Minutes())%60),
+		DayLengthSeconds: int64(dayLength.Seconds()),
+		MoonPhase:        phase,
+		MoonIllumination: illumination,
+	}
+}
+
+func (s *Server) getAstronomy(ctx context.Context, loc Location) (*AstronomyResponse, error) {
+	// Get API key from environment variable
+	apiKey := s.config().OpenWeatherAPIKey
+	if s.config().MockMode {
+		// In mock mode, return mock sun times instead of calling the API
+		year, month, day := time.Now().UTC().Date()
+		sunrise := time.Date(year, month, day, 6, 52, 0, 0, time.UTC)
+		sunset := time.Date(year, month, day, 18, 31, 0, 0, time.UTC)
+		return newAstronomyResponse(loc.ZipCode, s.mockName(loc), sunrise, sunset), nil
+	}
+
+	// Sunrise and sunset are part of the current conditions payload
+	apiResp, err := s.fetchCurrentWeather(ctx, s.config().OpenWeatherBaseURL, loc, Options{}, apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	zone := time.FixedZone("local", apiResp.Timezone)
+	sunrise := time.Unix(apiResp.Sys.Sunrise, 0).In(zone)
+	sunset := time.Unix(apiResp.Sys.Sunset, 0).In(zone)
+
+	return newAstronomyResponse(loc.ZipCode, apiResp.Name, sunrise, sunset), nil
+}
+
+// Astronomy handler using Chi
+func (s *Server) astronomyHandler(w http.ResponseWriter, r *http.Request) {
+	// Get zip code and country from query parameters
+	loc, ok := s.zipCodeFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	// Get astronomy data
+	astronomy, err := s.getAstronomy(r.Context(), loc)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	// Return astronomy data as JSON
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(astronomy)
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"net/http"
+	"strings"
+)
+
+// Scopes a JWT must grant for the weather and admin endpoints
+const (
+	scopeWeatherRead = "weather:read"
+	scopeAdmin       = "admin"
+)
+
+// Tier given to clients whose API key or token doesn't name one
+const defaultTier = "standard"
+
+// apiClient describes the client a request was authenticated as
+type apiClient struct {
+	Name string
+	Tier string
+}
+
+type apiClientContextKey struct{}
+
+// requestClient returns the client a request was authenticated as, if any
+func requestClient(ctx context.Context) (apiClient, bool) {
+	client, ok := ctx.Value(apiClientContextKey{}).(apiClient)
+	return client, ok
+}
+
+// bearerToken returns the token in a request's Authorization header
+func bearerToken(r *http.Request) (string, bool) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return token, ok && token != ""
+}
+
+// requireClientAuth only lets requests through that carry a known key in the
+// X-API-Key header or a JWT bearer token granting the weather:read scope, once
+// API keys or JWT authentication are configured
+func (s *Server) requireClientAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := s.config()
+		if !s.hasAPIKeys(c) && c.JWT == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		authenticated := func(client apiClient) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiClientContextKey{}, client)))
+		}
+
+		if provided := r.Header.Get(apiKeyHeader); provided != "" && s.hasAPIKeys(c) {
+			key, ok := s.findAPIKey(c, hashAPIKey(provided))
+			if !ok {
+				writeError(w, http.StatusUnauthorized, "invalid API key")
+				return
+			}
+			authenticated(key.apiClient)
+			return
+		}
+
+		if token, ok := bearerToken(r); ok && c.JWT != nil {
+			claims, err := c.JWT.verify(r.Context(), s.jwksClient, token)
+			if err != nil {
+				debugf("rejected bearer token: %v", err)
+				w.Header().Set("WWW-Authenticate", `Bearer realm="weather", error="invalid_token"`)
+				writeError(w, http.StatusUnauthorized, "invalid or expired bearer token")
+				return
+			}
+			if !claims.hasScope(scopeWeatherRead) {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="weather", error="insufficient_scope", scope="`+scopeWeatherRead+`"`)
+				writeError(w, http.StatusForbidden, "bearer token lacks the "+scopeWeatherRead+" scope")
+				return
+			}
+			authenticated(apiClient{Name: claims.Subject, Tier: cmp.Or(claims.Tier, defaultTier)})
+			return
+		}
+
+		switch {
+		case c.JWT == nil:
+			writeError(w, http.StatusUnauthorized, "an API key is required in the X-API-Key header")
+		case !s.hasAPIKeys(c):
+			w.Header().Set("WWW-Authenticate", `Bearer realm="weather"`)
+			writeError(w, http.StatusUnauthorized, "a bearer token is required")
+		default:
+			w.Header().Set("WWW-Authenticate", `Bearer realm="weather"`)
+			writeError(w, http.StatusUnauthorized, "an API key in the X-API-Key header or a bearer token is required")
+		}
+	})
+}
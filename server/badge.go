@@ -0,0 +1,127 @@
+package server
+
+import (
+	"cmp"
+	"fmt"
+	"html"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Badge colors, the same as shields.io's
+const (
+	badgeLabelColor = "#555"
+	badgeErrorColor = "#9f9f9f"
+)
+
+// Badge colors by temperature: the first whose threshold (in °F) the temperature is below
+var badgeTemperatureColors = []struct {
+	below float64
+	color string
+}{
+	{32, "#007ec6"},          // freezing: blue
+	{50, "#5ba4cf"},          // cold: light blue
+	{70, "#4c1"},             // mild: green
+	{85, "#fe7d37"},          // warm: orange
+	{math.Inf(1), "#e05d44"}, // hot: red
+}
+
+// Badge handler using Chi. Badges are meant to be embedded in READMEs and
+// wikis, which can't send credentials, so they're served without client
+// authentication; lookups go through the weather cache.
+func (s *Server) badgeHandler(w http.ResponseWriter, r *http.Request) {
+	opts, ok := s.optionsFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml; charset=utf-8")
+	// Nothing but the badge itself, even when it's opened directly
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+
+	label := r.URL.Query().Get("label")
+	loc, err := s.zipCodeLocation(chi.URLParam(r, "zip"), r.URL.Query().Get("country"))
+	if err != nil {
+		writeErrorBadge(w, serviceErrorStatus(err), label, "invalid zip code")
+		return
+	}
+
+	weather, err := s.weather.CurrentWeather(r.Context(), loc, opts)
+	if err != nil {
+		writeErrorBadge(w, serviceErrorStatus(err), label, "unavailable")
+		return
+	}
+
+	temperature, _ := unitSymbols(weather.Units)
+	value := fmt.Sprintf("%.0f%s %s", weather.Temperature, temperature, weather.Description)
+	setCacheStatusHeader(w, weather)
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, badgeSVG(cmp.Or(label, weather.Location), value, temperatureColor(*weather)))
+}
+
+// writeErrorBadge writes a gray badge for a failed lookup. Errors aren't cached.
+func writeErrorBadge(w http.ResponseWriter, status int, label, message string) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	fmt.Fprint(w, badgeSVG(cmp.Or(label, "weather"), message, badgeErrorColor))
+}
+
+// temperatureColor picks a badge color for the conditions' temperature
+func temperatureColor(weather WeatherResponse) string {
+	fahrenheit := weather.Temperature
+	switch weather.Units {
+	case unitsMetric:
+		fahrenheit = weather.Temperature*9/5 + 32
+	case unitsStandard:
+		fahrenheit = (weather.Temperature-273.15)*9/5 + 32
+	}
+	for _, threshold := range badgeTemperatureColors {
+		if fahrenheit < threshold.below {
+			return threshold.color
+		}
+	}
+	return badgeTemperatureColors[len(badgeTemperatureColors)-1].color
+}
+
+// badgeTextWidth estimates the width of badge text in pixels, in 11px Verdana
+func badgeTextWidth(text string) int {
+	width := 0.0
+	for _, c := range text {
+		switch {
+		case strings.ContainsRune("iljtfrI.,:;!|()' ", c):
+			width += 4
+		case strings.ContainsRune("mwMW%@", c):
+			width += 10.5
+		case c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+			width += 7.5
+		default:
+			width += 6.5
+		}
+	}
+	return int(width + 0.5)
+}
+
+// badgeSVG renders a flat badge in the style of shields.io, with a gray label
+// on the left and the value on the right
+func badgeSVG(label, value, color string) string {
+	const padding = 10
+	labelWidth := badgeTextWidth(label) + padding
+	valueWidth := badgeTextWidth(value) + padding
+	width := labelWidth + valueWidth
+	label, value = html.EscapeString(label), html.EscapeString(value)
+
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[4]s: %[5]s">
+<title>%[4]s: %[5]s</title>
+<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+<clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>
+<g clip-path="url(#r)"><rect width="%[2]d" height="20" fill="%[7]s"/><rect x="%[2]d" width="%[3]d" height="20" fill="%[6]s"/><rect width="%[1]d" height="20" fill="url(#s)"/></g>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="%[8]d" y="15" fill="#010101" fill-opacity=".3">%[4]s</text><text x="%[8]d" y="14">%[4]s</text>
+<text x="%[9]d" y="15" fill="#010101" fill-opacity=".3">%[5]s</text><text x="%[9]d" y="14">%[5]s</text>
+</g>
+</svg>
+`, width, labelWidth, valueWidth, label, value, color, badgeLabelColor, labelWidth/2, labelWidth+valueWidth/2)
+}
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// Batch lookup limits
+const (
+	maxBatchSize     = 50
+	maxBatchBodySize = 64 << 10 // 64 KiB
+	batchWorkers     = 8        // Concurrent upstream requests per batch
+)
+
+// BatchWeatherResult represents the outcome of one lookup in a batch
+type BatchWeatherResult struct {
+	ZipCode string           `json:"zip_code" xml:"zip_code"`
+	Weather *WeatherResponse `json:"weather,omitempty" xml:"weather,omitempty"`
+	Error   string           `json:"error,omitempty" xml:"error,omitempty"`
+}
+
+// BatchWeatherResponse represents the results of a batch lookup, in request order
+type BatchWeatherResponse struct {
+	XMLName xml.Name             `json:"-" xml:"batch"`
+	Results []BatchWeatherResult `json:"results" xml:"results>result"`
+}
+
+// BatchWeatherStreamResult is one line of an NDJSON batch response. Lines
+// arrive as lookups complete, so Index gives the zip code's position in the request.
+type BatchWeatherStreamResult struct {
+	Index int `json:"index"`
+	BatchWeatherResult
+}
+
+// getWeatherBatch looks up weather for each zip code, returning the results in request order
+func (s *Server) getWeatherBatch(ctx context.Context, zipCodes []string, opts Options) []BatchWeatherResult {
+	results := make([]BatchWeatherResult, len(zipCodes))
+	s.lookupWeatherBatch(ctx, zipCodes, opts, func(index int, result BatchWeatherResult) {
+		results[index] = result
+	})
+	return results
+}
+
+// lookupWeatherBatch looks up weather for each zip code using a bounded pool
+// of workers, calling done with each result as it completes. Calls to done
+// aren't concurrent. Failures are reported per item rather than failing the
+// whole batch.
+func (s *Server) lookupWeatherBatch(ctx context.Context, zipCodes []string, opts Options, done func(index int, result BatchWeatherResult)) {
+	jobs := make(chan int)
+	completed := make(chan BatchWeatherStreamResult)
+
+	var wg sync.WaitGroup
+	for i := 0; i < batchWorkers && i < len(zipCodes); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				completed <- BatchWeatherStreamResult{Index: index, BatchWeatherResult: s.lookupBatchItem(ctx, zipCodes[index], opts)}
+			}
+		}()
+	}
+	go func() {
+		for i := range zipCodes {
+			jobs <- i
+		}
+		close(jobs)
+		wg.Wait()
+		close(completed)
+	}()
+
+	for result := range completed {
+		done(result.Index, result.BatchWeatherResult)
+	}
+}
+
+// lookupBatchItem looks up the weather at one zip code of a batch
+func (s *Server) lookupBatchItem(ctx context.Context, zipCode string, opts Options) BatchWeatherResult {
+	result := BatchWeatherResult{ZipCode: zipCode}
+	zipCode, err := s.normalizeZipCode(zipCode)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	loc := Location{ZipCode: zipCode}
+	s.locationRequests.record(loc)
+	weather, err := s.weather.CurrentWeather(ctx, loc, opts)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Weather = weather
+	return result
+}
+
+// Batch weather handler using Chi
+func (s *Server) batchWeatherHandler(w http.ResponseWriter, r *http.Request) {
+	// Get units from query parameters
+	opts, ok := s.optionsFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	// Get response format from the format parameter or Accept header
+	format, ok := s.formatFromRequest(w, r, formatNDJSON, formatGeoJSON)
+	if !ok {
+		return
+	}
+
+	// Decode the JSON array of zip codes from the request body
+	var zipCodes []string
+	r.Body = http.MaxBytesReader(w, r.Body, maxBatchBodySize)
+	if err := json.NewDecoder(r.Body).Decode(&zipCodes); err != nil {
+		writeError(w, http.StatusBadRequest, "request body must be a JSON array of zip codes")
+		return
+	}
+
+	if len(zipCodes) == 0 {
+		writeError(w, http.StatusBadRequest, "at least one zip code is required")
+		return
+	}
+	if len(zipCodes) > maxBatchSize {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("at most %d zip codes are allowed per batch", maxBatchSize))
+		return
+	}
+
+	// Stream results as they complete for NDJSON clients
+	if format == formatNDJSON {
+		s.writeBatchStream(w, r, zipCodes, opts)
+		return
+	}
+
+	results := s.getWeatherBatch(r.Context(), zipCodes, opts)
+	if format == formatGeoJSON {
+		s.writeBatchGeoJSON(w, r, results)
+		return
+	}
+
+	// Return batch results in the requested format
+	writeFormatted(w, format, &BatchWeatherResponse{Results: results})
+}
+
+// writeBatchGeoJSON writes a batch's results as a feature per zip code, with
+// the weather as properties. Failed lookups, whose zip codes may not even be
+// valid, have no geometry and the zip code and error as properties.
+func (s *Server) writeBatchGeoJSON(w http.ResponseWriter, r *http.Request, results []BatchWeatherResult) {
+	features := make([]GeoJSONFeature, len(results))
+	for i, result := range results {
+		if result.Weather == nil {
+			features[i] = GeoJSONFeature{Type: "Feature", Properties: result}
+			continue
+		}
+		features[i] = s.geoJSONFeature(r.Context(), Location{ZipCode: result.ZipCode}, result.Weather)
+	}
+	writeGeoJSON(w, features...)
+}
+
+// writeBatchStream writes a batch's results as newline-delimited JSON, a line
+// per zip code, flushing each as soon as its lookup completes
+func (s *Server) writeBatchStream(w http.ResponseWriter, r *http.Request, zipCodes []string, opts Options) {
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", ndjsonContentType)
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	encoder := json.NewEncoder(w)
+	s.lookupWeatherBatch(r.Context(), zipCodes, opts, func(index int, result BatchWeatherResult) {
+		encoder.Encode(BatchWeatherStreamResult{Index: index, BatchWeatherResult: result})
+		rc.Flush()
+	})
+}
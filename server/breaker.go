@@ -0,0 +1,237 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Circuit breaker defaults, overridable with CIRCUIT_BREAKER_THRESHOLD and
+// CIRCUIT_BREAKER_COOLDOWN
+const (
+	defaultBreakerThreshold = 3
+	defaultBreakerCooldown  = 30 * time.Second
+)
+
+// Circuit breaker states
+const (
+	breakerClosed   = "closed"
+	breakerOpen     = "open"
+	breakerHalfOpen = "half-open"
+)
+
+// breakerStatus tracks recent results for one provider
+type breakerStatus struct {
+	state               string
+	consecutiveFailures int
+	openedAt            time.Time
+	probing             bool // a half-open trial call is in flight
+	trips               int64
+	successes           int64
+	failures            int64
+	rejected            int64
+	lastFailure         time.Time
+	lastError           string
+}
+
+// circuitBreakers holds a circuit breaker per provider. A breaker opens after
+// threshold consecutive failures, rejecting calls to the provider until
+// cooldown has passed; then a single trial call decides whether it closes
+// again or stays open for another cooldown.
+type circuitBreakers struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	statuses  map[string]*breakerStatus
+}
+
+// setupCircuitBreakers applies CIRCUIT_BREAKER_THRESHOLD and CIRCUIT_BREAKER_COOLDOWN
+func (s *Server) setupCircuitBreakers(c *Config) {
+	s.providerBreakers.mu.Lock()
+	defer s.providerBreakers.mu.Unlock()
+	s.providerBreakers.threshold = c.CircuitBreakerThreshold
+	s.providerBreakers.cooldown = c.CircuitBreakerCooldown
+}
+
+// status returns a provider's breaker, creating a closed one if needed. The caller holds mu.
+func (b *circuitBreakers) status(name string) *breakerStatus {
+	status, ok := b.statuses[name]
+	if !ok {
+		status = &breakerStatus{state: breakerClosed}
+		b.statuses[name] = status
+	}
+	return status
+}
+
+// allow reports whether a provider may be called. Once an open breaker's
+// cooldown has passed it goes half-open and lets one trial call through.
+func (b *circuitBreakers) allow(name string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := b.status(name)
+	if status.state == breakerOpen && time.Since(status.openedAt) >= b.cooldown {
+		status.state = breakerHalfOpen
+	}
+
+	switch {
+	case status.state == breakerClosed:
+		return true
+	case status.state == breakerHalfOpen && !status.probing:
+		status.probing = true
+		return true
+	default:
+		status.rejected++
+		return false
+	}
+}
+
+// record updates a provider's breaker after a call it allowed. Only the
+// provider being down counts as a failure: a lookup it answered, even with
+// not found or a bad request, shows it's up.
+func (b *circuitBreakers) record(name string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := b.status(name)
+	status.probing = false
+	if !providerDown(err) {
+		status.state = breakerClosed
+		status.consecutiveFailures = 0
+		status.successes++
+		return
+	}
+
+	status.consecutiveFailures++
+	status.failures++
+	status.lastFailure = time.Now()
+	status.lastError = err.Error()
+	if status.state == breakerHalfOpen || (status.state == breakerClosed && status.consecutiveFailures >= b.threshold) {
+		status.state = breakerOpen
+		status.openedAt = time.Now()
+		status.trips++
+	}
+}
+
+// release ends a call allowed without recording its result, such as one
+// abandoned by its client, so a half-open breaker can let another trial
+// call through
+func (b *circuitBreakers) release(name string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.status(name).probing = false
+}
+
+// providerDown reports whether a call's error shows its provider is down: a
+// server error, a timeout, or a failed connection, rather than the provider
+// turning the request away
+func providerDown(err error) bool {
+	var status *upstreamStatusError
+	if errors.As(err, &status) {
+		return status.status >= 500
+	}
+	return errors.Is(err, ErrUpstreamUnavailable)
+}
+
+// retryAfter returns how long until the soonest open breaker among names goes half-open
+func (b *circuitBreakers) retryAfter(names []string) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	wait := b.cooldown
+	for _, name := range names {
+		if status, ok := b.statuses[name]; ok && status.state == breakerOpen {
+			wait = min(wait, max(b.cooldown-time.Since(status.openedAt), 0))
+		}
+	}
+	return wait
+}
+
+// states returns each tracked provider's breaker state
+func (b *circuitBreakers) states() map[string]string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	states := make(map[string]string, len(b.statuses))
+	for name, status := range b.statuses {
+		states[name] = status.state
+	}
+	return states
+}
+
+// circuitOpenError is returned when every provider for a lookup has an open breaker
+type circuitOpenError struct {
+	providers  []string
+	retryAfter time.Duration
+}
+
+func (e *circuitOpenError) Error() string {
+	return fmt.Sprintf("weather provider temporarily unavailable (circuit open for %s); retry in %s",
+		strings.Join(e.providers, ", "), e.retryAfter.Round(time.Second))
+}
+
+func (e *circuitOpenError) Is(target error) bool { return target == ErrUpstreamUnavailable }
+
+// ProviderBreakerStats reports one provider's circuit breaker
+type ProviderBreakerStats struct {
+	Provider            string `json:"provider"`
+	State               string `json:"state"`
+	ConsecutiveFailures int    `json:"consecutive_failures"`
+	Trips               int64  `json:"trips"`
+	Successes           int64  `json:"successes"`
+	Failures            int64  `json:"failures"`
+	Rejected            int64  `json:"rejected"`
+	OpenedAt            string `json:"opened_at,omitempty"`
+	LastFailure         string `json:"last_failure,omitempty"`
+	LastError           string `json:"last_error,omitempty"`
+}
+
+// ProviderBreakersResponse represents the circuit breaker statistics we'll return
+type ProviderBreakersResponse struct {
+	Threshold       int                    `json:"threshold"`
+	CooldownSeconds int                    `json:"cooldown_seconds"`
+	Providers       []ProviderBreakerStats `json:"providers"`
+}
+
+// Provider circuit breaker statistics handler using Chi
+func (s *Server) providerBreakersHandler(w http.ResponseWriter, r *http.Request) {
+	s.providerBreakers.mu.Lock()
+	response := ProviderBreakersResponse{
+		Threshold:       s.providerBreakers.threshold,
+		CooldownSeconds: int(s.providerBreakers.cooldown.Seconds()),
+		Providers:       []ProviderBreakerStats{},
+	}
+	for name, status := range s.providerBreakers.statuses {
+		stats := ProviderBreakerStats{
+			Provider:            name,
+			State:               status.state,
+			ConsecutiveFailures: status.consecutiveFailures,
+			Trips:               status.trips,
+			Successes:           status.successes,
+			Failures:            status.failures,
+			Rejected:            status.rejected,
+			LastError:           status.lastError,
+		}
+		if status.state != breakerClosed {
+			stats.OpenedAt = status.openedAt.UTC().Format(time.RFC3339)
+		}
+		if !status.lastFailure.IsZero() {
+			stats.LastFailure = status.lastFailure.UTC().Format(time.RFC3339)
+		}
+		response.Providers = append(response.Providers, stats)
+	}
+	s.providerBreakers.mu.Unlock()
+
+	sort.Slice(response.Providers, func(i, j int) bool {
+		return response.Providers[i].Provider < response.Providers[j].Provider
+	})
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
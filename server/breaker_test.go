@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// fakeProvider answers lookups with current
+type fakeProvider struct {
+	current func(ctx context.Context) (*WeatherResponse, error)
+}
+
+func (p *fakeProvider) Name() string { return "fake" }
+
+func (p *fakeProvider) Current(ctx context.Context, loc Location, opts Options) (*WeatherResponse, error) {
+	return p.current(ctx)
+}
+
+// useBreakers gives a test server breakers that open on the first failure
+// and go half-open straight away
+func useBreakers(t *testing.T) (*Server, *circuitBreakers) {
+	t.Helper()
+	s := newTestServer(t)
+	s.providerBreakers = &circuitBreakers{threshold: 1, statuses: map[string]*breakerStatus{}}
+	return s, s.providerBreakers
+}
+
+func TestBreakerReleasesCanceledProbe(t *testing.T) {
+	s, breakers := useBreakers(t)
+	down := &fakeProvider{func(ctx context.Context) (*WeatherResponse, error) {
+		return nil, withKind(ErrUpstreamUnavailable, errors.New("down"))
+	}}
+	if _, err := s.currentWithFailover(context.Background(), []WeatherProvider{down}, Location{}, Options{}); err == nil {
+		t.Fatal("lookup succeeded with the provider down")
+	}
+	if state := breakers.states()["fake"]; state != breakerOpen {
+		t.Fatalf("breaker is %s after a failure, want %s", state, breakerOpen)
+	}
+
+	// The client gives up on the half-open trial call
+	ctx, cancel := context.WithCancel(context.Background())
+	abandoned := &fakeProvider{func(context.Context) (*WeatherResponse, error) {
+		cancel()
+		return nil, ctx.Err()
+	}}
+	if _, err := s.currentWithFailover(ctx, []WeatherProvider{abandoned}, Location{}, Options{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("canceled lookup returned %v, want %v", err, context.Canceled)
+	}
+
+	up := &fakeProvider{func(context.Context) (*WeatherResponse, error) {
+		return &WeatherResponse{}, nil
+	}}
+	if _, err := s.currentWithFailover(context.Background(), []WeatherProvider{up}, Location{}, Options{}); err != nil {
+		t.Fatalf("lookup after the canceled trial call failed: %v", err)
+	}
+	if state := breakers.states()["fake"]; state != breakerClosed {
+		t.Errorf("breaker is %s after a successful trial call, want %s", state, breakerClosed)
+	}
+}
+
+func TestBreakerReleasesProbeOverBudget(t *testing.T) {
+	s, breakers := useBreakers(t)
+	breakers.record("fake", withKind(ErrUpstreamUnavailable, errors.New("down")))
+
+	// The provider's daily budget runs out during the half-open trial call
+	exhausted := &fakeProvider{func(context.Context) (*WeatherResponse, error) {
+		return nil, &quotaExceededError{provider: "fake", budget: 1}
+	}}
+	if _, err := s.currentWithFailover(context.Background(), []WeatherProvider{exhausted}, Location{}, Options{}); !errors.As(err, new(*quotaExceededError)) {
+		t.Fatalf("lookup over budget returned %v, want a quota error", err)
+	}
+
+	// Once the budget refills
+	up := &fakeProvider{func(context.Context) (*WeatherResponse, error) {
+		return &WeatherResponse{}, nil
+	}}
+	if _, err := s.currentWithFailover(context.Background(), []WeatherProvider{up}, Location{}, Options{}); err != nil {
+		t.Fatalf("lookup after the trial call over budget failed: %v", err)
+	}
+	if state := breakers.states()["fake"]; state != breakerClosed {
+		t.Errorf("breaker is %s after a successful trial call, want %s", state, breakerClosed)
+	}
+}
+
+func TestBreakerIgnoresBadLookups(t *testing.T) {
+	_, breakers := useBreakers(t)
+	for _, err := range []error{
+		&upstreamStatusError{kind: "weather", status: 404},
+		&upstreamStatusError{kind: "weather", status: 400},
+		withKind(ErrInvalidZip, errors.New("invalid zip code")),
+		errors.New("location not covered"),
+	} {
+		breakers.record("fake", err)
+		if state := breakers.states()["fake"]; state != breakerClosed {
+			t.Errorf("breaker is %s after %q, want %s", state, err, breakerClosed)
+		}
+	}
+
+	for _, err := range []error{
+		&upstreamStatusError{kind: "weather", status: 502},
+		withKind(ErrUpstreamUnavailable, errors.New("fake timed out after 10s")),
+	} {
+		breakers.statuses = map[string]*breakerStatus{}
+		breakers.record("fake", err)
+		if state := breakers.states()["fake"]; state != breakerOpen {
+			t.Errorf("breaker is %s after %q, want %s", state, err, breakerOpen)
+		}
+	}
+}
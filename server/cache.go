@@ -0,0 +1,381 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// How long weather lookups are cached when CACHE_TTL isn't set
+const defaultCacheTTL = 10 * time.Minute
+
+// How long expired lookups may still be served while they are refreshed,
+// when CACHE_STALE_TTL isn't set
+const defaultCacheStaleTTL = time.Hour
+
+// How often the in-memory cache removes expired entries
+const cacheSweepInterval = time.Minute
+
+// Values for the response "cache" field
+const (
+	cacheHit  = "hit"
+	cacheMiss = "miss"
+)
+
+// Cache stores encoded responses by key. Implementations must be safe for
+// concurrent use; expired entries must not be returned by Get.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
+	Delete(key string)
+	// DeletePrefix removes every entry whose key starts with prefix and
+	// returns how many were removed; an empty prefix removes everything
+	DeletePrefix(prefix string) int
+	Stats() CacheStats
+	// Close stops any background work, such as removing expired entries
+	Close()
+}
+
+// CacheStats reports cache usage counters
+type CacheStats struct {
+	Hits        int64 `json:"hits"`
+	Misses      int64 `json:"misses"`
+	Entries     int   `json:"entries"`
+	Evictions   int64 `json:"evictions"`
+	MemoryBytes int64 `json:"memory_bytes"`
+}
+
+// cachedWeather is the cache entry for a weather lookup. Entries are kept in
+// the backend past FreshUntil so they can be served stale while refreshing.
+type cachedWeather struct {
+	FreshUntil time.Time       `json:"fresh_until"`
+	Weather    WeatherResponse `json:"weather"`
+}
+
+// setupCache creates the weather cache for the configured CACHE_BACKEND
+func (s *Server) setupCache(c *Config) {
+	if c.CacheBackend == "memory" {
+		s.weatherCache = newMemoryCache(cacheSweepInterval)
+	} else {
+		s.weatherCache = noopCache{}
+	}
+}
+
+// durationFromEnv reads a non-negative Go duration (such as "10m") from an environment variable
+func durationFromEnv(env *environment, name string, fallback time.Duration) (time.Duration, error) {
+	value := env.Getenv(name)
+	if value == "" {
+		return fallback, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("invalid %s %q: must be a duration such as 10m", name, value)
+	}
+	return duration, nil
+}
+
+// memoryCache is an in-process Cache backed by a map
+type memoryCache struct {
+	mu        sync.Mutex
+	entries   map[string]memoryCacheEntry
+	hits      int64
+	misses    int64
+	evictions int64
+	bytes     int64
+	done      chan struct{} // closed by Close to stop the sweeper
+	closeOnce sync.Once
+}
+
+type memoryCacheEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+// newMemoryCache creates an in-memory cache that removes expired entries every sweepInterval
+func newMemoryCache(sweepInterval time.Duration) *memoryCache {
+	c := &memoryCache{entries: map[string]memoryCacheEntry{}, done: make(chan struct{})}
+	ticker := time.NewTicker(sweepInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.sweep()
+			case <-c.done:
+				return
+			}
+		}
+	}()
+	return c
+}
+
+func (c *memoryCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if ok && time.Now().After(entry.expires) {
+		c.remove(key, entry)
+		c.evictions++
+		ok = false
+	}
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	return entry.value, true
+}
+
+func (c *memoryCache) Set(key string, value []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if old, ok := c.entries[key]; ok {
+		c.remove(key, old)
+	}
+	c.entries[key] = memoryCacheEntry{value: value, expires: time.Now().Add(ttl)}
+	c.bytes += int64(len(key) + len(value))
+}
+
+func (c *memoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.entries[key]; ok {
+		c.remove(key, entry)
+	}
+}
+
+func (c *memoryCache) DeletePrefix(prefix string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	deleted := 0
+	for key, entry := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			c.remove(key, entry)
+			deleted++
+		}
+	}
+	return deleted
+}
+
+func (c *memoryCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{
+		Hits:        c.hits,
+		Misses:      c.misses,
+		Entries:     len(c.entries),
+		Evictions:   c.evictions,
+		MemoryBytes: c.bytes,
+	}
+}
+
+// Close stops removing expired entries
+func (c *memoryCache) Close() {
+	c.closeOnce.Do(func() { close(c.done) })
+}
+
+// remove deletes an entry and its size from the cache; callers must hold c.mu
+func (c *memoryCache) remove(key string, entry memoryCacheEntry) {
+	delete(c.entries, key)
+	c.bytes -= int64(len(key) + len(entry.value))
+}
+
+// sweep removes expired entries
+func (c *memoryCache) sweep() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for key, entry := range c.entries {
+		if now.After(entry.expires) {
+			c.remove(key, entry)
+			c.evictions++
+		}
+	}
+}
+
+// noopCache is a Cache that stores nothing, used when caching is disabled
+type noopCache struct{}
+
+func (noopCache) Get(key string) ([]byte, bool)                   { return nil, false }
+func (noopCache) Set(key string, value []byte, ttl time.Duration) {}
+func (noopCache) Delete(key string)                               {}
+func (noopCache) DeletePrefix(prefix string) int                  { return 0 }
+func (noopCache) Stats() CacheStats                               { return CacheStats{} }
+func (noopCache) Close()                                          {}
+
+// cacheKey identifies a lookup by location, units, language, and provider
+func (s *Server) cacheKey(loc Location, opts Options) string {
+	return cacheKeyPrefix(loc) + opts.units() + "|" + opts.lang() + "|" + opts.provider(s.config())
+}
+
+// cacheKeyPrefix is the part of the cache key shared by every lookup for a location
+func cacheKeyPrefix(loc Location) string {
+	switch {
+	case loc.ZipCode != "":
+		return "zip:" + loc.ZipCode + "," + loc.country() + "|"
+	case loc.City != "":
+		return "city:" + loc.City + "," + loc.country() + "|"
+	case loc.Coords != nil:
+		return fmt.Sprintf("coords:%.4f,%.4f|", loc.Coords.Lat, loc.Coords.Lon)
+	}
+	return "|"
+}
+
+// getCachedWeather returns current conditions from the cache when possible,
+// falling back to an upstream lookup and caching the result. Expired entries
+// within the stale window are returned immediately (marked stale) and
+// refreshed in the background.
+func (s *Server) getCachedWeather(ctx context.Context, loc Location, opts Options) (*WeatherResponse, error) {
+	key := s.cacheKey(loc, opts)
+	if data, ok := s.weatherCache.Get(key); ok {
+		var entry cachedWeather
+		if err := json.Unmarshal(data, &entry); err == nil {
+			weather := &entry.Weather
+			weather.Cache = cacheHit
+			debugf("cache hit for %s", key)
+			if time.Now().After(entry.FreshUntil) {
+				weather.Stale = true
+				s.refreshWeatherInBackground(key, loc, opts)
+			}
+			refreshLocalTime(weather)
+			return weather, nil
+		}
+	}
+
+	debugf("cache miss for %s", key)
+	weather, err := s.fetchWeather(ctx, key, loc, opts)
+	if err != nil {
+		return nil, err
+	}
+	weather.Cache = cacheMiss
+	return weather, nil
+}
+
+// fetchWeather looks up current conditions upstream and caches them. Concurrent
+// calls for the same key share a single upstream request; each caller gets its
+// own copy. A caller whose ctx is canceled stops waiting, and the shared
+// request is canceled once no caller is waiting on it.
+func (s *Server) fetchWeather(ctx context.Context, key string, loc Location, opts Options) (*WeatherResponse, error) {
+	lookupCtx, leave := s.joinLookup(ctx, key)
+	defer leave()
+
+	// A shared request canceled just before this caller joined is retried once
+	for attempt := 0; ; attempt++ {
+		lookup := s.weatherLookups.DoChan(key, func() (interface{}, error) {
+			weather, err := s.getWeather(lookupCtx, loc, opts)
+			if err != nil {
+				return nil, err
+			}
+			s.storeWeather(key, weather)
+			s.observationRefreshed(key, loc, *weather)
+			return *weather, nil
+		})
+
+		var result singleflight.Result
+		select {
+		case result = <-lookup:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		if result.Err != nil {
+			if attempt == 0 && errors.Is(result.Err, context.Canceled) && lookupCtx.Err() == nil {
+				continue
+			}
+			return nil, result.Err
+		}
+		weather := result.Val.(WeatherResponse)
+		return &weather, nil
+	}
+}
+
+// sharedLookup is the context of a coalesced upstream lookup and how many
+// callers are waiting on it
+type sharedLookup struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	waiters int
+}
+
+// joinLookup registers a caller waiting on the lookup for key. It returns the
+// lookup's context and a function the caller runs when it stops waiting; the
+// context is canceled once the last caller stops. The lookup keeps the values,
+// such as the request ID, of the caller that started it.
+func (s *Server) joinLookup(ctx context.Context, key string) (context.Context, func()) {
+	s.sharedLookupsMu.Lock()
+	defer s.sharedLookupsMu.Unlock()
+
+	lookup, ok := s.sharedLookups[key]
+	if !ok {
+		lookupCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		lookup = &sharedLookup{ctx: lookupCtx, cancel: cancel}
+		s.sharedLookups[key] = lookup
+	}
+	lookup.waiters++
+
+	return lookup.ctx, func() {
+		s.sharedLookupsMu.Lock()
+		defer s.sharedLookupsMu.Unlock()
+
+		if lookup.waiters--; lookup.waiters == 0 {
+			lookup.cancel()
+			delete(s.sharedLookups, key)
+		}
+	}
+}
+
+// storeWeather caches a weather lookup, keeping it past its TTL for the stale window
+func (s *Server) storeWeather(key string, weather *WeatherResponse) {
+	c := s.config()
+	entry := cachedWeather{FreshUntil: time.Now().Add(c.CacheTTL), Weather: *weather}
+	if data, err := json.Marshal(entry); err == nil {
+		s.weatherCache.Set(key, data, c.CacheTTL+c.CacheStaleTTL)
+	}
+}
+
+// refreshWeatherInBackground re-fetches a stale entry, unless a refresh for
+// the same key is already running. On failure the stale entry is kept.
+func (s *Server) refreshWeatherInBackground(key string, loc Location, opts Options) {
+	if _, running := s.weatherCacheRefreshing.LoadOrStore(key, true); running {
+		return
+	}
+
+	go func() {
+		defer s.weatherCacheRefreshing.Delete(key)
+
+		if _, err := s.fetchWeather(context.Background(), key, loc, opts); err != nil {
+			warnf("background refresh of %s failed: %v", key, err)
+		}
+	}()
+}
+
+// setCacheStatusHeader reports how a weather response was served in the X-Cache
+// header: "hit", "miss", or "stale"
+func setCacheStatusHeader(w http.ResponseWriter, weather *WeatherResponse) {
+	status := weather.Cache
+	if weather.Stale {
+		status = "stale"
+	}
+	if status != "" {
+		w.Header().Set("X-Cache", status)
+	}
+}
+
+// refreshLocalTime updates a cached response's local_time to the current time,
+// keeping the location's UTC offset
+func refreshLocalTime(weather *WeatherResponse) {
+	if cached, err := time.Parse(time.RFC3339, weather.LocalTime); err == nil {
+		weather.LocalTime = time.Now().In(cached.Location()).Format(time.RFC3339)
+	}
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultCacheControl returns the default Cache-Control policy for a route.
+// Current conditions match the weather cache TTL; other data changes less often.
+func (s *Server) defaultCacheControl(route string) string {
+	maxAge := func(d time.Duration) string {
+		if d <= 0 {
+			return "no-cache"
+		}
+		return fmt.Sprintf("public, max-age=%d", int(d.Seconds()))
+	}
+
+	ttl := s.config().CacheTTL
+	switch route {
+	case "weather", "compare", "air-quality", "uv", "history/observations", "history/trend", "badge", "card":
+		return maxAge(ttl)
+	case "weather/me", "me/weather":
+		// Depends on the caller (its IP address or saved locations), so shared caches mustn't store it
+		if ttl <= 0 {
+			return "no-cache"
+		}
+		return fmt.Sprintf("private, max-age=%d", int(ttl.Seconds()))
+	case "forecast", "forecast/hourly", "calendar":
+		return maxAge(30 * time.Minute)
+	case "history", "astronomy", "zip-code":
+		return maxAge(time.Hour)
+	case "ui", "docs", "openapi":
+		// Embedded files have no modification time to revalidate with, and are small
+		return "no-cache"
+	case "health", "version", "weather/batch", "weather/stream", "graphql", "me/usage", "me/locations", "me/preferences", "subscriptions", "admin":
+		return "no-store"
+	}
+	return ""
+}
+
+// cacheControlEnvVar returns the environment variable that overrides a route's
+// policy, e.g. CACHE_CONTROL_FORECAST_HOURLY for "forecast/hourly"
+func cacheControlEnvVar(route string) string {
+	return "CACHE_CONTROL_" + strings.ToUpper(strings.NewReplacer("/", "_", "-", "_").Replace(route))
+}
+
+// cacheControl returns middleware that sets the route's Cache-Control header
+func (s *Server) cacheControl(route string) func(http.Handler) http.Handler {
+	value := s.config().CacheControl[cacheControlEnvVar(route)]
+	if value == "" {
+		value = s.defaultCacheControl(route)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if value != "" {
+				w.Header().Set("Cache-Control", value)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
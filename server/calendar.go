@@ -0,0 +1,102 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// How often subscribed calendar apps are asked to refresh the feed
+const calendarRefreshInterval = "PT6H"
+
+// Calendar feed handler using Chi. Calendar apps subscribe by URL and can't
+// send credentials, so like badges the feed is served without client
+// authentication.
+func (s *Server) calendarHandler(w http.ResponseWriter, r *http.Request) {
+	opts, ok := s.optionsFromRequest(w, r)
+	if !ok {
+		return
+	}
+	loc, err := s.zipCodeLocation(chi.URLParam(r, "zip"), r.URL.Query().Get("country"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	forecast, err := s.weather.Forecast(r.Context(), loc, opts)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="weather-%s.ics"`, loc.code()))
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, forecastCalendar(loc, forecast, time.Now()))
+}
+
+// forecastCalendar renders a forecast as an iCalendar (RFC 5545) feed with an
+// all-day event for each day. Event UIDs depend only on the location and date,
+// so refreshing the feed updates days already on the calendar.
+func forecastCalendar(loc Location, forecast *ForecastResponse, now time.Time) string {
+	temperature, _ := unitSymbols(forecast.Units)
+	stamp := now.UTC().Format("20060102T150405Z")
+
+	var b strings.Builder
+	line := func(name, value string) { b.WriteString(foldCalendarLine(name + ":" + value)) }
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//go-container-test//Weather API//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	line("X-WR-CALNAME", escapeCalendarText("Weather in "+forecast.Location))
+	line("REFRESH-INTERVAL;VALUE=DURATION", calendarRefreshInterval)
+	line("X-PUBLISHED-TTL", calendarRefreshInterval)
+	for _, day := range forecast.Days {
+		date, err := time.Parse(time.DateOnly, day.Date)
+		if err != nil {
+			continue
+		}
+		summary := fmt.Sprintf("%s %.0f°/%.0f%s %s", iconEmoji(day.Icon), day.High, day.Low, temperature, day.Description)
+		description := fmt.Sprintf("%s\nHigh %.0f%s, low %.0f%s\n%d%% chance of precipitation",
+			capitalize(day.Description), day.High, temperature, day.Low, temperature, day.PrecipitationChance)
+
+		line("BEGIN", "VEVENT")
+		line("UID", fmt.Sprintf("%s-%s@weather", date.Format("20060102"), loc.code()))
+		line("DTSTAMP", stamp)
+		line("DTSTART;VALUE=DATE", date.Format("20060102"))
+		line("DTEND;VALUE=DATE", date.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY", escapeCalendarText(summary))
+		line("DESCRIPTION", escapeCalendarText(description))
+		line("LOCATION", escapeCalendarText(forecast.Location))
+		// Weather shouldn't show the user as busy
+		line("TRANSP", "TRANSPARENT")
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return b.String()
+}
+
+// escapeCalendarText escapes an iCalendar TEXT value
+var escapeCalendarText = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace
+
+// foldCalendarLine ends a content line with CRLF, folding it into lines of at
+// most 75 octets without splitting UTF-8 characters
+func foldCalendarLine(line string) string {
+	const limit = 75
+	var b strings.Builder
+	width := 0
+	for _, c := range line {
+		size := len(string(c))
+		if width+size > limit {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(c)
+		width += size
+	}
+	b.WriteString("\r\n")
+	return b.String()
+}
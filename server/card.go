@@ -0,0 +1,320 @@
+package server
+
+import (
+	"cmp"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// Weather card size, the resolution of common 7.5" e-ink displays
+const (
+	cardWidth  = 800
+	cardHeight = 480
+)
+
+// cardTheme is a weather card's colors
+type cardTheme struct {
+	background, text, muted, divider  color.Color
+	sun, cloud, darkCloud, rain, snow color.Color
+	gray                              bool // encode the card in grayscale
+}
+
+// Weather card themes, by the theme parameter. The mono theme is black on
+// white, for e-ink displays.
+var cardThemes = map[string]cardTheme{
+	"light": {
+		background: color.White,
+		text:       color.RGBA{0x1d, 0x27, 0x30, 0xff},
+		muted:      color.RGBA{0x60, 0x70, 0x80, 0xff},
+		divider:    color.RGBA{0xdd, 0xe2, 0xe8, 0xff},
+		sun:        color.RGBA{0xf5, 0xb7, 0x00, 0xff},
+		cloud:      color.RGBA{0xa9, 0xb4, 0xc0, 0xff},
+		darkCloud:  color.RGBA{0x6b, 0x77, 0x85, 0xff},
+		rain:       color.RGBA{0x33, 0x7a, 0xb7, 0xff},
+		snow:       color.RGBA{0x5b, 0xa4, 0xcf, 0xff},
+	},
+	"mono": {
+		background: color.White,
+		text:       color.Black,
+		muted:      color.Black,
+		divider:    color.Black,
+		sun:        color.Black,
+		cloud:      color.Black,
+		darkCloud:  color.Black,
+		rain:       color.Black,
+		snow:       color.Black,
+		gray:       true,
+	},
+}
+
+// The card's typefaces, parsed once
+var cardFonts = sync.OnceValues(func() (*opentype.Font, *opentype.Font) {
+	regular, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		panic(err)
+	}
+	bold, err := opentype.Parse(gobold.TTF)
+	if err != nil {
+		panic(err)
+	}
+	return regular, bold
+})
+
+// Weather card handler using Chi. Cards are fetched by chat unfurlers and
+// displays that can't send credentials, so like badges they're served without
+// client authentication; lookups go through the weather cache.
+func (s *Server) cardHandler(w http.ResponseWriter, r *http.Request) {
+	opts, ok := s.optionsFromRequest(w, r)
+	if !ok {
+		return
+	}
+	theme, ok := cardThemes[cmp.Or(r.URL.Query().Get("theme"), "light")]
+	if !ok {
+		writeError(w, http.StatusBadRequest, "theme must be one of: light, mono")
+		return
+	}
+
+	loc, err := s.zipCodeLocation(chi.URLParam(r, "zip"), r.URL.Query().Get("country"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	weather, err := s.weather.CurrentWeather(r.Context(), loc, opts)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	// The forecast strip is left out when the forecast can't be looked up
+	forecast, err := s.weather.Forecast(r.Context(), loc, opts)
+	if err != nil {
+		debugf("weather card for %s without a forecast: %v", loc.ZipCode, err)
+		forecast = nil
+	}
+
+	var card image.Image = renderCard(*weather, forecast, theme)
+	if theme.gray {
+		gray := image.NewGray(card.Bounds())
+		draw.Draw(gray, gray.Bounds(), card, image.Point{}, draw.Src)
+		card = gray
+	}
+	w.Header().Set("Content-Type", "image/png")
+	setCacheStatusHeader(w, weather)
+	w.WriteHeader(http.StatusOK)
+	png.Encode(w, card)
+}
+
+// renderCard draws current conditions and, when there is one, a strip of the
+// forecast's days
+func renderCard(weather WeatherResponse, forecast *ForecastResponse, theme cardTheme) *image.RGBA {
+	card := image.NewRGBA(image.Rect(0, 0, cardWidth, cardHeight))
+	draw.Draw(card, card.Bounds(), image.NewUniform(theme.background), image.Point{}, draw.Src)
+	regular, bold := cardFonts()
+	temperature, speed := unitSymbols(weather.Units)
+
+	drawText(card, bold, 40, theme.text, 40, 72, weather.Location)
+	drawText(card, regular, 26, theme.muted, 40, 112, capitalize(weather.Description))
+	drawText(card, bold, 120, theme.text, 34, 250, fmt.Sprintf("%.0f%s", weather.Temperature, temperature))
+	drawText(card, regular, 22, theme.muted, 40, 300, fmt.Sprintf("Feels like %.0f%s · Humidity %d%% · Wind %.0f %s",
+		weather.FeelsLike, temperature, weather.Humidity, weather.WindSpeed, speed))
+	drawIcon(card, weather.Icon, theme, 540, 40, 210)
+
+	if forecast == nil || len(forecast.Days) == 0 {
+		return card
+	}
+	draw.Draw(card, image.Rect(40, 330, cardWidth-40, 332), image.NewUniform(theme.divider), image.Point{}, draw.Src)
+	columnWidth := (cardWidth - 80) / len(forecast.Days)
+	for i, day := range forecast.Days {
+		center := 40 + i*columnWidth + columnWidth/2
+		name := day.Date
+		if parsed, err := time.Parse(time.DateOnly, day.Date); err == nil {
+			name = parsed.Format("Mon")
+		}
+		drawCenteredText(card, bold, 22, theme.text, center, 368, name)
+		drawIcon(card, day.Icon, theme, center-32, 378, 64)
+		drawCenteredText(card, regular, 22, theme.muted, center, 466, fmt.Sprintf("%.0f° / %.0f°", day.High, day.Low))
+	}
+	return card
+}
+
+// capitalize upper-cases the first letter of a description
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// newFace returns a font face of a size in pixels
+func newFace(f *opentype.Font, size float64) font.Face {
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		panic(err)
+	}
+	return face
+}
+
+// drawText draws text with its baseline starting at x, y
+func drawText(dst draw.Image, f *opentype.Font, size float64, c color.Color, x, y int, text string) {
+	d := font.Drawer{Dst: dst, Src: image.NewUniform(c), Face: newFace(f, size), Dot: fixed.P(x, y)}
+	d.DrawString(text)
+}
+
+// drawCenteredText draws text centered on x, with its baseline at y
+func drawCenteredText(dst draw.Image, f *opentype.Font, size float64, c color.Color, x, y int, text string) {
+	d := font.Drawer{Dst: dst, Src: image.NewUniform(c), Face: newFace(f, size)}
+	d.Dot = fixed.P(x-d.MeasureString(text).Round()/2, y)
+	d.DrawString(text)
+}
+
+// iconPainter draws shapes in a square icon, in coordinates from 0 to 1
+type iconPainter struct {
+	dst     draw.Image
+	x, y    float32 // top left corner
+	size    float32
+	theme   cardTheme
+	outline float32 // width of the background around clouds, so they stand out from what's behind
+}
+
+// fill paints the shapes a path function adds to a rasterizer
+func (p iconPainter) fill(c color.Color, path func(z *vector.Rasterizer)) {
+	bounds := p.dst.Bounds()
+	z := vector.NewRasterizer(bounds.Dx(), bounds.Dy())
+	path(z)
+	z.Draw(p.dst, bounds, image.NewUniform(c), image.Point{})
+}
+
+// circle adds a circle to z, centered on cx, cy
+func (p iconPainter) circle(z *vector.Rasterizer, cx, cy, r float32) {
+	// Four cubic Bézier curves, each a quarter circle
+	const k = 0.5523
+	x, y, r := p.x+cx*p.size, p.y+cy*p.size, r*p.size
+	z.MoveTo(x+r, y)
+	z.CubeTo(x+r, y+k*r, x+k*r, y+r, x, y+r)
+	z.CubeTo(x-k*r, y+r, x-r, y+k*r, x-r, y)
+	z.CubeTo(x-r, y-k*r, x-k*r, y-r, x, y-r)
+	z.CubeTo(x+k*r, y-r, x+r, y-k*r, x+r, y)
+	z.ClosePath()
+}
+
+// polygon adds a closed polygon to z
+func (p iconPainter) polygon(z *vector.Rasterizer, points ...[2]float32) {
+	z.MoveTo(p.x+points[0][0]*p.size, p.y+points[0][1]*p.size)
+	for _, point := range points[1:] {
+		z.LineTo(p.x+point[0]*p.size, p.y+point[1]*p.size)
+	}
+	z.ClosePath()
+}
+
+// line adds a line of a width from x1, y1 to x2, y2 to z
+func (p iconPainter) line(z *vector.Rasterizer, x1, y1, x2, y2, width float32) {
+	length := float32(math.Hypot(float64(x2-x1), float64(y2-y1)))
+	nx, ny := -(y2-y1)/length*width/2, (x2-x1)/length*width/2
+	p.polygon(z, [2]float32{x1 + nx, y1 + ny}, [2]float32{x2 + nx, y2 + ny}, [2]float32{x2 - nx, y2 - ny}, [2]float32{x1 - nx, y1 - ny})
+}
+
+// sun draws a sun with rays, centered on cx, cy
+func (p iconPainter) sun(cx, cy, r float32) {
+	p.fill(p.theme.sun, func(z *vector.Rasterizer) {
+		p.circle(z, cx, cy, r)
+		for i := 0; i < 8; i++ {
+			angle := float64(i) * math.Pi / 4
+			sin, cos := float32(math.Sin(angle)), float32(math.Cos(angle))
+			p.line(z, cx+cos*r*1.35, cy+sin*r*1.35, cx+cos*r*1.75, cy+sin*r*1.75, r*0.18)
+		}
+	})
+}
+
+// moon draws a crescent moon, centered on cx, cy
+func (p iconPainter) moon(cx, cy, r float32) {
+	p.fill(p.theme.sun, func(z *vector.Rasterizer) { p.circle(z, cx, cy, r) })
+	p.fill(p.theme.background, func(z *vector.Rasterizer) { p.circle(z, cx+r*0.45, cy-r*0.35, r*0.85) })
+}
+
+// cloud draws a cloud whose base spans x1 to x2 at y
+func (p iconPainter) cloud(c color.Color, x1, x2, y float32) {
+	shape := func(grow float32) func(z *vector.Rasterizer) {
+		return func(z *vector.Rasterizer) {
+			w := x2 - x1
+			h := w * 0.22
+			p.circle(z, x1+h, y-h, h+grow)
+			p.circle(z, x1+w*0.42, y-h*1.55, h*1.45+grow)
+			p.circle(z, x2-w*0.26, y-h*1.2, h*1.1+grow)
+			p.circle(z, x2-h, y-h, h+grow)
+			p.polygon(z, [2]float32{x1 + h, y - h*1.2 - grow}, [2]float32{x2 - h, y - h*1.2 - grow}, [2]float32{x2 - h, y + grow}, [2]float32{x1 + h, y + grow})
+		}
+	}
+	p.fill(p.theme.background, shape(p.outline))
+	p.fill(c, shape(0))
+}
+
+// drawIcon draws an icon for an OpenWeatherMap icon code in a square at x, y
+func drawIcon(dst draw.Image, icon string, theme cardTheme, x, y, size int) {
+	p := iconPainter{dst: dst, x: float32(x), y: float32(y), size: float32(size), theme: theme, outline: 0.03}
+	night := strings.HasSuffix(icon, "n")
+	celestial := func(cx, cy, r float32) {
+		if night {
+			p.moon(cx, cy, r*0.85)
+		} else {
+			p.sun(cx, cy, r)
+		}
+	}
+
+	switch strings.TrimRight(icon, "dn") {
+	case "02":
+		celestial(0.38, 0.38, 0.18)
+		p.cloud(theme.cloud, 0.25, 0.92, 0.8)
+	case "03":
+		p.cloud(theme.cloud, 0.1, 0.9, 0.75)
+	case "04":
+		p.cloud(theme.cloud, 0.28, 0.95, 0.58)
+		p.cloud(theme.darkCloud, 0.05, 0.8, 0.82)
+	case "09", "10":
+		if icon[:2] == "10" {
+			celestial(0.35, 0.3, 0.16)
+		}
+		p.cloud(theme.darkCloud, 0.1, 0.9, 0.65)
+		p.fill(theme.rain, func(z *vector.Rasterizer) {
+			for _, dx := range []float32{0.3, 0.5, 0.7} {
+				p.line(z, dx, 0.74, dx-0.06, 0.92, 0.05)
+			}
+		})
+	case "11":
+		p.cloud(theme.darkCloud, 0.1, 0.9, 0.6)
+		p.fill(theme.sun, func(z *vector.Rasterizer) {
+			p.polygon(z, [2]float32{0.52, 0.6}, [2]float32{0.36, 0.8}, [2]float32{0.48, 0.8}, [2]float32{0.42, 0.97},
+				[2]float32{0.64, 0.72}, [2]float32{0.52, 0.72}, [2]float32{0.6, 0.6})
+		})
+	case "13":
+		p.cloud(theme.cloud, 0.1, 0.9, 0.65)
+		p.fill(theme.snow, func(z *vector.Rasterizer) {
+			for _, flake := range [][2]float32{{0.3, 0.78}, {0.5, 0.85}, {0.7, 0.78}, {0.4, 0.93}, {0.6, 0.93}} {
+				p.circle(z, flake[0], flake[1], 0.035)
+			}
+		})
+	case "50":
+		p.fill(theme.cloud, func(z *vector.Rasterizer) {
+			for i, bar := range [][2]float32{{0.15, 0.8}, {0.25, 0.9}, {0.1, 0.75}, {0.2, 0.85}} {
+				y := 0.3 + float32(i)*0.14
+				p.line(z, bar[0], y, bar[1], y, 0.07)
+			}
+		})
+	default:
+		celestial(0.5, 0.5, 0.24)
+	}
+}
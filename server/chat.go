@@ -0,0 +1,172 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// slackNotifier posts alerts to a Slack incoming webhook, the subscription's
+// callback URL, as Block Kit messages
+type slackNotifier struct {
+	s *Server
+}
+
+func (n slackNotifier) available() error { return nil }
+
+func (n slackNotifier) validate(sub *Subscription) error {
+	return n.s.validateCallbackURL(sub.CallbackURL)
+}
+
+func (n slackNotifier) notify(sub Subscription, alert SubscriptionAlert) (bool, error) {
+	body, err := json.Marshal(slackMessage(alert))
+	if err != nil {
+		return false, err
+	}
+	return n.s.postWebhook(sub.CallbackURL, body, chatHeaders())
+}
+
+// Slack's mrkdwn needs these characters escaped
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// SlackMessage is the body posted to a Slack incoming webhook (simplified)
+type SlackMessage struct {
+	Text   string       `json:"text"`
+	Blocks []SlackBlock `json:"blocks"`
+}
+
+// SlackBlock is a Block Kit header, section, or context block
+type SlackBlock struct {
+	Type     string      `json:"type"`
+	Text     *SlackText  `json:"text,omitempty"`
+	Elements []SlackText `json:"elements,omitempty"`
+}
+
+// SlackText is plain or mrkdwn text in a block
+type SlackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// slackMessage builds the Slack message for an alert. The text is what
+// notifications show; the blocks are what the channel does.
+func slackMessage(alert SubscriptionAlert) SlackMessage {
+	w := alert.Weather
+	details := fmt.Sprintf("%s\n\n*Current conditions in %s*\n%s", slackEscaper.Replace(alertSummary(alert)),
+		slackEscaper.Replace(w.Location), slackEscaper.Replace(strings.Join(weatherLines(w), "\n")))
+	msg := SlackMessage{
+		Text: slackEscaper.Replace(alertHeading(alert) + ": " + alert.Condition),
+		Blocks: []SlackBlock{
+			{Type: "header", Text: &SlackText{Type: "plain_text", Text: alertHeading(alert)}},
+			{Type: "section", Text: &SlackText{Type: "mrkdwn", Text: details}},
+		},
+	}
+	if len(alert.SevereAlerts) > 0 {
+		lines := []string{"*Severe weather alerts in effect*"}
+		for _, severe := range alert.SevereAlerts {
+			lines = append(lines, slackEscaper.Replace(fmt.Sprintf("• %s: %s", severe.Event, severe.Headline)))
+		}
+		msg.Blocks = append(msg.Blocks, SlackBlock{Type: "section", Text: &SlackText{Type: "mrkdwn", Text: strings.Join(lines, "\n")}})
+	}
+	msg.Blocks = append(msg.Blocks, SlackBlock{Type: "context", Elements: []SlackText{
+		{Type: "mrkdwn", Text: fmt.Sprintf("Subscription %s, checked at %s", alert.SubscriptionID, alert.At)},
+	}})
+	return msg
+}
+
+// teamsNotifier posts alerts to a Microsoft Teams incoming webhook or
+// Workflows webhook, the subscription's callback URL, as Adaptive Cards
+type teamsNotifier struct {
+	s *Server
+}
+
+func (n teamsNotifier) available() error { return nil }
+
+func (n teamsNotifier) validate(sub *Subscription) error {
+	return n.s.validateCallbackURL(sub.CallbackURL)
+}
+
+func (n teamsNotifier) notify(sub Subscription, alert SubscriptionAlert) (bool, error) {
+	body, err := json.Marshal(teamsMessage(alert))
+	if err != nil {
+		return false, err
+	}
+	return n.s.postWebhook(sub.CallbackURL, body, chatHeaders())
+}
+
+// TeamsMessage is the body posted to a Teams webhook: a message with one
+// Adaptive Card attached (simplified)
+type TeamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []TeamsAttachment `json:"attachments"`
+}
+
+// TeamsAttachment attaches an Adaptive Card to a Teams message
+type TeamsAttachment struct {
+	ContentType string    `json:"contentType"`
+	Content     TeamsCard `json:"content"`
+}
+
+// TeamsCard is an Adaptive Card of text blocks
+type TeamsCard struct {
+	Schema  string           `json:"$schema"`
+	Type    string           `json:"type"`
+	Version string           `json:"version"`
+	Body    []TeamsTextBlock `json:"body"`
+}
+
+// TeamsTextBlock is a line of text on an Adaptive Card
+type TeamsTextBlock struct {
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	Size     string `json:"size,omitempty"`
+	Weight   string `json:"weight,omitempty"`
+	Color    string `json:"color,omitempty"`
+	Spacing  string `json:"spacing,omitempty"`
+	IsSubtle bool   `json:"isSubtle,omitempty"`
+	Wrap     bool   `json:"wrap"`
+}
+
+// teamsMessage builds the Teams message for an alert: an Adaptive Card
+// headed in the attention color when the condition is met
+func teamsMessage(alert SubscriptionAlert) TeamsMessage {
+	w := alert.Weather
+	heading := TeamsTextBlock{Type: "TextBlock", Text: alertHeading(alert), Size: "Large", Weight: "Bolder", Wrap: true}
+	if alert.Event == "triggered" {
+		heading.Color = "Attention"
+	}
+	body := []TeamsTextBlock{
+		heading,
+		{Type: "TextBlock", Text: alertSummary(alert), Wrap: true},
+		{Type: "TextBlock", Text: "Current conditions in " + w.Location, Weight: "Bolder", Wrap: true},
+	}
+	for _, line := range weatherLines(w) {
+		body = append(body, TeamsTextBlock{Type: "TextBlock", Text: line, Spacing: "None", Wrap: true})
+	}
+	if len(alert.SevereAlerts) > 0 {
+		body = append(body, TeamsTextBlock{Type: "TextBlock", Text: "Severe weather alerts in effect", Weight: "Bolder", Color: "Attention", Wrap: true})
+		for _, severe := range alert.SevereAlerts {
+			body = append(body, TeamsTextBlock{Type: "TextBlock", Text: severe.Event + ": " + severe.Headline, Spacing: "None", Wrap: true})
+		}
+	}
+	body = append(body, TeamsTextBlock{Type: "TextBlock", Text: fmt.Sprintf("Subscription %s, checked at %s", alert.SubscriptionID, alert.At), Size: "Small", IsSubtle: true, Wrap: true})
+
+	return TeamsMessage{
+		Type: "message",
+		Attachments: []TeamsAttachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content: TeamsCard{
+				Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+				Type:    "AdaptiveCard",
+				Version: "1.4",
+				Body:    body,
+			},
+		}},
+	}
+}
+
+// chatHeaders are the headers of Slack and Teams deliveries, which aren't signed
+func chatHeaders() http.Header {
+	return http.Header{"User-Agent": {"weather-server/" + buildInfo().Version}}
+}
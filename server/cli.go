@@ -0,0 +1,188 @@
+package server
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Command-line help
+const usageText = `Usage: weather-server [command] [flags]
+
+Commands:
+  serve          Run the API server (the default when no command is given)
+  check-config   Validate the configuration and exit
+  migrate        Apply (up, the default), roll back one (down), or list (status)
+                 the schema migrations of the sqlite or postgres store
+  version        Print version information and exit
+  help           Show this help
+
+Flags for serve, check-config, and migrate:
+  --config FILE  YAML or TOML configuration file; environment variables take precedence
+`
+
+// Main runs the weather-server command with the given arguments (without the
+// program name), exiting the process when a command fails
+func Main(args []string) {
+	command := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	switch command {
+	case "serve":
+		serveCommand(args)
+	case "check-config":
+		checkConfigCommand(args)
+	case "migrate":
+		migrateCommand(args)
+	case "version":
+		fmt.Println(versionString())
+	case "help":
+		printUsage(os.Stdout)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", command)
+		printUsage(os.Stderr)
+		os.Exit(2)
+	}
+}
+
+// printUsage writes the command-line help
+func printUsage(w io.Writer) {
+	fmt.Fprint(w, usageText)
+}
+
+// parseConfigFlags parses a command's flags, returning the options that load
+// the --config file, if any
+func parseConfigFlags(command string, args []string) ([]Option, error) {
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	flags.Usage = func() { printUsage(os.Stderr) }
+	configPath := flags.String("config", "", "YAML or TOML configuration file")
+	flags.Parse(args)
+
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+	if *configPath != "" {
+		return []Option{WithConfigFile(*configPath)}, nil
+	}
+	return nil, nil
+}
+
+// setup loads and validates the configuration, then prepares the upstream
+// client, cache, circuit breakers, and CORS policy for it
+func (s *Server) setup() (*Config, error) {
+	c, err := loadConfig(s.env)
+	if err != nil {
+		return nil, err
+	}
+	s.activeConfig.Store(c)
+	logLevel.Store(c.LogLevel)
+
+	s.setupUpstream(c)
+	s.setupCache(c)
+	s.setupCircuitBreakers(c)
+	s.setupLocations(c)
+	s.setupCORS(c)
+	return c, nil
+}
+
+// checkConfigCommand validates the configuration without starting the server,
+// printing a summary of the effective settings. It exits with status 1 when
+// the configuration is invalid.
+func checkConfigCommand(args []string) {
+	opts, err := parseConfigFlags("check-config", args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration: %v\n", err)
+		os.Exit(1)
+	}
+	s, err := newServer(opts...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	c := s.config()
+
+	provider := c.WeatherProvider
+	if provider == "" {
+		provider = "openweathermap"
+	}
+	fallbacks := strings.Join(c.ProviderFallbacks, ", ")
+	if fallbacks == "" {
+		fallbacks = "none"
+	}
+	tlsMode := "off"
+	switch {
+	case c.TLS != nil && c.TLS.autocert != nil:
+		tlsMode = "Let's Encrypt"
+	case c.TLS != nil:
+		tlsMode = "certificate " + c.TLS.certFile
+	}
+
+	fmt.Println("Configuration OK")
+	fmt.Printf("  listen:            %s\n", net.JoinHostPort(c.BindAddr, c.Port))
+	fmt.Printf("  tls:               %s\n", tlsMode)
+	switch {
+	case c.AdminPort == "":
+		fmt.Printf("  admin listener:    same as listen\n")
+	case c.AdminClientCAs != nil:
+		fmt.Printf("  admin listener:    %s (client certificates from %s)\n", net.JoinHostPort(c.BindAddr, c.AdminPort), c.AdminClientCA)
+	default:
+		fmt.Printf("  admin listener:    %s\n", net.JoinHostPort(c.BindAddr, c.AdminPort))
+	}
+	if c.GRPCPort == "" {
+		fmt.Printf("  grpc listener:     off\n")
+	} else {
+		fmt.Printf("  grpc listener:     %s\n", net.JoinHostPort(c.BindAddr, c.GRPCPort))
+	}
+	fmt.Printf("  mock mode:         %t\n", c.MockMode)
+	fmt.Printf("  upstream mode:     %s\n", c.UpstreamMode)
+	fmt.Printf("  provider:          %s\n", provider)
+	fmt.Printf("  enabled providers: %s\n", strings.Join(s.enabledProviders(), ", "))
+	fmt.Printf("  fallbacks:         %s\n", fallbacks)
+	fmt.Printf("  cache:             %s (ttl %s, stale %s)\n", c.CacheBackend, c.CacheTTL, c.CacheStaleTTL)
+	if c.StoreBackend == "postgres" {
+		fmt.Printf("  store:             postgres %s (pool of %d)\n", databaseDescription(c.DatabaseURL), c.DatabaseMaxConns)
+	} else if c.StoreBackend != "memory" {
+		fmt.Printf("  store:             %s %s\n", c.StoreBackend, c.StorePath)
+	} else {
+		fmt.Printf("  store:             %s\n", c.StoreBackend)
+	}
+	fmt.Printf("  schedule:          %s\n", describeSchedule(c.Schedule))
+	if c.MQTTBrokerURL != "" {
+		fmt.Printf("  mqtt:              %s (%s, %s)\n", mqttBrokerDescription(c.MQTTBrokerURL), mqttTopic(c, Location{ZipCode: "{zip}"}), c.MQTTUnits)
+	} else {
+		fmt.Printf("  mqtt:              off\n")
+	}
+	if c.EventBus != "" {
+		fmt.Printf("  event bus:         %s %s (%s)\n", c.EventBus, eventBusDescription(c.EventBusURL), c.EventTopic)
+	} else {
+		fmt.Printf("  event bus:         off\n")
+	}
+	if c.SMTPHost != "" {
+		fmt.Printf("  email alerts:      %s from %s\n", net.JoinHostPort(c.SMTPHost, strconv.Itoa(c.SMTPPort)), c.SMTPFrom)
+	} else {
+		fmt.Printf("  email alerts:      off\n")
+	}
+	fmt.Printf("  zip codes:         %d (%s)\n", len(c.ZipCodeDB), cmp.Or(c.ZipCodeDBFile, "built-in"))
+	fmt.Printf("  api keys:          %d\n", len(c.APIKeys))
+	fmt.Printf("  jwt:               %s\n", c.JWT.description())
+	fmt.Printf("  cors origins:      %s (credentials %t)\n", strings.Join(c.CORSAllowedOrigins, ", "), c.CORSAllowCredentials)
+	fmt.Printf("  hsts:              %s\n", cmp.Or(c.HSTS, "off"))
+	fmt.Printf("  rate limits:       %s\n", describeRateLimits(c.RateLimits))
+	if c.IPRateLimit.requests > 0 {
+		fmt.Printf("  ip rate limit:     %s (%d allowlisted networks)\n", c.IPRateLimit, len(c.IPRateLimitAllowlist))
+	} else {
+		fmt.Printf("  ip rate limit:     none\n")
+	}
+	fmt.Printf("  trusted proxies:   %d networks\n", len(c.TrustedProxies))
+
+	if !c.openWeatherMapAvailable() {
+		fmt.Fprintln(os.Stderr, "warning: OPENWEATHER_API_KEY is not set, so endpoints backed only by OpenWeatherMap (forecasts other providers don't cover, air quality, UV, history, astronomy, and zip codes missing from the zip code database) respond with 501 Not Implemented")
+	}
+}
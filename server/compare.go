@@ -0,0 +1,108 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Maximum number of locations in a single comparison
+const maxCompareLocations = 10
+
+// CompareResponse represents a side-by-side comparison of several locations
+type CompareResponse struct {
+	Units     string               `json:"units"`
+	Locations []BatchWeatherResult `json:"locations"`
+	Summary   CompareSummary       `json:"summary"`
+}
+
+// CompareSummary highlights the extremes across the compared locations.
+// Locations whose lookup failed are left out of the summary.
+type CompareSummary struct {
+	Warmest            *CompareExtreme `json:"warmest,omitempty"`
+	Coldest            *CompareExtreme `json:"coldest,omitempty"`
+	Windiest           *CompareExtreme `json:"windiest,omitempty"`
+	AverageTemperature float64         `json:"average_temperature"`
+	TemperatureSpread  float64         `json:"temperature_spread"`
+}
+
+// CompareExtreme identifies the location with the most extreme value of a measurement
+type CompareExtreme struct {
+	ZipCode  string  `json:"zip_code"`
+	Location string  `json:"location"`
+	Value    float64 `json:"value"`
+}
+
+// summarizeComparison computes the warmest, coldest, and windiest locations
+func summarizeComparison(results []BatchWeatherResult) CompareSummary {
+	var summary CompareSummary
+	var total float64
+	var count int
+
+	for _, result := range results {
+		weather := result.Weather
+		if weather == nil {
+			continue
+		}
+
+		extreme := func(value float64) *CompareExtreme {
+			return &CompareExtreme{ZipCode: result.ZipCode, Location: weather.Location, Value: value}
+		}
+		if summary.Warmest == nil || weather.Temperature > summary.Warmest.Value {
+			summary.Warmest = extreme(weather.Temperature)
+		}
+		if summary.Coldest == nil || weather.Temperature < summary.Coldest.Value {
+			summary.Coldest = extreme(weather.Temperature)
+		}
+		if summary.Windiest == nil || weather.WindSpeed > summary.Windiest.Value {
+			summary.Windiest = extreme(weather.WindSpeed)
+		}
+
+		total += weather.Temperature
+		count++
+	}
+
+	if count > 0 {
+		summary.AverageTemperature = round1(total / float64(count))
+		summary.TemperatureSpread = round1(summary.Warmest.Value - summary.Coldest.Value)
+	}
+	return summary
+}
+
+// Compare handler using Chi
+func (s *Server) compareHandler(w http.ResponseWriter, r *http.Request) {
+	// Get comma-separated zip codes from query parameter
+	value := r.URL.Query().Get("zip_codes")
+	if value == "" {
+		writeError(w, http.StatusBadRequest, "zip_codes parameter is required")
+		return
+	}
+
+	var zipCodes []string
+	for _, zipCode := range strings.Split(value, ",") {
+		zipCodes = append(zipCodes, strings.TrimSpace(zipCode))
+	}
+
+	if len(zipCodes) < 2 || len(zipCodes) > maxCompareLocations {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("zip_codes must list between 2 and %d zip codes", maxCompareLocations))
+		return
+	}
+
+	// Get units and language from query parameters
+	opts, ok := s.optionsFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	// Look up all locations concurrently, then aggregate
+	results := s.getWeatherBatch(r.Context(), zipCodes, opts)
+
+	// Return comparison as JSON
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(CompareResponse{
+		Units:     opts.units(),
+		Locations: results,
+		Summary:   summarizeComparison(results),
+	})
+}
@@ -0,0 +1,154 @@
+package server
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Most comparisons a subscription condition may combine
+const maxConditionComparisons = 10
+
+// conditionReadings are what a condition is checked against: the current
+// weather, and the severe alerts in effect when the condition asks for them
+type conditionReadings struct {
+	weather      *WeatherResponse
+	severeAlerts []SevereAlert
+}
+
+// The reading that counts severe alerts, which need their own lookup
+const severeAlertsField = "severe_alerts"
+
+// conditionFields are the readings a condition can compare
+var conditionFields = map[string]func(r *conditionReadings) float64{
+	"temperature":     func(r *conditionReadings) float64 { return r.weather.Temperature },
+	"feels_like":      func(r *conditionReadings) float64 { return r.weather.FeelsLike },
+	"dew_point":       func(r *conditionReadings) float64 { return r.weather.DewPoint },
+	"humidity":        func(r *conditionReadings) float64 { return float64(r.weather.Humidity) },
+	"pressure":        func(r *conditionReadings) float64 { return float64(r.weather.Pressure) },
+	"visibility":      func(r *conditionReadings) float64 { return float64(r.weather.Visibility) },
+	"cloud_cover":     func(r *conditionReadings) float64 { return float64(r.weather.CloudCover) },
+	"wind_speed":      func(r *conditionReadings) float64 { return r.weather.WindSpeed },
+	"wind_gust":       func(r *conditionReadings) float64 { return r.weather.WindGust },
+	"wind_direction":  func(r *conditionReadings) float64 { return float64(r.weather.WindDirection) },
+	severeAlertsField: func(r *conditionReadings) float64 { return float64(len(r.severeAlerts)) },
+}
+
+// A comparison is written field, operator, number, e.g. "wind_speed >= 25"
+var comparisonRegex = regexp.MustCompile(`^([a-z_]+)\s*(<=|>=|==|!=|<|>)\s*(-?\d+(?:\.\d+)?)$`)
+
+// Words joining comparisons; "and" binds tighter than "or"
+var (
+	orRegex  = regexp.MustCompile(`(?i)\s+or\s+`)
+	andRegex = regexp.MustCompile(`(?i)\s+and\s+`)
+)
+
+// comparison tests one weather reading against a number
+type comparison struct {
+	field string
+	op    string
+	value float64
+}
+
+func (c comparison) matches(readings *conditionReadings) bool {
+	reading := conditionFields[c.field](readings)
+	switch c.op {
+	case "<":
+		return reading < c.value
+	case "<=":
+		return reading <= c.value
+	case ">":
+		return reading > c.value
+	case ">=":
+		return reading >= c.value
+	case "==":
+		return reading == c.value
+	default:
+		return reading != c.value
+	}
+}
+
+func (c comparison) String() string {
+	return c.field + " " + c.op + " " + strconv.FormatFloat(c.value, 'f', -1, 64)
+}
+
+// condition is a subscription's rule: it matches when every comparison of any
+// one of its alternatives does
+type condition [][]comparison
+
+// parseCondition parses a rule such as "temperature < 32" or
+// "wind_speed > 30 or wind_gust > 45 and humidity > 80" or "severe_alerts > 0"
+func parseCondition(text string) (condition, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("condition is required, e.g. \"temperature < 32\"")
+	}
+
+	var cond condition
+	count := 0
+	for _, alternative := range orRegex.Split(text, -1) {
+		var all []comparison
+		for _, part := range andRegex.Split(alternative, -1) {
+			match := comparisonRegex.FindStringSubmatch(strings.TrimSpace(part))
+			if match == nil {
+				return nil, fmt.Errorf("invalid condition %q: comparisons must be written field operator number, e.g. \"temperature < 32\"", part)
+			}
+			if _, ok := conditionFields[match[1]]; !ok {
+				return nil, fmt.Errorf("invalid condition field %q: must be one of: %s", match[1], strings.Join(conditionFieldNames(), ", "))
+			}
+			value, _ := strconv.ParseFloat(match[3], 64)
+			all = append(all, comparison{field: match[1], op: match[2], value: value})
+			count++
+		}
+		cond = append(cond, all)
+	}
+	if count > maxConditionComparisons {
+		return nil, fmt.Errorf("condition may combine at most %d comparisons", maxConditionComparisons)
+	}
+	return cond, nil
+}
+
+// matches reports whether the readings meet the rule
+func (cond condition) matches(readings *conditionReadings) bool {
+	return slices.ContainsFunc(cond, func(all []comparison) bool {
+		for _, c := range all {
+			if !c.matches(readings) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// uses reports whether the rule compares a reading
+func (cond condition) uses(field string) bool {
+	return slices.ContainsFunc(cond, func(all []comparison) bool {
+		return slices.ContainsFunc(all, func(c comparison) bool { return c.field == field })
+	})
+}
+
+// String writes the rule in the normalized form it is stored in
+func (cond condition) String() string {
+	alternatives := make([]string, len(cond))
+	for i, all := range cond {
+		parts := make([]string, len(all))
+		for j, c := range all {
+			parts[j] = c.String()
+		}
+		alternatives[i] = strings.Join(parts, " and ")
+	}
+	return strings.Join(alternatives, " or ")
+}
+
+// conditionFieldNames lists the fields conditions can compare, sorted
+func conditionFieldNames() []string {
+	names := make([]string, 0, len(conditionFields))
+	for name := range conditionFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
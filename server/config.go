@@ -0,0 +1,721 @@
+package server
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds every server setting. It is read from the environment (and any
+// --config file) and validated once at startup, so misconfiguration stops the
+// server before it serves a request.
+type Config struct {
+	// Listener
+	Port            string
+	BindAddr        string
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	IdleTimeout     time.Duration
+	ShutdownTimeout time.Duration
+	RequestTimeout  time.Duration // 0 doesn't limit requests
+	MaxHeaderBytes  int
+	MaxBodyBytes    int64
+	HTTP2           bool
+	HTTP2Cleartext  bool
+	TLS             *serverTLS // nil when TLS is off
+	AdminPort       string     // empty serves /admin on PORT
+	AdminClientCA   string     // ADMIN_CLIENT_CA_FILE, for comparing on reload
+	AdminClientCAs  *x509.CertPool
+	GRPCPort        string // empty doesn't serve gRPC
+
+	// Data sources
+	MockMode           bool
+	OpenWeatherAPIKey  string
+	OpenWeatherBaseURL string // without a trailing slash
+	WeatherAPIKey      string
+	TomorrowAPIKey     string
+	GeoIPURL           string
+	IconBaseURL        string            // with a trailing slash
+	ZipCodeCities      map[string]string // "City,ST,CC" by zip code, for demo data
+	ZipCodeCitiesFile  string
+	ZipCodeDB          map[string]zipCodeRecord // by 5-digit zip code, for demo data and geocoding
+	ZipCodeDBFile      string
+	ZipCodeStrict      bool // reject zip codes that aren't in ZipCodeDB
+
+	// Provider selection and failover
+	WeatherProvider         string
+	ProviderFallbacks       []string
+	EnabledProviders        []string // nil enables every configured provider
+	ProviderTimeout         time.Duration
+	CircuitBreakerThreshold int
+	CircuitBreakerCooldown  time.Duration
+	DailyBudgets            map[string]int64 // by provider; missing means unlimited
+
+	// Upstream calls
+	UpstreamMode    string
+	FixturesDir     string
+	UpstreamTimeout time.Duration
+	UpstreamRetry   retryPolicy
+
+	// Caching
+	CacheBackend      string // "none" when CACHE_TTL is 0
+	CacheTTL          time.Duration
+	CacheStaleTTL     time.Duration
+	CacheWarmZipCodes []string
+	CacheWarmInterval time.Duration
+	CacheControl      map[string]string // by CACHE_CONTROL_<ROUTE> variable name
+
+	// Access
+	APIKeys              map[string]apiKey // by key hash; keys in the store are in storedAPIKeys
+	APIKeysFile          string
+	JWT                  *jwtVerifier         // nil when JWT authentication is off
+	RateLimits           map[string]rateLimit // by client tier; missing means unlimited
+	IPRateLimit          rateLimit            // zero when off
+	IPRateLimitAllowlist []netip.Prefix
+	TrustedProxies       []netip.Prefix   // whose forwarding headers name the client
+	DailyQuotas          map[string]int64 // requests per UTC day by client tier; missing means unlimited
+	UsageFile            string           // where usage is saved; empty keeps it in memory only
+	AdminToken           string
+	LogLevel             int32
+
+	// Cross-origin requests from browsers
+	CORSAllowedOrigins   []string // "*" allows any origin
+	CORSAllowedMethods   []string
+	CORSAllowedHeaders   []string
+	CORSAllowCredentials bool
+	CORSMaxAge           time.Duration // how long browsers may cache a preflight response
+
+	// Security headers on every response; empty ones aren't sent
+	HSTS                    string // Strict-Transport-Security, on HTTPS requests
+	ReferrerPolicy          string
+	ContentSecurityPolicy   string // of API responses
+	UIContentSecurityPolicy string // of the /ui dashboard
+	ContentTypeNosniff      bool   // X-Content-Type-Options: nosniff
+
+	// Storage of per-client data
+	StoreBackend         string
+	StorePath            string        // the file backend's JSON file or the sqlite backend's database file
+	ObservationRetention time.Duration // how long the sqlite and postgres backends keep observations; 0 records none
+	StoreAutoMigrate     bool          // apply database migrations on startup
+
+	// The postgres backend's database and connection pool
+	DatabaseURL             string
+	DatabaseMaxConns        int
+	DatabaseMaxIdleConns    int
+	DatabaseConnMaxLifetime time.Duration
+
+	// Weather alert subscriptions
+	SubscriptionPollInterval    time.Duration // 0 stops checking conditions
+	WebhookAllowPrivateNetworks bool          // allow callback URLs on loopback and private networks
+
+	// Jobs refreshing zip codes on a schedule
+	Schedule []*scheduledJob
+
+	// Most zip codes one /ws connection may subscribe to
+	WebSocketMaxSubscriptions int
+
+	// Answer curl, Wget, and HTTPie with text when they don't ask for a format
+	CLIPlainText bool
+
+	// When the unversioned /weather route will be removed, for its Sunset header
+	UnversionedSunset time.Time
+
+	// The MQTT broker refreshed observations are published to; nothing is
+	// published without MQTTBrokerURL
+	MQTTBrokerURL   string
+	MQTTClientID    string
+	MQTTUsername    string
+	MQTTPassword    string
+	MQTTTopicPrefix string
+	MQTTQoS         int
+	MQTTRetain      bool
+	MQTTUnits       string // observations in other units aren't published
+
+	// The event bus observations and alerts are sent to as CloudEvents
+	EventBus    string // kafka, nats, or empty for none
+	EventBusURL string // Kafka brokers or NATS server URLs, comma-separated
+	EventTopic  string
+	EventSource string
+
+	// The SMTP server email alerts are sent through; email subscriptions need SMTPHost
+	SMTPHost     string
+	SMTPPort     int // 465 connects with TLS, other ports upgrade with STARTTLS when offered
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+}
+
+// config returns the settings the server is running with
+func (s *Server) config() *Config {
+	return s.activeConfig.Load()
+}
+
+// ConfigError lists every problem found in the configuration
+type ConfigError struct {
+	Problems []string
+}
+
+func (e *ConfigError) Error() string {
+	return "invalid configuration:\n  " + strings.Join(e.Problems, "\n  ")
+}
+
+// loadConfig reads and validates the configuration from the environment. It
+// reports every problem at once in a *ConfigError rather than stopping at the first.
+func loadConfig(env *environment) (*Config, error) {
+	var problems []string
+	check := func(err error) {
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	duration := func(name string, fallback time.Duration) time.Duration {
+		value, err := durationFromEnv(env, name, fallback)
+		check(err)
+		return value
+	}
+	boolean := func(name string, fallback bool) bool {
+		value, err := boolFromEnv(env, name, fallback)
+		check(err)
+		return value
+	}
+	integer := func(name string, fallback, minimum int, rule string) int {
+		value := env.Getenv(name)
+		if value == "" {
+			return fallback
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < minimum {
+			check(fmt.Errorf("invalid %s %q: must be a %s", name, value, rule))
+			return fallback
+		}
+		return parsed
+	}
+	header := func(name, fallback string) string {
+		if value := stringFromEnv(env, name, fallback); value != "off" {
+			return value
+		}
+		return ""
+	}
+	list := func(name string, fallback []string) []string {
+		if items := listFromEnv(env, name); items != nil {
+			return items
+		}
+		return fallback
+	}
+
+	c := &Config{
+		Port:            stringFromEnv(env, "PORT", "8080"),
+		BindAddr:        env.Getenv("BIND_ADDR"),
+		ReadTimeout:     duration("READ_TIMEOUT", defaultReadTimeout),
+		WriteTimeout:    duration("WRITE_TIMEOUT", defaultWriteTimeout),
+		IdleTimeout:     duration("IDLE_TIMEOUT", defaultIdleTimeout),
+		ShutdownTimeout: duration("SHUTDOWN_TIMEOUT", defaultShutdownTimeout),
+		RequestTimeout:  duration("REQUEST_TIMEOUT", defaultRequestTimeout),
+		MaxHeaderBytes:  integer("MAX_HEADER_BYTES", defaultMaxHeaderBytes, 1, "positive number of bytes"),
+		MaxBodyBytes:    int64(integer("MAX_BODY_BYTES", defaultMaxBodyBytes, 1, "positive number of bytes")),
+		HTTP2:           boolean("HTTP2", true),
+		HTTP2Cleartext:  boolean("HTTP2_CLEARTEXT", false),
+
+		MockMode:           boolean("MOCK_MODE", false),
+		OpenWeatherAPIKey:  env.Getenv("OPENWEATHER_API_KEY"),
+		OpenWeatherBaseURL: strings.TrimSuffix(stringFromEnv(env, "OPENWEATHER_BASE_URL", defaultOpenWeatherBaseURL), "/"),
+		WeatherAPIKey:      env.Getenv("WEATHERAPI_KEY"),
+		TomorrowAPIKey:     env.Getenv("TOMORROW_API_KEY"),
+		GeoIPURL:           stringFromEnv(env, "GEOIP_API_URL", defaultGeoIPURL),
+		IconBaseURL:        stringFromEnv(env, "ICON_BASE_URL", defaultIconBaseURL),
+
+		WeatherProvider:         env.Getenv("WEATHER_PROVIDER"),
+		ProviderFallbacks:       listFromEnv(env, "WEATHER_PROVIDER_FALLBACKS"),
+		EnabledProviders:        listFromEnv(env, "ENABLED_PROVIDERS"),
+		ProviderTimeout:         duration("PROVIDER_TIMEOUT", defaultProviderTimeout),
+		CircuitBreakerThreshold: integer("CIRCUIT_BREAKER_THRESHOLD", defaultBreakerThreshold, 1, "positive integer"),
+		CircuitBreakerCooldown:  duration("CIRCUIT_BREAKER_COOLDOWN", defaultBreakerCooldown),
+		DailyBudgets:            map[string]int64{},
+
+		UpstreamMode:    stringFromEnv(env, "UPSTREAM_MODE", "live"),
+		FixturesDir:     stringFromEnv(env, "FIXTURES_DIR", defaultFixturesDir),
+		UpstreamTimeout: duration("UPSTREAM_TIMEOUT", defaultUpstreamTimeout),
+		UpstreamRetry: retryPolicy{
+			retries:  integer("UPSTREAM_RETRIES", defaultUpstreamRetries, 0, "non-negative integer"),
+			backoff:  duration("UPSTREAM_RETRY_BACKOFF", defaultUpstreamRetryBackoff),
+			deadline: duration("UPSTREAM_RETRY_DEADLINE", defaultUpstreamRetryDeadline),
+		},
+
+		CacheBackend:      stringFromEnv(env, "CACHE_BACKEND", "memory"),
+		CacheTTL:          duration("CACHE_TTL", defaultCacheTTL),
+		CacheStaleTTL:     duration("CACHE_STALE_TTL", defaultCacheStaleTTL),
+		CacheWarmZipCodes: listFromEnv(env, "CACHE_WARM_ZIP_CODES"),
+		CacheControl:      map[string]string{},
+
+		AdminToken: env.Getenv("ADMIN_TOKEN"),
+	}
+	var err error
+	if c.LogLevel, err = parseLogLevel(stringFromEnv(env, "LOG_LEVEL", "info")); err != nil {
+		check(fmt.Errorf("invalid LOG_LEVEL %q: %v", env.Getenv("LOG_LEVEL"), err))
+	}
+	if !strings.HasSuffix(c.IconBaseURL, "/") {
+		c.IconBaseURL += "/"
+	}
+	c.ZipCodeDBFile = env.Getenv("ZIP_CODE_DATABASE_FILE")
+	c.ZipCodeDB, err = loadZipCodeDB(c.ZipCodeDBFile)
+	check(err)
+	c.ZipCodeStrict = boolean("ZIP_CODE_STRICT", false)
+	c.ZipCodeCities, c.ZipCodeCitiesFile = zipCodeCities(c.ZipCodeDB), env.Getenv("ZIP_CODE_CITIES_FILE")
+	if c.ZipCodeCitiesFile != "" {
+		cities, err := loadZipCodeCities(c.ZipCodeCitiesFile)
+		check(err)
+		if err == nil {
+			c.ZipCodeCities = cities
+		}
+	}
+
+	// Listener
+	c.TLS, err = tlsFromEnv(env)
+	check(err)
+	switch {
+	case c.HTTP2Cleartext && c.TLS != nil:
+		check(fmt.Errorf("HTTP2_CLEARTEXT only applies when TLS is off"))
+	case c.HTTP2Cleartext && !c.HTTP2:
+		check(fmt.Errorf("HTTP2_CLEARTEXT requires HTTP2 to be enabled"))
+	}
+	c.AdminPort, c.AdminClientCA = env.Getenv("ADMIN_PORT"), env.Getenv("ADMIN_CLIENT_CA_FILE")
+	if c.AdminPort != "" && c.AdminPort == c.Port {
+		check(fmt.Errorf("ADMIN_PORT must differ from PORT"))
+	}
+	c.GRPCPort = env.Getenv("GRPC_PORT")
+	if c.GRPCPort != "" && (c.GRPCPort == c.Port || c.GRPCPort == c.AdminPort) {
+		check(fmt.Errorf("GRPC_PORT must differ from PORT and ADMIN_PORT"))
+	}
+	if c.AdminClientCA != "" {
+		switch pool, err := loadCertPool(c.AdminClientCA); {
+		case err != nil:
+			check(fmt.Errorf("invalid ADMIN_CLIENT_CA_FILE: %v", err))
+		case c.AdminPort == "" || c.TLS == nil:
+			check(fmt.Errorf("ADMIN_CLIENT_CA_FILE requires ADMIN_PORT and TLS"))
+		default:
+			c.AdminClientCAs = pool
+		}
+	}
+
+	// Data sources
+	if !slices.Contains([]string{"live", "record", "replay"}, c.UpstreamMode) {
+		check(fmt.Errorf("invalid UPSTREAM_MODE %q: must be live, record, or replay", c.UpstreamMode))
+	}
+	if !c.openWeatherMapAvailable() && c.usesOpenWeatherMap() {
+		check(fmt.Errorf("OPENWEATHER_API_KEY is missing and MOCK_MODE is not set: set OPENWEATHER_API_KEY for live data, or MOCK_MODE=true to serve demo data"))
+	}
+	if parsed, err := url.Parse(c.OpenWeatherBaseURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		check(fmt.Errorf("invalid OPENWEATHER_BASE_URL %q: must be an http or https URL", env.Getenv("OPENWEATHER_BASE_URL")))
+	}
+
+	// Providers
+	if err := c.checkProvider(c.WeatherProvider); err != nil {
+		check(fmt.Errorf("invalid WEATHER_PROVIDER: %v", err))
+	}
+	for _, name := range c.ProviderFallbacks {
+		if err := c.checkProvider(name); err != nil {
+			check(fmt.Errorf("invalid WEATHER_PROVIDER_FALLBACKS entry %q: %v", name, err))
+		}
+	}
+	for _, name := range c.EnabledProviders {
+		if !slices.Contains(providerNames, name) {
+			check(fmt.Errorf("invalid ENABLED_PROVIDERS entry %q: provider must be one of: %s", name, strings.Join(providerNames, ", ")))
+		}
+	}
+	for _, provider := range providerNames {
+		name := quotaBudgetEnvVar(provider)
+		if value := env.Getenv(name); value != "" {
+			budget, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || budget < 1 {
+				check(fmt.Errorf("invalid %s %q: must be a positive integer", name, value))
+				continue
+			}
+			c.DailyBudgets[provider] = budget
+		}
+	}
+
+	// Caching; a zero TTL disables it
+	if c.CacheTTL == 0 {
+		c.CacheBackend = "none"
+	}
+	if c.CacheBackend != "memory" && c.CacheBackend != "none" {
+		check(fmt.Errorf("invalid CACHE_BACKEND %q: must be memory or none", c.CacheBackend))
+	}
+	for i, zipCode := range c.CacheWarmZipCodes {
+		if err := validateZipCode(zipCode); err != nil {
+			check(fmt.Errorf("invalid CACHE_WARM_ZIP_CODES entry %q: %v", zipCode, err))
+			continue
+		}
+		// Requests for ZIP+4 codes are cached by their 5-digit zip code
+		c.CacheWarmZipCodes[i] = zipCode[:5]
+	}
+	if c.CacheBackend != "none" {
+		c.CacheWarmInterval = duration("CACHE_WARM_INTERVAL", c.CacheTTL/2)
+		if len(c.CacheWarmZipCodes) > 0 && c.CacheWarmInterval <= 0 {
+			check(fmt.Errorf("CACHE_WARM_INTERVAL must be greater than 0"))
+		}
+	}
+	for _, env := range env.Environ() {
+		if name, value, _ := strings.Cut(env, "="); strings.HasPrefix(name, "CACHE_CONTROL_") && value != "" {
+			c.CacheControl[name] = value
+		}
+	}
+
+	// Access
+	var keyProblems []string
+	c.APIKeysFile = env.Getenv("API_KEYS_FILE")
+	c.APIKeys, keyProblems = loadAPIKeys(env, c.APIKeysFile)
+	problems = append(problems, keyProblems...)
+	c.JWT, err = jwtFromEnv(env)
+	check(err)
+	var limitProblems []string
+	c.RateLimits, limitProblems = rateLimitsFromEnv(env)
+	problems = append(problems, limitProblems...)
+	if value := env.Getenv("IP_RATE_LIMIT"); value != "" {
+		if c.IPRateLimit, err = parseRateLimit(value); err != nil {
+			check(fmt.Errorf("invalid IP_RATE_LIMIT %q: %v", value, err))
+		}
+	}
+	c.IPRateLimitAllowlist, limitProblems = networksFromEnv(env, "IP_RATE_LIMIT_ALLOWLIST")
+	problems = append(problems, limitProblems...)
+	c.TrustedProxies, limitProblems = networksFromEnv(env, "TRUSTED_PROXIES")
+	problems = append(problems, limitProblems...)
+	c.DailyQuotas = map[string]int64{}
+	forEachTierEnvVar(env, "DAILY_QUOTA_", func(name, tier, value string) {
+		quota, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || quota < 1 {
+			check(fmt.Errorf("invalid %s %q: must be a positive integer", name, value))
+			return
+		}
+		c.DailyQuotas[tier] = quota
+	})
+	c.UsageFile = env.Getenv("USAGE_FILE")
+	c.CORSAllowedOrigins = list("CORS_ALLOWED_ORIGINS", defaultCORSOrigins)
+	for _, origin := range c.CORSAllowedOrigins {
+		check(validateCORSOrigin(origin))
+	}
+	c.CORSAllowedMethods = list("CORS_ALLOWED_METHODS", defaultCORSMethods)
+	c.CORSAllowedHeaders = list("CORS_ALLOWED_HEADERS", defaultCORSHeaders)
+	c.CORSAllowCredentials = boolean("CORS_ALLOW_CREDENTIALS", false)
+	c.CORSMaxAge = duration("CORS_MAX_AGE", defaultCORSMaxAge)
+	if c.CORSAllowCredentials && slices.Contains(c.CORSAllowedOrigins, "*") {
+		check(fmt.Errorf("CORS_ALLOW_CREDENTIALS requires CORS_ALLOWED_ORIGINS to list origins: browsers reject credentialed responses that allow any origin"))
+	}
+	c.HSTS = header("HSTS", defaultHSTS)
+	check(validateHSTS(c.HSTS))
+	c.ReferrerPolicy = header("REFERRER_POLICY", defaultReferrerPolicy)
+	check(validateReferrerPolicy(c.ReferrerPolicy))
+	c.ContentSecurityPolicy = header("CONTENT_SECURITY_POLICY", defaultAPICSP)
+	c.UIContentSecurityPolicy = header("UI_CONTENT_SECURITY_POLICY", defaultUICSP)
+	c.ContentTypeNosniff = boolean("CONTENT_TYPE_NOSNIFF", true)
+
+	// Storage
+	c.StoreBackend, c.StorePath = stringFromEnv(env, "STORE_BACKEND", "memory"), env.Getenv("STORE_PATH")
+	c.DatabaseURL = env.Getenv("DATABASE_URL")
+	if c.DatabaseURL != "" {
+		// The error could quote the password, so it isn't passed on
+		if _, err := pgconn.ParseConfig(c.DatabaseURL); err != nil {
+			check(fmt.Errorf("invalid DATABASE_URL: must be a PostgreSQL connection URL or key=value connection string"))
+		}
+	}
+	c.DatabaseMaxConns = integer("DATABASE_MAX_CONNS", defaultDatabaseMaxConns, 1, "positive integer")
+	c.DatabaseMaxIdleConns = integer("DATABASE_MAX_IDLE_CONNS", defaultDatabaseMaxIdleConns, 0, "non-negative integer")
+	c.DatabaseConnMaxLifetime = duration("DATABASE_CONN_MAX_LIFETIME", defaultDatabaseConnMaxLifetime)
+	switch {
+	case !slices.Contains([]string{"memory", "file", "sqlite", "postgres"}, c.StoreBackend):
+		check(fmt.Errorf("invalid STORE_BACKEND %q: must be memory, file, sqlite, or postgres", c.StoreBackend))
+	case (c.StoreBackend == "file" || c.StoreBackend == "sqlite") && c.StorePath == "":
+		check(fmt.Errorf("STORE_BACKEND=%s requires STORE_PATH", c.StoreBackend))
+	case c.StoreBackend == "postgres" && c.DatabaseURL == "":
+		check(fmt.Errorf("STORE_BACKEND=postgres requires DATABASE_URL"))
+	}
+	c.ObservationRetention = duration("OBSERVATION_RETENTION", defaultObservationRetention)
+	c.StoreAutoMigrate = boolean("STORE_AUTO_MIGRATE", true)
+
+	// Subscriptions
+	c.SubscriptionPollInterval = duration("SUBSCRIPTION_POLL_INTERVAL", defaultSubscriptionPollInterval)
+	c.WebhookAllowPrivateNetworks = boolean("WEBHOOK_ALLOW_PRIVATE_NETWORKS", false)
+	c.SMTPHost = env.Getenv("SMTP_HOST")
+	c.SMTPPort = integer("SMTP_PORT", defaultSMTPPort, 1, "port number")
+	c.SMTPUsername = env.Getenv("SMTP_USERNAME")
+	c.SMTPPassword = env.Getenv("SMTP_PASSWORD")
+	c.SMTPFrom = env.Getenv("SMTP_FROM")
+	if c.SMTPHost != "" {
+		if _, err := mail.ParseAddress(c.SMTPFrom); err != nil {
+			check(fmt.Errorf("SMTP_HOST requires SMTP_FROM to be an email address such as \"Weather Alerts <alerts@example.com>\""))
+		}
+	}
+
+	// Live weather over WebSockets
+	c.WebSocketMaxSubscriptions = integer("WEBSOCKET_MAX_SUBSCRIPTIONS", defaultWebSocketMaxSubscriptions, 1, "positive integer")
+	c.CLIPlainText = boolean("CLI_PLAIN_TEXT", false)
+	c.UnversionedSunset = defaultUnversionedSunset
+	if value := env.Getenv("UNVERSIONED_SUNSET"); value != "" {
+		sunset, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			check(fmt.Errorf("invalid UNVERSIONED_SUNSET %q: must be a date such as 2027-04-14", value))
+		}
+		c.UnversionedSunset = sunset
+	}
+
+	// MQTT publishing
+	c.MQTTBrokerURL = env.Getenv("MQTT_BROKER_URL")
+	if c.MQTTBrokerURL != "" {
+		check(validateMQTTBrokerURL(c.MQTTBrokerURL))
+	}
+	c.MQTTClientID = env.Getenv("MQTT_CLIENT_ID")
+	c.MQTTUsername = env.Getenv("MQTT_USERNAME")
+	c.MQTTPassword = env.Getenv("MQTT_PASSWORD")
+	c.MQTTTopicPrefix = strings.TrimSuffix(stringFromEnv(env, "MQTT_TOPIC_PREFIX", defaultMQTTTopicPrefix), "/")
+	if strings.ContainsAny(c.MQTTTopicPrefix, "+#") || c.MQTTTopicPrefix == "" {
+		check(fmt.Errorf("invalid MQTT_TOPIC_PREFIX %q: must be a topic without wildcards, such as weather or home/weather", c.MQTTTopicPrefix))
+	}
+	c.MQTTQoS = integer("MQTT_QOS", defaultMQTTQoS, 0, "QoS level of 0, 1, or 2")
+	if c.MQTTQoS > 2 {
+		check(fmt.Errorf("invalid MQTT_QOS %q: must be a QoS level of 0, 1, or 2", env.Getenv("MQTT_QOS")))
+	}
+	c.MQTTRetain = boolean("MQTT_RETAIN", true)
+	c.MQTTUnits = stringFromEnv(env, "MQTT_UNITS", unitsImperial)
+	if err := validateUnits(c.MQTTUnits); err != nil {
+		check(fmt.Errorf("invalid MQTT_UNITS: %v", err))
+	}
+
+	// Event streaming
+	c.EventBus = env.Getenv("EVENT_BUS")
+	c.EventBusURL = env.Getenv("EVENT_BUS_URL")
+	c.EventTopic = stringFromEnv(env, "EVENT_TOPIC", defaultEventTopic)
+	c.EventSource = stringFromEnv(env, "EVENT_SOURCE", defaultEventSource)
+	switch {
+	case c.EventBus != "" && c.EventBus != "kafka" && c.EventBus != "nats":
+		check(fmt.Errorf("invalid EVENT_BUS %q: must be kafka or nats", c.EventBus))
+	case c.EventBus != "" && c.EventBusURL == "":
+		check(fmt.Errorf("EVENT_BUS=%s requires EVENT_BUS_URL", c.EventBus))
+	}
+	if !eventTopicRegex.MatchString(c.EventTopic) {
+		check(fmt.Errorf("invalid EVENT_TOPIC %q: may only contain letters, digits, periods, underscores, and hyphens", c.EventTopic))
+	}
+
+	// Scheduled refreshes
+	var scheduleProblems []string
+	c.Schedule, scheduleProblems = scheduleFromEnv(env)
+	problems = append(problems, scheduleProblems...)
+
+	if len(problems) > 0 {
+		return nil, &ConfigError{Problems: problems}
+	}
+	return c, nil
+}
+
+// loadZipCodeCities reads a zip code to city mapping from a JSON or YAML
+// object such as {"10001": "New York,NY,US"}
+func loadZipCodeCities(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ZIP_CODE_CITIES_FILE: %v", err)
+	}
+
+	var cities map[string]string
+	if err := yaml.Unmarshal(data, &cities); err != nil {
+		return nil, fmt.Errorf("invalid ZIP_CODE_CITIES_FILE %s: %v", path, err)
+	}
+	normalized := make(map[string]string, len(cities))
+	for zipCode, city := range cities {
+		if err := validateZipCode(zipCode); err != nil {
+			return nil, fmt.Errorf("invalid ZIP_CODE_CITIES_FILE entry %q: %v", zipCode, err)
+		}
+		if city == "" {
+			return nil, fmt.Errorf("invalid ZIP_CODE_CITIES_FILE entry %q: city must not be empty", zipCode)
+		}
+		normalized[zipCode[:5]] = city
+	}
+	return normalized, nil
+}
+
+// stringFromEnv reads an environment variable, using fallback when it isn't set
+func stringFromEnv(env *environment, name, fallback string) string {
+	if value := env.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// listFromEnv reads a comma-separated list from an environment variable, skipping empty entries
+func listFromEnv(env *environment, name string) []string {
+	var items []string
+	for _, item := range strings.Split(env.Getenv(name), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// forEachTierEnvVar calls fn for each set environment variable named prefix
+// followed by a client tier. The tier is the rest of the name, lower-cased and
+// with underscores read as hyphens: RATE_LIMIT_GOLD_PLUS is for tier gold-plus.
+func forEachTierEnvVar(env *environment, prefix string, fn func(name, tier, value string)) {
+	for _, env := range env.Environ() {
+		name, value, _ := strings.Cut(env, "=")
+		if suffix, ok := strings.CutPrefix(name, prefix); ok && suffix != "" && value != "" {
+			fn(name, strings.ToLower(strings.ReplaceAll(suffix, "_", "-")), value)
+		}
+	}
+}
+
+// writeFileAtomic replaces the file at path with data in one step, so a crash
+// mid-write leaves the previous contents. An existing file keeps its permissions.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if info, statErr := os.Stat(path); statErr == nil {
+		err = tmp.Chmod(info.Mode().Perm())
+	}
+	if err == nil {
+		_, err = tmp.Write(data)
+	}
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// environment holds the variables a server's configuration is read from: its
+// WithSettings settings, then the process environment, then its --config file
+type environment struct {
+	settings map[string]string
+	filePath string            // the --config file, re-read on reload
+	file     map[string]string // the file's settings, as variables
+}
+
+// Getenv returns the value of a variable, or "" when it isn't set. Settings
+// take precedence over the process environment, and environment variables
+// that are set (non-empty) over the file.
+func (e *environment) Getenv(name string) string {
+	if value, ok := e.settings[name]; ok {
+		return value
+	}
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return e.file[name]
+}
+
+// Environ returns every set variable as name=value, like os.Environ
+func (e *environment) Environ() []string {
+	names := map[string]bool{}
+	for _, env := range os.Environ() {
+		name, _, _ := strings.Cut(env, "=")
+		names[name] = true
+	}
+	for name := range e.settings {
+		names[name] = true
+	}
+	for name := range e.file {
+		names[name] = true
+	}
+
+	var environ []string
+	for name := range names {
+		if value := e.Getenv(name); value != "" {
+			environ = append(environ, name+"="+value)
+		}
+	}
+	sort.Strings(environ)
+	return environ
+}
+
+// loadConfigFile reads a YAML (.yaml, .yml) or TOML (.toml) configuration file
+// into variables, which the rest of the server reads like environment
+// variables. Nested keys are joined with underscores and upper-cased, and
+// lists are joined with commas: cache.ttl sets CACHE_TTL and
+// enabled_providers: [nws, open-meteo] sets ENABLED_PROVIDERS=nws,open-meteo.
+// The file replaces any loaded before, including settings it no longer has.
+func (e *environment) loadConfigFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %v", err)
+	}
+
+	var settings map[string]interface{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &settings)
+	case ".toml":
+		err = toml.Unmarshal(data, &settings)
+	default:
+		return fmt.Errorf("unsupported config file type %q: must be .yaml, .yml, or .toml", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse config file %s: %v", path, err)
+	}
+
+	vars := map[string]string{}
+	if err := flattenConfig("", settings, vars); err != nil {
+		return fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+	e.filePath, e.file = path, vars
+	return nil
+}
+
+// flattenConfig converts a parsed config value into environment variables named after its key path
+func flattenConfig(name string, value interface{}, vars map[string]string) error {
+	switch value := value.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			child := strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+			if name != "" {
+				child = name + "_" + child
+			}
+			if err := flattenConfig(child, value[key], vars); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			switch item.(type) {
+			case map[string]interface{}, []interface{}:
+				return fmt.Errorf("%s: lists may only contain plain values", name)
+			}
+			items = append(items, fmt.Sprint(item))
+		}
+		vars[name] = strings.Join(items, ",")
+		return nil
+	default:
+		if name == "" {
+			return fmt.Errorf("expected a mapping of settings")
+		}
+		vars[name] = fmt.Sprint(value)
+		return nil
+	}
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Values for the weather endpoint's mode parameter
+const (
+	modeSingle    = "single"
+	modeConsensus = "consensus"
+)
+
+// ConsensusResponse combines current conditions from every enabled provider
+type ConsensusResponse struct {
+	XMLName           xml.Name          `json:"-" xml:"consensus"`
+	ZipCode           string            `json:"zip_code,omitempty" xml:"zip_code,omitempty"`
+	Location          string            `json:"location" xml:"location"`
+	Units             string            `json:"units" xml:"units"`
+	Temperature       float64           `json:"temperature" xml:"temperature"`
+	Humidity          int               `json:"humidity" xml:"humidity"`
+	TemperatureSpread float64           `json:"temperature_spread" xml:"temperature_spread"`
+	ProviderCount     int               `json:"provider_count" xml:"provider_count"`
+	Providers         []ProviderReading `json:"providers" xml:"providers>reading"`
+}
+
+// ProviderReading is one provider's result in a consensus response
+type ProviderReading struct {
+	Provider string           `json:"provider" xml:"provider"`
+	Weather  *WeatherResponse `json:"weather,omitempty" xml:"weather,omitempty"`
+	Error    string           `json:"error,omitempty" xml:"error,omitempty"`
+}
+
+// getConsensus queries every enabled provider concurrently and averages their
+// temperature and humidity. Providers that fail are reported but left out of the averages.
+func (s *Server) getConsensus(ctx context.Context, loc Location, opts Options) (*ConsensusResponse, error) {
+	names := s.enabledProviders()
+	readings := make([]ProviderReading, len(names))
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+
+			providerOpts := opts
+			providerOpts.Provider = name
+			readings[i] = ProviderReading{Provider: name}
+			weather, err := s.weather.CurrentWeather(ctx, loc, providerOpts)
+			if err != nil {
+				readings[i].Error = err.Error()
+				return
+			}
+			readings[i].Weather = weather
+		}(i, name)
+	}
+	wg.Wait()
+
+	response := &ConsensusResponse{
+		ZipCode:   loc.ZipCode,
+		Units:     opts.units(),
+		Providers: readings,
+	}
+
+	var totalTemperature, totalHumidity float64
+	low, high := math.Inf(1), math.Inf(-1)
+	var failures []string
+	for _, reading := range readings {
+		weather := reading.Weather
+		if weather == nil {
+			failures = append(failures, reading.Provider+": "+reading.Error)
+			continue
+		}
+		if response.Location == "" {
+			response.Location = weather.Location
+		}
+		totalTemperature += weather.Temperature
+		totalHumidity += float64(weather.Humidity)
+		low, high = math.Min(low, weather.Temperature), math.Max(high, weather.Temperature)
+		response.ProviderCount++
+	}
+
+	if response.ProviderCount == 0 {
+		return nil, withKind(ErrUpstreamUnavailable, fmt.Errorf("all weather providers failed: %s", strings.Join(failures, "; ")))
+	}
+
+	count := float64(response.ProviderCount)
+	response.Temperature = round1(totalTemperature / count)
+	response.Humidity = int(math.Round(totalHumidity / count))
+	response.TemperatureSpread = round1(high - low)
+	return response, nil
+}
+
+// modeFromRequest reads and validates the optional mode query parameter
+func modeFromRequest(w http.ResponseWriter, r *http.Request) (string, bool) {
+	mode := r.URL.Query().Get("mode")
+	switch mode {
+	case "", modeSingle:
+		return modeSingle, true
+	case modeConsensus:
+		if r.URL.Query().Get("provider") != "" {
+			writeError(w, http.StatusBadRequest, "provider can't be combined with mode=consensus")
+			return "", false
+		}
+		return modeConsensus, true
+	default:
+		writeError(w, http.StatusBadRequest, "mode must be one of: single, consensus")
+		return "", false
+	}
+}
@@ -0,0 +1,276 @@
+// Code generated by github.com/tinylib/msgp DO NOT EDIT.
+
+package server
+
+import (
+	"github.com/tinylib/msgp/msgp"
+)
+
+// MarshalMsg implements msgp.Marshaler
+func (z *ConsensusResponse) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// check for omitted fields
+	zb0001Len := uint32(8)
+	var zb0001Mask uint8 /* 8 bits */
+	_ = zb0001Mask
+	if z.ZipCode == "" {
+		zb0001Len--
+		zb0001Mask |= 0x1
+	}
+	// variable map header, size zb0001Len
+	o = append(o, 0x80|uint8(zb0001Len))
+
+	// skip if no fields are to be emitted
+	if zb0001Len != 0 {
+		if (zb0001Mask & 0x1) == 0 { // if not omitted
+			// string "zip_code"
+			o = append(o, 0xa8, 0x7a, 0x69, 0x70, 0x5f, 0x63, 0x6f, 0x64, 0x65)
+			o = msgp.AppendString(o, z.ZipCode)
+		}
+		// string "location"
+		o = append(o, 0xa8, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e)
+		o = msgp.AppendString(o, z.Location)
+		// string "units"
+		o = append(o, 0xa5, 0x75, 0x6e, 0x69, 0x74, 0x73)
+		o = msgp.AppendString(o, z.Units)
+		// string "temperature"
+		o = append(o, 0xab, 0x74, 0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65)
+		o = msgp.AppendFloat64(o, z.Temperature)
+		// string "humidity"
+		o = append(o, 0xa8, 0x68, 0x75, 0x6d, 0x69, 0x64, 0x69, 0x74, 0x79)
+		o = msgp.AppendInt(o, z.Humidity)
+		// string "temperature_spread"
+		o = append(o, 0xb2, 0x74, 0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65, 0x5f, 0x73, 0x70, 0x72, 0x65, 0x61, 0x64)
+		o = msgp.AppendFloat64(o, z.TemperatureSpread)
+		// string "provider_count"
+		o = append(o, 0xae, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74)
+		o = msgp.AppendInt(o, z.ProviderCount)
+		// string "providers"
+		o = append(o, 0xa9, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x73)
+		o = msgp.AppendArrayHeader(o, uint32(len(z.Providers)))
+		for za0001 := range z.Providers {
+			o, err = z.Providers[za0001].MarshalMsg(o)
+			if err != nil {
+				err = msgp.WrapError(err, "Providers", za0001)
+				return
+			}
+		}
+	}
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *ConsensusResponse) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "zip_code":
+			z.ZipCode, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "ZipCode")
+				return
+			}
+		case "location":
+			z.Location, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Location")
+				return
+			}
+		case "units":
+			z.Units, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Units")
+				return
+			}
+		case "temperature":
+			z.Temperature, bts, err = msgp.ReadFloat64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Temperature")
+				return
+			}
+		case "humidity":
+			z.Humidity, bts, err = msgp.ReadIntBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Humidity")
+				return
+			}
+		case "temperature_spread":
+			z.TemperatureSpread, bts, err = msgp.ReadFloat64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "TemperatureSpread")
+				return
+			}
+		case "provider_count":
+			z.ProviderCount, bts, err = msgp.ReadIntBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "ProviderCount")
+				return
+			}
+		case "providers":
+			var zb0002 uint32
+			zb0002, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Providers")
+				return
+			}
+			if cap(z.Providers) >= int(zb0002) {
+				z.Providers = (z.Providers)[:zb0002]
+			} else {
+				z.Providers = make([]ProviderReading, zb0002)
+			}
+			for za0001 := range z.Providers {
+				bts, err = z.Providers[za0001].UnmarshalMsg(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Providers", za0001)
+					return
+				}
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *ConsensusResponse) Msgsize() (s int) {
+	s = 1 + 9 + msgp.StringPrefixSize + len(z.ZipCode) + 9 + msgp.StringPrefixSize + len(z.Location) + 6 + msgp.StringPrefixSize + len(z.Units) + 12 + msgp.Float64Size + 9 + msgp.IntSize + 19 + msgp.Float64Size + 15 + msgp.IntSize + 10 + msgp.ArrayHeaderSize
+	for za0001 := range z.Providers {
+		s += z.Providers[za0001].Msgsize()
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *ProviderReading) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// check for omitted fields
+	zb0001Len := uint32(3)
+	var zb0001Mask uint8 /* 3 bits */
+	_ = zb0001Mask
+	if z.Weather == nil {
+		zb0001Len--
+		zb0001Mask |= 0x2
+	}
+	if z.Error == "" {
+		zb0001Len--
+		zb0001Mask |= 0x4
+	}
+	// variable map header, size zb0001Len
+	o = append(o, 0x80|uint8(zb0001Len))
+
+	// skip if no fields are to be emitted
+	if zb0001Len != 0 {
+		// string "provider"
+		o = append(o, 0xa8, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72)
+		o = msgp.AppendString(o, z.Provider)
+		if (zb0001Mask & 0x2) == 0 { // if not omitted
+			// string "weather"
+			o = append(o, 0xa7, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72)
+			if z.Weather == nil {
+				o = msgp.AppendNil(o)
+			} else {
+				o, err = z.Weather.MarshalMsg(o)
+				if err != nil {
+					err = msgp.WrapError(err, "Weather")
+					return
+				}
+			}
+		}
+		if (zb0001Mask & 0x4) == 0 { // if not omitted
+			// string "error"
+			o = append(o, 0xa5, 0x65, 0x72, 0x72, 0x6f, 0x72)
+			o = msgp.AppendString(o, z.Error)
+		}
+	}
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *ProviderReading) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "provider":
+			z.Provider, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Provider")
+				return
+			}
+		case "weather":
+			if msgp.IsNil(bts) {
+				bts, err = msgp.ReadNilBytes(bts)
+				if err != nil {
+					return
+				}
+				z.Weather = nil
+			} else {
+				if z.Weather == nil {
+					z.Weather = new(WeatherResponse)
+				}
+				bts, err = z.Weather.UnmarshalMsg(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Weather")
+					return
+				}
+			}
+		case "error":
+			z.Error, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Error")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *ProviderReading) Msgsize() (s int) {
+	s = 1 + 9 + msgp.StringPrefixSize + len(z.Provider) + 8
+	if z.Weather == nil {
+		s += msgp.NilSize
+	} else {
+		s += z.Weather.Msgsize()
+	}
+	s += 6 + msgp.StringPrefixSize + len(z.Error)
+	return
+}
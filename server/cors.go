@@ -0,0 +1,77 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-chi/cors"
+)
+
+// CORS defaults: any origin may call the API without credentials, with the
+// methods and headers its endpoints take
+var (
+	defaultCORSOrigins = []string{"*"}
+	defaultCORSMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
+	defaultCORSHeaders = []string{"Content-Type", "Authorization", "X-API-Key", "X-Request-ID"}
+)
+
+const defaultCORSMaxAge = 10 * time.Minute
+
+// Response headers cross-origin scripts may read
+var corsExposedHeaders = []string{
+	"X-Request-ID", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset",
+	"Retry-After", "Deprecation", "Sunset", "Link",
+}
+
+// setupCORS builds the CORS policy from the configuration
+func (s *Server) setupCORS(c *Config) {
+	s.activeCORS.Store(cors.New(cors.Options{
+		AllowedOrigins:   c.CORSAllowedOrigins,
+		AllowedMethods:   c.CORSAllowedMethods,
+		AllowedHeaders:   c.CORSAllowedHeaders,
+		ExposedHeaders:   corsExposedHeaders,
+		AllowCredentials: c.CORSAllowCredentials,
+		MaxAge:           int(c.CORSMaxAge / time.Second),
+	}))
+}
+
+// corsPolicy adds CORS headers to responses for allowed origins, and answers
+// preflight requests itself, before authentication could turn them away
+func (s *Server) corsPolicy(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.activeCORS.Load().Handler(next).ServeHTTP(w, r)
+	})
+}
+
+// validateCORSOrigin checks an allowed origin: "*", or a scheme and host such
+// as https://app.example.com, which may have one * to match any part of it,
+// as in https://*.example.com
+func validateCORSOrigin(origin string) error {
+	if origin == "*" {
+		return nil
+	}
+	parsed, err := url.Parse(strings.Replace(origin, "*", "wildcard", 1))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" ||
+		parsed.Path != "" || parsed.RawQuery != "" || strings.Count(origin, "*") > 1 {
+		return fmt.Errorf("invalid CORS_ALLOWED_ORIGINS entry %q: must be * or an origin such as https://app.example.com or https://*.example.com", origin)
+	}
+	return nil
+}
+
+// corsOriginAllowed reports whether the CORS policy allows an origin, the same
+// way the CORS middleware matches them
+func corsOriginAllowed(c *Config, origin string) bool {
+	origin = strings.ToLower(origin)
+	return slices.ContainsFunc(c.CORSAllowedOrigins, func(allowed string) bool {
+		allowed = strings.ToLower(allowed)
+		prefix, suffix, wildcard := strings.Cut(allowed, "*")
+		if !wildcard {
+			return origin == allowed
+		}
+		return len(origin) >= len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix)
+	})
+}
@@ -0,0 +1,8 @@
+// Render the server's OpenAPI spec. Credentials entered under "Authorize"
+// are sent with "Try it out" requests.
+window.ui = SwaggerUIBundle({
+  url: "/openapi.json",
+  dom_id: "#swagger-ui",
+  deepLinking: true,
+  persistAuthorization: true,
+});
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The SMTP port email alerts are sent to by default, and how long sending one may take
+const (
+	defaultSMTPPort = 587
+	smtpTimeout     = 30 * time.Second
+)
+
+// emailNotifier sends alerts as plain text email through the SMTP_HOST server
+type emailNotifier struct {
+	s *Server
+}
+
+func (n emailNotifier) available() error {
+	if n.s.config().SMTPHost == "" {
+		return errors.New("email subscriptions require SMTP_HOST")
+	}
+	return nil
+}
+
+func (n emailNotifier) validate(sub *Subscription) error {
+	address, err := mail.ParseAddress(sub.Email)
+	if err != nil {
+		return errors.New("email must be an email address such as you@example.com")
+	}
+	sub.Email = address.Address
+	return nil
+}
+
+func (n emailNotifier) notify(sub Subscription, alert SubscriptionAlert) (bool, error) {
+	c := n.s.config()
+	err := sendEmail(c, sub.Email, alertHeading(alert)+": "+alert.Condition, alertEmailBody(alert))
+	var smtpErr *textproto.Error
+	if errors.As(err, &smtpErr) {
+		// 4xx replies are temporary failures
+		return smtpErr.Code >= 400 && smtpErr.Code < 500, err
+	}
+	return err != nil, err
+}
+
+// alertEmailBody writes the text of an alert email: what changed, the current
+// conditions, and any severe alerts in effect
+func alertEmailBody(alert SubscriptionAlert) string {
+	var b strings.Builder
+	b.WriteString(alertSummary(alert) + "\n\n")
+
+	w := alert.Weather
+	fmt.Fprintf(&b, "Current conditions in %s as of %s:\n", w.Location, w.ObservedAt)
+	for _, line := range weatherLines(w) {
+		b.WriteString("  " + line + "\n")
+	}
+
+	if len(alert.SevereAlerts) > 0 {
+		b.WriteString("\nSevere weather alerts in effect:\n")
+		for _, severe := range alert.SevereAlerts {
+			fmt.Fprintf(&b, "  %s: %s\n", severe.Event, severe.Headline)
+		}
+	}
+
+	fmt.Fprintf(&b, "\nSubscription %s, checked at %s.\n", alert.SubscriptionID, alert.At)
+	fmt.Fprintf(&b, "To stop these emails, DELETE /subscriptions/%s.\n", alert.SubscriptionID)
+	return b.String()
+}
+
+// sendEmail sends a plain text email through the configured SMTP server
+func sendEmail(c *Config, to, subject, body string) error {
+	from, err := mail.ParseAddress(c.SMTPFrom)
+	if err != nil {
+		return fmt.Errorf("invalid SMTP_FROM: %v", err)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from.String())
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	address := net.JoinHostPort(c.SMTPHost, strconv.Itoa(c.SMTPPort))
+	tlsConfig := &tls.Config{ServerName: c.SMTPHost}
+	var conn net.Conn
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+	if c.SMTPPort == 465 {
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", address)
+	}
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(smtpTimeout))
+
+	client, err := smtp.NewClient(conn, c.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+	if ok, _ := client.Extension("STARTTLS"); ok && c.SMTPPort != 465 {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if c.SMTPUsername != "" {
+		if err := client.Auth(smtp.PlainAuth("", c.SMTPUsername, c.SMTPPassword, c.SMTPHost)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	data, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := data.Write(msg.Bytes()); err != nil {
+		return err
+	}
+	if err := data.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Kinds of failed lookup, for errors.Is. Lookups return errors of one of
+// these kinds, and writeServiceError responds to each with its own status.
+var (
+	ErrInvalidZip          = errors.New("invalid zip code")     // 400 Bad Request
+	ErrNotFound            = errors.New("not found")            // 404 Not Found
+	ErrRateLimited         = errors.New("rate limited")         // 429 Too Many Requests
+	ErrUpstreamUnavailable = errors.New("upstream unavailable") // 503 Service Unavailable
+	ErrNotImplemented      = errors.New("not implemented")      // 501 Not Implemented
+)
+
+// kindError gives an error one of the kinds above, keeping its message
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string   { return e.err.Error() }
+func (e *kindError) Unwrap() []error { return []error{e.kind, e.err} }
+
+// withKind returns err as an error of the given kind
+func withKind(kind, err error) error {
+	return &kindError{kind: kind, err: err}
+}
+
+// upstreamStatusError reports an upstream API responding with other than 200 OK
+type upstreamStatusError struct {
+	kind   string // the kind of data requested, e.g. "weather"
+	status int
+}
+
+func (e *upstreamStatusError) Error() string {
+	return fmt.Sprintf("%s API returned status: %d", e.kind, e.status)
+}
+
+// Is classifies the response: the upstream not knowing the location, it
+// rate limiting us, or anything else it couldn't answer
+func (e *upstreamStatusError) Is(target error) bool {
+	switch e.status {
+	case http.StatusNotFound:
+		return target == ErrNotFound
+	case http.StatusTooManyRequests:
+		return target == ErrRateLimited
+	}
+	return target == ErrUpstreamUnavailable
+}
+
+// serviceErrorStatus returns the HTTP status for a failed lookup. Errors of
+// no kind are problems with the request, such as a location a provider
+// doesn't cover, unless the request ran out of time.
+func serviceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidZip):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrRateLimited):
+		return http.StatusTooManyRequests
+	case errors.Is(err, ErrUpstreamUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, ErrNotImplemented):
+		return http.StatusNotImplemented
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusBadRequest
+}
+
+// retryAfter returns when a failed lookup is worth retrying, when that's
+// known: once the provider's circuit closes, or its daily budget resets
+func retryAfter(err error) (time.Duration, bool) {
+	var open *circuitOpenError
+	var exhausted *quotaExceededError
+	switch {
+	case errors.As(err, &open):
+		return open.retryAfter, true
+	case errors.As(err, &exhausted):
+		return untilQuotaReset(), true
+	}
+	return 0, false
+}
+
+// writeServiceError writes a failed lookup with the status for its kind, and
+// Retry-After when it's known. Unknown zip codes also get an
+// "unknown_zip_code" code, so clients can tell them from places a provider
+// has no data for.
+func writeServiceError(w http.ResponseWriter, err error) {
+	if after, ok := retryAfter(err); ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(after.Seconds()))))
+	}
+
+	var unknown *unknownZipCodeError
+	if !errors.As(err, &unknown) {
+		writeError(w, serviceErrorStatus(err), err.Error())
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusNotFound)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error":    err.Error(),
+		"code":     "unknown_zip_code",
+		"zip_code": unknown.zipCode,
+	})
+}
@@ -0,0 +1,197 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/segmentio/kafka-go"
+)
+
+// Event bus defaults
+const (
+	defaultEventTopic  = "weather.events"
+	defaultEventSource = "/weather-server"
+)
+
+// Kafka topics and NATS subject tokens may only use these characters
+var eventTopicRegex = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// The content type of events in CloudEvents' structured JSON mode
+const cloudEventContentType = "application/cloudevents+json"
+
+// CloudEvent is a CloudEvents 1.0 event
+type CloudEvent struct {
+	SpecVersion     string `json:"specversion"`
+	ID              string `json:"id"`
+	Source          string `json:"source"`
+	Type            string `json:"type"`
+	Subject         string `json:"subject"`
+	Time            string `json:"time"`
+	DataContentType string `json:"datacontenttype"`
+	Data            any    `json:"data"`
+}
+
+// An eventProducer sends encoded events to an event bus without waiting for
+// the bus to take them
+type eventProducer interface {
+	// publish sends an event of a kind ("observation" or "alert") about a location code
+	publish(kind, code string, event []byte)
+	close()
+}
+
+// startEventBus sets up the EVENT_BUS producer, if there is one. Both
+// producers connect, and reconnect, in the background.
+func (s *Server) startEventBus(c *Config) error {
+	var err error
+	switch c.EventBus {
+	case "kafka":
+		s.eventBus = newKafkaProducer(c)
+	case "nats":
+		s.eventBus, err = newNATSProducer(c)
+	}
+	return err
+}
+
+// stopEventBus sends the events still waiting and disconnects
+func (s *Server) stopEventBus() {
+	if s.eventBus != nil {
+		s.eventBus.close()
+	}
+}
+
+// emitObservationEvent sends a weather.observation event for current
+// conditions looked up upstream
+func (s *Server) emitObservationEvent(loc Location, weather WeatherResponse) {
+	if loc.ZipCode == "" {
+		return
+	}
+	s.emitEvent("observation", "weather.observation", loc, weather)
+}
+
+// emitAlertEvent sends a weather.alert.triggered or weather.alert.cleared
+// event for a subscription alert
+func (s *Server) emitAlertEvent(alert SubscriptionAlert) {
+	s.emitEvent("alert", "weather.alert."+alert.Event, Location{ZipCode: alert.ZipCode}, alert)
+}
+
+// emitEvent wraps data in a CloudEvent and sends it to the event bus
+func (s *Server) emitEvent(kind, eventType string, loc Location, data any) {
+	if s.eventBus == nil {
+		return
+	}
+	event, err := json.Marshal(CloudEvent{
+		SpecVersion:     "1.0",
+		ID:              rand.Text(),
+		Source:          s.config().EventSource,
+		Type:            eventType,
+		Subject:         loc.code(),
+		Time:            time.Now().UTC().Format(time.RFC3339Nano),
+		DataContentType: "application/json",
+		Data:            data,
+	})
+	if err != nil {
+		warnf("Failed to encode %s event: %v", eventType, err)
+		return
+	}
+	s.eventBus.publish(kind, loc.code(), event)
+}
+
+// eventBusDescription returns EVENT_BUS_URL without any credentials in it
+func eventBusDescription(busURL string) string {
+	addresses := strings.Split(busURL, ",")
+	for i, address := range addresses {
+		if parsed, err := url.Parse(address); err == nil && parsed.User != nil {
+			parsed.User = nil
+			addresses[i] = parsed.String()
+		}
+	}
+	return strings.Join(addresses, ",")
+}
+
+// kafkaProducer writes events to EVENT_TOPIC, keyed by location code so each
+// location's events stay in order on one partition
+type kafkaProducer struct {
+	writer *kafka.Writer
+}
+
+func newKafkaProducer(c *Config) *kafkaProducer {
+	return &kafkaProducer{writer: &kafka.Writer{
+		Addr:         kafka.TCP(strings.Split(c.EventBusURL, ",")...),
+		Topic:        c.EventTopic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireOne,
+		BatchTimeout: 100 * time.Millisecond,
+		Async:        true,
+		Completion: func(messages []kafka.Message, err error) {
+			if err != nil {
+				warnf("Failed to send %d events to Kafka: %v", len(messages), err)
+			}
+		},
+	}}
+}
+
+func (p *kafkaProducer) publish(kind, code string, event []byte) {
+	// With Async set, errors are reported to Completion
+	p.writer.WriteMessages(context.Background(), kafka.Message{
+		Key:     []byte(code),
+		Value:   event,
+		Headers: []kafka.Header{{Key: "content-type", Value: []byte(cloudEventContentType)}},
+	})
+}
+
+func (p *kafkaProducer) close() {
+	if err := p.writer.Close(); err != nil {
+		warnf("Failed to send events to Kafka: %v", err)
+	}
+}
+
+// natsProducer publishes events to subjects {EVENT_TOPIC}.{kind}.{code}, e.g.
+// weather.events.observation.10001, so subscribers can pick what they want
+// with wildcards such as weather.events.alert.>
+type natsProducer struct {
+	conn  *nats.Conn
+	topic string
+}
+
+func newNATSProducer(c *Config) (*natsProducer, error) {
+	conn, err := nats.Connect(c.EventBusURL,
+		nats.Name("weather-server"),
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(-1),
+		nats.ConnectHandler(func(*nats.Conn) { infof("Connected to NATS") }),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				warnf("Lost connection to NATS: %v", err)
+			}
+		}),
+		nats.ReconnectHandler(func(*nats.Conn) { infof("Reconnected to NATS") }),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %v", err)
+	}
+	return &natsProducer{conn: conn, topic: c.EventTopic}, nil
+}
+
+func (p *natsProducer) publish(kind, code string, event []byte) {
+	err := p.conn.PublishMsg(&nats.Msg{
+		Subject: p.topic + "." + kind + "." + code,
+		Data:    event,
+		Header:  nats.Header{"Content-Type": {cloudEventContentType}},
+	})
+	if err != nil {
+		warnf("Failed to publish %s event to NATS: %v", kind, err)
+	}
+}
+
+func (p *natsProducer) close() {
+	if err := p.conn.Drain(); err != nil {
+		warnf("Failed to send events to NATS: %v", err)
+	}
+}
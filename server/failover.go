@@ -0,0 +1,115 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// How long a provider may take before failing over, when PROVIDER_TIMEOUT isn't set
+const defaultProviderTimeout = 10 * time.Second
+
+// providerChain returns the providers to try for a lookup, in order. A provider
+// chosen explicitly with the provider parameter is used on its own.
+func (s *Server) providerChain(opts Options) ([]WeatherProvider, error) {
+	primary, err := s.weatherProvider(opts.provider(s.config()))
+	if err != nil {
+		return nil, err
+	}
+
+	chain := []WeatherProvider{primary}
+	if opts.Provider != "" {
+		return chain, nil
+	}
+	for _, name := range s.config().ProviderFallbacks {
+		provider, err := s.weatherProvider(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid WEATHER_PROVIDER_FALLBACKS entry %q: %v", name, err)
+		}
+		chain = append(chain, provider)
+	}
+	return chain, nil
+}
+
+// currentWithFailover tries each provider in the chain until one succeeds,
+// skipping providers whose circuit breaker is open. The response's source
+// names the provider used, marked when it was a fallback.
+func (s *Server) currentWithFailover(ctx context.Context, chain []WeatherProvider, loc Location, opts Options) (*WeatherResponse, error) {
+	var failures, open []string
+	for _, provider := range chain {
+		// Stop once the client has gone away rather than trying fallbacks
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if !s.providerBreakers.allow(provider.Name()) {
+			open = append(open, provider.Name())
+			continue
+		}
+
+		// A used-up daily budget or a canceled request says nothing about the
+		// provider's health
+		weather, err := currentWithTimeout(ctx, provider, loc, opts, s.config().ProviderTimeout)
+		var exhausted *quotaExceededError
+		if errors.As(err, &exhausted) || ctx.Err() != nil {
+			s.providerBreakers.release(provider.Name())
+		} else {
+			s.providerBreakers.record(provider.Name(), err)
+		}
+		if err != nil {
+			// A lone provider's error is returned as is
+			if len(chain) == 1 {
+				return nil, err
+			}
+			debugf("provider %s failed, trying the next one: %v", provider.Name(), err)
+			failures = append(failures, provider.Name()+": "+err.Error())
+			continue
+		}
+
+		weather.Source = provider.Name()
+		if provider != chain[0] {
+			weather.Source += " (fallback)"
+		}
+		return weather, nil
+	}
+
+	if len(failures) == 0 {
+		return nil, &circuitOpenError{providers: open, retryAfter: s.providerBreakers.retryAfter(open)}
+	}
+	for _, name := range open {
+		failures = append(failures, name+": circuit open")
+	}
+	return nil, withKind(ErrUpstreamUnavailable, fmt.Errorf("all weather providers failed: %s", strings.Join(failures, "; ")))
+}
+
+// currentWithTimeout calls a provider, giving up after timeout (0 means no
+// limit) or once ctx is canceled
+func currentWithTimeout(ctx context.Context, provider WeatherProvider, loc Location, opts Options, timeout time.Duration) (*WeatherResponse, error) {
+	if timeout <= 0 {
+		return provider.Current(ctx, loc, opts)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		weather *WeatherResponse
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		weather, err := provider.Current(timeoutCtx, loc, opts)
+		done <- result{weather, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.weather, r.err
+	case <-timeoutCtx.Done():
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return nil, withKind(ErrUpstreamUnavailable, fmt.Errorf("%s timed out after %s", provider.Name(), timeout))
+	}
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Most locations a client may save
+const maxSavedLocations = 20
+
+// savedLocationsClient returns the client whose saved locations a request is
+// for. Without one it writes a 401 response and returns false.
+func savedLocationsClient(w http.ResponseWriter, r *http.Request) (apiClient, bool) {
+	client, ok := requestClient(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "saved locations are only kept for requests with an API key or bearer token")
+	}
+	return client, ok
+}
+
+// Saved locations handler using Chi
+func (s *Server) savedLocationsHandler(w http.ResponseWriter, r *http.Request) {
+	client, ok := savedLocationsClient(w, r)
+	if !ok {
+		return
+	}
+	locations, err := s.dataStore.SavedLocations(client.Name)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if locations == nil {
+		locations = []SavedLocation{}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(locations)
+}
+
+// Save location handler using Chi. Saving a zip code again changes its name.
+func (s *Server) saveLocationHandler(w http.ResponseWriter, r *http.Request) {
+	client, ok := savedLocationsClient(w, r)
+	if !ok {
+		return
+	}
+
+	var loc SavedLocation
+	r.Body = http.MaxBytesReader(w, r.Body, 1024)
+	if err := json.NewDecoder(r.Body).Decode(&loc); err != nil || loc.ZipCode == "" {
+		writeError(w, http.StatusBadRequest, `request body must be a JSON object such as {"zip_code": "10001", "name": "Office"}`)
+		return
+	}
+	zipCode, err := s.normalizeZipCode(loc.ZipCode)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	loc.ZipCode, loc.Name = zipCode, strings.TrimSpace(loc.Name)
+
+	saved, err := s.dataStore.SavedLocations(client.Name)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(saved) >= maxSavedLocations && !slices.ContainsFunc(saved, func(existing SavedLocation) bool { return existing.ZipCode == zipCode }) {
+		writeError(w, http.StatusConflict, fmt.Sprintf("at most %d locations can be saved", maxSavedLocations))
+		return
+	}
+
+	created, err := s.dataStore.SaveLocation(client.Name, loc)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !created {
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(loc)
+		return
+	}
+
+	// New locations get the client's saved-locations alerts
+	if err := s.subscribeSavedLocation(client.Name, zipCode); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(loc)
+}
+
+// Delete saved location handler using Chi
+func (s *Server) deleteSavedLocationHandler(w http.ResponseWriter, r *http.Request) {
+	client, ok := savedLocationsClient(w, r)
+	if !ok {
+		return
+	}
+	zipCode, ok := zipCodeFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	deleted, err := s.dataStore.DeleteSavedLocation(client.Name, zipCode)
+	switch {
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	case deleted == nil:
+		writeError(w, http.StatusNotFound, "zip code "+zipCode+" is not saved")
+		return
+	}
+	if err := s.unsubscribeSavedLocation(client.Name, zipCode); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(deleted)
+}
+
+// SavedLocationWeather is the current weather at one saved location
+type SavedLocationWeather struct {
+	Name string `json:"name,omitempty"`
+	BatchWeatherResult
+}
+
+// MyWeatherResponse represents the weather at the caller's saved locations we'll return from /me/weather
+type MyWeatherResponse struct {
+	Units     string                 `json:"units"`
+	Locations []SavedLocationWeather `json:"locations"`
+}
+
+// Saved locations weather handler using Chi
+func (s *Server) savedWeatherHandler(w http.ResponseWriter, r *http.Request) {
+	client, ok := savedLocationsClient(w, r)
+	if !ok {
+		return
+	}
+	opts, ok := s.optionsFromRequest(w, r)
+	if !ok {
+		return
+	}
+	saved, err := s.dataStore.SavedLocations(client.Name)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	zipCodes := make([]string, len(saved))
+	for i, loc := range saved {
+		zipCodes[i] = loc.ZipCode
+	}
+	response := MyWeatherResponse{Units: opts.units(), Locations: []SavedLocationWeather{}}
+	for i, result := range s.getWeatherBatch(r.Context(), zipCodes, opts) {
+		response.Locations = append(response.Locations, SavedLocationWeather{Name: saved[i].Name, BatchWeatherResult: result})
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// fieldSet is a set of JSON field names, each with the fields to keep of its
+// value, or nil to keep all of it
+type fieldSet map[string]fieldSet
+
+// parseFields reads a fields parameter: comma-separated field names, with
+// dots between the names of nested fields, e.g. "location,temperature.current"
+func parseFields(param string) (fieldSet, bool) {
+	fields := fieldSet{}
+	for _, path := range strings.Split(param, ",") {
+		names := strings.Split(strings.TrimSpace(path), ".")
+		set := fields
+		for i, name := range names {
+			if name == "" {
+				return nil, false
+			}
+			sub, ok := set[name]
+			if ok && sub == nil {
+				break // already kept whole
+			}
+			if i == len(names)-1 {
+				set[name] = nil
+				break
+			}
+			if sub == nil {
+				sub = fieldSet{}
+				set[name] = sub
+			}
+			set = sub
+		}
+	}
+	return fields, true
+}
+
+// apply keeps only the fields of a decoded JSON value. The fields of an
+// array apply to each of its elements, and values that aren't objects are
+// kept as they are.
+func (fields fieldSet) apply(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		selected := make(map[string]interface{}, len(fields))
+		for name, sub := range fields {
+			if value, ok := v[name]; ok {
+				if sub != nil {
+					value = sub.apply(value)
+				}
+				selected[name] = value
+			}
+		}
+		return selected
+	case []interface{}:
+		for i := range v {
+			v[i] = fields.apply(v[i])
+		}
+	}
+	return v
+}
+
+// selectFields trims successful JSON responses to the fields parameter, e.g.
+// fields=temperature,description, for clients on slow or metered links.
+// Names that match nothing are ignored, since omitted fields are often just
+// empty. Errors, other formats, and streams are written whole.
+func selectFields(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		param := r.URL.Query().Get("fields")
+		if param == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		fields, ok := parseFields(param)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "fields must be comma-separated field names, e.g. temperature,description")
+			return
+		}
+
+		fw := &fieldsWriter{ResponseWriter: w, fields: fields}
+		next.ServeHTTP(fw, r)
+		fw.finish()
+	})
+}
+
+// fieldsWriter holds back a successful JSON response, so selectFields can
+// trim it once it's complete
+type fieldsWriter struct {
+	http.ResponseWriter
+	fields      fieldSet
+	wroteHeader bool
+	body        *bytes.Buffer // the held back response, if it's being trimmed
+}
+
+func (w *fieldsWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if mediaType, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type")); status == http.StatusOK && mediaType == "application/json" {
+		w.body = &bytes.Buffer{}
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *fieldsWriter) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.body != nil {
+		return w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// Unwrap lets http.ResponseController flush streams
+func (w *fieldsWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Hijack is required by WebSocket upgrades
+func (w *fieldsWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// finish writes the held back response with only the selected fields
+func (w *fieldsWriter) finish() {
+	if w.body == nil {
+		return
+	}
+	decoder := json.NewDecoder(bytes.NewReader(w.body.Bytes()))
+	decoder.UseNumber() // keep numbers as they were written
+	var v interface{}
+	w.ResponseWriter.WriteHeader(http.StatusOK)
+	if err := decoder.Decode(&v); err != nil {
+		w.ResponseWriter.Write(w.body.Bytes()) // not JSON after all
+		return
+	}
+	json.NewEncoder(w.ResponseWriter).Encode(w.fields.apply(v))
+}
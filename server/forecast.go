@@ -0,0 +1,404 @@
+package server
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Number of days returned by the forecast endpoint
+const forecastDays = 5
+
+// Hourly forecast window limits (the upstream forecast covers 5 days)
+const (
+	defaultForecastHours = 24
+	maxForecastHours     = 120
+)
+
+// Spacing of upstream forecast entries, in hours
+const forecastIntervalHours = 3
+
+// ForecastResponse represents the multi-day forecast we'll return
+type ForecastResponse struct {
+	XMLName  xml.Name        `json:"-" xml:"forecast"`
+	ZipCode  string          `json:"zip_code" xml:"zip_code"`
+	Location string          `json:"location" xml:"location"`
+	Units    string          `json:"units" xml:"units"`
+	Days     []DailyForecast `json:"days" xml:"days>day"`
+}
+
+// DailyForecast summarizes the forecast for a single day
+type DailyForecast struct {
+	Date                string  `json:"date" xml:"date"`
+	High                float64 `json:"high" xml:"high"`
+	Low                 float64 `json:"low" xml:"low"`
+	Description         string  `json:"description" xml:"description"`
+	Icon                string  `json:"icon" xml:"icon"`
+	IconURL             string  `json:"icon_url" xml:"icon_url"`
+	PrecipitationChance int     `json:"precipitation_chance" xml:"precipitation_chance"`
+}
+
+// HourlyForecastResponse represents the hour-by-hour forecast we'll return
+type HourlyForecastResponse struct {
+	XMLName       xml.Name         `json:"-" xml:"hourly_forecast"`
+	ZipCode       string           `json:"zip_code" xml:"zip_code"`
+	Location      string           `json:"location" xml:"location"`
+	Units         string           `json:"units" xml:"units"`
+	IntervalHours int              `json:"interval_hours" xml:"interval_hours"`
+	Hours         []HourlyForecast `json:"hours" xml:"hours>hour"`
+}
+
+// HourlyForecast represents the forecast for a single forecast period
+type HourlyForecast struct {
+	Time                string  `json:"time" xml:"time"`
+	Temperature         float64 `json:"temperature" xml:"temperature"`
+	Description         string  `json:"description" xml:"description"`
+	Icon                string  `json:"icon" xml:"icon"`
+	IconURL             string  `json:"icon_url" xml:"icon_url"`
+	PrecipitationChance int     `json:"precipitation_chance" xml:"precipitation_chance"`
+	Precipitation       float64 `json:"precipitation" xml:"precipitation"`
+	WindSpeed           float64 `json:"wind_speed" xml:"wind_speed"`
+	WindDirection       int     `json:"wind_direction" xml:"wind_direction"`
+}
+
+// OpenWeatherMap 5 day / 3 hour forecast response structure (simplified)
+type OpenWeatherForecastAPIResponse struct {
+	List []struct {
+		Dt   int64 `json:"dt"`
+		Main struct {
+			Temp    float64 `json:"temp"`
+			TempMin float64 `json:"temp_min"`
+			TempMax float64 `json:"temp_max"`
+		} `json:"main"`
+		Weather []struct {
+			Description string `json:"description"`
+			Icon        string `json:"icon"`
+		} `json:"weather"`
+		Wind struct {
+			Speed float64 `json:"speed"`
+			Deg   int     `json:"deg"`
+		} `json:"wind"`
+		Rain struct {
+			ThreeHour float64 `json:"3h"`
+		} `json:"rain"`
+		Snow struct {
+			ThreeHour float64 `json:"3h"`
+		} `json:"snow"`
+		Pop float64 `json:"pop"`
+	} `json:"list"`
+	City struct {
+		Name     string `json:"name"`
+		Timezone int    `json:"timezone"`
+	} `json:"city"`
+}
+
+// fetchForecast retrieves the raw 5 day / 3 hour forecast for a location
+func (s *Server) fetchForecast(ctx context.Context, loc Location, opts Options, apiKey string) (*OpenWeatherForecastAPIResponse, error) {
+	// Build API request - the forecast API accepts the same location query as current weather
+	params, err := s.locationParams(ctx, loc, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	params.Add("appid", apiKey)
+	params.Add("units", opts.units())
+	params.Add("lang", opts.lang())
+
+	var apiResp OpenWeatherForecastAPIResponse
+	if err := s.fetchOpenWeather(ctx, "/data/2.5/forecast", params, &apiResp); err != nil {
+		return nil, err
+	}
+	return &apiResp, nil
+}
+
+func (s *Server) getForecast(ctx context.Context, loc Location, opts Options) (*ForecastResponse, error) {
+	// Use the requested provider when it supplies forecasts
+	provider, err := s.weatherProvider(opts.provider(s.config()))
+	if err != nil {
+		return nil, err
+	}
+	if forecaster, ok := provider.(ForecastProvider); ok {
+		return forecaster.Forecast(ctx, loc, opts)
+	}
+
+	// Get API key from environment variable
+	apiKey := s.config().OpenWeatherAPIKey
+	if s.config().MockMode {
+		// In mock mode, return mock data instead of calling the API
+		return s.mockForecast(loc, opts), nil
+	}
+
+	apiResp, err := s.fetchForecast(ctx, loc, opts, apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ForecastResponse{
+		ZipCode:  loc.ZipCode,
+		Location: apiResp.City.Name,
+		Units:    opts.units(),
+		Days:     s.summarizeForecast(apiResp),
+	}, nil
+}
+
+func (s *Server) getHourlyForecast(ctx context.Context, loc Location, hours int, opts Options) (*HourlyForecastResponse, error) {
+	// Use the requested provider; not all of them supply hourly forecasts
+	provider, err := s.weatherProvider(opts.provider(s.config()))
+	if err != nil {
+		return nil, err
+	}
+	forecaster, ok := provider.(HourlyForecastProvider)
+	if !ok {
+		return nil, withKind(ErrNotImplemented, fmt.Errorf("the %s provider doesn't supply hourly forecasts", provider.Name()))
+	}
+	return forecaster.HourlyForecast(ctx, loc, hours, opts)
+}
+
+func (p openWeatherMapProvider) HourlyForecast(ctx context.Context, loc Location, hours int, opts Options) (*HourlyForecastResponse, error) {
+	apiResp, err := p.s.fetchForecast(ctx, loc, opts, p.apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the entries that start within the requested window
+	zone := time.FixedZone("local", apiResp.City.Timezone)
+	var periods []HourlyForecast
+	for _, entry := range apiResp.List {
+		if entry.Dt >= apiResp.List[0].Dt+int64(hours)*3600 {
+			break
+		}
+
+		description, icon := "clear", defaultIcon
+		if len(entry.Weather) > 0 {
+			description = entry.Weather[0].Description
+			icon = entry.Weather[0].Icon
+		}
+
+		periods = append(periods, HourlyForecast{
+			Time:                time.Unix(entry.Dt, 0).In(zone).Format(time.RFC3339),
+			Temperature:         entry.Main.Temp,
+			Description:         description,
+			Icon:                icon,
+			IconURL:             p.s.iconURL(icon),
+			PrecipitationChance: int(math.Round(entry.Pop * 100)),
+			Precipitation:       convertPrecipitation(entry.Rain.ThreeHour+entry.Snow.ThreeHour, opts.units()),
+			WindSpeed:           entry.Wind.Speed,
+			WindDirection:       entry.Wind.Deg,
+		})
+	}
+
+	return &HourlyForecastResponse{
+		ZipCode:       loc.ZipCode,
+		Location:      apiResp.City.Name,
+		Units:         opts.units(),
+		IntervalHours: forecastIntervalHours,
+		Hours:         periods,
+	}, nil
+}
+
+// summarizeForecast groups 3-hour forecast entries into daily summaries,
+// using the location's local date to decide which day an entry belongs to
+func (s *Server) summarizeForecast(apiResp *OpenWeatherForecastAPIResponse) []DailyForecast {
+	zone := time.FixedZone("local", apiResp.City.Timezone)
+
+	var days []DailyForecast
+	descriptionCounts := map[string]map[string]int{}
+	for _, entry := range apiResp.List {
+		date := time.Unix(entry.Dt, 0).In(zone).Format("2006-01-02")
+
+		// Start a new day when the date changes (entries are chronological)
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			if len(days) == forecastDays {
+				break
+			}
+			days = append(days, DailyForecast{
+				Date: date,
+				High: entry.Main.TempMax,
+				Low:  entry.Main.TempMin,
+			})
+			descriptionCounts[date] = map[string]int{}
+		}
+
+		day := &days[len(days)-1]
+		day.High = math.Max(day.High, entry.Main.TempMax)
+		day.Low = math.Min(day.Low, entry.Main.TempMin)
+		if chance := int(math.Round(entry.Pop * 100)); chance > day.PrecipitationChance {
+			day.PrecipitationChance = chance
+		}
+
+		// Use the most common description (and its icon) for the day
+		if len(entry.Weather) > 0 {
+			counts := descriptionCounts[date]
+			description := entry.Weather[0].Description
+			counts[description]++
+			if day.Description == "" || counts[description] > counts[day.Description] {
+				day.Description = description
+				day.Icon = dayIcon(entry.Weather[0].Icon)
+			}
+		}
+	}
+
+	for i := range days {
+		if days[i].Description == "" {
+			days[i].Description = "clear"
+			days[i].Icon = defaultIcon
+		}
+		days[i].IconURL = s.iconURL(days[i].Icon)
+	}
+	return days
+}
+
+// mockForecast returns demo forecast data starting today
+func (s *Server) mockForecast(loc Location, opts Options) *ForecastResponse {
+	highs := []float64{75.2, 73.8, 70.1, 68.4, 71.9}
+	lows := []float64{61.3, 60.2, 57.8, 55.0, 58.6}
+	descriptions := []string{"partly cloudy", "scattered clouds", "light rain", "overcast clouds", "clear sky"}
+	icons := []string{"02d", "03d", "10d", "04d", "01d"}
+	chances := []int{10, 20, 70, 40, 0}
+
+	today := time.Now()
+	days := make([]DailyForecast, forecastDays)
+	for i := range days {
+		days[i] = DailyForecast{
+			Date:                today.AddDate(0, 0, i).Format("2006-01-02"),
+			High:                convertTemperature(highs[i], opts.units()),
+			Low:                 convertTemperature(lows[i], opts.units()),
+			Description:         descriptions[i] + " (demo data)",
+			Icon:                icons[i],
+			IconURL:             s.iconURL(icons[i]),
+			PrecipitationChance: chances[i],
+		}
+	}
+
+	return &ForecastResponse{
+		ZipCode:  loc.ZipCode,
+		Location: s.mockName(loc),
+		Units:    opts.units(),
+		Days:     days,
+	}
+}
+
+// mockHourlyForecast returns demo hourly forecast data starting at the next hour
+func (s *Server) mockHourlyForecast(loc Location, hours int, opts Options) *HourlyForecastResponse {
+	temperatures := []float64{68.2, 65.9, 63.4, 62.1, 66.8, 72.3, 75.0, 71.6}
+	chances := []int{0, 0, 10, 20, 30, 10, 0, 0}
+
+	start := time.Now().Truncate(time.Hour).Add(time.Hour)
+	var periods []HourlyForecast
+	for i := 0; i*forecastIntervalHours < hours; i++ {
+		periods = append(periods, HourlyForecast{
+			Time:                start.Add(time.Duration(i*forecastIntervalHours) * time.Hour).Format(time.RFC3339),
+			Temperature:         convertTemperature(temperatures[i%len(temperatures)], opts.units()),
+			Description:         "partly cloudy (demo data)",
+			Icon:                "02d",
+			IconURL:             s.iconURL("02d"),
+			PrecipitationChance: chances[i%len(chances)],
+			WindSpeed:           convertSpeed(8.2, opts.units()),
+			WindDirection:       225,
+		})
+	}
+
+	return &HourlyForecastResponse{
+		ZipCode:       loc.ZipCode,
+		Location:      s.mockName(loc),
+		Units:         opts.units(),
+		IntervalHours: forecastIntervalHours,
+		Hours:         periods,
+	}
+}
+
+// Forecast handler using Chi
+func (s *Server) forecastHandler(w http.ResponseWriter, r *http.Request) {
+	// Get zip code and country from query parameters
+	loc, ok := s.zipCodeFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	// Get units from query parameters
+	opts, ok := s.optionsFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	// Get response format from the format parameter or Accept header
+	format, ok := s.formatFromRequest(w, r, formatGeoJSON)
+	if !ok {
+		return
+	}
+
+	// Get forecast data
+	forecast, err := s.weather.Forecast(r.Context(), loc, opts)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	if format == formatGeoJSON {
+		writeGeoJSON(w, s.geoJSONFeature(r.Context(), loc, forecast))
+		return
+	}
+
+	// Return forecast data in the requested format
+	writeFormatted(w, format, forecast)
+}
+
+// hoursFromRequest reads and validates the hours query parameter, the hourly
+// forecast's window. On failure it writes a 400 response and returns false.
+func hoursFromRequest(w http.ResponseWriter, r *http.Request) (int, bool) {
+	value := r.URL.Query().Get("hours")
+	if value == "" {
+		return defaultForecastHours, true
+	}
+	hours, err := strconv.Atoi(value)
+	if err != nil || hours < 1 || hours > maxForecastHours {
+		writeError(w, http.StatusBadRequest, "hours must be an integer between 1 and "+strconv.Itoa(maxForecastHours))
+		return 0, false
+	}
+	return hours, true
+}
+
+// Hourly forecast handler using Chi
+func (s *Server) hourlyForecastHandler(w http.ResponseWriter, r *http.Request) {
+	// Get zip code and country from query parameters
+	loc, ok := s.zipCodeFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	// Get forecast window from query parameter
+	hours, ok := hoursFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	// Get units from query parameters
+	opts, ok := s.optionsFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	// Get response format from the format parameter or Accept header
+	format, ok := s.formatFromRequest(w, r, formatGeoJSON)
+	if !ok {
+		return
+	}
+
+	// Get forecast data
+	forecast, err := s.weather.HourlyForecast(r.Context(), loc, hours, opts)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	if format == formatGeoJSON {
+		writeGeoJSON(w, s.geoJSONFeature(r.Context(), loc, forecast))
+		return
+	}
+
+	// Return forecast data in the requested format
+	writeFormatted(w, format, forecast)
+}
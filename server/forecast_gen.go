@@ -0,0 +1,450 @@
+// Code generated by github.com/tinylib/msgp DO NOT EDIT.
+
+package server
+
+import (
+	"github.com/tinylib/msgp/msgp"
+)
+
+// MarshalMsg implements msgp.Marshaler
+func (z *DailyForecast) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 7
+	// string "date"
+	o = append(o, 0x87, 0xa4, 0x64, 0x61, 0x74, 0x65)
+	o = msgp.AppendString(o, z.Date)
+	// string "high"
+	o = append(o, 0xa4, 0x68, 0x69, 0x67, 0x68)
+	o = msgp.AppendFloat64(o, z.High)
+	// string "low"
+	o = append(o, 0xa3, 0x6c, 0x6f, 0x77)
+	o = msgp.AppendFloat64(o, z.Low)
+	// string "description"
+	o = append(o, 0xab, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e)
+	o = msgp.AppendString(o, z.Description)
+	// string "icon"
+	o = append(o, 0xa4, 0x69, 0x63, 0x6f, 0x6e)
+	o = msgp.AppendString(o, z.Icon)
+	// string "icon_url"
+	o = append(o, 0xa8, 0x69, 0x63, 0x6f, 0x6e, 0x5f, 0x75, 0x72, 0x6c)
+	o = msgp.AppendString(o, z.IconURL)
+	// string "precipitation_chance"
+	o = append(o, 0xb4, 0x70, 0x72, 0x65, 0x63, 0x69, 0x70, 0x69, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x63, 0x68, 0x61, 0x6e, 0x63, 0x65)
+	o = msgp.AppendInt(o, z.PrecipitationChance)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *DailyForecast) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "date":
+			z.Date, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Date")
+				return
+			}
+		case "high":
+			z.High, bts, err = msgp.ReadFloat64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "High")
+				return
+			}
+		case "low":
+			z.Low, bts, err = msgp.ReadFloat64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Low")
+				return
+			}
+		case "description":
+			z.Description, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Description")
+				return
+			}
+		case "icon":
+			z.Icon, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Icon")
+				return
+			}
+		case "icon_url":
+			z.IconURL, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "IconURL")
+				return
+			}
+		case "precipitation_chance":
+			z.PrecipitationChance, bts, err = msgp.ReadIntBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "PrecipitationChance")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *DailyForecast) Msgsize() (s int) {
+	s = 1 + 5 + msgp.StringPrefixSize + len(z.Date) + 5 + msgp.Float64Size + 4 + msgp.Float64Size + 12 + msgp.StringPrefixSize + len(z.Description) + 5 + msgp.StringPrefixSize + len(z.Icon) + 9 + msgp.StringPrefixSize + len(z.IconURL) + 21 + msgp.IntSize
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *ForecastResponse) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 4
+	// string "zip_code"
+	o = append(o, 0x84, 0xa8, 0x7a, 0x69, 0x70, 0x5f, 0x63, 0x6f, 0x64, 0x65)
+	o = msgp.AppendString(o, z.ZipCode)
+	// string "location"
+	o = append(o, 0xa8, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e)
+	o = msgp.AppendString(o, z.Location)
+	// string "units"
+	o = append(o, 0xa5, 0x75, 0x6e, 0x69, 0x74, 0x73)
+	o = msgp.AppendString(o, z.Units)
+	// string "days"
+	o = append(o, 0xa4, 0x64, 0x61, 0x79, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Days)))
+	for za0001 := range z.Days {
+		o, err = z.Days[za0001].MarshalMsg(o)
+		if err != nil {
+			err = msgp.WrapError(err, "Days", za0001)
+			return
+		}
+	}
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *ForecastResponse) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "zip_code":
+			z.ZipCode, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "ZipCode")
+				return
+			}
+		case "location":
+			z.Location, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Location")
+				return
+			}
+		case "units":
+			z.Units, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Units")
+				return
+			}
+		case "days":
+			var zb0002 uint32
+			zb0002, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Days")
+				return
+			}
+			if cap(z.Days) >= int(zb0002) {
+				z.Days = (z.Days)[:zb0002]
+			} else {
+				z.Days = make([]DailyForecast, zb0002)
+			}
+			for za0001 := range z.Days {
+				bts, err = z.Days[za0001].UnmarshalMsg(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Days", za0001)
+					return
+				}
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *ForecastResponse) Msgsize() (s int) {
+	s = 1 + 9 + msgp.StringPrefixSize + len(z.ZipCode) + 9 + msgp.StringPrefixSize + len(z.Location) + 6 + msgp.StringPrefixSize + len(z.Units) + 5 + msgp.ArrayHeaderSize
+	for za0001 := range z.Days {
+		s += z.Days[za0001].Msgsize()
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *HourlyForecast) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 9
+	// string "time"
+	o = append(o, 0x89, 0xa4, 0x74, 0x69, 0x6d, 0x65)
+	o = msgp.AppendString(o, z.Time)
+	// string "temperature"
+	o = append(o, 0xab, 0x74, 0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65)
+	o = msgp.AppendFloat64(o, z.Temperature)
+	// string "description"
+	o = append(o, 0xab, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e)
+	o = msgp.AppendString(o, z.Description)
+	// string "icon"
+	o = append(o, 0xa4, 0x69, 0x63, 0x6f, 0x6e)
+	o = msgp.AppendString(o, z.Icon)
+	// string "icon_url"
+	o = append(o, 0xa8, 0x69, 0x63, 0x6f, 0x6e, 0x5f, 0x75, 0x72, 0x6c)
+	o = msgp.AppendString(o, z.IconURL)
+	// string "precipitation_chance"
+	o = append(o, 0xb4, 0x70, 0x72, 0x65, 0x63, 0x69, 0x70, 0x69, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x63, 0x68, 0x61, 0x6e, 0x63, 0x65)
+	o = msgp.AppendInt(o, z.PrecipitationChance)
+	// string "precipitation"
+	o = append(o, 0xad, 0x70, 0x72, 0x65, 0x63, 0x69, 0x70, 0x69, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e)
+	o = msgp.AppendFloat64(o, z.Precipitation)
+	// string "wind_speed"
+	o = append(o, 0xaa, 0x77, 0x69, 0x6e, 0x64, 0x5f, 0x73, 0x70, 0x65, 0x65, 0x64)
+	o = msgp.AppendFloat64(o, z.WindSpeed)
+	// string "wind_direction"
+	o = append(o, 0xae, 0x77, 0x69, 0x6e, 0x64, 0x5f, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e)
+	o = msgp.AppendInt(o, z.WindDirection)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *HourlyForecast) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "time":
+			z.Time, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Time")
+				return
+			}
+		case "temperature":
+			z.Temperature, bts, err = msgp.ReadFloat64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Temperature")
+				return
+			}
+		case "description":
+			z.Description, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Description")
+				return
+			}
+		case "icon":
+			z.Icon, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Icon")
+				return
+			}
+		case "icon_url":
+			z.IconURL, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "IconURL")
+				return
+			}
+		case "precipitation_chance":
+			z.PrecipitationChance, bts, err = msgp.ReadIntBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "PrecipitationChance")
+				return
+			}
+		case "precipitation":
+			z.Precipitation, bts, err = msgp.ReadFloat64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Precipitation")
+				return
+			}
+		case "wind_speed":
+			z.WindSpeed, bts, err = msgp.ReadFloat64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "WindSpeed")
+				return
+			}
+		case "wind_direction":
+			z.WindDirection, bts, err = msgp.ReadIntBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "WindDirection")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *HourlyForecast) Msgsize() (s int) {
+	s = 1 + 5 + msgp.StringPrefixSize + len(z.Time) + 12 + msgp.Float64Size + 12 + msgp.StringPrefixSize + len(z.Description) + 5 + msgp.StringPrefixSize + len(z.Icon) + 9 + msgp.StringPrefixSize + len(z.IconURL) + 21 + msgp.IntSize + 14 + msgp.Float64Size + 11 + msgp.Float64Size + 15 + msgp.IntSize
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *HourlyForecastResponse) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 5
+	// string "zip_code"
+	o = append(o, 0x85, 0xa8, 0x7a, 0x69, 0x70, 0x5f, 0x63, 0x6f, 0x64, 0x65)
+	o = msgp.AppendString(o, z.ZipCode)
+	// string "location"
+	o = append(o, 0xa8, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e)
+	o = msgp.AppendString(o, z.Location)
+	// string "units"
+	o = append(o, 0xa5, 0x75, 0x6e, 0x69, 0x74, 0x73)
+	o = msgp.AppendString(o, z.Units)
+	// string "interval_hours"
+	o = append(o, 0xae, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x5f, 0x68, 0x6f, 0x75, 0x72, 0x73)
+	o = msgp.AppendInt(o, z.IntervalHours)
+	// string "hours"
+	o = append(o, 0xa5, 0x68, 0x6f, 0x75, 0x72, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Hours)))
+	for za0001 := range z.Hours {
+		o, err = z.Hours[za0001].MarshalMsg(o)
+		if err != nil {
+			err = msgp.WrapError(err, "Hours", za0001)
+			return
+		}
+	}
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *HourlyForecastResponse) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "zip_code":
+			z.ZipCode, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "ZipCode")
+				return
+			}
+		case "location":
+			z.Location, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Location")
+				return
+			}
+		case "units":
+			z.Units, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Units")
+				return
+			}
+		case "interval_hours":
+			z.IntervalHours, bts, err = msgp.ReadIntBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "IntervalHours")
+				return
+			}
+		case "hours":
+			var zb0002 uint32
+			zb0002, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Hours")
+				return
+			}
+			if cap(z.Hours) >= int(zb0002) {
+				z.Hours = (z.Hours)[:zb0002]
+			} else {
+				z.Hours = make([]HourlyForecast, zb0002)
+			}
+			for za0001 := range z.Hours {
+				bts, err = z.Hours[za0001].UnmarshalMsg(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Hours", za0001)
+					return
+				}
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *HourlyForecastResponse) Msgsize() (s int) {
+	s = 1 + 9 + msgp.StringPrefixSize + len(z.ZipCode) + 9 + msgp.StringPrefixSize + len(z.Location) + 6 + msgp.StringPrefixSize + len(z.Units) + 15 + msgp.IntSize + 6 + msgp.ArrayHeaderSize
+	for za0001 := range z.Hours {
+		s += z.Hours[za0001].Msgsize()
+	}
+	return
+}
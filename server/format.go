@@ -0,0 +1,418 @@
+package server
+
+//go:generate msgp -file main.go -o main_gen.go -io=false -tests=false -d "tag json"
+//go:generate msgp -file batch.go -o batch_gen.go -io=false -tests=false -d "tag json"
+//go:generate msgp -file consensus.go -o consensus_gen.go -io=false -tests=false -d "tag json"
+//go:generate msgp -file forecast.go -o forecast_gen.go -io=false -tests=false -d "tag json" -d "ignore OpenWeatherForecastAPIResponse"
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dekkagaijin/go-container-test/weatherpb"
+	"github.com/tinylib/msgp/msgp"
+	"google.golang.org/protobuf/proto"
+)
+
+// Response formats for weather and forecast responses
+const (
+	formatJSON     = "json"
+	formatXML      = "xml"
+	formatCSV      = "csv"
+	formatProtobuf = "protobuf"
+	formatMsgpack  = "msgpack"
+	formatText     = "text"
+
+	// Newline-delimited JSON, streamed by POST /weather/batch only
+	formatNDJSON      = "ndjson"
+	ndjsonContentType = "application/x-ndjson"
+)
+
+// Formats every formatted response can be written in, in the order error messages list them
+var formatNames = []string{formatJSON, formatXML, formatCSV, formatProtobuf, formatMsgpack, formatText}
+
+// responseFormat is how a response is written in one format
+type responseFormat struct {
+	contentType string
+	write       func(w io.Writer, v formattedResponse) error
+}
+
+var responseFormats = map[string]responseFormat{
+	formatJSON: {"application/json", func(w io.Writer, v formattedResponse) error {
+		return json.NewEncoder(w).Encode(v)
+	}},
+	formatXML: {"application/xml; charset=utf-8", func(w io.Writer, v formattedResponse) error {
+		if _, err := io.WriteString(w, xml.Header); err != nil {
+			return err
+		}
+		encoder := xml.NewEncoder(w)
+		encoder.Indent("", "  ")
+		if err := encoder.Encode(v); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "\n")
+		return err
+	}},
+	formatCSV: {"text/csv; charset=utf-8", func(w io.Writer, v formattedResponse) error {
+		return csv.NewWriter(w).WriteAll(v.csvRecords())
+	}},
+	formatProtobuf: {"application/x-protobuf", func(w io.Writer, v formattedResponse) error {
+		data, err := proto.Marshal(v.protoMessage())
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}},
+	formatMsgpack: {"application/msgpack", func(w io.Writer, v formattedResponse) error {
+		data, err := v.MarshalMsg(nil)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}},
+	formatText: {"text/plain; charset=utf-8", func(w io.Writer, v formattedResponse) error {
+		_, err := io.WriteString(w, v.plainText())
+		return err
+	}},
+}
+
+// Formats for the media types of an Accept header
+var acceptFormats = map[string]string{
+	"application/json":       formatJSON,
+	"application/xml":        formatXML,
+	"text/xml":               formatXML,
+	"text/csv":               formatCSV,
+	"text/plain":             formatText,
+	"application/x-protobuf": formatProtobuf,
+	"application/protobuf":   formatProtobuf,
+	"application/msgpack":    formatMsgpack,
+	"application/x-msgpack":  formatMsgpack,
+	"application/x-ndjson":   formatNDJSON,
+	"application/ndjson":     formatNDJSON,
+	"application/geo+json":   formatGeoJSON,
+	"application/*":          formatJSON,
+	"*/*":                    formatJSON,
+}
+
+// formattedResponse is a response that can be written in every format. XML
+// uses the struct's xml tags; CSV is a header row followed by the records;
+// protobuf is the weatherpb message gRPC clients also get; MessagePack
+// uses the JSON field names, with encoders generated by msgp; and text is a
+// short summary for terminals.
+type formattedResponse interface {
+	msgp.Marshaler
+	csvRecords() [][]string
+	protoMessage() proto.Message
+	plainText() string
+}
+
+// formatFromRequest reads the format query parameter, falling back to the
+// most preferred format in the Accept header, then JSON. With CLI_PLAIN_TEXT,
+// command-line clients that accept anything get text. Handlers that can also
+// write formats only they support, such as NDJSON, pass them as extra.
+func (s *Server) formatFromRequest(w http.ResponseWriter, r *http.Request, extra ...string) (string, bool) {
+	// The response depends on Accept, so caches must key on it
+	w.Header().Add("Vary", "Accept")
+	plainTextForCLI := s.config().CLIPlainText
+	if plainTextForCLI {
+		w.Header().Add("Vary", "User-Agent")
+	}
+
+	supported := append(slices.Clone(formatNames), extra...)
+	if format := r.URL.Query().Get("format"); format != "" {
+		if !slices.Contains(supported, format) {
+			writeError(w, http.StatusBadRequest, "format must be one of: "+strings.Join(supported, ", "))
+			return "", false
+		}
+		return format, true
+	}
+	if accept := r.Header.Get("Accept"); plainTextForCLI && (accept == "" || accept == "*/*") && isCLIClient(r.UserAgent()) {
+		return formatText, true
+	}
+	if format := formatFromAccept(r.Header.Get("Accept"), supported); format != "" {
+		return format, true
+	}
+	return formatJSON, true
+}
+
+// formatFromAccept picks the most preferred of the supported formats from an
+// Accept header, returning "" if none is supported
+func formatFromAccept(header string, supported []string) string {
+	type preference struct {
+		format  string
+		quality float64
+	}
+
+	var preferences []preference
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if value, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				quality = parsed
+			}
+		}
+
+		if format, ok := acceptFormats[mediaType]; ok && slices.Contains(supported, format) && quality > 0 {
+			preferences = append(preferences, preference{format, quality})
+		}
+	}
+
+	if len(preferences) == 0 {
+		return ""
+	}
+	sort.SliceStable(preferences, func(i, j int) bool {
+		return preferences[i].quality > preferences[j].quality
+	})
+	return preferences[0].format
+}
+
+// User-Agent prefixes of command-line HTTP clients
+var cliUserAgents = []string{"curl/", "Wget/", "HTTPie/", "xh/"}
+
+// isCLIClient reports whether a User-Agent is a command-line HTTP client
+func isCLIClient(userAgent string) bool {
+	for _, prefix := range cliUserAgents {
+		if strings.HasPrefix(userAgent, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeFormatted writes a successful response in the given format
+func writeFormatted(w http.ResponseWriter, format string, v formattedResponse) {
+	f := responseFormats[format]
+	w.Header().Set("Content-Type", f.contentType)
+	w.WriteHeader(http.StatusOK)
+	f.write(w, v)
+}
+
+// CSV columns of current conditions, matching their JSON fields
+var weatherCSVHeader = []string{
+	"zip_code", "location", "units", "temperature", "feels_like", "dew_point",
+	"description", "icon", "icon_url", "humidity", "pressure", "visibility",
+	"cloud_cover", "wind_speed", "wind_direction", "wind_gust", "observed_at",
+	"local_time", "cache", "stale", "source",
+}
+
+// formatFloat formats a number for CSV without trailing zeros
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// csvRow returns the conditions' values in weatherCSVHeader order
+func (weather WeatherResponse) csvRow() []string {
+	return []string{
+		weather.ZipCode, weather.Location, weather.Units,
+		formatFloat(weather.Temperature), formatFloat(weather.FeelsLike), formatFloat(weather.DewPoint),
+		weather.Description, weather.Icon, weather.IconURL,
+		strconv.Itoa(weather.Humidity), strconv.Itoa(weather.Pressure), strconv.Itoa(weather.Visibility),
+		strconv.Itoa(weather.CloudCover), formatFloat(weather.WindSpeed), strconv.Itoa(weather.WindDirection),
+		formatFloat(weather.WindGust), weather.ObservedAt, weather.LocalTime,
+		weather.Cache, strconv.FormatBool(weather.Stale), weather.Source,
+	}
+}
+
+func (weather WeatherResponse) csvRecords() [][]string {
+	return [][]string{weatherCSVHeader, weather.csvRow()}
+}
+
+// A row per zip code, with the error or the weather's columns
+func (batch BatchWeatherResponse) csvRecords() [][]string {
+	records := [][]string{append([]string{"zip_code", "error"}, weatherCSVHeader[1:]...)}
+	for _, result := range batch.Results {
+		row := []string{result.ZipCode, result.Error}
+		if result.Weather != nil {
+			row = append(row, result.Weather.csvRow()[1:]...)
+		} else {
+			row = append(row, make([]string, len(weatherCSVHeader)-1)...)
+		}
+		records = append(records, row)
+	}
+	return records
+}
+
+// A row with the averages, provider "consensus", then a row per provider
+func (consensus ConsensusResponse) csvRecords() [][]string {
+	records := [][]string{
+		{"zip_code", "location", "units", "provider", "temperature", "humidity", "error"},
+		{consensus.ZipCode, consensus.Location, consensus.Units, modeConsensus,
+			formatFloat(consensus.Temperature), strconv.Itoa(consensus.Humidity), ""},
+	}
+	for _, reading := range consensus.Providers {
+		row := []string{consensus.ZipCode, consensus.Location, consensus.Units, reading.Provider, "", "", reading.Error}
+		if reading.Weather != nil {
+			row[4], row[5] = formatFloat(reading.Weather.Temperature), strconv.Itoa(reading.Weather.Humidity)
+		}
+		records = append(records, row)
+	}
+	return records
+}
+
+// A row per day
+func (forecast ForecastResponse) csvRecords() [][]string {
+	records := [][]string{{"zip_code", "location", "units", "date", "high", "low",
+		"description", "icon", "icon_url", "precipitation_chance"}}
+	for _, day := range forecast.Days {
+		records = append(records, []string{forecast.ZipCode, forecast.Location, forecast.Units,
+			day.Date, formatFloat(day.High), formatFloat(day.Low),
+			day.Description, day.Icon, day.IconURL, strconv.Itoa(day.PrecipitationChance)})
+	}
+	return records
+}
+
+// A row per forecast period
+func (forecast HourlyForecastResponse) csvRecords() [][]string {
+	records := [][]string{{"zip_code", "location", "units", "time", "temperature",
+		"description", "icon", "icon_url", "precipitation_chance", "precipitation",
+		"wind_speed", "wind_direction"}}
+	for _, hour := range forecast.Hours {
+		records = append(records, []string{forecast.ZipCode, forecast.Location, forecast.Units,
+			hour.Time, formatFloat(hour.Temperature),
+			hour.Description, hour.Icon, hour.IconURL, strconv.Itoa(hour.PrecipitationChance),
+			formatFloat(hour.Precipitation), formatFloat(hour.WindSpeed), strconv.Itoa(hour.WindDirection)})
+	}
+	return records
+}
+
+func (weather WeatherResponse) protoMessage() proto.Message {
+	return weatherMessage(weather)
+}
+
+func (batch BatchWeatherResponse) protoMessage() proto.Message {
+	message := &weatherpb.BatchWeather{}
+	for _, result := range batch.Results {
+		item := &weatherpb.BatchResult{ZipCode: result.ZipCode, Error: result.Error}
+		if result.Weather != nil {
+			item.Weather = weatherMessage(*result.Weather)
+		}
+		message.Results = append(message.Results, item)
+	}
+	return message
+}
+
+func (consensus ConsensusResponse) protoMessage() proto.Message {
+	message := &weatherpb.Consensus{
+		ZipCode:           consensus.ZipCode,
+		Location:          consensus.Location,
+		Units:             consensus.Units,
+		Temperature:       consensus.Temperature,
+		Humidity:          int32(consensus.Humidity),
+		TemperatureSpread: consensus.TemperatureSpread,
+		ProviderCount:     int32(consensus.ProviderCount),
+	}
+	for _, reading := range consensus.Providers {
+		item := &weatherpb.ProviderReading{Provider: reading.Provider, Error: reading.Error}
+		if reading.Weather != nil {
+			item.Weather = weatherMessage(*reading.Weather)
+		}
+		message.Providers = append(message.Providers, item)
+	}
+	return message
+}
+
+func (forecast ForecastResponse) protoMessage() proto.Message {
+	return forecastMessage(forecast)
+}
+
+func (forecast HourlyForecastResponse) protoMessage() proto.Message {
+	message := &weatherpb.HourlyForecast{
+		ZipCode:       forecast.ZipCode,
+		Location:      forecast.Location,
+		Units:         forecast.Units,
+		IntervalHours: int32(forecast.IntervalHours),
+	}
+	for _, hour := range forecast.Hours {
+		message.Hours = append(message.Hours, &weatherpb.HourlyPeriod{
+			Time:                hour.Time,
+			Temperature:         hour.Temperature,
+			Description:         hour.Description,
+			Icon:                hour.Icon,
+			IconUrl:             hour.IconURL,
+			PrecipitationChance: int32(hour.PrecipitationChance),
+			Precipitation:       hour.Precipitation,
+			WindSpeed:           hour.WindSpeed,
+			WindDirection:       int32(hour.WindDirection),
+		})
+	}
+	return message
+}
+
+// summary is the conditions in one line, e.g. "New York: ☀️ 72°F, wind 8mph"
+func (weather WeatherResponse) summary() string {
+	temperature, speed := unitSymbols(weather.Units)
+	return fmt.Sprintf("%s: %s %.0f%s, wind %.0f%s", cmp.Or(weather.Location, weather.ZipCode),
+		iconEmoji(weather.Icon), weather.Temperature, temperature, weather.WindSpeed, speed)
+}
+
+func (weather WeatherResponse) plainText() string {
+	return weather.summary() + "\n"
+}
+
+// A line per zip code
+func (batch BatchWeatherResponse) plainText() string {
+	var b strings.Builder
+	for _, result := range batch.Results {
+		if result.Weather != nil {
+			fmt.Fprintf(&b, "%s %s\n", result.ZipCode, result.Weather.summary())
+		} else {
+			fmt.Fprintf(&b, "%s: %s\n", result.ZipCode, result.Error)
+		}
+	}
+	return b.String()
+}
+
+func (consensus ConsensusResponse) plainText() string {
+	temperature, _ := unitSymbols(consensus.Units)
+	return fmt.Sprintf("%s: %.0f%s, humidity %d%% (consensus of %d providers)\n", consensus.Location,
+		consensus.Temperature, temperature, consensus.Humidity, consensus.ProviderCount)
+}
+
+// The location, then a line per day
+func (forecast ForecastResponse) plainText() string {
+	temperature, _ := unitSymbols(forecast.Units)
+	var b strings.Builder
+	b.WriteString(forecast.Location + "\n")
+	for _, day := range forecast.Days {
+		date := day.Date
+		if parsed, err := time.Parse(time.DateOnly, day.Date); err == nil {
+			date = parsed.Format("Mon Jan 2")
+		}
+		fmt.Fprintf(&b, "  %-10s %s %.0f%s / %.0f%s, %d%% precipitation\n", date, iconEmoji(day.Icon),
+			day.High, temperature, day.Low, temperature, day.PrecipitationChance)
+	}
+	return b.String()
+}
+
+// The location, then a line per forecast period
+func (forecast HourlyForecastResponse) plainText() string {
+	temperature, speed := unitSymbols(forecast.Units)
+	var b strings.Builder
+	b.WriteString(forecast.Location + "\n")
+	for _, hour := range forecast.Hours {
+		at := hour.Time
+		if parsed, err := time.Parse(time.RFC3339, hour.Time); err == nil {
+			at = parsed.Format("Mon 15:04")
+		}
+		fmt.Fprintf(&b, "  %s %s %.0f%s, wind %.0f%s, %d%% precipitation\n", at, iconEmoji(hour.Icon),
+			hour.Temperature, temperature, hour.WindSpeed, speed, hour.PrecipitationChance)
+	}
+	return b.String()
+}
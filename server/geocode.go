@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Coordinates represents a geocoded location
+type Coordinates struct {
+	Name string  `json:"name"`
+	Lat  float64 `json:"lat"`
+	Lon  float64 `json:"lon"`
+}
+
+// OpenWeatherMap geocoding API response structure (simplified)
+type OpenWeatherGeocodeAPIResponse struct {
+	Name string  `json:"name"`
+	Lat  float64 `json:"lat"`
+	Lon  float64 `json:"lon"`
+}
+
+// geocodeZipCode resolves a zip code to coordinates using the zip code database,
+// or the OpenWeatherMap geocoding API for zip codes it doesn't have
+func (s *Server) geocodeZipCode(ctx context.Context, loc Location, apiKey string) (*Coordinates, error) {
+	if coords, ok := s.lookupZipCode(loc); ok {
+		return coords, nil
+	}
+
+	params := url.Values{}
+	params.Add("zip", loc.upstreamZipCode())
+	params.Add("appid", apiKey)
+
+	var apiResp OpenWeatherGeocodeAPIResponse
+	if err := s.fetchOpenWeather(ctx, "/geo/1.0/zip", params, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to geocode zip code: %w", err)
+	}
+
+	return &Coordinates{
+		Name: apiResp.Name,
+		Lat:  apiResp.Lat,
+		Lon:  apiResp.Lon,
+	}, nil
+}
+
+// geocodeCity resolves a "City" or "City,ST" query to coordinates using the OpenWeatherMap geocoding API
+func (s *Server) geocodeCity(ctx context.Context, loc Location, apiKey string) (*Coordinates, error) {
+	params := url.Values{}
+	params.Add("q", loc.City+","+loc.country())
+	params.Add("limit", "1")
+	params.Add("appid", apiKey)
+
+	var apiResp []OpenWeatherGeocodeAPIResponse
+	if err := s.fetchOpenWeather(ctx, "/geo/1.0/direct", params, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to geocode city: %w", err)
+	}
+
+	if len(apiResp) == 0 {
+		return nil, withKind(ErrNotFound, fmt.Errorf("no location found for city %q", loc.City))
+	}
+
+	return &Coordinates{
+		Name: apiResp[0].Name,
+		Lat:  apiResp[0].Lat,
+		Lon:  apiResp[0].Lon,
+	}, nil
+}
+
+// Zippopotam.us postal code API base URL (no API key required)
+const zippopotamBaseURL = "https://api.zippopotam.us"
+
+// Zippopotam.us postal code API response structure (simplified)
+type ZippopotamAPIResponse struct {
+	Places []struct {
+		PlaceName string `json:"place name"`
+		Latitude  string `json:"latitude"`
+		Longitude string `json:"longitude"`
+	} `json:"places"`
+}
+
+// geocodeZipCodeKeyless resolves a zip code to coordinates using the zip code
+// database or Zippopotam.us, for providers that don't have an OpenWeatherMap
+// API key to geocode with
+func (s *Server) geocodeZipCodeKeyless(ctx context.Context, loc Location) (*Coordinates, error) {
+	if coords, ok := s.lookupZipCode(loc); ok {
+		return coords, nil
+	}
+
+	code := postalCodeFormats[loc.country()].upstream(loc.ZipCode)
+	fullURL := fmt.Sprintf("%s/%s/%s", zippopotamBaseURL, strings.ToLower(loc.country()), url.PathEscape(code))
+	var apiResp ZippopotamAPIResponse
+	if err := s.fetchJSON(ctx, fullURL, "geocoding", &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to geocode zip code: %w", err)
+	}
+	if len(apiResp.Places) == 0 {
+		return nil, withKind(ErrNotFound, fmt.Errorf("no location found for zip code %q", loc.ZipCode))
+	}
+
+	place := apiResp.Places[0]
+	lat, latErr := strconv.ParseFloat(place.Latitude, 64)
+	lon, lonErr := strconv.ParseFloat(place.Longitude, 64)
+	if latErr != nil || lonErr != nil {
+		return nil, withKind(ErrUpstreamUnavailable, fmt.Errorf("failed to parse geocoding data for zip code %q", loc.ZipCode))
+	}
+
+	return &Coordinates{
+		Name: place.PlaceName,
+		Lat:  lat,
+		Lon:  lon,
+	}, nil
+}
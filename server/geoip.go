@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// Default IP geolocation API (ip-api.com, no key required)
+const defaultGeoIPURL = "http://ip-api.com/json/"
+
+// ip-api.com response structure (simplified)
+type GeoIPAPIResponse struct {
+	Status  string  `json:"status"`
+	Message string  `json:"message"`
+	City    string  `json:"city"`
+	Lat     float64 `json:"lat"`
+	Lon     float64 `json:"lon"`
+}
+
+// clientIP returns the caller's IP address. realIP has already replaced
+// RemoteAddr with the address a trusted proxy forwarded, if any.
+func clientIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		// realIP sets RemoteAddr to a bare IP without a port
+		host = r.RemoteAddr
+	}
+	return net.ParseIP(host)
+}
+
+// geolocateIP resolves a public IP address to coordinates.
+// The lookup URL can be changed with GEOIP_API_URL (the IP is appended to it).
+func (s *Server) geolocateIP(ctx context.Context, ip net.IP) (*Coordinates, error) {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() {
+		return nil, fmt.Errorf("cannot determine location for non-public IP address %s", ip)
+	}
+
+	var apiResp GeoIPAPIResponse
+	if err := s.fetchJSON(ctx, s.config().GeoIPURL+url.PathEscape(ip.String()), "geolocation", &apiResp); err != nil {
+		return nil, err
+	}
+
+	if apiResp.Status != "success" {
+		return nil, withKind(ErrNotFound, fmt.Errorf("cannot determine location for IP address %s: %s", ip, apiResp.Message))
+	}
+
+	return &Coordinates{
+		Name: apiResp.City,
+		Lat:  apiResp.Lat,
+		Lon:  apiResp.Lon,
+	}, nil
+}
+
+// Caller location weather handler using Chi
+func (s *Server) myWeatherHandler(w http.ResponseWriter, r *http.Request) {
+	ip := clientIP(r)
+	if ip == nil {
+		writeError(w, http.StatusBadRequest, "cannot determine client IP address")
+		return
+	}
+
+	// Get units from query parameters
+	opts, ok := s.optionsFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	// Get response format from the format parameter or Accept header
+	format, ok := s.formatFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	// In mock mode, skip geolocation
+	loc := Location{Coords: &Coordinates{}}
+	if !s.config().MockMode {
+		coords, err := s.geolocateIP(r.Context(), ip)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		loc.Coords = coords
+	}
+
+	// Get weather data
+	weather, err := s.weather.CurrentWeather(r.Context(), loc, opts)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	// Return weather data in the requested format
+	setCacheStatusHeader(w, weather)
+	writeFormatted(w, format, weather)
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// GeoJSON (RFC 7946), written by the weather, batch, and forecast endpoints
+// only, since it needs the coordinates of each location
+const (
+	formatGeoJSON      = "geojson"
+	geoJSONContentType = "application/geo+json"
+)
+
+// GeoJSONFeatureCollection is a GeoJSON response, with a feature per location
+type GeoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []GeoJSONFeature `json:"features"`
+}
+
+// GeoJSONFeature is a location's point, with the response for it as properties
+type GeoJSONFeature struct {
+	Type       string        `json:"type"`
+	Geometry   *GeoJSONPoint `json:"geometry"` // null when the location couldn't be geocoded
+	Properties interface{}   `json:"properties"`
+}
+
+// GeoJSONPoint is a location's coordinates
+type GeoJSONPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"` // longitude, latitude
+}
+
+// geoJSONFeature returns a feature for a location, geocoding it if its
+// coordinates aren't known. Zip codes in the zip code database don't need an
+// upstream call.
+func (s *Server) geoJSONFeature(ctx context.Context, loc Location, properties interface{}) GeoJSONFeature {
+	feature := GeoJSONFeature{Type: "Feature", Properties: properties}
+	coords, err := s.resolveCoordinates(ctx, loc, s.config().OpenWeatherAPIKey)
+	if err != nil {
+		debugf("GeoJSON feature without coordinates: %v", err)
+		return feature
+	}
+	feature.Geometry = &GeoJSONPoint{Type: "Point", Coordinates: [2]float64{coords.Lon, coords.Lat}}
+	return feature
+}
+
+// writeGeoJSON writes features as a GeoJSON FeatureCollection
+func writeGeoJSON(w http.ResponseWriter, features ...GeoJSONFeature) {
+	w.Header().Set("Content-Type", geoJSONContentType)
+	json.NewEncoder(w).Encode(GeoJSONFeatureCollection{Type: "FeatureCollection", Features: features})
+}
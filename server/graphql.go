@@ -0,0 +1,339 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+)
+
+// GraphQL request limits
+const (
+	maxGraphQLBodySize = 64 << 10 // 64 KiB
+	maxGraphQLDepth    = 8
+	maxGraphQLLookups  = maxBatchSize // weather, forecast, and alerts fields and batch zip codes per query
+)
+
+// The /graphql schema. Types mirror the JSON API's responses, with fields in camelCase.
+const graphQLSchemaSDL = `
+schema {
+	query: Query
+}
+
+type Query {
+	# Current weather at a zip code, like GET /weather
+	weather(zipCode: String!, country: String, units: String, lang: String, provider: String): Weather!
+	# The 5-day forecast at a zip code, like GET /forecast
+	forecast(zipCode: String!, country: String, units: String, lang: String, provider: String): Forecast!
+	# Severe and extreme weather alerts in effect at a US zip code
+	alerts(zipCode: String!): [Alert!]!
+	# Current weather at up to 50 US zip codes, like POST /weather/batch
+	batch(zipCodes: [String!]!, units: String, lang: String, provider: String): [BatchResult!]!
+}
+
+type Weather {
+	zipCode: String!
+	location: String!
+	units: String!
+	temperature: Float!
+	feelsLike: Float!
+	dewPoint: Float!
+	description: String!
+	icon: String!
+	iconUrl: String!
+	humidity: Int!
+	pressure: Int!
+	visibility: Int!
+	cloudCover: Int!
+	windSpeed: Float!
+	windDirection: Int!
+	windGust: Float!
+	observedAt: String!
+	localTime: String!
+	cache: String!
+	stale: Boolean!
+	source: String!
+}
+
+type Forecast {
+	zipCode: String!
+	location: String!
+	units: String!
+	days: [DailyForecast!]!
+}
+
+type DailyForecast {
+	date: String!
+	high: Float!
+	low: Float!
+	description: String!
+	icon: String!
+	iconUrl: String!
+	precipitationChance: Int!
+}
+
+type Alert {
+	id: String!
+	event: String!
+	headline: String!
+	severity: String!
+	urgency: String!
+	area: String!
+	effective: String!
+	expires: String!
+}
+
+type BatchResult {
+	zipCode: String!
+	# Null when the lookup failed
+	weather: Weather
+	error: String
+}
+`
+
+// newGraphQLSchema parses the /graphql schema, resolving queries for s.
+// Fields without a resolver method are read from the struct field of the same
+// name.
+func newGraphQLSchema(s *Server) *graphql.Schema {
+	return graphql.MustParseSchema(graphQLSchemaSDL, &graphQLResolver{s: s},
+		graphql.UseFieldResolvers(), graphql.MaxDepth(maxGraphQLDepth))
+}
+
+// graphQLLookupsContextKey holds the lookups counted by a counting resolver
+type graphQLLookupsContextKey struct{}
+
+// countGraphQLLookups returns how many lookups a query would make, by running
+// it with a resolver that counts them instead. Aliases, fragments, @skip and
+// @include, and variables are all accounted for the way execution sees them.
+func (s *Server) countGraphQLLookups(ctx context.Context, request GraphQLRequest) int64 {
+	var lookups atomic.Int64
+	ctx = context.WithValue(ctx, graphQLLookupsContextKey{}, &lookups)
+	s.graphQLCounter.Exec(ctx, request.Query, request.OperationName, request.Variables)
+	return lookups.Load()
+}
+
+// GraphQLRequest is a query sent to /graphql
+type GraphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// GraphQL handler using Chi. Queries are POSTed as JSON, or sent with GET as
+// the query, operationName, and variables parameters.
+func (s *Server) graphQLHandler(w http.ResponseWriter, r *http.Request) {
+	var request GraphQLRequest
+	if r.Method == http.MethodGet {
+		query := r.URL.Query()
+		request.Query, request.OperationName = query.Get("query"), query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				writeError(w, http.StatusBadRequest, "variables must be a JSON object")
+				return
+			}
+		}
+	} else {
+		r.Body = http.MaxBytesReader(w, r.Body, maxGraphQLBodySize)
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeError(w, http.StatusBadRequest, "request body must be a JSON object with a query")
+			return
+		}
+	}
+	if request.Query == "" {
+		writeError(w, http.StatusBadRequest, "query is required")
+		return
+	}
+
+	// Errors in the query or its resolvers are reported in the response's errors
+	if lookups := s.countGraphQLLookups(r.Context(), request); lookups > maxGraphQLLookups {
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(&graphql.Response{Errors: []*gqlerrors.QueryError{
+			gqlerrors.Errorf("query makes %d lookups: at most %d weather, forecast, and alerts fields and batch zip codes are allowed per query", lookups, maxGraphQLLookups),
+		}})
+		return
+	}
+	response := s.graphQL.Exec(r.Context(), request.Query, request.OperationName, request.Variables)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// graphQLResolver resolves the Query type with a server's weather service.
+// A counting resolver looks nothing up, and only adds the lookups each field
+// would make to the count in its context.
+type graphQLResolver struct {
+	s        *Server
+	counting bool
+}
+
+// count adds a field's lookups to the count, for a counting resolver
+func (q *graphQLResolver) count(ctx context.Context, lookups int) bool {
+	if !q.counting {
+		return false
+	}
+	ctx.Value(graphQLLookupsContextKey{}).(*atomic.Int64).Add(int64(lookups))
+	return true
+}
+
+// locationArgs are the arguments of the weather and forecast queries
+type locationArgs struct {
+	ZipCode  string
+	Country  *string
+	Units    *string
+	Lang     *string
+	Provider *string
+}
+
+// stringArg returns an optional argument's value, or "" when it isn't given
+func stringArg(arg *string) string {
+	if arg == nil {
+		return ""
+	}
+	return *arg
+}
+
+// location validates the arguments' zip code, country, and options
+func (q *graphQLResolver) location(ctx context.Context, args locationArgs) (Location, Options, error) {
+	loc, err := q.s.zipCodeLocation(args.ZipCode, stringArg(args.Country))
+	if err != nil {
+		return Location{}, Options{}, err
+	}
+	opts, err := q.s.optionsFromArguments(ctx, stringArg(args.Units), stringArg(args.Lang), stringArg(args.Provider))
+	if err != nil {
+		return Location{}, Options{}, err
+	}
+	return loc, opts, nil
+}
+
+func (q *graphQLResolver) Weather(ctx context.Context, args locationArgs) (*weatherResolver, error) {
+	if q.count(ctx, 1) {
+		return &weatherResolver{}, nil
+	}
+	loc, opts, err := q.location(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	weather, err := q.s.weather.CurrentWeather(ctx, loc, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &weatherResolver{*weather}, nil
+}
+
+func (q *graphQLResolver) Forecast(ctx context.Context, args locationArgs) (*forecastResolver, error) {
+	if q.count(ctx, 1) {
+		return &forecastResolver{}, nil
+	}
+	loc, opts, err := q.location(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	forecast, err := q.s.weather.Forecast(ctx, loc, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &forecastResolver{*forecast}, nil
+}
+
+func (q *graphQLResolver) Alerts(ctx context.Context, args struct{ ZipCode string }) ([]*SevereAlert, error) {
+	if q.count(ctx, 1) {
+		return nil, nil
+	}
+	loc, err := q.s.zipCodeLocation(args.ZipCode, defaultCountry)
+	if err != nil {
+		return nil, err
+	}
+	alerts, err := q.s.getSevereAlerts(ctx, loc)
+	if err != nil {
+		return nil, err
+	}
+	resolved := make([]*SevereAlert, len(alerts))
+	for i := range alerts {
+		resolved[i] = &alerts[i]
+	}
+	return resolved, nil
+}
+
+func (q *graphQLResolver) Batch(ctx context.Context, args struct {
+	ZipCodes []string
+	Units    *string
+	Lang     *string
+	Provider *string
+}) ([]*batchResultResolver, error) {
+	if q.count(ctx, len(args.ZipCodes)) {
+		return nil, nil
+	}
+	if len(args.ZipCodes) > maxBatchSize {
+		return nil, fmt.Errorf("at most %d zip codes are allowed per batch", maxBatchSize)
+	}
+	opts, err := q.s.optionsFromArguments(ctx, stringArg(args.Units), stringArg(args.Lang), stringArg(args.Provider))
+	if err != nil {
+		return nil, err
+	}
+
+	results := q.s.getWeatherBatch(ctx, args.ZipCodes, opts)
+	resolved := make([]*batchResultResolver, len(results))
+	for i := range results {
+		resolved[i] = &batchResultResolver{results[i]}
+	}
+	return resolved, nil
+}
+
+// weatherResolver resolves the Weather type; GraphQL's Int is 32 bits
+type weatherResolver struct {
+	WeatherResponse
+}
+
+func (w *weatherResolver) Humidity() int32      { return int32(w.WeatherResponse.Humidity) }
+func (w *weatherResolver) Pressure() int32      { return int32(w.WeatherResponse.Pressure) }
+func (w *weatherResolver) Visibility() int32    { return int32(w.WeatherResponse.Visibility) }
+func (w *weatherResolver) CloudCover() int32    { return int32(w.WeatherResponse.CloudCover) }
+func (w *weatherResolver) WindDirection() int32 { return int32(w.WeatherResponse.WindDirection) }
+
+// forecastResolver resolves the Forecast type
+type forecastResolver struct {
+	ForecastResponse
+}
+
+func (f *forecastResolver) Days() []*dailyForecastResolver {
+	days := make([]*dailyForecastResolver, len(f.ForecastResponse.Days))
+	for i, day := range f.ForecastResponse.Days {
+		days[i] = &dailyForecastResolver{day}
+	}
+	return days
+}
+
+// dailyForecastResolver resolves the DailyForecast type
+type dailyForecastResolver struct {
+	DailyForecast
+}
+
+func (d *dailyForecastResolver) PrecipitationChance() int32 {
+	return int32(d.DailyForecast.PrecipitationChance)
+}
+
+// batchResultResolver resolves the BatchResult type
+type batchResultResolver struct {
+	result BatchWeatherResult
+}
+
+func (b *batchResultResolver) ZipCode() string {
+	return b.result.ZipCode
+}
+
+func (b *batchResultResolver) Weather() *weatherResolver {
+	if b.result.Weather == nil {
+		return nil
+	}
+	return &weatherResolver{*b.result.Weather}
+}
+
+func (b *batchResultResolver) Error() *string {
+	if b.result.Error == "" {
+		return nil
+	}
+	return &b.result.Error
+}
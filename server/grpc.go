@@ -0,0 +1,284 @@
+package server
+
+//go:generate protoc -I .. --go_out=.. --go_opt=paths=source_relative --go-grpc_out=.. --go-grpc_opt=paths=source_relative ../weatherpb/weather.proto
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dekkagaijin/go-container-test/weatherpb"
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// weatherService serves the gRPC WeatherService
+type weatherService struct {
+	weatherpb.UnimplementedWeatherServiceServer
+	s *Server
+}
+
+// newGRPCServer creates the server for the gRPC WeatherService on GRPC_PORT.
+// gRPC is HTTP/2 only: over TLS with the main server's settings, or with
+// prior knowledge (h2c) otherwise. Requests pass through the same request
+// IDs, logging, client authentication, rate limits, and daily quotas as the
+// JSON API, and aren't
+// limited by READ_TIMEOUT and WRITE_TIMEOUT, so streams can stay open.
+func (s *Server) newGRPCServer(c *Config) *http.Server {
+	grpcServer := grpc.NewServer()
+	weatherpb.RegisterWeatherServiceServer(grpcServer, weatherService{s: s})
+
+	var protocols http.Protocols
+	if c.TLS != nil {
+		protocols.SetHTTP2(true)
+	} else {
+		protocols.SetUnencryptedHTTP2(true)
+	}
+	return &http.Server{
+		Addr:        net.JoinHostPort(c.BindAddr, c.GRPCPort),
+		Handler:     chi.Chain(requestID, requestLogger, grpcErrors, s.rateLimitIPs, s.requireClientAuth, s.rateLimitClients, s.trackUsage).Handler(grpcServer),
+		IdleTimeout: c.IdleTimeout,
+		Protocols:   &protocols,
+	}
+}
+
+// gRPC codes for the HTTP errors the middleware in front of the gRPC server
+// responds with, and the statuses of failed lookups
+var grpcErrorCodes = map[int]codes.Code{
+	http.StatusBadRequest:         codes.InvalidArgument,
+	http.StatusNotFound:           codes.NotFound,
+	http.StatusUnauthorized:       codes.Unauthenticated,
+	http.StatusForbidden:          codes.PermissionDenied,
+	http.StatusTooManyRequests:    codes.ResourceExhausted,
+	http.StatusServiceUnavailable: codes.Unavailable,
+	http.StatusGatewayTimeout:     codes.DeadlineExceeded,
+	http.StatusNotImplemented:     codes.Unimplemented,
+}
+
+// grpcErrorWriter holds back an HTTP error response, so grpcErrors can send it as a gRPC status
+type grpcErrorWriter struct {
+	http.ResponseWriter
+	status int // the error status, once one is written
+	body   bytes.Buffer
+}
+
+func (w *grpcErrorWriter) WriteHeader(status int) {
+	if status < http.StatusBadRequest {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	w.status = status
+}
+
+func (w *grpcErrorWriter) Write(data []byte) (int, error) {
+	if w.status != 0 {
+		return w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// Flush is required by the gRPC server
+func (w *grpcErrorWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok && w.status == 0 {
+		flusher.Flush()
+	}
+}
+
+// grpcErrors turns the JSON error responses of HTTP middleware, such as a
+// missing API key or a spent rate limit, into gRPC statuses
+func grpcErrors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ew := &grpcErrorWriter{ResponseWriter: w}
+		next.ServeHTTP(ew, r)
+		if ew.status == 0 {
+			return
+		}
+
+		var body struct {
+			Error string `json:"error"`
+		}
+		json.Unmarshal(ew.body.Bytes(), &body)
+		code, ok := grpcErrorCodes[ew.status]
+		if !ok {
+			code = codes.Unknown
+		}
+		// A response with only headers, carrying the status
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Grpc-Status", strconv.Itoa(int(code)))
+		w.Header().Set("Grpc-Message", grpcMessage(cmp.Or(body.Error, http.StatusText(ew.status))))
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// grpcMessage percent-encodes a status message for the Grpc-Message header
+func grpcMessage(message string) string {
+	var b strings.Builder
+	for i := 0; i < len(message); i++ {
+		if c := message[i]; c < ' ' || c > '~' || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// grpcLocation validates a request's zip code and country with zipCodeLocation
+func (s *Server) grpcLocation(zipCode, country string) (Location, error) {
+	loc, err := s.zipCodeLocation(zipCode, country)
+	if err != nil {
+		return Location{}, grpcWeatherError(err)
+	}
+	return loc, nil
+}
+
+// grpcOptions validates a request's options with optionsFromArguments
+func (s *Server) grpcOptions(ctx context.Context, requested *weatherpb.Options) (Options, error) {
+	opts, err := s.optionsFromArguments(ctx, requested.GetUnits(), requested.GetLang(), requested.GetProvider())
+	if err != nil {
+		return Options{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return opts, nil
+}
+
+// grpcWeatherError converts a failed lookup to the gRPC status for its kind, like writeServiceError
+func grpcWeatherError(err error) error {
+	return status.Error(grpcErrorCodes[serviceErrorStatus(err)], err.Error())
+}
+
+// weatherMessage converts current conditions to their protobuf message
+func weatherMessage(weather WeatherResponse) *weatherpb.Weather {
+	return &weatherpb.Weather{
+		ZipCode:       weather.ZipCode,
+		Location:      weather.Location,
+		Units:         weather.Units,
+		Temperature:   weather.Temperature,
+		FeelsLike:     weather.FeelsLike,
+		DewPoint:      weather.DewPoint,
+		Description:   weather.Description,
+		Icon:          weather.Icon,
+		IconUrl:       weather.IconURL,
+		Humidity:      int32(weather.Humidity),
+		Pressure:      int32(weather.Pressure),
+		Visibility:    int32(weather.Visibility),
+		CloudCover:    int32(weather.CloudCover),
+		WindSpeed:     weather.WindSpeed,
+		WindDirection: int32(weather.WindDirection),
+		WindGust:      weather.WindGust,
+		ObservedAt:    weather.ObservedAt,
+		LocalTime:     weather.LocalTime,
+		Source:        weather.Source,
+		Cache:         weather.Cache,
+		Stale:         weather.Stale,
+	}
+}
+
+// forecastMessage converts a daily forecast to its protobuf message
+func forecastMessage(forecast ForecastResponse) *weatherpb.Forecast {
+	message := &weatherpb.Forecast{ZipCode: forecast.ZipCode, Location: forecast.Location, Units: forecast.Units}
+	for _, day := range forecast.Days {
+		message.Days = append(message.Days, &weatherpb.DailyForecast{
+			Date:                day.Date,
+			High:                day.High,
+			Low:                 day.Low,
+			Description:         day.Description,
+			Icon:                day.Icon,
+			IconUrl:             day.IconURL,
+			PrecipitationChance: int32(day.PrecipitationChance),
+		})
+	}
+	return message
+}
+
+func (g weatherService) GetCurrent(ctx context.Context, req *weatherpb.GetCurrentRequest) (*weatherpb.Weather, error) {
+	loc, err := g.s.grpcLocation(req.GetZipCode(), req.GetCountry())
+	if err != nil {
+		return nil, err
+	}
+	opts, err := g.s.grpcOptions(ctx, req.GetOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	weather, err := g.s.weather.CurrentWeather(ctx, loc, opts)
+	if err != nil {
+		return nil, grpcWeatherError(err)
+	}
+	return weatherMessage(*weather), nil
+}
+
+func (g weatherService) GetForecast(ctx context.Context, req *weatherpb.GetForecastRequest) (*weatherpb.Forecast, error) {
+	loc, err := g.s.grpcLocation(req.GetZipCode(), req.GetCountry())
+	if err != nil {
+		return nil, err
+	}
+	opts, err := g.s.grpcOptions(ctx, req.GetOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	forecast, err := g.s.weather.Forecast(ctx, loc, opts)
+	if err != nil {
+		return nil, grpcWeatherError(err)
+	}
+	return forecastMessage(*forecast), nil
+}
+
+// StreamUpdates sends the current conditions right away and again whenever
+// they're refreshed with a new observation, keeping them refreshed, like
+// /weather/stream
+func (g weatherService) StreamUpdates(req *weatherpb.StreamUpdatesRequest, stream grpc.ServerStreamingServer[weatherpb.Weather]) error {
+	ctx := stream.Context()
+	loc, err := g.s.grpcLocation(req.GetZipCode(), req.GetCountry())
+	if err != nil {
+		return err
+	}
+	opts, err := g.s.grpcOptions(ctx, req.GetOptions())
+	if err != nil {
+		return err
+	}
+
+	// Listen before the first lookup so a refresh right after it isn't missed
+	updates, stop := g.s.observationListeners.listen(g.s.cacheKey(loc, opts))
+	defer stop()
+
+	weather, err := g.s.weather.CurrentWeather(ctx, loc, opts)
+	if err != nil {
+		return grpcWeatherError(err)
+	}
+	last := *weather
+	if err := stream.Send(weatherMessage(liveWeather(last))); err != nil {
+		return err
+	}
+
+	refresh := time.NewTicker(g.s.liveRefreshInterval())
+	defer refresh.Stop()
+
+	for {
+		select {
+		case weather := <-updates:
+			if weather.ObservedAt == last.ObservedAt && weather.Source == last.Source {
+				continue // refreshed, but with the same observation
+			}
+			last = weather
+			if err := stream.Send(weatherMessage(liveWeather(weather))); err != nil {
+				return err
+			}
+		case <-refresh.C:
+			g.s.weather.CurrentWeather(ctx, loc, opts)
+		case <-ctx.Done():
+			return nil
+		case <-g.s.observationListeners.done:
+			return status.Error(codes.Unavailable, "server shutting down")
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Dependency health states reported by /health?verbose=true
+const (
+	dependencyUp       = "up"
+	dependencyDegraded = "degraded"
+	dependencyDown     = "down"
+	dependencyUnknown  = "unknown" // not called since startup
+)
+
+// dependencyStatus is the outcome of the latest upstream calls to one dependency
+type dependencyStatus struct {
+	lastCall    time.Time
+	latency     time.Duration
+	failing     bool // the latest call failed
+	lastError   string
+	lastErrorAt time.Time
+}
+
+// dependencyTracker records the latest upstream call to each dependency,
+// named like quota counts: by provider, or by host for other services
+type dependencyTracker struct {
+	mu       sync.Mutex
+	statuses map[string]*dependencyStatus
+}
+
+// record updates a dependency after a call. Canceled calls say nothing about
+// the dependency and are ignored.
+func (t *dependencyTracker) record(name string, latency time.Duration, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	status, ok := t.statuses[name]
+	if !ok {
+		status = &dependencyStatus{}
+		t.statuses[name] = status
+	}
+	status.lastCall, status.latency, status.failing = time.Now(), latency, err != nil
+	if err != nil {
+		status.lastError, status.lastErrorAt = err.Error(), time.Now()
+	}
+}
+
+// snapshot returns a copy of every dependency's status
+func (t *dependencyTracker) snapshot() map[string]dependencyStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	statuses := make(map[string]dependencyStatus, len(t.statuses))
+	for name, status := range t.statuses {
+		statuses[name] = *status
+	}
+	return statuses
+}
+
+// healthTransport records the latency and outcome of upstream calls. Server
+// errors and rejected credentials or rate limits count as failures.
+type healthTransport struct {
+	s    *Server
+	next http.RoundTripper
+}
+
+func (t *healthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+
+	failure := err
+	if err == nil {
+		switch code := resp.StatusCode; {
+		case code >= 500, code == http.StatusUnauthorized, code == http.StatusForbidden, code == http.StatusTooManyRequests:
+			failure = fmt.Errorf("status %d", code)
+		}
+	}
+	t.s.upstreamHealth.record(t.s.quotaProvider(req.URL), time.Since(start), failure)
+	return resp, err
+}
+
+// DependencyHealth reports one dependency in a verbose health check
+type DependencyHealth struct {
+	Name        string `json:"name"`
+	Kind        string `json:"kind"`
+	Critical    bool   `json:"critical"`
+	Status      string `json:"status"`
+	Breaker     string `json:"breaker,omitempty"`
+	LastCall    string `json:"last_call,omitempty"`
+	LatencyMS   *int64 `json:"latency_ms,omitempty"`
+	LastError   string `json:"last_error,omitempty"`
+	LastErrorAt string `json:"last_error_at,omitempty"`
+}
+
+// dependencyHealth lists the server's dependencies: the weather providers in
+// the default failover chain (critical, since weather can't be served without
+// one), the cache, and any other upstream services called since startup, such
+// as geocoders. It reports whether every critical dependency is down.
+func (s *Server) dependencyHealth() ([]DependencyHealth, bool) {
+	c := s.config()
+	statuses := s.upstreamHealth.snapshot()
+	breakers := s.providerBreakers.states()
+
+	var chain []string
+	if c.MockMode {
+		chain = []string{"mock"}
+	} else {
+		chain = append([]string{cmp.Or(c.WeatherProvider, "openweathermap")}, c.ProviderFallbacks...)
+	}
+
+	dependencies := []DependencyHealth{}
+	allDown := true
+	for _, name := range chain {
+		dependency := DependencyHealth{Name: name, Kind: "weather provider", Critical: true, Breaker: cmp.Or(breakers[name], breakerClosed)}
+		status, called := statuses[name]
+		dependency.describe(status, called)
+		// A provider is only down once its breaker opens; failures short of
+		// that, or a trial call after one, leave it degraded
+		switch {
+		case name == "mock":
+			dependency.Status = dependencyUp
+		case dependency.Breaker == breakerOpen:
+			dependency.Status = dependencyDown
+		case dependency.Breaker == breakerHalfOpen, dependency.Status == dependencyDown:
+			dependency.Status = dependencyDegraded
+		}
+		if dependency.Status != dependencyDown {
+			allDown = false
+		}
+		dependencies = append(dependencies, dependency)
+	}
+
+	dependencies = append(dependencies, DependencyHealth{Name: "cache", Kind: c.CacheBackend + " cache", Status: dependencyUp})
+
+	var others []string
+	for name := range statuses {
+		if !slices.Contains(chain, name) {
+			others = append(others, name)
+		}
+	}
+	sort.Strings(others)
+	for _, name := range others {
+		kind := "service"
+		if slices.Contains(providerNames, name) {
+			kind = "weather provider"
+		}
+		dependency := DependencyHealth{Name: name, Kind: kind}
+		dependency.describe(statuses[name], true)
+		dependencies = append(dependencies, dependency)
+	}
+	return dependencies, allDown
+}
+
+// describe fills in a dependency's status from its latest upstream call
+func (d *DependencyHealth) describe(status dependencyStatus, called bool) {
+	if !called {
+		d.Status = dependencyUnknown
+		return
+	}
+
+	d.Status = dependencyUp
+	if status.failing {
+		d.Status = dependencyDown
+	}
+	latency := status.latency.Milliseconds()
+	d.LastCall, d.LatencyMS = status.lastCall.UTC().Format(time.RFC3339), &latency
+	if status.lastError != "" {
+		d.LastError, d.LastErrorAt = status.lastError, status.lastErrorAt.UTC().Format(time.RFC3339)
+	}
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Earliest date with historical data available from OpenWeatherMap
+const earliestHistoryDate = "1979-01-02"
+
+// HistoryResponse represents the observed weather for a past date
+type HistoryResponse struct {
+	ZipCode       string  `json:"zip_code"`
+	Location      string  `json:"location"`
+	Date          string  `json:"date"`
+	Units         string  `json:"units"`
+	High          float64 `json:"high"`
+	Low           float64 `json:"low"`
+	Humidity      int     `json:"humidity"`
+	Precipitation float64 `json:"precipitation"`
+	WindSpeed     float64 `json:"wind_speed"`
+}
+
+// OpenWeatherMap One Call daily aggregation response structure (simplified)
+type OpenWeatherDaySummaryAPIResponse struct {
+	Date     string `json:"date"`
+	Humidity struct {
+		Afternoon float64 `json:"afternoon"`
+	} `json:"humidity"`
+	Precipitation struct {
+		Total float64 `json:"total"`
+	} `json:"precipitation"`
+	Temperature struct {
+		Min float64 `json:"min"`
+		Max float64 `json:"max"`
+	} `json:"temperature"`
+	Wind struct {
+		Max struct {
+			Speed float64 `json:"speed"`
+		} `json:"max"`
+	} `json:"wind"`
+}
+
+func (s *Server) getHistory(ctx context.Context, loc Location, date string, opts Options) (*HistoryResponse, error) {
+	// Get API key from environment variable
+	apiKey := s.config().OpenWeatherAPIKey
+	if s.config().MockMode {
+		// In mock mode, return mock data instead of calling the API
+		return &HistoryResponse{
+			ZipCode:       loc.ZipCode,
+			Location:      s.mockName(loc),
+			Date:          date,
+			Units:         opts.units(),
+			High:          convertTemperature(78.4, opts.units()),
+			Low:           convertTemperature(62.1, opts.units()),
+			Humidity:      58,
+			Precipitation: convertPrecipitation(3.1, opts.units()),
+			WindSpeed:     convertSpeed(14.3, opts.units()),
+		}, nil
+	}
+
+	// The historical API only accepts coordinates
+	coords, err := s.geocodeZipCode(ctx, loc, apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Add("lat", fmt.Sprintf("%f", coords.Lat))
+	params.Add("lon", fmt.Sprintf("%f", coords.Lon))
+	params.Add("date", date)
+	params.Add("appid", apiKey)
+	params.Add("units", opts.units())
+
+	var apiResp OpenWeatherDaySummaryAPIResponse
+	if err := s.fetchOpenWeather(ctx, "/data/3.0/onecall/day_summary", params, &apiResp); err != nil {
+		return nil, err
+	}
+
+	return &HistoryResponse{
+		ZipCode:       loc.ZipCode,
+		Location:      coords.Name,
+		Date:          apiResp.Date,
+		Units:         opts.units(),
+		High:          apiResp.Temperature.Max,
+		Low:           apiResp.Temperature.Min,
+		Humidity:      int(math.Round(apiResp.Humidity.Afternoon)),
+		Precipitation: convertPrecipitation(apiResp.Precipitation.Total, opts.units()),
+		WindSpeed:     apiResp.Wind.Max.Speed,
+	}, nil
+}
+
+// History handler using Chi
+func (s *Server) historyHandler(w http.ResponseWriter, r *http.Request) {
+	// Get zip code and country from query parameters
+	loc, ok := s.zipCodeFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	// Get and validate date from query parameter
+	date := r.URL.Query().Get("date")
+	if date == "" {
+		writeError(w, http.StatusBadRequest, "date parameter is required")
+		return
+	}
+
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "date must be in format YYYY-MM-DD")
+		return
+	}
+
+	earliest, _ := time.Parse("2006-01-02", earliestHistoryDate)
+	if day.Before(earliest) || day.After(time.Now().UTC()) {
+		writeError(w, http.StatusBadRequest, "date must be between "+earliestHistoryDate+" and today")
+		return
+	}
+
+	// Get units from query parameters
+	opts, ok := s.optionsFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	// Get historical weather data
+	history, err := s.getHistory(r.Context(), loc, date, opts)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	// Return historical data as JSON
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(history)
+}
@@ -0,0 +1,47 @@
+package server
+
+import "strings"
+
+// Default location of OpenWeatherMap's hosted condition icons
+const defaultIconBaseURL = "https://openweathermap.org/img/wn/"
+
+// Icon used when the upstream response doesn't include a condition
+const defaultIcon = "01d"
+
+// iconURL returns the image URL for an OpenWeatherMap icon code (e.g. "10d").
+// ICON_BASE_URL can point at a mirror that uses the same "{code}@2x.png" naming.
+func (s *Server) iconURL(icon string) string {
+	return s.config().IconBaseURL + icon + "@2x.png"
+}
+
+// dayIcon returns the daytime variant of an icon code, used for daily summaries
+func dayIcon(icon string) string {
+	return strings.TrimSuffix(icon, "n") + "d"
+}
+
+// iconEmoji returns an emoji for an OpenWeatherMap icon code, for plain text responses
+func iconEmoji(icon string) string {
+	night := strings.HasSuffix(icon, "n")
+	switch strings.TrimRight(icon, "dn") {
+	case "01":
+		if night {
+			return "🌙"
+		}
+		return "☀️"
+	case "02":
+		return "⛅"
+	case "03", "04":
+		return "☁️"
+	case "09":
+		return "🌧️"
+	case "10":
+		return "🌦️"
+	case "11":
+		return "⛈️"
+	case "13":
+		return "❄️"
+	case "50":
+		return "🌫️"
+	}
+	return "🌡️"
+}
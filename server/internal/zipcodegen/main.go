@@ -0,0 +1,139 @@
+// Command zipcodegen builds the server's embedded zip code database from the
+// GeoNames US postal code dump (CC BY 4.0), which has every US zip code with
+// its place name, state, and coordinates:
+//
+//	go run ./internal/zipcodegen -o zipcodes.csv
+//
+// -src reads a US.zip or US.txt already downloaded instead.
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Where GeoNames publishes the US postal codes
+const geoNamesURL = "https://download.geonames.org/export/zip/US.zip"
+
+// Columns of a GeoNames postal code line, which is tab-separated
+const (
+	columnZip   = 1
+	columnPlace = 2
+	columnState = 4
+	columnLat   = 9
+	columnLon   = 10
+)
+
+// place is a zip code's row in the database
+type place struct {
+	zip, city, state string
+	lat, lon         float64
+}
+
+func main() {
+	src := flag.String("src", geoNamesURL, "GeoNames US.zip or US.txt, as a URL or a file")
+	out := flag.String("o", "zipcodes.csv", "CSV file to write")
+	flag.Parse()
+
+	data, err := read(*src)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if strings.EqualFold(filepath.Ext(*src), ".zip") {
+		if data, err = unzip(data, "US.txt"); err != nil {
+			log.Fatal(err)
+		}
+	}
+	places, err := parse(bytes.NewReader(data))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	w.Write([]string{"zip", "city", "state", "lat", "lon"})
+	for _, p := range places {
+		w.Write([]string{p.zip, p.city, p.state, strconv.FormatFloat(p.lat, 'f', 4, 64), strconv.FormatFloat(p.lon, 'f', 4, 64)})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, b.Bytes(), 0o644); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Wrote %d zip codes to %s\n", len(places), *out)
+}
+
+// read returns the contents of a file or URL
+func read(src string) ([]byte, error) {
+	if !strings.HasPrefix(src, "https://") && !strings.HasPrefix(src, "http://") {
+		return os.ReadFile(src)
+	}
+	client := &http.Client{Timeout: 5 * time.Minute}
+	resp, err := client.Get(src)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", src, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// unzip returns the contents of the named file in a zip archive
+func unzip(data []byte, name string) ([]byte, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	f, err := archive.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// parse reads GeoNames postal code lines, sorted by zip code. Zip codes
+// without coordinates or a state, such as some military ones, are left out,
+// and only the first line of a zip code listed more than once is kept.
+func parse(r io.Reader) ([]place, error) {
+	seen := map[string]bool{}
+	var places []place
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) <= columnLon {
+			return nil, fmt.Errorf("line %d: %d columns, want at least %d", line, len(fields), columnLon+1)
+		}
+		p := place{zip: fields[columnZip], city: fields[columnPlace], state: fields[columnState]}
+		lat, latErr := strconv.ParseFloat(fields[columnLat], 64)
+		lon, lonErr := strconv.ParseFloat(fields[columnLon], 64)
+		if len(p.zip) != 5 || p.city == "" || p.state == "" || latErr != nil || lonErr != nil || seen[p.zip] {
+			continue
+		}
+		p.lat, p.lon = lat, lon
+		seen[p.zip] = true
+		places = append(places, p)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(places, func(i, j int) bool { return places[i].zip < places[j].zip })
+	return places, nil
+}
@@ -0,0 +1,316 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/sync/singleflight"
+)
+
+// Signing algorithms accepted for tokens signed with JWT_SECRET and with JWT_JWKS_URL keys
+var (
+	jwtSecretMethods = []string{"HS256", "HS384", "HS512"}
+	jwtJWKSMethods   = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+)
+
+// Shortest JWT_SECRET accepted
+const minJWTSecretLength = 32
+
+// Clock skew allowed when checking when a token expires
+const jwtLeeway = 30 * time.Second
+
+// Signing keys are fetched again once they are jwksMaxAge old, or to look for
+// a key they don't have, but no more than once per jwksMinInterval
+const (
+	jwksMaxAge      = time.Hour
+	jwksMinInterval = time.Minute
+)
+
+// Timeout for fetching JWT_JWKS_URL
+const jwksTimeout = 10 * time.Second
+
+// jwtVerifier validates bearer tokens signed with JWT_SECRET or with a key
+// published at JWT_JWKS_URL
+type jwtVerifier struct {
+	secret []byte
+	jwks   *jwksCache
+	parser *jwt.Parser
+}
+
+// jwtClaims are the claims read from a token. Scopes may be granted in the
+// space-separated "scope" claim or in "scp", as a list or a space-separated string.
+type jwtClaims struct {
+	Scope string           `json:"scope"`
+	Scp   jwt.ClaimStrings `json:"scp"`
+	Tier  string           `json:"tier"`
+	jwt.RegisteredClaims
+}
+
+// hasScope reports whether the token grants scope
+func (c *jwtClaims) hasScope(scope string) bool {
+	scopes := strings.Fields(c.Scope)
+	for _, granted := range c.Scp {
+		scopes = append(scopes, strings.Fields(granted)...)
+	}
+	return slices.Contains(scopes, scope)
+}
+
+// jwtFromEnv reads the JWT authentication settings: JWT_SECRET for tokens
+// signed with a shared secret, JWT_JWKS_URL for tokens signed by an identity
+// provider, and the JWT_ISSUER and JWT_AUDIENCE tokens must be issued by and
+// for. It returns nil when JWT authentication is off.
+func jwtFromEnv(env *environment) (*jwtVerifier, error) {
+	secret, jwksURL := env.Getenv("JWT_SECRET"), env.Getenv("JWT_JWKS_URL")
+	issuer, audience := env.Getenv("JWT_ISSUER"), env.Getenv("JWT_AUDIENCE")
+	if secret == "" && jwksURL == "" {
+		if issuer != "" || audience != "" {
+			return nil, fmt.Errorf("JWT_ISSUER and JWT_AUDIENCE require JWT_SECRET or JWT_JWKS_URL")
+		}
+		return nil, nil
+	}
+
+	v := &jwtVerifier{}
+	var methods []string
+	if secret != "" {
+		if len(secret) < minJWTSecretLength {
+			return nil, fmt.Errorf("invalid JWT_SECRET: must be at least %d characters", minJWTSecretLength)
+		}
+		v.secret = []byte(secret)
+		methods = append(methods, jwtSecretMethods...)
+	}
+	if jwksURL != "" {
+		if parsed, err := url.Parse(jwksURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid JWT_JWKS_URL %q: must be an http or https URL", jwksURL)
+		}
+		v.jwks = &jwksCache{url: jwksURL}
+		methods = append(methods, jwtJWKSMethods...)
+	}
+
+	options := []jwt.ParserOption{jwt.WithValidMethods(methods), jwt.WithExpirationRequired(), jwt.WithLeeway(jwtLeeway)}
+	if issuer != "" {
+		options = append(options, jwt.WithIssuer(issuer))
+	}
+	if audience != "" {
+		options = append(options, jwt.WithAudience(audience))
+	}
+	v.parser = jwt.NewParser(options...)
+	return v, nil
+}
+
+// description summarizes the verifier's settings for check-config
+func (v *jwtVerifier) description() string {
+	switch {
+	case v == nil:
+		return "off"
+	case v.secret != nil && v.jwks != nil:
+		return "shared secret, JWKS " + v.jwks.url
+	case v.secret != nil:
+		return "shared secret"
+	default:
+		return "JWKS " + v.jwks.url
+	}
+}
+
+// verify checks a token's signature, expiry, issuer, and audience, and returns
+// its claims. Signing keys are fetched from JWT_JWKS_URL with client.
+func (v *jwtVerifier) verify(ctx context.Context, client *http.Client, token string) (*jwtClaims, error) {
+	claims := &jwtClaims{}
+	// Only the algorithms of the configured keys are accepted, so HMAC tokens
+	// imply a secret and any others a JWKS URL
+	_, err := v.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); ok {
+			return v.secret, nil
+		}
+		kid, _ := t.Header["kid"].(string)
+		return v.jwks.key(ctx, client, kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// jwksCache holds the signing keys published at a JWKS URL
+type jwksCache struct {
+	url     string
+	mu      sync.Mutex
+	keys    map[string]interface{} // by key ID
+	fetched time.Time              // when keys were fetched
+	checked time.Time              // when fetching last finished
+	fetches singleflight.Group     // coalesces concurrent fetches
+}
+
+// key returns the signing key with the given ID. When the key set is out of
+// date it's refreshed in the background, and when it doesn't have the key the
+// request waits for the set to be fetched again.
+func (j *jwksCache) key(ctx context.Context, client *http.Client, kid string) (interface{}, error) {
+	j.mu.Lock()
+	key, ok := j.lookup(kid)
+	stale := time.Since(j.checked) > jwksMinInterval && (!ok || time.Since(j.fetched) > jwksMaxAge)
+	j.mu.Unlock()
+
+	switch {
+	case stale && ok:
+		j.refresh(ctx, client)
+	case stale:
+		select {
+		case result := <-j.refresh(ctx, client):
+			if result.Err != nil {
+				return nil, result.Err
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		j.mu.Lock()
+		key, ok = j.lookup(kid)
+		j.mu.Unlock()
+	}
+	if !ok {
+		return nil, fmt.Errorf("no signing key %q at JWT_JWKS_URL", kid)
+	}
+	return key, nil
+}
+
+// refresh fetches the key set again, sharing one fetch between concurrent
+// callers. The lock is only taken to swap the new keys in, so lookups of the
+// keys already held never wait on the identity provider.
+func (j *jwksCache) refresh(ctx context.Context, client *http.Client) <-chan singleflight.Result {
+	// A client hanging up mustn't fail the fetch for everyone waiting on it
+	ctx = context.WithoutCancel(ctx)
+	return j.fetches.DoChan(j.url, func() (interface{}, error) {
+		keys, err := fetchJWKS(ctx, client, j.url)
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		j.checked = time.Now()
+		if err != nil {
+			if j.keys != nil {
+				warnf("Failed to refresh JWT signing keys, using the previous ones: %v", err)
+			}
+			return nil, err
+		}
+		j.keys, j.fetched = keys, j.checked
+		return nil, nil
+	})
+}
+
+// lookup finds a key by ID; a token without one may use the only key in the
+// set. The caller holds j.mu.
+func (j *jwksCache) lookup(kid string) (interface{}, bool) {
+	if kid == "" && len(j.keys) == 1 {
+		for _, key := range j.keys {
+			return key, true
+		}
+	}
+	key, ok := j.keys[kid]
+	return key, ok
+}
+
+// jsonWebKey is a public key in a JWKS document (RFC 7517)
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// fetchJWKS fetches the RSA and EC signing keys published at a JWKS URL, by key ID
+func fetchJWKS(ctx context.Context, client *http.Client, jwksURL string) (map[string]interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWT signing keys: %v", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWT signing keys: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("JWT_JWKS_URL returned status: %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWT signing keys: %v", err)
+	}
+
+	keys := map[string]interface{}{}
+	for _, jwk := range set.Keys {
+		if jwk.Use == "enc" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			warnf("Skipping JWT signing key %q: %v", jwk.Kid, err)
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+// publicKey decodes an RSA or EC key
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	decode := func(value string) (*big.Int, error) {
+		data, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil || len(data) == 0 {
+			return nil, fmt.Errorf("invalid key parameter %q", value)
+		}
+		return new(big.Int).SetBytes(data), nil
+	}
+
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		curves := map[string]elliptic.Curve{"P-256": elliptic.P256(), "P-384": elliptic.P384(), "P-521": elliptic.P521()}
+		curve, ok := curves[k.Crv]
+		if !ok {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on curve %s", k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Request limits used when REQUEST_TIMEOUT, MAX_HEADER_BYTES, and
+// MAX_BODY_BYTES aren't set. The timeout leaves room for failover between
+// providers, and ends requests before WRITE_TIMEOUT cuts them off.
+const (
+	defaultRequestTimeout = 45 * time.Second
+	defaultMaxHeaderBytes = 64 << 10 // 64 KiB
+	defaultMaxBodyBytes   = 1 << 20  // 1 MiB
+)
+
+// requestTimeout gives each request REQUEST_TIMEOUT to finish, by cancelling
+// its context, so lookups stuck on a slow upstream give up. A request that
+// runs out of time without responding gets 504 Gateway Timeout. Routes that
+// stay open for longer, such as streams, WebSockets, and profiles, are left
+// out of the route groups that use it.
+func (s *Server) requestTimeout(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timeout := s.config().RequestTimeout
+		if timeout <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		tw := &timeoutWriter{ResponseWriter: w}
+		middleware.Timeout(timeout)(next).ServeHTTP(tw, r)
+		if tw.pending {
+			w.Header().Set("Content-Type", "application/json")
+			writeError(w, http.StatusGatewayTimeout, "request timed out")
+		}
+	})
+}
+
+// timeoutWriter keeps the bare 504 that middleware.Timeout writes from
+// following a response the handler already wrote, and holds it back
+// otherwise, so requestTimeout can write it as a JSON error
+type timeoutWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+	pending     bool // a 504 is held back
+}
+
+func (w *timeoutWriter) WriteHeader(status int) {
+	if w.wroteHeader || w.pending {
+		return
+	}
+	if status == http.StatusGatewayTimeout {
+		w.pending = true
+		return
+	}
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	if w.pending {
+		// The handler wrote its own 504
+		w.pending = false
+		w.wroteHeader = true
+		w.ResponseWriter.WriteHeader(http.StatusGatewayTimeout)
+	}
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(data)
+}
+
+// Unwrap lets http.ResponseController reach the connection
+func (w *timeoutWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// limitBody caps request bodies at MAX_BODY_BYTES. Bodies declared bigger get
+// 413 Content Too Large before they're read; handlers reading past the limit
+// of a body sent without its length get an error. Endpoints that take small
+// bodies set lower limits of their own.
+func (s *Server) limitBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit := s.config().MaxBodyBytes
+		if r.ContentLength > limit {
+			writeError(w, http.StatusRequestEntityTooLarge, "request body is too large")
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,376 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// City query format ("City" or "City,ST")
+var cityRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z .'-]*(,\s*[A-Za-z]{2})?$`)
+
+// Country assumed when the country parameter is omitted
+const defaultCountry = "US"
+
+// postalCodeFormat describes how postal codes are written in a country
+type postalCodeFormat struct {
+	regex   *regexp.Regexp
+	example string
+	// upstream converts a valid postal code into the form OpenWeatherMap expects
+	upstream func(code string) string
+}
+
+// Supported countries and their postal code formats
+var postalCodeFormats = map[string]postalCodeFormat{
+	"US": {
+		regex:   regexp.MustCompile(`^\d{5}(-\d{4})?$`),
+		example: "XXXXX or XXXXX-XXXX",
+		// OpenWeatherMap only knows the 5-digit portion of ZIP+4 codes
+		upstream: func(code string) string { return code[:5] },
+	},
+	"CA": {
+		regex:   regexp.MustCompile(`^[A-Za-z]\d[A-Za-z][ -]?\d[A-Za-z]\d$`),
+		example: "A1A 1A1",
+		// OpenWeatherMap resolves Canadian codes by forward sortation area (first 3 characters)
+		upstream: func(code string) string { return strings.ToUpper(code[:3]) },
+	},
+	"GB": {
+		regex:   regexp.MustCompile(`^[A-Za-z]{1,2}\d[A-Za-z\d]?(\s*\d[A-Za-z]{2})?$`),
+		example: "SW1A 1AA or SW1A",
+		// OpenWeatherMap resolves UK postcodes by outward code (the part before the space)
+		upstream: func(code string) string {
+			code = strings.ToUpper(strings.ReplaceAll(code, " ", ""))
+			if len(code) > 4 {
+				code = code[:len(code)-3]
+			}
+			return code
+		},
+	},
+	"DE": {
+		regex:    regexp.MustCompile(`^\d{5}$`),
+		example:  "XXXXX",
+		upstream: func(code string) string { return code },
+	},
+	"AU": {
+		regex:    regexp.MustCompile(`^\d{4}$`),
+		example:  "XXXX",
+		upstream: func(code string) string { return code },
+	},
+}
+
+// Location identifies the place a client asked about, by zip code, city name, or coordinates.
+// Country applies to zip codes and cities and defaults to US when empty.
+type Location struct {
+	ZipCode string
+	City    string
+	Country string
+	Coords  *Coordinates
+}
+
+// country returns the location's country code, defaulting to US
+func (loc Location) country() string {
+	if loc.Country == "" {
+		return defaultCountry
+	}
+	return loc.Country
+}
+
+// code identifies the location's zip code in topic and subject names: the
+// zip code in the US, and the postal code and country elsewhere, e.g. SW1A1AA-GB
+func (loc Location) code() string {
+	if country := loc.country(); country != defaultCountry {
+		return strings.ReplaceAll(loc.ZipCode, " ", "") + "-" + country
+	}
+	return loc.ZipCode
+}
+
+// upstreamZipCode returns the zip query value OpenWeatherMap expects ("code,country")
+func (loc Location) upstreamZipCode() string {
+	country := loc.country()
+	return postalCodeFormats[country].upstream(loc.ZipCode) + "," + country
+}
+
+// countryFromRequest reads and validates the optional country query parameter
+func countryFromRequest(r *http.Request) (string, error) {
+	return validateCountry(r.URL.Query().Get("country"))
+}
+
+// validateCountry checks a country code has a supported postal code format,
+// returning it in upper case, or the default country if it is empty
+func validateCountry(country string) (string, error) {
+	country = strings.ToUpper(country)
+	if country == "" {
+		return defaultCountry, nil
+	}
+
+	if _, ok := postalCodeFormats[country]; !ok {
+		countries := make([]string, 0, len(postalCodeFormats))
+		for code := range postalCodeFormats {
+			countries = append(countries, code)
+		}
+		sort.Strings(countries)
+		return "", fmt.Errorf("country must be one of: %s", strings.Join(countries, ", "))
+	}
+	return country, nil
+}
+
+// zipCodeFromRequest reads and validates the zip_code and country query
+// parameters, counting the request for /admin/analytics/top-locations.
+// On failure it writes a 400 response and returns false.
+func (s *Server) zipCodeFromRequest(w http.ResponseWriter, r *http.Request) (Location, bool) {
+	loc, ok := s.readZipCode(w, r)
+	if ok {
+		s.locationRequests.record(loc)
+	}
+	return loc, ok
+}
+
+// readZipCode reads and validates the zip_code and country query parameters
+// like zipCodeFromRequest, without counting the request
+func (s *Server) readZipCode(w http.ResponseWriter, r *http.Request) (Location, bool) {
+	country, err := countryFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return Location{}, false
+	}
+
+	zipCode := r.URL.Query().Get("zip_code")
+	if zipCode == "" {
+		writeError(w, http.StatusBadRequest, "zip_code parameter is required")
+		return Location{}, false
+	}
+
+	zipCode, err = s.normalizePostalCode(zipCode, country)
+	if err != nil {
+		writeServiceError(w, err)
+		return Location{}, false
+	}
+	return Location{ZipCode: zipCode, Country: country}, true
+}
+
+// zipCodeLocation validates a zip code and country given other than as query
+// parameters, like zipCodeFromRequest, counting the request
+func (s *Server) zipCodeLocation(zipCode, country string) (Location, error) {
+	country, err := validateCountry(country)
+	if err != nil {
+		return Location{}, err
+	}
+	if zipCode == "" {
+		return Location{}, withKind(ErrInvalidZip, errors.New("zip_code is required"))
+	}
+	zipCode, err = s.normalizePostalCode(zipCode, country)
+	if err != nil {
+		return Location{}, err
+	}
+
+	loc := Location{ZipCode: zipCode, Country: country}
+	s.locationRequests.record(loc)
+	return loc, nil
+}
+
+// validateZipCode checks that a zip code is in format XXXXX or XXXXX-XXXX
+func validateZipCode(zipCode string) error {
+	return validatePostalCode(zipCode, defaultCountry)
+}
+
+// unknownZipCodeError reports a well-formed zip code that isn't in the zip code database
+type unknownZipCodeError struct {
+	zipCode string
+}
+
+func (e *unknownZipCodeError) Error() string {
+	return "unknown zip code " + e.zipCode
+}
+
+func (e *unknownZipCodeError) Is(target error) bool { return target == ErrNotFound }
+
+// normalizeZipCode validates a US zip code and returns its 5-digit form,
+// dropping the +4 of ZIP+4 codes. With ZIP_CODE_STRICT on, zip codes that
+// aren't in the zip code database are rejected with an *unknownZipCodeError.
+func (s *Server) normalizeZipCode(zipCode string) (string, error) {
+	if err := validateZipCode(zipCode); err != nil {
+		return "", err
+	}
+	zipCode = zipCode[:5]
+	if c := s.config(); c.ZipCodeStrict {
+		if _, ok := c.ZipCodeDB[zipCode]; !ok {
+			return "", &unknownZipCodeError{zipCode: zipCode}
+		}
+	}
+	return zipCode, nil
+}
+
+// normalizePostalCode validates a postal code in the given country, normalizing US zip codes
+func (s *Server) normalizePostalCode(code, country string) (string, error) {
+	if country == defaultCountry {
+		return s.normalizeZipCode(code)
+	}
+	if err := validatePostalCode(code, country); err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// validatePostalCode checks that a postal code matches the format used in the given country
+func validatePostalCode(code, country string) error {
+	format := postalCodeFormats[country]
+	if !format.regex.MatchString(code) {
+		if country == defaultCountry {
+			return withKind(ErrInvalidZip, errors.New("zip_code must be in format "+format.example))
+		}
+		return withKind(ErrInvalidZip, fmt.Errorf("zip_code must be in format %s for country %s", format.example, country))
+	}
+	return nil
+}
+
+// zipCodePath serves a /locations/{zip}/... route like its query string
+// counterpart, with the zip code in the path as the zip_code parameter. Naming
+// the location in the query string as well is rejected.
+func zipCodePath(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		for _, name := range []string{"zip_code", "city", "lat", "lon"} {
+			if query.Has(name) {
+				writeError(w, http.StatusBadRequest, name+" can't be combined with a zip code in the path")
+				return
+			}
+		}
+		query.Set("zip_code", chi.URLParam(r, "zip"))
+
+		r = r.Clone(r.Context())
+		r.URL.RawQuery = query.Encode()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// locationFromRequest reads and validates the zip_code, city, or lat/lon query parameters,
+// counting zip code requests for /admin/analytics/top-locations. On failure it writes a 400 response and returns false.
+func (s *Server) locationFromRequest(w http.ResponseWriter, r *http.Request) (Location, bool) {
+	query := r.URL.Query()
+	zipCode, city := query.Get("zip_code"), query.Get("city")
+	lat, lon := query.Get("lat"), query.Get("lon")
+
+	// Exactly one way of identifying the location is allowed
+	specified := 0
+	for _, given := range []bool{zipCode != "", city != "", lat != "" || lon != ""} {
+		if given {
+			specified++
+		}
+	}
+
+	country, err := countryFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return Location{}, false
+	}
+
+	switch {
+	case specified > 1:
+		writeError(w, http.StatusBadRequest, "only one of zip_code, city, or lat/lon may be specified")
+		return Location{}, false
+	case lat != "" || lon != "":
+		coords, err := parseCoordinates(lat, lon)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return Location{}, false
+		}
+		return Location{Coords: coords}, true
+	case zipCode != "":
+		zipCode, err = s.normalizePostalCode(zipCode, country)
+		if err != nil {
+			writeServiceError(w, err)
+			return Location{}, false
+		}
+		loc := Location{ZipCode: zipCode, Country: country}
+		s.locationRequests.record(loc)
+		return loc, true
+	case city != "":
+		if err := validateCity(city); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return Location{}, false
+		}
+		return Location{City: city, Country: country}, true
+	default:
+		writeError(w, http.StatusBadRequest, "zip_code, city, or lat/lon parameters are required")
+		return Location{}, false
+	}
+}
+
+// validateCity checks that a city query is in format City or City,ST
+func validateCity(city string) error {
+	if !cityRegex.MatchString(city) {
+		return errors.New("city must be in format City or City,ST")
+	}
+	return nil
+}
+
+// parseCoordinates parses and range-checks latitude and longitude query values
+func parseCoordinates(lat, lon string) (*Coordinates, error) {
+	if lat == "" || lon == "" {
+		return nil, errors.New("lat and lon must both be specified")
+	}
+
+	latitude, err := strconv.ParseFloat(lat, 64)
+	if err != nil || math.IsNaN(latitude) || latitude < -90 || latitude > 90 {
+		return nil, errors.New("lat must be a number between -90 and 90")
+	}
+
+	longitude, err := strconv.ParseFloat(lon, 64)
+	if err != nil || math.IsNaN(longitude) || longitude < -180 || longitude > 180 {
+		return nil, errors.New("lon must be a number between -180 and 180")
+	}
+
+	return &Coordinates{Lat: latitude, Lon: longitude}, nil
+}
+
+// locationParams returns the OpenWeatherMap query parameters identifying a location.
+// Zip codes and coordinates are passed through directly, while city names are geocoded first.
+func (s *Server) locationParams(ctx context.Context, loc Location, apiKey string) (url.Values, error) {
+	params := url.Values{}
+	if loc.ZipCode != "" {
+		params.Add("zip", loc.upstreamZipCode())
+		return params, nil
+	}
+
+	coords, err := s.resolveCoordinates(ctx, loc, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	params.Add("lat", fmt.Sprintf("%f", coords.Lat))
+	params.Add("lon", fmt.Sprintf("%f", coords.Lon))
+	return params, nil
+}
+
+// resolveCoordinates returns the coordinates of a location, geocoding zip codes and cities.
+// APIs that only accept lat/lon (history, air quality, etc.) need this first.
+func (s *Server) resolveCoordinates(ctx context.Context, loc Location, apiKey string) (*Coordinates, error) {
+	switch {
+	case loc.Coords != nil:
+		return loc.Coords, nil
+	case loc.ZipCode != "":
+		return s.geocodeZipCode(ctx, loc, apiKey)
+	default:
+		return s.geocodeCity(ctx, loc, apiKey)
+	}
+}
+
+// mockName returns the place name used in demo data for a location
+func (s *Server) mockName(loc Location) string {
+	switch {
+	case loc.City != "":
+		return strings.TrimSpace(strings.Split(loc.City, ",")[0])
+	case loc.Coords != nil || loc.country() != defaultCountry:
+		return "Unknown Location"
+	default:
+		return s.mockLocationName(loc.ZipCode)
+	}
+}
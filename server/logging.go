@@ -0,0 +1,105 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Log levels, from most to least verbose. Request logs are info level.
+const (
+	logDebug int32 = iota
+	logInfo
+	logWarn
+	logError
+)
+
+// Names accepted by LOG_LEVEL and PUT /admin/loglevel, indexed by level
+var logLevelNames = []string{"debug", "info", "warn", "error"}
+
+// Current log level; messages below it are dropped
+var logLevel atomic.Int32
+
+func init() {
+	logLevel.Store(logInfo)
+}
+
+// parseLogLevel converts a log level name such as "debug" into its level
+func parseLogLevel(name string) (int32, error) {
+	level := slices.Index(logLevelNames, strings.ToLower(name))
+	if level < 0 {
+		return 0, fmt.Errorf("log level must be one of: %s", strings.Join(logLevelNames, ", "))
+	}
+	return int32(level), nil
+}
+
+// logEnabled reports whether messages at level are logged
+func logEnabled(level int32) bool {
+	return level >= logLevel.Load()
+}
+
+// logf logs a message at level, prefixed with the level's name
+func logf(level int32, format string, args ...interface{}) {
+	if logEnabled(level) {
+		log.Printf(strings.ToUpper(logLevelNames[level])+" "+format, args...)
+	}
+}
+
+func debugf(format string, args ...interface{}) { logf(logDebug, format, args...) }
+func infof(format string, args ...interface{})  { logf(logInfo, format, args...) }
+func warnf(format string, args ...interface{})  { logf(logWarn, format, args...) }
+
+// requestLogger logs each request, as long as info messages are enabled
+func requestLogger(next http.Handler) http.Handler {
+	logged := middleware.Logger(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if logEnabled(logInfo) {
+			logged.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// LogLevelRequest and LogLevelResponse carry the log level for /admin/loglevel
+type LogLevelRequest struct {
+	Level string `json:"level"`
+}
+
+type LogLevelResponse struct {
+	Level string `json:"level"`
+}
+
+// Log level handler using Chi
+func logLevelHandler(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(LogLevelResponse{Level: logLevelNames[logLevel.Load()]})
+}
+
+// Log level update handler using Chi. The level applies until the next
+// restart, or a reload that changes LOG_LEVEL.
+func setLogLevelHandler(w http.ResponseWriter, r *http.Request) {
+	var body LogLevelRequest
+	r.Body = http.MaxBytesReader(w, r.Body, 1024)
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, `request body must be a JSON object such as {"level": "debug"}`)
+		return
+	}
+	level, err := parseLogLevel(body.Level)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	previous := logLevel.Swap(level)
+	log.Printf("Log level changed from %s to %s", logLevelNames[previous], logLevelNames[level])
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(LogLevelResponse{Level: logLevelNames[level]})
+}
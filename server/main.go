@@ -0,0 +1,532 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// WeatherResponse represents the structure of weather data we'll return
+type WeatherResponse struct {
+	XMLName       xml.Name `json:"-" xml:"weather"`
+	ZipCode       string   `json:"zip_code,omitempty" xml:"zip_code,omitempty"`
+	Location      string   `json:"location" xml:"location"`
+	Units         string   `json:"units" xml:"units"`
+	Temperature   float64  `json:"temperature" xml:"temperature"`
+	FeelsLike     float64  `json:"feels_like" xml:"feels_like"`
+	DewPoint      float64  `json:"dew_point" xml:"dew_point"`
+	Description   string   `json:"description" xml:"description"`
+	Icon          string   `json:"icon" xml:"icon"`
+	IconURL       string   `json:"icon_url" xml:"icon_url"`
+	Humidity      int      `json:"humidity" xml:"humidity"`
+	Pressure      int      `json:"pressure" xml:"pressure"`
+	Visibility    int      `json:"visibility" xml:"visibility"`
+	CloudCover    int      `json:"cloud_cover" xml:"cloud_cover"`
+	WindSpeed     float64  `json:"wind_speed" xml:"wind_speed"`
+	WindDirection int      `json:"wind_direction" xml:"wind_direction"`
+	WindGust      float64  `json:"wind_gust" xml:"wind_gust"`
+	ObservedAt    string   `json:"observed_at" xml:"observed_at"`
+	LocalTime     string   `json:"local_time" xml:"local_time"`
+	Cache         string   `json:"cache,omitempty" xml:"cache,omitempty"`
+	Stale         bool     `json:"stale,omitempty" xml:"stale,omitempty"`
+	Source        string   `json:"source,omitempty" xml:"source,omitempty"`
+}
+
+// fetchJSON makes a GET request and decodes the JSON response into v.
+// The kind of data ("weather", "geolocation", ...) is used in error messages.
+func (s *Server) fetchJSON(ctx context.Context, fullURL, kind string, v interface{}) error {
+	return s.fetchJSONWithHeaders(ctx, fullURL, kind, nil, v)
+}
+
+// fetchJSONWithHeaders is fetchJSON for APIs that need extra request headers
+func (s *Server) fetchJSONWithHeaders(ctx context.Context, fullURL, kind string, headers http.Header, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s data: %v", kind, err)
+	}
+	for name, values := range headers {
+		req.Header[name] = values
+	}
+	// Forward the request ID so upstream logs can be matched with ours
+	id := middleware.GetReqID(ctx)
+	if id != "" {
+		req.Header.Set(requestIDHeader, id)
+	}
+
+	// Make HTTP request
+	start := time.Now()
+	resp, err := doWithRetry(s.upstreamClient, req, s.config().UpstreamRetry)
+	if err != nil {
+		debugf("[%s] upstream %s request to %s failed after %s: %v", id, kind, redactedURL(req.URL), time.Since(start), err)
+		return withKind(ErrUpstreamUnavailable, fmt.Errorf("failed to fetch %s data: %w", kind, err))
+	}
+	defer resp.Body.Close()
+	debugf("[%s] upstream %s request to %s returned %d in %s", id, kind, redactedURL(req.URL), resp.StatusCode, time.Since(start))
+
+	if resp.StatusCode != http.StatusOK {
+		return &upstreamStatusError{kind: kind, status: resp.StatusCode}
+	}
+
+	// Read response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return withKind(ErrUpstreamUnavailable, fmt.Errorf("failed to read response body: %v", err))
+	}
+
+	// Parse JSON response
+	if err := json.Unmarshal(body, v); err != nil {
+		return withKind(ErrUpstreamUnavailable, fmt.Errorf("failed to parse %s data: %v", kind, err))
+	}
+	return nil
+}
+
+// getWeather returns current conditions from the requested or configured
+// provider, failing over to WEATHER_PROVIDER_FALLBACKS when it's unavailable
+func (s *Server) getWeather(ctx context.Context, loc Location, opts Options) (*WeatherResponse, error) {
+	chain, err := s.providerChain(opts)
+	if err != nil {
+		return nil, err
+	}
+	return s.currentWithFailover(ctx, chain, loc, opts)
+}
+
+// Middleware to set the JSON content type
+func jsonMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeError writes a JSON error message with the given status code
+func writeError(w http.ResponseWriter, status int, message string) {
+	// Errors shouldn't be cached by CDNs or browsers
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// Weather handler using Chi
+func (s *Server) weatherHandler(w http.ResponseWriter, r *http.Request) {
+	// Get zip code, city, or coordinates from query parameters
+	loc, ok := s.locationFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	// Get units from query parameters
+	opts, ok := s.optionsFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	// Get lookup mode from query parameter
+	mode, ok := modeFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	// Get response format from the format parameter or Accept header
+	format, ok := s.formatFromRequest(w, r, formatGeoJSON)
+	if !ok {
+		return
+	}
+
+	// Combine every enabled provider in consensus mode
+	if mode == modeConsensus {
+		consensus, err := s.getConsensus(r.Context(), loc, opts)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		if format == formatGeoJSON {
+			writeGeoJSON(w, s.geoJSONFeature(r.Context(), loc, consensus))
+			return
+		}
+		writeFormatted(w, format, consensus)
+		return
+	}
+
+	// Get weather data
+	weather, err := s.weather.CurrentWeather(r.Context(), loc, opts)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	// Return weather data in the requested format
+	setCacheStatusHeader(w, weather)
+	if format == formatGeoJSON {
+		writeGeoJSON(w, s.geoJSONFeature(r.Context(), loc, weather))
+		return
+	}
+	writeFormatted(w, format, weather)
+}
+
+// Health check handler. The service reports itself degraded (still with a
+// 200) while any provider's circuit breaker is open. With verbose=true it
+// also lists each dependency, and returns 503 when every critical one is down.
+func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
+	status := "healthy"
+	breakers := s.providerBreakers.states()
+	for _, state := range breakers {
+		if state != breakerClosed {
+			status = "degraded"
+		}
+	}
+
+	response := map[string]interface{}{
+		"status":   status,
+		"service":  "weather-api",
+		"breakers": breakers,
+	}
+	if r.URL.Query().Get("verbose") != "true" {
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	dependencies, down := s.dependencyHealth()
+	response["dependencies"] = dependencies
+	if down {
+		response["status"] = "unhealthy"
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// Root handler with API documentation
+func (s *Server) rootHandler(w http.ResponseWriter, r *http.Request) {
+	usage := map[string]interface{}{
+		"service": "Weather API Server",
+		"endpoints": map[string]string{
+			"GET /weather?zip_code=XXXXX":                   "Get weather by zip code (5 digits); deprecated in favor of /api/v2/weather",
+			"GET /api/v2/weather?zip_code=XXXXX":            "Get weather in the v2 schema: nested temperature and wind with units, and provenance",
+			"GET /api/v2/forecast?zip_code=XXXXX":           "Get the 5-day forecast in the v2 schema (also /api/v2/forecast/hourly)",
+			"GET /weather?city=City,ST":                     "Get weather by city name",
+			"GET /weather?lat=XX.X&lon=YY.Y":                "Get weather by latitude/longitude",
+			"GET /weather?zip_code=XXXXX&mode=consensus":    "Get averaged weather from every enabled provider",
+			"GET /weather?zip_code=XXXXX&format=xml":        "Get weather as xml, csv, protobuf, msgpack, or text, also chosen with the Accept header",
+			"GET /locations/{zip}/weather":                  "Get weather by zip code in the path (also /locations/{zip}/forecast and /forecast/hourly)",
+			"GET /weather/me":                               "Get weather for the caller's location (by IP address)",
+			"GET /openapi.json":                             "OpenAPI 3 spec of the endpoints",
+			"GET /docs":                                     "Interactive API documentation (Swagger UI)",
+			"GET /ui":                                       "Web dashboard: search a zip code for current conditions and a forecast chart",
+			"GET /badge/{zip}.svg":                          "SVG badge with the current temperature and conditions, for READMEs and wikis",
+			"GET /calendar/{zip}.ics":                       "iCalendar feed of the daily forecast, to subscribe to from calendar apps",
+			"GET /card/{zip}.png":                           "PNG weather card with current conditions and the forecast, for chat unfurls and e-ink displays",
+			"GET /weather/stream?zip_code=XXXXX":            "Stream current weather as Server-Sent Events, pushed whenever it refreshes",
+			"GET /ws":                                       "WebSocket: subscribe and unsubscribe to zip codes, and receive their weather as it refreshes",
+			"POST /weather/batch":                           "Get weather for up to 50 zip codes (JSON array body)",
+			"POST /graphql":                                 "GraphQL queries for current weather, forecasts, alerts, and batches (also GET ?query=)",
+			"GET /compare?zip_codes=XXXXX,YYYYY":            "Compare current weather across 2-10 zip codes",
+			"GET /forecast?zip_code=XXXXX":                  "Get 5-day forecast by zip code",
+			"GET /forecast/hourly?zip_code=XXXXX&hours=24":  "Get hourly forecast by zip code (up to 120 hours)",
+			"GET /history?zip_code=XXXXX&date=YYYY-MM-DD":   "Get observed weather for a past date",
+			"GET /history/observations?zip_code=XXXXX":      "Current conditions recorded for a zip code on a day (database store)",
+			"GET /history/trend?zip_code=XXXXX&window=7d":   "Min/max/avg temperature series from recorded conditions (database store)",
+			"GET /air-quality?zip_code=XXXXX":               "Get air quality index and pollutants by zip code",
+			"GET /uv?zip_code=XXXXX":                        "Get UV index by zip code",
+			"GET /astronomy?zip_code=XXXXX":                 "Get sunrise, sunset, day length, and moon phase by zip code",
+			"GET /zip-code?zip_code=XXXXX-XXXX":             "Normalize a zip code and look up its city, state, and coordinates",
+			"GET /health":                                   "Health check endpoint; verbose=true lists each dependency",
+			"GET /version":                                  "Version, git commit, build date, and Go version of the running server",
+			"GET /admin/cache/stats":                        "Cache hit ratio, entry count, evictions, and memory usage (admin)",
+			"DELETE /admin/cache?zip_code=XXXXX":            "Remove a zip code from the cache, or everything with all=true (admin)",
+			"GET /admin/quota":                              "Upstream API calls made today per provider, against any daily budget (admin)",
+			"GET /admin/usage?date=YYYY-MM-DD":              "Requests per API key or token today (or on date), against its tier's daily quota (admin)",
+			"GET /admin/analytics/top-locations?window=24h": "Most requested zip codes over the last day (or window, up to 7d) (admin)",
+			"GET /me/usage":                                 "The caller's requests today and on recent days, against its daily quota",
+			"GET /me/locations":                             "The caller's saved locations",
+			"POST /me/locations":                            "Save a location, e.g. {\"zip_code\": \"10001\", \"name\": \"Office\"}",
+			"DELETE /me/locations/{zip}":                    "Remove a saved location",
+			"GET /me/preferences":                           "The caller's default units, language, and provider",
+			"GET /subscriptions":                            "The caller's weather alert subscriptions",
+			"GET /subscriptions/{id}":                       "One of the caller's subscriptions",
+			"POST /subscriptions":                           "Be alerted at a callback URL, by email, or in Slack or Teams, e.g. {\"zip_code\": \"10001\", \"condition\": \"temperature < 32\", \"callback_url\": \"https://...\"}",
+			"DELETE /subscriptions/{id}":                    "Stop a subscription's alerts",
+			"PUT /me/preferences":                           "Set the caller's defaults, e.g. {\"units\": \"metric\", \"lang\": \"de\"}",
+			"GET /me/weather":                               "Current weather at each of the caller's saved locations",
+			"GET /admin/providers":                          "Circuit breaker state and call counts per weather provider (admin)",
+			"POST /admin/reload":                            "Reload the configuration without restarting, like SIGHUP (admin)",
+			"GET /admin/loglevel":                           "Current log level (admin)",
+			"GET /admin/debug/pprof/":                       "Go runtime profiles (CPU, heap, goroutines, ...) for go tool pprof (admin)",
+			"PUT /admin/loglevel":                           "Change the log level at runtime, e.g. {\"level\": \"debug\"} (admin)",
+			"GET /admin/locations":                          "Zip codes and the cities demo data uses for them (admin)",
+			"GET /admin/locations/{zip}":                    "The city of one zip code (admin)",
+			"PUT /admin/locations/{zip}":                    "Add or change a zip code's city, e.g. {\"city\": \"New York,NY,US\"} (admin)",
+			"DELETE /admin/locations/{zip}":                 "Remove a zip code (admin)",
+			"GET /admin/keys":                               "Client API keys, by name and tier (admin)",
+			"POST /admin/keys":                              "Create an API key in API_KEYS_FILE, e.g. {\"name\": \"mobile-app\", \"tier\": \"pro\"} (admin)",
+			"DELETE /admin/keys/{id}":                       "Revoke an API key in API_KEYS_FILE (admin)",
+		},
+		"example":             "GET /weather?zip_code=10001",
+		"providers":           s.enabledProviders(),
+		"supported_countries": []string{"US", "CA", "GB", "DE", "AU"},
+		"known_zip_codes":     s.zipLocations.count(),
+		"zip_code_lookup":     "GET /zip-code?zip_code=10001",
+	}
+	json.NewEncoder(w).Encode(usage)
+}
+
+// weatherRoutes adds the client endpoints, which require an API key or bearer
+// token once client authentication is configured
+func (s *Server) weatherRoutes(r chi.Router) {
+	r.Use(s.requireClientAuth)
+	r.Use(s.rateLimitClients)
+	r.Use(s.trackUsage)
+	r.Use(selectFields)
+	// Streams and WebSockets stay open for as long as their clients listen
+	r.With(s.cacheControl("weather/stream")).Get("/weather/stream", s.weatherStreamHandler)
+	r.Get("/ws", s.webSocketHandler) // upgraded responses don't carry headers set here
+	r.Group(s.timedWeatherRoutes)
+}
+
+// timedWeatherRoutes adds the client endpoints that answer a request with a
+// single response, within REQUEST_TIMEOUT
+func (s *Server) timedWeatherRoutes(r chi.Router) {
+	r.Use(s.requestTimeout)
+	r.With(s.cacheControl("weather")).Get("/weather", s.weatherHandler)
+	r.With(s.cacheControl("weather/me")).Get("/weather/me", s.myWeatherHandler)
+	r.With(s.cacheControl("weather/batch")).Post("/weather/batch", s.batchWeatherHandler)
+	r.With(s.cacheControl("graphql")).Get("/graphql", s.graphQLHandler)
+	r.With(s.cacheControl("graphql")).Post("/graphql", s.graphQLHandler)
+	r.With(s.cacheControl("compare")).Get("/compare", s.compareHandler)
+	r.With(s.cacheControl("forecast")).Get("/forecast", s.forecastHandler)
+	r.With(s.cacheControl("forecast/hourly")).Get("/forecast/hourly", s.hourlyForecastHandler)
+	r.Route("/locations/{zip}", func(r chi.Router) {
+		r.Use(zipCodePath)
+		r.With(s.cacheControl("weather")).Get("/weather", s.weatherHandler)
+		r.With(s.cacheControl("forecast")).Get("/forecast", s.forecastHandler)
+		r.With(s.cacheControl("forecast/hourly")).Get("/forecast/hourly", s.hourlyForecastHandler)
+	})
+	r.With(s.cacheControl("history")).Get("/history", s.historyHandler)
+	r.With(s.cacheControl("history/observations")).Get("/history/observations", s.observationsHandler)
+	r.With(s.cacheControl("history/trend")).Get("/history/trend", s.trendHandler)
+	r.With(s.cacheControl("air-quality")).Get("/air-quality", s.airQualityHandler)
+	r.With(s.cacheControl("uv")).Get("/uv", s.uvHandler)
+	r.With(s.cacheControl("astronomy")).Get("/astronomy", s.astronomyHandler)
+	r.With(s.cacheControl("zip-code")).Get("/zip-code", s.zipCodeHandler)
+	r.With(s.cacheControl("me/weather")).Get("/me/weather", s.savedWeatherHandler)
+}
+
+// meRoutes adds the endpoints for the caller's own data, which need an API key
+// or bearer token to identify the caller
+func (s *Server) meRoutes(r chi.Router) {
+	r.Use(s.requestTimeout)
+	r.Use(s.requireClientAuth)
+	r.With(s.cacheControl("me/usage")).Get("/me/usage", s.myUsageHandler)
+	r.With(s.cacheControl("me/locations")).Get("/me/locations", s.savedLocationsHandler)
+	r.With(s.cacheControl("me/locations")).Post("/me/locations", s.saveLocationHandler)
+	r.With(s.cacheControl("me/locations")).Delete("/me/locations/{zip}", s.deleteSavedLocationHandler)
+	r.With(s.cacheControl("me/preferences")).Get("/me/preferences", s.preferencesHandler)
+	r.With(s.cacheControl("me/preferences")).Put("/me/preferences", s.setPreferencesHandler)
+	r.With(s.cacheControl("subscriptions")).Get("/subscriptions", s.subscriptionsHandler)
+	r.With(s.cacheControl("subscriptions")).Post("/subscriptions", s.createSubscriptionHandler)
+	r.With(s.cacheControl("subscriptions")).Get("/subscriptions/{id}", s.subscriptionHandler)
+	r.With(s.cacheControl("subscriptions")).Delete("/subscriptions/{id}", s.deleteSubscriptionHandler)
+}
+
+// adminRoutes adds the operator endpoints, which require the admin token, a
+// JWT granting the admin scope, or a trusted client certificate
+func (s *Server) adminRoutes(r chi.Router) {
+	r.Use(s.requireAdmin)
+	// CPU profiles and traces run for as long as they're asked to
+	r.Mount("/debug", middleware.Profiler()) // pprof profiles and expvar
+	r.Group(s.timedAdminRoutes)
+}
+
+// timedAdminRoutes adds the operator endpoints limited to REQUEST_TIMEOUT
+func (s *Server) timedAdminRoutes(r chi.Router) {
+	r.Use(s.requestTimeout)
+	r.With(s.cacheControl("admin")).Get("/cache/stats", s.cacheStatsHandler)
+	r.With(s.cacheControl("admin")).Delete("/cache", s.cacheInvalidationHandler)
+	r.With(s.cacheControl("admin")).Get("/providers", s.providerBreakersHandler)
+	r.With(s.cacheControl("admin")).Get("/quota", s.quotaHandler)
+	r.With(s.cacheControl("admin")).Get("/usage", s.usageHandler)
+	r.With(s.cacheControl("admin")).Get("/analytics/top-locations", s.topLocationsHandler)
+	r.With(s.cacheControl("admin")).Get("/locations", s.locationsHandler)
+	r.With(s.cacheControl("admin")).Get("/locations/{zip}", s.locationHandler)
+	r.With(s.cacheControl("admin")).Put("/locations/{zip}", s.setLocationHandler)
+	r.With(s.cacheControl("admin")).Delete("/locations/{zip}", s.deleteLocationHandler)
+	r.With(s.cacheControl("admin")).Get("/keys", s.apiKeysHandler)
+	r.With(s.cacheControl("admin")).Post("/keys", s.createAPIKeyHandler)
+	r.With(s.cacheControl("admin")).Delete("/keys/{id}", s.deleteAPIKeyHandler)
+	r.With(s.cacheControl("admin")).Post("/reload", s.reloadHandler)
+	r.With(s.cacheControl("admin")).Get("/loglevel", logLevelHandler)
+	r.With(s.cacheControl("admin")).Put("/loglevel", setLogLevelHandler)
+}
+
+// serveCommand runs the API server
+func serveCommand(args []string) {
+	opts, err := parseConfigFlags("serve", args)
+	if err != nil {
+		log.Fatal(err)
+	}
+	s, err := NewServer(opts...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := s.Run(); err != nil {
+		log.Fatalf("Server failed: %v", err)
+	}
+}
+
+// newRouter creates the router for the API and, when ADMIN_PORT is set, a
+// separate one for the admin endpoints (nil otherwise)
+func (s *Server) newRouter(c *Config) (api, admin chi.Router) {
+	// Create Chi router
+	r := chi.NewRouter()
+
+	// Add middleware
+	r.Use(requestID)              // Add request ID to context and response
+	r.Use(requestLogger)          // Log API request details
+	r.Use(middleware.Recoverer)   // Recover from panics without crashing server
+	r.Use(s.realIP)               // Set RemoteAddr to the client IP a trusted proxy forwarded
+	r.Use(s.securityHeaders)      // Set HSTS, CSP, and other security headers
+	r.Use(s.corsPolicy)           // Apply the CORS policy and answer preflight requests
+	r.Use(jsonMiddleware)         // Set JSON headers
+	r.Use(s.limitBody)            // Cap request bodies at MAX_BODY_BYTES
+	r.Use(s.rateLimitIPs)         // Limit requests per IP address
+	r.Use(s.mockHeaderMiddleware) // Label demo data responses
+
+	// Define routes
+	r.Group(func(r chi.Router) {
+		r.Use(s.requestTimeout)
+		r.Get("/", s.rootHandler)
+		r.With(s.cacheControl("health")).Get("/health", s.healthHandler)
+		r.With(s.cacheControl("version")).Get("/version", versionHandler)
+		r.With(s.cacheControl("openapi")).Get("/openapi.json", openAPIHandler)
+		r.Get("/docs", http.RedirectHandler("/docs/", http.StatusMovedPermanently).ServeHTTP)
+		r.With(s.cacheControl("docs")).Handle("/docs/*", docsHandler())
+		r.Get("/ui", http.RedirectHandler("/ui/", http.StatusMovedPermanently).ServeHTTP)
+		r.With(s.cacheControl("ui")).Handle("/ui/*", s.uiHandler())
+		r.With(s.cacheControl("badge")).Get("/badge/{zip}.svg", s.badgeHandler)
+		r.With(s.cacheControl("card")).Get("/card/{zip}.png", s.cardHandler)
+		r.With(s.cacheControl("calendar")).Get("/calendar/{zip}.ics", s.calendarHandler)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(s.deprecateUnversioned)
+		s.weatherRoutes(r)
+	})
+	r.Group(s.meRoutes)
+
+	// API versioning route group (optional)
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Group(s.weatherRoutes)
+		r.Group(s.meRoutes)
+		r.Group(func(r chi.Router) {
+			r.Use(s.requestTimeout)
+			r.With(s.cacheControl("health")).Get("/health", s.healthHandler)
+			r.With(s.cacheControl("version")).Get("/version", versionHandler)
+		})
+	})
+	r.Route("/api/v2", s.v2Routes)
+
+	// Operator endpoints, on their own listener when ADMIN_PORT is set
+	if c.AdminPort == "" {
+		r.Route("/admin", s.adminRoutes)
+	} else {
+		admin = chi.NewRouter()
+		admin.Use(requestID)
+		admin.Use(requestLogger)
+		admin.Use(middleware.Recoverer)
+		admin.Use(s.realIP)
+		admin.Use(s.securityHeaders)
+		admin.Use(s.corsPolicy)
+		admin.Use(jsonMiddleware)
+		admin.Use(s.limitBody)
+		admin.Route("/admin", s.adminRoutes)
+	}
+	return r, admin
+}
+
+// printEndpoints lists example requests for each endpoint at startup
+func printEndpoints() {
+	fmt.Printf("Endpoints available:\n")
+	fmt.Printf("  GET /weather?zip_code=10001\n")
+	fmt.Printf("  GET /weather?city=Seattle,WA\n")
+	fmt.Printf("  GET /weather?lat=47.6&lon=-122.3\n")
+	fmt.Printf("  GET /weather/me\n")
+	fmt.Printf("  GET /weather/stream?zip_code=10001\n")
+	fmt.Printf("  GET /ws (WebSocket)\n")
+	fmt.Printf("  POST /weather/batch\n")
+	fmt.Printf("  POST /graphql\n")
+	fmt.Printf("  GET /compare?zip_codes=10001,94102,60601\n")
+	fmt.Printf("  GET /forecast?zip_code=10001\n")
+	fmt.Printf("  GET /forecast/hourly?zip_code=10001&hours=24\n")
+	fmt.Printf("  GET /locations/10001/weather\n")
+	fmt.Printf("  GET /locations/10001/forecast\n")
+	fmt.Printf("  GET /locations/10001/forecast/hourly?hours=24\n")
+	fmt.Printf("  GET /history?zip_code=10001&date=2024-01-15\n")
+	fmt.Printf("  GET /history/observations?zip_code=10001\n")
+	fmt.Printf("  GET /history/trend?zip_code=10001&window=7d\n")
+	fmt.Printf("  GET /air-quality?zip_code=10001\n")
+	fmt.Printf("  GET /uv?zip_code=10001\n")
+	fmt.Printf("  GET /astronomy?zip_code=10001\n")
+	fmt.Printf("  GET /zip-code?zip_code=10001-1234\n")
+	fmt.Printf("  GET /health\n")
+	fmt.Printf("  GET /version\n")
+	fmt.Printf("  GET /openapi.json\n")
+	fmt.Printf("  GET /docs (Swagger UI)\n")
+	fmt.Printf("  GET /ui (web dashboard)\n")
+	fmt.Printf("  GET /badge/10001.svg\n")
+	fmt.Printf("  GET /card/10001.png\n")
+	fmt.Printf("  GET /calendar/10001.ics\n")
+	fmt.Printf("  GET /api/v1/weather?zip_code=10001\n")
+	fmt.Printf("  GET /api/v2/weather?zip_code=10001\n")
+	fmt.Printf("  GET /api/v2/forecast?zip_code=10001\n")
+	fmt.Printf("  GET /api/v2/forecast/hourly?zip_code=10001&hours=24\n")
+	fmt.Printf("  GET /api/v1/weather/me\n")
+	fmt.Printf("  GET /api/v1/weather/stream?zip_code=10001\n")
+	fmt.Printf("  GET /api/v1/ws (WebSocket)\n")
+	fmt.Printf("  POST /api/v1/weather/batch\n")
+	fmt.Printf("  POST /api/v1/graphql\n")
+	fmt.Printf("  GET /api/v1/compare?zip_codes=10001,94102,60601\n")
+	fmt.Printf("  GET /api/v1/forecast?zip_code=10001\n")
+	fmt.Printf("  GET /api/v1/forecast/hourly?zip_code=10001&hours=24\n")
+	fmt.Printf("  GET /api/v1/locations/10001/weather\n")
+	fmt.Printf("  GET /api/v1/locations/10001/forecast\n")
+	fmt.Printf("  GET /api/v1/history?zip_code=10001&date=2024-01-15\n")
+	fmt.Printf("  GET /api/v1/air-quality?zip_code=10001\n")
+	fmt.Printf("  GET /api/v1/uv?zip_code=10001\n")
+	fmt.Printf("  GET /api/v1/astronomy?zip_code=10001\n")
+	fmt.Printf("  GET /api/v1/zip-code?zip_code=10001-1234\n")
+	fmt.Printf("  GET /api/v1/health\n")
+	fmt.Printf("  GET /me/usage\n")
+	fmt.Printf("  GET /api/v1/me/usage\n")
+	fmt.Printf("  GET /me/locations\n")
+	fmt.Printf("  POST /me/locations\n")
+	fmt.Printf("  DELETE /me/locations/{zip}\n")
+	fmt.Printf("  GET /me/preferences\n")
+	fmt.Printf("  PUT /me/preferences\n")
+	fmt.Printf("  GET /subscriptions\n")
+	fmt.Printf("  POST /subscriptions\n")
+	fmt.Printf("  DELETE /subscriptions/{id}\n")
+	fmt.Printf("  GET /me/weather\n")
+	fmt.Printf("  GET /api/v1/me/weather\n")
+	fmt.Printf("  GET /admin/cache/stats\n")
+	fmt.Printf("  DELETE /admin/cache?zip_code=10001\n")
+	fmt.Printf("  GET /admin/providers\n")
+	fmt.Printf("  GET /admin/quota\n")
+	fmt.Printf("  GET /admin/usage\n")
+	fmt.Printf("  GET /admin/analytics/top-locations?window=24h\n")
+	fmt.Printf("  GET /admin/locations\n")
+	fmt.Printf("  GET /admin/locations/{zip}\n")
+	fmt.Printf("  PUT /admin/locations/{zip}\n")
+	fmt.Printf("  DELETE /admin/locations/{zip}\n")
+	fmt.Printf("  GET /admin/keys\n")
+	fmt.Printf("  POST /admin/keys\n")
+	fmt.Printf("  DELETE /admin/keys/{id}\n")
+	fmt.Printf("  POST /admin/reload\n")
+	fmt.Printf("  GET /admin/loglevel\n")
+	fmt.Printf("  PUT /admin/loglevel\n")
+	fmt.Printf("  GET /admin/debug/pprof/\n")
+}
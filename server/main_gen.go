@@ -0,0 +1,272 @@
+// Code generated by github.com/tinylib/msgp DO NOT EDIT.
+
+package server
+
+import (
+	"github.com/tinylib/msgp/msgp"
+)
+
+// MarshalMsg implements msgp.Marshaler
+func (z *WeatherResponse) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// check for omitted fields
+	zb0001Len := uint32(21)
+	var zb0001Mask uint32 /* 21 bits */
+	_ = zb0001Mask
+	if z.ZipCode == "" {
+		zb0001Len--
+		zb0001Mask |= 0x1
+	}
+	if z.Cache == "" {
+		zb0001Len--
+		zb0001Mask |= 0x40000
+	}
+	if z.Stale == false {
+		zb0001Len--
+		zb0001Mask |= 0x80000
+	}
+	if z.Source == "" {
+		zb0001Len--
+		zb0001Mask |= 0x100000
+	}
+	// variable map header, size zb0001Len
+	o = msgp.AppendMapHeader(o, zb0001Len)
+
+	// skip if no fields are to be emitted
+	if zb0001Len != 0 {
+		if (zb0001Mask & 0x1) == 0 { // if not omitted
+			// string "zip_code"
+			o = append(o, 0xa8, 0x7a, 0x69, 0x70, 0x5f, 0x63, 0x6f, 0x64, 0x65)
+			o = msgp.AppendString(o, z.ZipCode)
+		}
+		// string "location"
+		o = append(o, 0xa8, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e)
+		o = msgp.AppendString(o, z.Location)
+		// string "units"
+		o = append(o, 0xa5, 0x75, 0x6e, 0x69, 0x74, 0x73)
+		o = msgp.AppendString(o, z.Units)
+		// string "temperature"
+		o = append(o, 0xab, 0x74, 0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65)
+		o = msgp.AppendFloat64(o, z.Temperature)
+		// string "feels_like"
+		o = append(o, 0xaa, 0x66, 0x65, 0x65, 0x6c, 0x73, 0x5f, 0x6c, 0x69, 0x6b, 0x65)
+		o = msgp.AppendFloat64(o, z.FeelsLike)
+		// string "dew_point"
+		o = append(o, 0xa9, 0x64, 0x65, 0x77, 0x5f, 0x70, 0x6f, 0x69, 0x6e, 0x74)
+		o = msgp.AppendFloat64(o, z.DewPoint)
+		// string "description"
+		o = append(o, 0xab, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e)
+		o = msgp.AppendString(o, z.Description)
+		// string "icon"
+		o = append(o, 0xa4, 0x69, 0x63, 0x6f, 0x6e)
+		o = msgp.AppendString(o, z.Icon)
+		// string "icon_url"
+		o = append(o, 0xa8, 0x69, 0x63, 0x6f, 0x6e, 0x5f, 0x75, 0x72, 0x6c)
+		o = msgp.AppendString(o, z.IconURL)
+		// string "humidity"
+		o = append(o, 0xa8, 0x68, 0x75, 0x6d, 0x69, 0x64, 0x69, 0x74, 0x79)
+		o = msgp.AppendInt(o, z.Humidity)
+		// string "pressure"
+		o = append(o, 0xa8, 0x70, 0x72, 0x65, 0x73, 0x73, 0x75, 0x72, 0x65)
+		o = msgp.AppendInt(o, z.Pressure)
+		// string "visibility"
+		o = append(o, 0xaa, 0x76, 0x69, 0x73, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79)
+		o = msgp.AppendInt(o, z.Visibility)
+		// string "cloud_cover"
+		o = append(o, 0xab, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x5f, 0x63, 0x6f, 0x76, 0x65, 0x72)
+		o = msgp.AppendInt(o, z.CloudCover)
+		// string "wind_speed"
+		o = append(o, 0xaa, 0x77, 0x69, 0x6e, 0x64, 0x5f, 0x73, 0x70, 0x65, 0x65, 0x64)
+		o = msgp.AppendFloat64(o, z.WindSpeed)
+		// string "wind_direction"
+		o = append(o, 0xae, 0x77, 0x69, 0x6e, 0x64, 0x5f, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e)
+		o = msgp.AppendInt(o, z.WindDirection)
+		// string "wind_gust"
+		o = append(o, 0xa9, 0x77, 0x69, 0x6e, 0x64, 0x5f, 0x67, 0x75, 0x73, 0x74)
+		o = msgp.AppendFloat64(o, z.WindGust)
+		// string "observed_at"
+		o = append(o, 0xab, 0x6f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x5f, 0x61, 0x74)
+		o = msgp.AppendString(o, z.ObservedAt)
+		// string "local_time"
+		o = append(o, 0xaa, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x5f, 0x74, 0x69, 0x6d, 0x65)
+		o = msgp.AppendString(o, z.LocalTime)
+		if (zb0001Mask & 0x40000) == 0 { // if not omitted
+			// string "cache"
+			o = append(o, 0xa5, 0x63, 0x61, 0x63, 0x68, 0x65)
+			o = msgp.AppendString(o, z.Cache)
+		}
+		if (zb0001Mask & 0x80000) == 0 { // if not omitted
+			// string "stale"
+			o = append(o, 0xa5, 0x73, 0x74, 0x61, 0x6c, 0x65)
+			o = msgp.AppendBool(o, z.Stale)
+		}
+		if (zb0001Mask & 0x100000) == 0 { // if not omitted
+			// string "source"
+			o = append(o, 0xa6, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65)
+			o = msgp.AppendString(o, z.Source)
+		}
+	}
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *WeatherResponse) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "zip_code":
+			z.ZipCode, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "ZipCode")
+				return
+			}
+		case "location":
+			z.Location, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Location")
+				return
+			}
+		case "units":
+			z.Units, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Units")
+				return
+			}
+		case "temperature":
+			z.Temperature, bts, err = msgp.ReadFloat64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Temperature")
+				return
+			}
+		case "feels_like":
+			z.FeelsLike, bts, err = msgp.ReadFloat64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "FeelsLike")
+				return
+			}
+		case "dew_point":
+			z.DewPoint, bts, err = msgp.ReadFloat64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "DewPoint")
+				return
+			}
+		case "description":
+			z.Description, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Description")
+				return
+			}
+		case "icon":
+			z.Icon, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Icon")
+				return
+			}
+		case "icon_url":
+			z.IconURL, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "IconURL")
+				return
+			}
+		case "humidity":
+			z.Humidity, bts, err = msgp.ReadIntBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Humidity")
+				return
+			}
+		case "pressure":
+			z.Pressure, bts, err = msgp.ReadIntBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Pressure")
+				return
+			}
+		case "visibility":
+			z.Visibility, bts, err = msgp.ReadIntBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Visibility")
+				return
+			}
+		case "cloud_cover":
+			z.CloudCover, bts, err = msgp.ReadIntBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "CloudCover")
+				return
+			}
+		case "wind_speed":
+			z.WindSpeed, bts, err = msgp.ReadFloat64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "WindSpeed")
+				return
+			}
+		case "wind_direction":
+			z.WindDirection, bts, err = msgp.ReadIntBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "WindDirection")
+				return
+			}
+		case "wind_gust":
+			z.WindGust, bts, err = msgp.ReadFloat64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "WindGust")
+				return
+			}
+		case "observed_at":
+			z.ObservedAt, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "ObservedAt")
+				return
+			}
+		case "local_time":
+			z.LocalTime, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "LocalTime")
+				return
+			}
+		case "cache":
+			z.Cache, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Cache")
+				return
+			}
+		case "stale":
+			z.Stale, bts, err = msgp.ReadBoolBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Stale")
+				return
+			}
+		case "source":
+			z.Source, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Source")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *WeatherResponse) Msgsize() (s int) {
+	s = 3 + 9 + msgp.StringPrefixSize + len(z.ZipCode) + 9 + msgp.StringPrefixSize + len(z.Location) + 6 + msgp.StringPrefixSize + len(z.Units) + 12 + msgp.Float64Size + 11 + msgp.Float64Size + 10 + msgp.Float64Size + 12 + msgp.StringPrefixSize + len(z.Description) + 5 + msgp.StringPrefixSize + len(z.Icon) + 9 + msgp.StringPrefixSize + len(z.IconURL) + 9 + msgp.IntSize + 9 + msgp.IntSize + 11 + msgp.IntSize + 12 + msgp.IntSize + 11 + msgp.Float64Size + 15 + msgp.IntSize + 10 + msgp.Float64Size + 12 + msgp.StringPrefixSize + len(z.ObservedAt) + 11 + msgp.StringPrefixSize + len(z.LocalTime) + 6 + msgp.StringPrefixSize + len(z.Cache) + 6 + msgp.BoolSize + 7 + msgp.StringPrefixSize + len(z.Source)
+	return
+}
@@ -0,0 +1,166 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+// Schema migrations of the database stores, one directory per backend. Files
+// are named NNNNN_description.sql and applied in order; once released, a
+// migration must never change, so add a new one instead.
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+// How long startup migrations may take, including waiting for another server
+// that is migrating the same database
+const migrationTimeout = 5 * time.Minute
+
+// migratingStore is implemented by stores whose database schema is versioned
+type migratingStore interface {
+	// migrator returns the migration provider for the store's database
+	migrator() (*goose.Provider, error)
+}
+
+// newMigrator returns a migration provider for db using the migrations in
+// the backend's directory
+func newMigrator(dialect goose.Dialect, db *sql.DB, backend string) (*goose.Provider, error) {
+	migrations, err := fs.Sub(migrationFiles, "migrations/"+backend)
+	if err != nil {
+		return nil, err
+	}
+	var options []goose.ProviderOption
+	if dialect == goose.DialectPostgres {
+		// Replicas starting together take turns, so none applies a migration twice
+		locker, err := lock.NewPostgresSessionLocker()
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, goose.WithSessionLocker(locker))
+	}
+	return goose.NewProvider(dialect, db, migrations, options...)
+}
+
+// prepareSchema brings a store's schema up to date with STORE_AUTO_MIGRATE, or
+// else checks that it is
+func prepareSchema(store Store, autoMigrate bool) error {
+	versioned, ok := store.(migratingStore)
+	if !ok {
+		return nil
+	}
+	migrator, err := versioned.migrator()
+	if err != nil {
+		return fmt.Errorf("failed to load database migrations: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), migrationTimeout)
+	defer cancel()
+
+	if !autoMigrate {
+		current, target, err := migrator.GetVersions(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to read the database schema version: %v", err)
+		}
+		if current < target {
+			return fmt.Errorf("database schema is at version %d but this server needs version %d: run the migrate command or set STORE_AUTO_MIGRATE=true", current, target)
+		}
+		return nil
+	}
+
+	results, err := migrator.Up(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to migrate the database: %v", err)
+	}
+	for _, result := range results {
+		infof("Applied database migration %s", result.Source.Path)
+	}
+	return nil
+}
+
+// migrateCommand applies, rolls back, or lists the schema migrations of the
+// configured database store
+func migrateCommand(args []string) {
+	action := "up"
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		action, args = args[0], args[1:]
+	}
+	opts, err := parseConfigFlags("migrate", args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration: %v\n", err)
+		os.Exit(1)
+	}
+	s, err := newServer(opts...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	c := s.config()
+
+	store, err := openStore(c)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	err = s.runMigrations(store, action)
+	store.Close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// runMigrations applies, rolls back, or lists a store's migrations, printing
+// what it did
+func (s *Server) runMigrations(store Store, action string) error {
+	versioned, ok := store.(migratingStore)
+	if !ok {
+		return fmt.Errorf("STORE_BACKEND=%s has no database to migrate", s.config().StoreBackend)
+	}
+	migrator, err := versioned.migrator()
+	if err != nil {
+		return fmt.Errorf("failed to load database migrations: %v", err)
+	}
+
+	ctx := context.Background()
+	switch action {
+	case "up":
+		results, err := migrator.Up(ctx)
+		if err != nil {
+			return fmt.Errorf("migrate up failed: %v", err)
+		}
+		for _, result := range results {
+			fmt.Printf("applied %s (%s)\n", result.Source.Path, result.Duration.Round(time.Millisecond))
+		}
+		if len(results) == 0 {
+			fmt.Println("database schema is up to date")
+		}
+	case "down":
+		result, err := migrator.Down(ctx)
+		if err != nil {
+			return fmt.Errorf("migrate down failed: %v", err)
+		}
+		fmt.Printf("rolled back %s (%s)\n", result.Source.Path, result.Duration.Round(time.Millisecond))
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return fmt.Errorf("migrate status failed: %v", err)
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.State == goose.StateApplied {
+				state = "applied " + status.AppliedAt.UTC().Format(time.RFC3339)
+			}
+			fmt.Printf("  %-30s %s\n", status.Source.Path, state)
+		}
+	default:
+		return fmt.Errorf("unknown migrate action %q: must be up, down, or status", action)
+	}
+	return nil
+}
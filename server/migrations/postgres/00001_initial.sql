@@ -0,0 +1,49 @@
+-- Client data, API keys, usage, and observations. Tables may already exist in
+-- databases created before migrations used goose, whose own version table,
+-- schema_migrations, is dropped.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS saved_locations (
+	id       BIGSERIAL PRIMARY KEY,
+	client   TEXT NOT NULL,
+	zip_code TEXT NOT NULL,
+	name     TEXT NOT NULL DEFAULT '',
+	UNIQUE (client, zip_code)
+);
+CREATE TABLE IF NOT EXISTS preferences (
+	client   TEXT PRIMARY KEY,
+	units    TEXT NOT NULL DEFAULT '',
+	lang     TEXT NOT NULL DEFAULT '',
+	provider TEXT NOT NULL DEFAULT ''
+);
+CREATE TABLE IF NOT EXISTS api_keys (
+	sha256     TEXT PRIMARY KEY,
+	name       TEXT NOT NULL,
+	tier       TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE TABLE IF NOT EXISTS usage (
+	day      TEXT NOT NULL,
+	client   TEXT NOT NULL,
+	tier     TEXT NOT NULL,
+	requests BIGINT NOT NULL,
+	PRIMARY KEY (day, client)
+);
+CREATE TABLE IF NOT EXISTS observations (
+	zip_code    TEXT NOT NULL,
+	country     TEXT NOT NULL,
+	observed_at TIMESTAMPTZ NOT NULL,
+	source      TEXT NOT NULL,
+	units       TEXT NOT NULL,
+	weather     JSONB NOT NULL,
+	PRIMARY KEY (zip_code, country, observed_at, source, units)
+);
+CREATE INDEX IF NOT EXISTS observations_observed_at ON observations (observed_at);
+DROP TABLE IF EXISTS schema_migrations;
+
+-- +goose Down
+DROP TABLE observations;
+DROP TABLE usage;
+DROP TABLE api_keys;
+DROP TABLE preferences;
+DROP TABLE saved_locations;
@@ -0,0 +1,19 @@
+-- Weather alert subscriptions, polled by every server sharing the database.
+
+-- +goose Up
+CREATE TABLE subscriptions (
+	id            TEXT PRIMARY KEY,
+	client        TEXT NOT NULL,
+	zip_code      TEXT NOT NULL,
+	condition     TEXT NOT NULL,
+	units         TEXT NOT NULL,
+	callback_url  TEXT NOT NULL,
+	secret        TEXT NOT NULL,
+	created_at    TIMESTAMPTZ NOT NULL,
+	triggered     BOOLEAN NOT NULL DEFAULT false,
+	last_alert_at TIMESTAMPTZ
+);
+CREATE INDEX subscriptions_client ON subscriptions (client, created_at);
+
+-- +goose Down
+DROP TABLE subscriptions;
@@ -0,0 +1,10 @@
+-- Subscriptions can alert on a client's saved locations, through a
+-- subscription per location made from them.
+
+-- +goose Up
+ALTER TABLE subscriptions ADD COLUMN saved_locations BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE subscriptions ADD COLUMN parent_id TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE subscriptions DROP COLUMN parent_id;
+ALTER TABLE subscriptions DROP COLUMN saved_locations;
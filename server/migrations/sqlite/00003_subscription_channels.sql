@@ -0,0 +1,9 @@
+-- Subscriptions alert by webhook or by email.
+
+-- +goose Up
+ALTER TABLE subscriptions ADD COLUMN channel TEXT NOT NULL DEFAULT 'webhook';
+ALTER TABLE subscriptions ADD COLUMN email TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE subscriptions DROP COLUMN email;
+ALTER TABLE subscriptions DROP COLUMN channel;
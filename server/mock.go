@@ -0,0 +1,54 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// mockHeaderMiddleware labels responses served in mock mode with X-Mock-Data: true
+func (s *Server) mockHeaderMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.config().MockMode {
+			w.Header().Set("X-Mock-Data", "true")
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// mockProvider serves demo data without calling any upstream API
+type mockProvider struct {
+	s *Server
+}
+
+func (p mockProvider) Name() string {
+	return "mock"
+}
+
+func (p mockProvider) Current(ctx context.Context, loc Location, opts Options) (*WeatherResponse, error) {
+	now := time.Now().UTC()
+	return &WeatherResponse{
+		ZipCode:       loc.ZipCode,
+		Location:      p.s.mockName(loc),
+		Units:         opts.units(),
+		Temperature:   convertTemperature(72.5, opts.units()),
+		FeelsLike:     convertTemperature(73.1, opts.units()),
+		DewPoint:      dewPoint(convertTemperature(72.5, opts.units()), 65, opts.units()),
+		Description:   "partly cloudy (demo data)",
+		Icon:          "02d",
+		IconURL:       p.s.iconURL("02d"),
+		Humidity:      65,
+		Pressure:      1015,
+		Visibility:    10000,
+		CloudCover:    40,
+		WindSpeed:     convertSpeed(8.2, opts.units()),
+		WindDirection: 225,
+		WindGust:      convertSpeed(14.1, opts.units()),
+		ObservedAt:    now.Truncate(10 * time.Minute).Format(time.RFC3339),
+		LocalTime:     now.Format(time.RFC3339),
+	}, nil
+}
+
+func (p mockProvider) HourlyForecast(ctx context.Context, loc Location, hours int, opts Options) (*HourlyForecastResponse, error) {
+	return p.s.mockHourlyForecast(loc, hours, opts), nil
+}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// MQTT defaults, and how long publishing waits for a broker that is away
+const (
+	defaultMQTTTopicPrefix = "weather"
+	defaultMQTTQoS         = 1
+	mqttConnectTimeout     = 10 * time.Second
+)
+
+// URL schemes the MQTT client can connect with
+var mqttSchemes = []string{"tcp", "mqtt", "ssl", "tls", "mqtts", "ws", "wss"}
+
+// validateMQTTBrokerURL checks that a broker URL has a scheme the client
+// supports and a host
+func validateMQTTBrokerURL(brokerURL string) error {
+	parsed, err := url.Parse(brokerURL)
+	if err != nil || !slices.Contains(mqttSchemes, parsed.Scheme) || parsed.Host == "" {
+		return fmt.Errorf("invalid MQTT_BROKER_URL %q: must be a URL such as tcp://broker:1883 or ssl://broker:8883", brokerURL)
+	}
+	return nil
+}
+
+// mqttBrokerDescription returns the broker URL without any credentials in it
+func mqttBrokerDescription(brokerURL string) string {
+	parsed, err := url.Parse(brokerURL)
+	if err != nil {
+		return "(invalid)"
+	}
+	return parsed.Scheme + "://" + parsed.Host
+}
+
+// mqttStatusTopic is where the server says whether it is online: a retained
+// "online" while it is connected, and "offline" when it stops or drops off
+func mqttStatusTopic(c *Config) string {
+	return c.MQTTTopicPrefix + "/status"
+}
+
+// mqttTopic returns the topic a location's current conditions are published
+// to, {prefix}/{zip}/current. Postal codes outside the US add their country,
+// e.g. weather/SW1A1AA-GB/current.
+func mqttTopic(c *Config, loc Location) string {
+	return c.MQTTTopicPrefix + "/" + loc.code() + "/current"
+}
+
+// startMQTT connects to MQTT_BROKER_URL, if it is set, in the background;
+// the client keeps reconnecting until it gets through and whenever it drops
+func (s *Server) startMQTT(c *Config) {
+	if c.MQTTBrokerURL == "" {
+		return
+	}
+
+	clientID := c.MQTTClientID
+	if clientID == "" {
+		hostname, _ := os.Hostname()
+		clientID = "weather-server-" + hostname
+	}
+	status := mqttStatusTopic(c)
+	opts := mqtt.NewClientOptions().
+		AddBroker(c.MQTTBrokerURL).
+		SetClientID(clientID).
+		SetUsername(c.MQTTUsername).
+		SetPassword(c.MQTTPassword).
+		SetConnectTimeout(mqttConnectTimeout).
+		SetConnectRetry(true).
+		SetAutoReconnect(true).
+		SetWill(status, "offline", 1, true).
+		SetOnConnectHandler(func(client mqtt.Client) {
+			infof("Connected to MQTT broker %s", mqttBrokerDescription(c.MQTTBrokerURL))
+			client.Publish(status, 1, true, "online")
+		}).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			warnf("Lost connection to MQTT broker %s: %v", mqttBrokerDescription(c.MQTTBrokerURL), err)
+		})
+	s.mqttClient = mqtt.NewClient(opts)
+	s.mqttClient.Connect()
+}
+
+// stopMQTT marks the server offline and disconnects from the broker
+func (s *Server) stopMQTT() {
+	if s.mqttClient == nil || !s.mqttClient.IsConnectionOpen() {
+		return
+	}
+	s.mqttClient.Publish(mqttStatusTopic(s.config()), 1, true, "offline").WaitTimeout(time.Second)
+	s.mqttClient.Disconnect(250)
+}
+
+// publishObservation publishes current conditions looked up upstream for a
+// zip code in MQTT_UNITS to the location's MQTT topic, as a retained message
+// unless MQTT_RETAIN=false so new subscribers get the latest right away
+func (s *Server) publishObservation(loc Location, weather WeatherResponse) {
+	c := s.config()
+	if s.mqttClient == nil || loc.ZipCode == "" || weather.Units != c.MQTTUnits {
+		return
+	}
+
+	payload, err := json.Marshal(weather)
+	if err != nil {
+		warnf("Failed to encode MQTT message for %s: %v", loc.ZipCode, err)
+		return
+	}
+	topic := mqttTopic(c, loc)
+	token := s.mqttClient.Publish(topic, byte(c.MQTTQoS), c.MQTTRetain, payload)
+	if !token.WaitTimeout(mqttConnectTimeout) {
+		err = errors.New("timed out")
+	} else {
+		err = token.Error()
+	}
+	if err != nil {
+		warnf("Failed to publish to MQTT topic %s: %v", topic, err)
+		return
+	}
+	debugf("Published %s", topic)
+}
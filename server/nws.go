@@ -0,0 +1,277 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// National Weather Service API base URL (no API key required, US only)
+const nwsBaseURL = "https://api.weather.gov"
+
+// The NWS API asks clients to identify themselves with a User-Agent
+const nwsUserAgent = "weather-api (github.com/dekkagaijin/go-container-test)"
+
+// NWS points API response structure (simplified)
+type NWSPointAPIResponse struct {
+	Properties struct {
+		ObservationStations string `json:"observationStations"`
+		TimeZone            string `json:"timeZone"`
+		RelativeLocation    struct {
+			Properties struct {
+				City  string `json:"city"`
+				State string `json:"state"`
+			} `json:"properties"`
+		} `json:"relativeLocation"`
+	} `json:"properties"`
+}
+
+// NWS gridpoint stations API response structure (simplified)
+type NWSStationsAPIResponse struct {
+	Features []struct {
+		Properties struct {
+			StationIdentifier string `json:"stationIdentifier"`
+		} `json:"properties"`
+	} `json:"features"`
+}
+
+// nwsValue is an NWS quantity; Value is nil when the station didn't report it
+type nwsValue struct {
+	Value *float64 `json:"value"`
+}
+
+// or returns the value, or fallback when it wasn't reported
+func (v nwsValue) or(fallback float64) float64 {
+	if v.Value == nil {
+		return fallback
+	}
+	return *v.Value
+}
+
+// NWS latest observation API response structure (simplified). Quantities are SI:
+// degrees Celsius, km/h, pascals, and meters.
+type NWSObservationAPIResponse struct {
+	Properties struct {
+		Timestamp          string   `json:"timestamp"`
+		TextDescription    string   `json:"textDescription"`
+		Icon               string   `json:"icon"`
+		Temperature        nwsValue `json:"temperature"`
+		Dewpoint           nwsValue `json:"dewpoint"`
+		WindDirection      nwsValue `json:"windDirection"`
+		WindSpeed          nwsValue `json:"windSpeed"`
+		WindGust           nwsValue `json:"windGust"`
+		BarometricPressure nwsValue `json:"barometricPressure"`
+		SeaLevelPressure   nwsValue `json:"seaLevelPressure"`
+		Visibility         nwsValue `json:"visibility"`
+		RelativeHumidity   nwsValue `json:"relativeHumidity"`
+		WindChill          nwsValue `json:"windChill"`
+		HeatIndex          nwsValue `json:"heatIndex"`
+		CloudLayers        []struct {
+			Amount string `json:"amount"`
+		} `json:"cloudLayers"`
+	} `json:"properties"`
+}
+
+// Approximate cloud cover (percent) for NWS cloud layer amounts
+var nwsCloudCover = map[string]int{
+	"SKC": 0,
+	"CLR": 0,
+	"FEW": 20,
+	"SCT": 40,
+	"BKN": 75,
+	"OVC": 100,
+}
+
+// OpenWeatherMap icon codes (without the day/night suffix) for NWS icon names
+var nwsIconCodes = map[string]string{
+	"skc":             "01",
+	"hot":             "01",
+	"cold":            "01",
+	"wind_skc":        "01",
+	"few":             "02",
+	"wind_few":        "02",
+	"sct":             "03",
+	"wind_sct":        "03",
+	"bkn":             "04",
+	"ovc":             "04",
+	"wind_bkn":        "04",
+	"wind_ovc":        "04",
+	"rain_showers":    "09",
+	"rain_showers_hi": "09",
+	"rain":            "10",
+	"fzra":            "10",
+	"rain_fzra":       "10",
+	"tsra":            "11",
+	"tsra_sct":        "11",
+	"tsra_hi":         "11",
+	"tornado":         "11",
+	"hurricane":       "11",
+	"tropical_storm":  "11",
+	"snow":            "13",
+	"rain_snow":       "13",
+	"rain_sleet":      "13",
+	"snow_sleet":      "13",
+	"snow_fzra":       "13",
+	"sleet":           "13",
+	"blizzard":        "13",
+	"fog":             "50",
+	"haze":            "50",
+	"smoke":           "50",
+	"dust":            "50",
+}
+
+// nwsProvider serves current conditions from the National Weather Service API
+type nwsProvider struct {
+	s *Server
+}
+
+func (p nwsProvider) Name() string {
+	return "nws"
+}
+
+func (p nwsProvider) Current(ctx context.Context, loc Location, opts Options) (*WeatherResponse, error) {
+	coords, err := p.s.nwsCoordinates(ctx, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve the coordinates to a forecast gridpoint, then to its nearest observation station
+	var point NWSPointAPIResponse
+	if err := p.s.fetchNWS(ctx, fmt.Sprintf("%s/points/%.4f,%.4f", nwsBaseURL, coords.Lat, coords.Lon), &point); err != nil {
+		return nil, err
+	}
+
+	var stations NWSStationsAPIResponse
+	if err := p.s.fetchNWS(ctx, point.Properties.ObservationStations, &stations); err != nil {
+		return nil, err
+	}
+	if len(stations.Features) == 0 {
+		return nil, withKind(ErrNotFound, fmt.Errorf("no NWS observation stations found near this location"))
+	}
+
+	var observation NWSObservationAPIResponse
+	station := stations.Features[0].Properties.StationIdentifier
+	if err := p.s.fetchNWS(ctx, fmt.Sprintf("%s/stations/%s/observations/latest", nwsBaseURL, station), &observation); err != nil {
+		return nil, err
+	}
+	obs := observation.Properties
+
+	// Feels-like is the heat index or wind chill when either applies
+	temperature := obs.Temperature.or(0)
+	feelsLike := obs.HeatIndex.or(obs.WindChill.or(temperature))
+
+	cloudCover := 0
+	for _, layer := range obs.CloudLayers {
+		if cover := nwsCloudCover[layer.Amount]; cover > cloudCover {
+			cloudCover = cover
+		}
+	}
+
+	// Prefer the geocoded place name over the gridpoint's nearest city
+	name := coords.Name
+	if name == "" {
+		name = point.Properties.RelativeLocation.Properties.City
+	}
+
+	// Observation time is UTC; local time uses the gridpoint's time zone
+	observedAt := time.Now().UTC()
+	if parsed, err := time.Parse(time.RFC3339, obs.Timestamp); err == nil {
+		observedAt = parsed.UTC()
+	}
+	zone, err := time.LoadLocation(point.Properties.TimeZone)
+	if err != nil {
+		zone = time.UTC
+	}
+
+	icon := nwsIcon(obs.Icon)
+	return &WeatherResponse{
+		ZipCode:       loc.ZipCode,
+		Location:      name,
+		Units:         opts.units(),
+		Temperature:   celsiusTo(temperature, opts.units()),
+		FeelsLike:     celsiusTo(feelsLike, opts.units()),
+		DewPoint:      celsiusTo(obs.Dewpoint.or(temperature), opts.units()),
+		Description:   strings.ToLower(obs.TextDescription),
+		Icon:          icon,
+		IconURL:       p.s.iconURL(icon),
+		Humidity:      int(math.Round(obs.RelativeHumidity.or(0))),
+		Pressure:      int(math.Round(obs.SeaLevelPressure.or(obs.BarometricPressure.or(0)) / 100)),
+		Visibility:    int(math.Round(obs.Visibility.or(0))),
+		CloudCover:    cloudCover,
+		WindSpeed:     kmhTo(obs.WindSpeed.or(0), opts.units()),
+		WindDirection: int(math.Round(obs.WindDirection.or(0))),
+		WindGust:      kmhTo(obs.WindGust.or(0), opts.units()),
+		ObservedAt:    observedAt.Format(time.RFC3339),
+		LocalTime:     time.Now().In(zone).Format(time.RFC3339),
+	}, nil
+}
+
+// nwsCoordinates resolves a location to coordinates without an API key.
+// The NWS only covers the US, and can't look up cities by name.
+func (s *Server) nwsCoordinates(ctx context.Context, loc Location) (*Coordinates, error) {
+	switch {
+	case loc.Coords != nil:
+		return loc.Coords, nil
+	case loc.City != "":
+		return nil, fmt.Errorf("the nws provider supports zip_code or lat/lon locations, not city")
+	case loc.country() != defaultCountry:
+		return nil, fmt.Errorf("the nws provider only covers US locations")
+	default:
+		return s.geocodeZipCodeKeyless(ctx, loc)
+	}
+}
+
+// fetchNWS calls an NWS API URL with the headers the API expects
+func (s *Server) fetchNWS(ctx context.Context, fullURL string, v interface{}) error {
+	headers := http.Header{}
+	headers.Set("User-Agent", nwsUserAgent)
+	headers.Set("Accept", "application/geo+json")
+	return s.fetchJSONWithHeaders(ctx, fullURL, "NWS", headers, v)
+}
+
+// nwsIcon converts an NWS icon URL (e.g. ".../icons/land/day/bkn?size=medium")
+// into the equivalent OpenWeatherMap icon code (e.g. "04d")
+func nwsIcon(iconURL string) string {
+	path, _, _ := strings.Cut(iconURL, "?")
+	_, rest, ok := strings.Cut(path, "/icons/land/")
+	if !ok {
+		return defaultIcon
+	}
+
+	// rest is "{day|night}/{icon}[,{chance}][/{icon}...]"; the first icon wins
+	parts := strings.Split(rest, "/")
+	if len(parts) < 2 {
+		return defaultIcon
+	}
+	code, ok := nwsIconCodes[strings.Split(parts[1], ",")[0]]
+	if !ok {
+		return defaultIcon
+	}
+	if parts[0] == "night" {
+		return code + "n"
+	}
+	return code + "d"
+}
+
+// celsiusTo converts a Celsius temperature to the requested unit system
+func celsiusTo(celsius float64, units string) float64 {
+	switch units {
+	case unitsImperial:
+		return round1(celsius*9/5 + 32)
+	case unitsStandard:
+		return round1(celsius + 273.15)
+	default:
+		return round1(celsius)
+	}
+}
+
+// kmhTo converts a speed in km/h to the requested unit system (m/s outside imperial)
+func kmhTo(kmh float64, units string) float64 {
+	if units == unitsImperial {
+		return round1(kmh / 1.609344)
+	}
+	return round1(kmh / 3.6)
+}
@@ -0,0 +1,295 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// How long recorded observations are kept by default
+const defaultObservationRetention = 30 * 24 * time.Hour
+
+// observationRefreshed hands current conditions just looked up upstream, and
+// cached under key, to everything that follows them
+func (s *Server) observationRefreshed(key string, loc Location, weather WeatherResponse) {
+	weather.Cache, weather.Stale = "", false
+	s.observationListeners.broadcast(key, weather)
+	go s.recordObservation(loc, weather)
+	go s.publishObservation(loc, weather)
+	go s.emitObservationEvent(loc, weather)
+}
+
+// recordObservation records current conditions looked up for a zip code, if
+// the store keeps observations, and forgets those past OBSERVATION_RETENTION
+func (s *Server) recordObservation(loc Location, weather WeatherResponse) {
+	store, ok := s.dataStore.(observationStore)
+	retention := s.config().ObservationRetention
+	if !ok || loc.ZipCode == "" || retention <= 0 {
+		return
+	}
+
+	if err := store.RecordObservation(loc.ZipCode, loc.country(), weather); err != nil {
+		warnf("%v", err)
+		return
+	}
+	if err := store.PruneObservations(time.Now().Add(-retention)); err != nil {
+		warnf("%v", err)
+	}
+}
+
+// ObservationsResponse represents the recorded observations we'll return from /history/observations
+type ObservationsResponse struct {
+	ZipCode      string            `json:"zip_code"`
+	Date         string            `json:"date"`
+	Observations []WeatherResponse `json:"observations"`
+}
+
+// Observations handler using Chi
+func (s *Server) observationsHandler(w http.ResponseWriter, r *http.Request) {
+	store, ok := s.dataStore.(observationStore)
+	if !ok {
+		writeError(w, http.StatusConflict, "observation history requires STORE_BACKEND=sqlite or postgres")
+		return
+	}
+
+	loc, ok := s.zipCodeFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	date := r.URL.Query().Get("date")
+	if date == "" {
+		date = today()
+	}
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "date must be in format YYYY-MM-DD")
+		return
+	}
+
+	// Observations are kept in the units they were looked up in
+	units := r.URL.Query().Get("units")
+	if units != "" {
+		if err := validateUnits(units); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	observations, err := store.Observations(loc.ZipCode, loc.country(), day, day.AddDate(0, 0, 1))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	response := ObservationsResponse{ZipCode: loc.ZipCode, Date: date, Observations: []WeatherResponse{}}
+	for _, observation := range observations {
+		if units == "" || observation.Units == units {
+			response.Observations = append(response.Observations, observation)
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// Longest window and most buckets a trend covers
+const (
+	maxTrendWindow  = 366 * 24 * time.Hour
+	maxTrendBuckets = 1000
+)
+
+// parseWindowDuration parses a window or interval: a whole number of days
+// such as 7d, or a Go duration such as 12h
+func parseWindowDuration(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// formatWindowDuration formats a window or interval like parseWindowDuration reads it
+func formatWindowDuration(d time.Duration) string {
+	if d%(24*time.Hour) == 0 {
+		return strconv.Itoa(int(d/(24*time.Hour))) + "d"
+	}
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// toFahrenheit converts a temperature in a unit system to Fahrenheit
+func toFahrenheit(temperature float64, units string) float64 {
+	switch units {
+	case unitsMetric:
+		return temperature*9/5 + 32
+	case unitsStandard:
+		return (temperature-273.15)*9/5 + 32
+	default:
+		return temperature
+	}
+}
+
+// TemperatureStats summarizes the temperatures of a set of observations
+type TemperatureStats struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+	Avg float64 `json:"avg"`
+}
+
+// TrendPoint summarizes the observations in one interval of a trend
+type TrendPoint struct {
+	Start        string `json:"start"`
+	Observations int    `json:"observations"`
+	TemperatureStats
+}
+
+// TrendResponse represents the temperature trend we'll return from /history/trend
+type TrendResponse struct {
+	ZipCode      string            `json:"zip_code"`
+	Units        string            `json:"units"`
+	Window       string            `json:"window"`
+	Interval     string            `json:"interval"`
+	From         string            `json:"from"`
+	To           string            `json:"to"`
+	Observations int               `json:"observations"`
+	Temperature  *TemperatureStats `json:"temperature"`
+	Series       []TrendPoint      `json:"series"`
+}
+
+// temperatureStats accumulates temperatures for TemperatureStats
+type temperatureStats struct {
+	count         int
+	min, max, sum float64
+}
+
+func (s *temperatureStats) add(temperature float64) {
+	if s.count == 0 || temperature < s.min {
+		s.min = temperature
+	}
+	if s.count == 0 || temperature > s.max {
+		s.max = temperature
+	}
+	s.count++
+	s.sum += temperature
+}
+
+// stats returns the summary in a unit system; the temperatures are in Fahrenheit
+func (s *temperatureStats) stats(units string) TemperatureStats {
+	return TemperatureStats{
+		Min: convertTemperature(s.min, units),
+		Max: convertTemperature(s.max, units),
+		Avg: convertTemperature(s.sum/float64(s.count), units),
+	}
+}
+
+// Trend handler using Chi
+func (s *Server) trendHandler(w http.ResponseWriter, r *http.Request) {
+	store, ok := s.dataStore.(observationStore)
+	if !ok {
+		writeError(w, http.StatusConflict, "observation history requires STORE_BACKEND=sqlite or postgres")
+		return
+	}
+
+	loc, ok := s.zipCodeFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	window := 7 * 24 * time.Hour
+	if value := r.URL.Query().Get("window"); value != "" {
+		parsed, err := parseWindowDuration(value)
+		if err != nil || parsed < time.Hour || parsed > maxTrendWindow {
+			writeError(w, http.StatusBadRequest, "window must be a duration between 1h and 366d, such as 24h or 7d")
+			return
+		}
+		window = parsed
+	}
+
+	// Daily points for windows of two days or more, hourly ones otherwise
+	interval := time.Hour
+	if window >= 48*time.Hour {
+		interval = 24 * time.Hour
+	}
+	if value := r.URL.Query().Get("interval"); value != "" {
+		parsed, err := parseWindowDuration(value)
+		if err != nil || parsed < time.Hour || parsed > window {
+			writeError(w, http.StatusBadRequest, "interval must be a duration between 1h and the window, such as 6h or 1d")
+			return
+		}
+		interval = parsed
+	}
+	if window/interval > maxTrendBuckets {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("window must span at most %d intervals", maxTrendBuckets))
+		return
+	}
+
+	opts, ok := s.optionsFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	to := time.Now().UTC()
+	from := to.Add(-window)
+	observations, err := store.Observations(loc.ZipCode, loc.country(), from, to)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// The same observation may be recorded in several unit systems; count it once
+	type observationKey struct{ observedAt, source string }
+	seen := map[observationKey]bool{}
+	var overall temperatureStats
+	buckets := map[time.Time]*temperatureStats{}
+	for _, observation := range observations {
+		key := observationKey{observation.ObservedAt, observation.Source}
+		observedAt, err := time.Parse(time.RFC3339, observation.ObservedAt)
+		if seen[key] || err != nil {
+			continue
+		}
+		seen[key] = true
+
+		temperature := toFahrenheit(observation.Temperature, observation.Units)
+		overall.add(temperature)
+		start := observedAt.Truncate(interval)
+		if buckets[start] == nil {
+			buckets[start] = &temperatureStats{}
+		}
+		buckets[start].add(temperature)
+	}
+
+	response := TrendResponse{
+		ZipCode:      loc.ZipCode,
+		Units:        opts.units(),
+		Window:       formatWindowDuration(window),
+		Interval:     formatWindowDuration(interval),
+		From:         from.Format(time.RFC3339),
+		To:           to.Format(time.RFC3339),
+		Observations: overall.count,
+		Series:       []TrendPoint{},
+	}
+	if overall.count > 0 {
+		stats := overall.stats(opts.units())
+		response.Temperature = &stats
+	}
+	for start, bucket := range buckets {
+		response.Series = append(response.Series, TrendPoint{Start: start.Format(time.RFC3339), Observations: bucket.count, TemperatureStats: bucket.stats(opts.units())})
+	}
+	sort.Slice(response.Series, func(i, j int) bool { return response.Series[i].Start < response.Series[j].Start })
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
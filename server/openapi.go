@@ -0,0 +1,417 @@
+package server
+
+import (
+	"cmp"
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"mime"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// The /docs page, which renders the OpenAPI spec with Swagger UI
+//
+//go:embed docs
+var docsFiles embed.FS
+
+// openAPIParam is a query or path parameter of an operation
+type openAPIParam struct {
+	name, in    string
+	description string
+	schema      map[string]interface{}
+	required    bool
+}
+
+// optional returns the parameter as an optional one, for operations that
+// take it or an alternative
+func (p openAPIParam) optional() openAPIParam {
+	p.required = false
+	return p
+}
+
+func queryParam(name, description string, schema map[string]interface{}) openAPIParam {
+	return openAPIParam{name: name, in: "query", description: description, schema: schema}
+}
+
+func pathParam(name, description string) openAPIParam {
+	return openAPIParam{name: name, in: "path", description: description, schema: stringSchema(), required: true}
+}
+
+func stringSchema() map[string]interface{} { return map[string]interface{}{"type": "string"} }
+
+func enumSchema(values ...string) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "enum": values}
+}
+
+// Parameters shared by many operations
+var (
+	zipCodeParam  = openAPIParam{name: "zip_code", in: "query", description: "Zip or postal code, e.g. 10001", schema: stringSchema(), required: true}
+	zipParam      = pathParam("zip", "Zip or postal code, e.g. 10001")
+	countryParam  = queryParam("country", "Country of the postal code (default: US)", enumSchema("US", "CA", "GB", "DE", "AU"))
+	unitsParam    = queryParam("units", "Unit system (default: imperial, or the caller's saved preference)", enumSchema(unitsImperial, unitsMetric, unitsStandard))
+	langParam     = queryParam("lang", "Language for descriptions, e.g. es or zh-CN", stringSchema())
+	providerParam = queryParam("provider", "Weather provider to ask", enumSchema(providerNames...))
+	dateParam     = queryParam("date", "Date, as YYYY-MM-DD", map[string]interface{}{"type": "string", "format": "date"})
+	fieldsParam   = queryParam("fields", "Comma-separated JSON fields to respond with, e.g. temperature,description (default: all)", stringSchema())
+	optionParams  = []openAPIParam{unitsParam, langParam, providerParam}
+)
+
+// formatParam is the format parameter of an operation that also writes the
+// given formats only it supports
+func formatParam(extra ...string) openAPIParam {
+	return queryParam("format", "Response format (default: from the Accept header, then json)", enumSchema(append(slices.Clone(formatNames), extra...)...))
+}
+
+// openAPIOperation describes an endpoint for the OpenAPI spec. Request and
+// response schemas are generated from the types the handlers encode, so they
+// stay in step with the code.
+type openAPIOperation struct {
+	method, path string
+	tag          string
+	summary      string
+	params       []openAPIParam
+	body         interface{} // a value of the request body's type, if there is one
+	status       int         // of a successful response, when it isn't 200
+	response     interface{} // a value of the JSON response's type, or nil for any JSON
+	contentType  string      // of the response, when it isn't JSON
+	formats      []string    // other formats the response can be written in, from the format parameter
+	security     string      // "client" or "admin", for endpoints that need credentials
+	deprecated   bool
+}
+
+// with returns params followed by the shared ones
+func with(params []openAPIParam, more ...openAPIParam) []openAPIParam {
+	return append(slices.Clone(params), more...)
+}
+
+// The documented endpoints. Add new ones here as they're routed.
+var openAPIOperations = []openAPIOperation{
+	{method: "GET", path: "/weather", tag: "weather", summary: "Current weather by zip code, city, or coordinates",
+		params: with(optionParams, fieldsParam, zipCodeParam.optional(), queryParam("city", `City name, as "City" or "City,ST"`, stringSchema()),
+			queryParam("lat", "Latitude", map[string]interface{}{"type": "number"}), queryParam("lon", "Longitude", map[string]interface{}{"type": "number"}),
+			countryParam, queryParam("mode", "consensus averages every enabled provider", enumSchema(modeConsensus)), formatParam(formatGeoJSON)),
+		response: WeatherResponse{}, formats: append(slices.Clone(formatNames), formatGeoJSON), security: "client", deprecated: true},
+	{method: "GET", path: "/api/v2/weather", tag: "v2", summary: "Current weather in the v2 schema",
+		params: with(optionParams, fieldsParam, zipCodeParam.optional(), queryParam("city", `City name, as "City" or "City,ST"`, stringSchema()),
+			queryParam("lat", "Latitude", map[string]interface{}{"type": "number"}), queryParam("lon", "Longitude", map[string]interface{}{"type": "number"}), countryParam),
+		response: WeatherV2{}, security: "client"},
+	{method: "GET", path: "/api/v2/forecast", tag: "v2", summary: "5-day forecast in the v2 schema",
+		params: with(optionParams, fieldsParam, zipCodeParam, countryParam), response: ForecastV2{}, security: "client"},
+	{method: "GET", path: "/api/v2/forecast/hourly", tag: "v2", summary: "Hour-by-hour forecast in the v2 schema",
+		params:   with(optionParams, fieldsParam, zipCodeParam, countryParam, queryParam("hours", "Hours ahead (default: 24, at most 120)", map[string]interface{}{"type": "integer", "minimum": 1, "maximum": maxForecastHours})),
+		response: HourlyForecastV2{}, security: "client"},
+	{method: "GET", path: "/weather/me", tag: "weather", summary: "Current weather at the caller's location, by IP address",
+		params: with(optionParams, fieldsParam, formatParam()), response: WeatherResponse{}, formats: formatNames, security: "client"},
+	{method: "GET", path: "/weather/stream", tag: "weather", summary: "Current weather as Server-Sent Events, whenever it refreshes",
+		params: with(optionParams, zipCodeParam, countryParam), contentType: "text/event-stream", security: "client"},
+	{method: "POST", path: "/weather/batch", tag: "weather", summary: "Current weather for up to 50 zip codes",
+		params: with(optionParams, fieldsParam, formatParam(formatNDJSON, formatGeoJSON)), body: []string{},
+		response: BatchWeatherResponse{}, formats: append(slices.Clone(formatNames), formatNDJSON, formatGeoJSON), security: "client"},
+	{method: "GET", path: "/compare", tag: "weather", summary: "Compare current weather across 2-10 zip codes",
+		params: with(optionParams, fieldsParam, queryParam("zip_codes", "Comma-separated zip codes", stringSchema())), response: CompareResponse{}, security: "client"},
+	{method: "GET", path: "/forecast", tag: "forecast", summary: "5-day forecast",
+		params:   with(optionParams, fieldsParam, zipCodeParam, countryParam, formatParam(formatGeoJSON)),
+		response: ForecastResponse{}, formats: append(slices.Clone(formatNames), formatGeoJSON), security: "client"},
+	{method: "GET", path: "/forecast/hourly", tag: "forecast", summary: "Hour-by-hour forecast",
+		params:   with(optionParams, fieldsParam, zipCodeParam, countryParam, queryParam("hours", "Hours ahead (default: 24, at most 120)", map[string]interface{}{"type": "integer", "minimum": 1, "maximum": maxForecastHours}), formatParam(formatGeoJSON)),
+		response: HourlyForecastResponse{}, formats: append(slices.Clone(formatNames), formatGeoJSON), security: "client"},
+	{method: "GET", path: "/locations/{zip}/weather", tag: "weather", summary: "Current weather by zip code, in the path",
+		params:   with(optionParams, fieldsParam, zipParam, countryParam, queryParam("mode", "consensus averages every enabled provider", enumSchema(modeConsensus)), formatParam(formatGeoJSON)),
+		response: WeatherResponse{}, formats: append(slices.Clone(formatNames), formatGeoJSON), security: "client"},
+	{method: "GET", path: "/locations/{zip}/forecast", tag: "forecast", summary: "5-day forecast by zip code, in the path",
+		params:   with(optionParams, fieldsParam, zipParam, countryParam, formatParam(formatGeoJSON)),
+		response: ForecastResponse{}, formats: append(slices.Clone(formatNames), formatGeoJSON), security: "client"},
+	{method: "GET", path: "/locations/{zip}/forecast/hourly", tag: "forecast", summary: "Hour-by-hour forecast by zip code, in the path",
+		params:   with(optionParams, fieldsParam, zipParam, countryParam, queryParam("hours", "Hours ahead (default: 24, at most 120)", map[string]interface{}{"type": "integer", "minimum": 1, "maximum": maxForecastHours}), formatParam(formatGeoJSON)),
+		response: HourlyForecastResponse{}, formats: append(slices.Clone(formatNames), formatGeoJSON), security: "client"},
+	{method: "GET", path: "/history", tag: "history", summary: "Observed weather on a past date",
+		params: with(optionParams, fieldsParam, zipCodeParam, countryParam, dateParam), response: HistoryResponse{}, security: "client"},
+	{method: "GET", path: "/history/observations", tag: "history", summary: "Current conditions recorded for a zip code on a day",
+		params: []openAPIParam{zipCodeParam, countryParam, dateParam, unitsParam, fieldsParam}, response: ObservationsResponse{}, security: "client"},
+	{method: "GET", path: "/history/trend", tag: "history", summary: "Temperature statistics from recorded conditions",
+		params:   with(optionParams, fieldsParam, zipCodeParam, countryParam, queryParam("window", "How far back, e.g. 7d", stringSchema()), queryParam("interval", "Bucket size, e.g. 1d", stringSchema())),
+		response: TrendResponse{}, security: "client"},
+	{method: "GET", path: "/air-quality", tag: "conditions", summary: "Air quality index and pollutants",
+		params: []openAPIParam{zipCodeParam, countryParam, fieldsParam}, response: AirQualityResponse{}, security: "client"},
+	{method: "GET", path: "/uv", tag: "conditions", summary: "UV index",
+		params: []openAPIParam{zipCodeParam, countryParam, fieldsParam}, response: UVResponse{}, security: "client"},
+	{method: "GET", path: "/astronomy", tag: "conditions", summary: "Sunrise, sunset, day length, and moon phase",
+		params: []openAPIParam{zipCodeParam, countryParam, fieldsParam}, response: AstronomyResponse{}, security: "client"},
+	{method: "GET", path: "/zip-code", tag: "locations", summary: "Normalize a zip code and look up its city, state, and coordinates",
+		params: []openAPIParam{zipCodeParam, fieldsParam}, response: ZipCodeResponse{}, security: "client"},
+	{method: "GET", path: "/graphql", tag: "graphql", summary: "GraphQL query",
+		params:   []openAPIParam{{name: "query", in: "query", description: "GraphQL query", schema: stringSchema(), required: true}, queryParam("variables", "JSON object of variables", stringSchema()), queryParam("operationName", "Operation to run, for documents with several", stringSchema())},
+		security: "client"},
+	{method: "POST", path: "/graphql", tag: "graphql", summary: "GraphQL query", body: GraphQLRequest{}, security: "client"},
+
+	{method: "GET", path: "/badge/{zip}.svg", tag: "embeds", summary: "SVG badge with the current temperature and conditions",
+		params: with(optionParams[:2], zipParam, countryParam, queryParam("label", "Text for the left side (default: the location's name)", stringSchema())), contentType: "image/svg+xml"},
+	{method: "GET", path: "/card/{zip}.png", tag: "embeds", summary: "PNG card with current conditions and the forecast",
+		params: with(optionParams[:2], zipParam, countryParam, queryParam("theme", "Colors (default: light)", enumSchema("light", "mono"))), contentType: "image/png"},
+	{method: "GET", path: "/calendar/{zip}.ics", tag: "embeds", summary: "iCalendar feed of the daily forecast",
+		params: with(optionParams[:2], zipParam, countryParam), contentType: "text/calendar"},
+
+	{method: "GET", path: "/me/weather", tag: "me", summary: "Current weather at each of the caller's saved locations",
+		params: with(optionParams, fieldsParam), response: MyWeatherResponse{}, security: "client"},
+	{method: "GET", path: "/me/usage", tag: "me", summary: "The caller's requests against its daily quota", response: MyUsageResponse{}, security: "client"},
+	{method: "GET", path: "/me/locations", tag: "me", summary: "The caller's saved locations", response: []SavedLocation{}, security: "client"},
+	{method: "POST", path: "/me/locations", tag: "me", summary: "Save a location", body: SavedLocation{}, status: http.StatusCreated, response: SavedLocation{}, security: "client"},
+	{method: "DELETE", path: "/me/locations/{zip}", tag: "me", summary: "Remove a saved location", params: []openAPIParam{zipParam}, response: SavedLocation{}, security: "client"},
+	{method: "GET", path: "/me/preferences", tag: "me", summary: "The caller's default units, language, and provider", response: Preferences{}, security: "client"},
+	{method: "PUT", path: "/me/preferences", tag: "me", summary: "Set the caller's defaults", body: Preferences{}, response: Preferences{}, security: "client"},
+	{method: "GET", path: "/subscriptions", tag: "subscriptions", summary: "The caller's alert subscriptions", response: []Subscription{}, security: "client"},
+	{method: "POST", path: "/subscriptions", tag: "subscriptions", summary: "Subscribe to alerts when a condition is met",
+		body: SubscriptionRequest{}, status: http.StatusCreated, response: Subscription{}, security: "client"},
+	{method: "GET", path: "/subscriptions/{id}", tag: "subscriptions", summary: "One of the caller's subscriptions",
+		params: []openAPIParam{pathParam("id", "Subscription ID")}, response: Subscription{}, security: "client"},
+	{method: "DELETE", path: "/subscriptions/{id}", tag: "subscriptions", summary: "Stop a subscription's alerts",
+		params: []openAPIParam{pathParam("id", "Subscription ID")}, response: Subscription{}, security: "client"},
+
+	{method: "GET", path: "/health", tag: "service", summary: "Health check",
+		params: []openAPIParam{queryParam("verbose", "List each dependency, and return 503 when a critical one is down", enumSchema("true"))}},
+	{method: "GET", path: "/version", tag: "service", summary: "Version of the running server", response: VersionResponse{}},
+
+	{method: "GET", path: "/admin/cache/stats", tag: "admin", summary: "Cache hit ratio, entries, and memory", response: CacheStatsResponse{}, security: "admin"},
+	{method: "DELETE", path: "/admin/cache", tag: "admin", summary: "Remove a zip code, or everything, from the cache",
+		params: []openAPIParam{zipCodeParam.optional(), countryParam, queryParam("all", "Remove every entry", enumSchema("true"))}, response: CacheInvalidationResponse{}, security: "admin"},
+	{method: "GET", path: "/admin/providers", tag: "admin", summary: "Circuit breaker state per provider", response: ProviderBreakersResponse{}, security: "admin"},
+	{method: "GET", path: "/admin/quota", tag: "admin", summary: "Upstream calls today per provider", response: QuotaResponse{}, security: "admin"},
+	{method: "GET", path: "/admin/usage", tag: "admin", summary: "Requests per client against its daily quota",
+		params: []openAPIParam{dateParam}, response: UsageResponse{}, security: "admin"},
+	{method: "GET", path: "/admin/analytics/top-locations", tag: "admin", summary: "Most requested zip codes",
+		params:   []openAPIParam{queryParam("window", "How far back, up to 7d (default: 24h)", stringSchema()), queryParam("limit", "Most zip codes to list", map[string]interface{}{"type": "integer"})},
+		response: TopLocationsResponse{}, security: "admin"},
+	{method: "GET", path: "/admin/locations", tag: "admin", summary: "Zip codes and their cities", response: []LocationEntry{}, security: "admin"},
+	{method: "GET", path: "/admin/locations/{zip}", tag: "admin", summary: "The city of a zip code", params: []openAPIParam{zipParam}, response: LocationEntry{}, security: "admin"},
+	{method: "PUT", path: "/admin/locations/{zip}", tag: "admin", summary: "Add or change a zip code's city",
+		params: []openAPIParam{zipParam}, body: LocationRequest{}, response: LocationEntry{}, security: "admin"},
+	{method: "DELETE", path: "/admin/locations/{zip}", tag: "admin", summary: "Remove a zip code", params: []openAPIParam{zipParam}, response: LocationEntry{}, security: "admin"},
+	{method: "GET", path: "/admin/keys", tag: "admin", summary: "Client API keys", response: []APIKeyResponse{}, security: "admin"},
+	{method: "POST", path: "/admin/keys", tag: "admin", summary: "Create an API key", body: APIKeyRequest{}, status: http.StatusCreated, response: APIKeyResponse{}, security: "admin"},
+	{method: "DELETE", path: "/admin/keys/{id}", tag: "admin", summary: "Revoke an API key", params: []openAPIParam{pathParam("id", "API key ID")}, response: APIKeyResponse{}, security: "admin"},
+	{method: "POST", path: "/admin/reload", tag: "admin", summary: "Reload the configuration", response: ReloadResponse{}, security: "admin"},
+	{method: "GET", path: "/admin/loglevel", tag: "admin", summary: "Current log level", response: LogLevelResponse{}, security: "admin"},
+	{method: "PUT", path: "/admin/loglevel", tag: "admin", summary: "Change the log level", body: LogLevelRequest{}, response: LogLevelResponse{}, security: "admin"},
+}
+
+// Security requirements by operation security. Client credentials are only
+// checked when client authentication is on, so they're optional.
+var openAPISecurity = map[string][]map[string][]string{
+	"client": {{"apiKey": {}}, {"bearerToken": {}}, {}},
+	"admin":  {{"adminToken": {}}},
+}
+
+// openAPISchemas generates JSON schemas for Go types from their json tags,
+// collecting named structs as components
+type openAPISchemas map[string]interface{}
+
+func (s openAPISchemas) schema(t reflect.Type) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return s.schema(t.Elem())
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": s.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": s.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		if _, ok := s[t.Name()]; !ok {
+			s[t.Name()] = nil // placeholder, for types that refer to themselves
+			s[t.Name()] = s.object(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+	}
+	return map[string]interface{}{} // anything
+}
+
+// object returns the schema of a struct's JSON object, with embedded structs'
+// fields inlined like encoding/json does
+func (s openAPISchemas) object(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+	var addFields func(t reflect.Type)
+	addFields = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			tag := field.Tag.Get("json")
+			if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+				continue
+			}
+			name, options, _ := strings.Cut(tag, ",")
+			if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+				addFields(field.Type)
+				continue
+			}
+			name = cmp.Or(name, field.Name)
+			properties[name] = s.schema(field.Type)
+			if !strings.Contains(options, "omitempty") && field.Type.Kind() != reflect.Pointer {
+				required = append(required, name)
+			}
+		}
+	}
+	addFields(t)
+
+	object := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		object["required"] = required
+	}
+	return object
+}
+
+// openAPISpec builds the OpenAPI 3 spec of the documented endpoints, once
+var openAPISpec = sync.OnceValue(func() []byte {
+	schemas := openAPISchemas{}
+	errorResponse := map[string]interface{}{
+		"description": "Error",
+		"content": map[string]interface{}{"application/json": map[string]interface{}{
+			"schema": map[string]interface{}{"$ref": "#/components/schemas/Error"},
+		}},
+	}
+
+	paths := map[string]map[string]interface{}{}
+	for _, op := range openAPIOperations {
+		params := []interface{}{}
+		for _, p := range op.params {
+			params = append(params, map[string]interface{}{"name": p.name, "in": p.in, "description": p.description, "required": p.required, "schema": p.schema})
+		}
+
+		content := map[string]interface{}{}
+		switch {
+		case op.contentType != "":
+			content[op.contentType] = map[string]interface{}{}
+		case op.response != nil:
+			content["application/json"] = map[string]interface{}{"schema": schemas.schema(reflect.TypeOf(op.response))}
+		default:
+			content["application/json"] = map[string]interface{}{"schema": map[string]interface{}{"type": "object"}}
+		}
+		for _, format := range op.formats {
+			switch format {
+			case formatJSON:
+			case formatGeoJSON:
+				content[geoJSONContentType] = map[string]interface{}{"schema": schemas.schema(reflect.TypeOf(GeoJSONFeatureCollection{}))}
+			case formatNDJSON:
+				content[ndjsonContentType] = map[string]interface{}{"schema": schemas.schema(reflect.TypeOf(BatchWeatherStreamResult{}))}
+			default:
+				mediaType, _, _ := mime.ParseMediaType(responseFormats[format].contentType)
+				content[mediaType] = map[string]interface{}{}
+			}
+		}
+
+		status := cmp.Or(op.status, http.StatusOK)
+		operation := map[string]interface{}{
+			"summary":     op.summary,
+			"operationId": strings.ToLower(op.method) + openAPIOperationName(op.path),
+			"tags":        []string{op.tag},
+			"parameters":  params,
+			"responses": map[string]interface{}{
+				strconv.Itoa(status): map[string]interface{}{"description": http.StatusText(status), "content": content},
+				"default":            errorResponse,
+			},
+		}
+		if op.body != nil {
+			operation["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content":  map[string]interface{}{"application/json": map[string]interface{}{"schema": schemas.schema(reflect.TypeOf(op.body))}},
+			}
+		}
+		if op.deprecated {
+			operation["deprecated"] = true
+		}
+		if security, ok := openAPISecurity[op.security]; ok {
+			operation["security"] = security
+		}
+		if paths[op.path] == nil {
+			paths[op.path] = map[string]interface{}{}
+		}
+		paths[op.path][strings.ToLower(op.method)] = operation
+	}
+
+	schemas["Error"] = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"error":    map[string]interface{}{"type": "string"},
+			"code":     map[string]interface{}{"type": "string", "description": "unknown_zip_code for zip codes that don't exist"},
+			"zip_code": map[string]interface{}{"type": "string"},
+		},
+		"required": []string{"error"},
+	}
+	spec := map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "Weather API",
+			"version":     version,
+			"description": "Current weather, forecasts, and history by zip code. Endpoints other than /admin, /api/v2, /ui, /docs, and the embeds are also served under /api/v1.",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"securitySchemes": map[string]interface{}{
+				"apiKey":      map[string]interface{}{"type": "apiKey", "in": "header", "name": apiKeyHeader},
+				"bearerToken": map[string]interface{}{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+				"adminToken":  map[string]interface{}{"type": "http", "scheme": "bearer", "description": "ADMIN_TOKEN, or a JWT granting the admin scope"},
+			},
+		},
+	}
+	data, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	return data
+})
+
+// openAPIOperationName turns a path into an operationId suffix, e.g.
+// "/air-quality" into "AirQuality" and "/badge/{zip}.svg" into "BadgeByZip"
+func openAPIOperationName(path string) string {
+	var b strings.Builder
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		if param, ok := strings.CutPrefix(segment, "{"); ok {
+			param, _, _ = strings.Cut(param, "}")
+			segment = "by-" + param
+		}
+		for _, word := range strings.Split(segment, "-") {
+			b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return b.String()
+}
+
+// OpenAPI spec handler using Chi
+func openAPIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Write(openAPISpec())
+}
+
+// docsHandler serves Swagger UI for the spec at /docs/. Like the dashboard,
+// it's static and served without client authentication.
+func docsHandler() http.Handler {
+	files, err := fs.Sub(docsFiles, "docs")
+	if err != nil {
+		panic(err)
+	}
+	fileServer := http.StripPrefix("/docs", http.FileServerFS(files))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The file server sets each file's type, so drop the JSON default
+		w.Header().Del("Content-Type")
+		// Swagger UI's scripts and styles come from its CDN
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; script-src 'self' "+swaggerUICDN+"; style-src 'self' "+swaggerUICDN+"; img-src * data:; frame-ancestors 'none'")
+		fileServer.ServeHTTP(w, r)
+	})
+}
+
+// Where /docs loads Swagger UI from
+const swaggerUICDN = "https://cdn.jsdelivr.net"
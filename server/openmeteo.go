@@ -0,0 +1,350 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Open-Meteo API base URLs (no API key required)
+const (
+	openMeteoBaseURL          = "https://api.open-meteo.com"
+	openMeteoGeocodingBaseURL = "https://geocoding-api.open-meteo.com"
+)
+
+// Current variables requested from Open-Meteo
+const openMeteoCurrentVariables = "temperature_2m,apparent_temperature,dew_point_2m,relative_humidity_2m," +
+	"weather_code,is_day,pressure_msl,visibility,cloud_cover,wind_speed_10m,wind_direction_10m,wind_gusts_10m"
+
+// Daily variables requested from Open-Meteo
+const openMeteoDailyVariables = "weather_code,temperature_2m_max,temperature_2m_min,precipitation_probability_max"
+
+// Hourly variables requested from Open-Meteo
+const openMeteoHourlyVariables = "temperature_2m,weather_code,is_day,precipitation_probability,precipitation,wind_speed_10m,wind_direction_10m"
+
+// Open-Meteo forecast API response structure (simplified), requested with timeformat=unixtime
+type OpenMeteoAPIResponse struct {
+	UTCOffsetSeconds int `json:"utc_offset_seconds"`
+	Current          struct {
+		Time                int64   `json:"time"`
+		Temperature         float64 `json:"temperature_2m"`
+		ApparentTemperature float64 `json:"apparent_temperature"`
+		DewPoint            float64 `json:"dew_point_2m"`
+		RelativeHumidity    int     `json:"relative_humidity_2m"`
+		WeatherCode         int     `json:"weather_code"`
+		IsDay               int     `json:"is_day"`
+		PressureMSL         float64 `json:"pressure_msl"`
+		Visibility          float64 `json:"visibility"`
+		CloudCover          int     `json:"cloud_cover"`
+		WindSpeed           float64 `json:"wind_speed_10m"`
+		WindDirection       int     `json:"wind_direction_10m"`
+		WindGusts           float64 `json:"wind_gusts_10m"`
+	} `json:"current"`
+	Daily struct {
+		Time                        []int64   `json:"time"`
+		WeatherCode                 []int     `json:"weather_code"`
+		TemperatureMax              []float64 `json:"temperature_2m_max"`
+		TemperatureMin              []float64 `json:"temperature_2m_min"`
+		PrecipitationProbabilityMax []int     `json:"precipitation_probability_max"`
+	} `json:"daily"`
+	Hourly struct {
+		Time                     []int64   `json:"time"`
+		Temperature              []float64 `json:"temperature_2m"`
+		WeatherCode              []int     `json:"weather_code"`
+		IsDay                    []int     `json:"is_day"`
+		PrecipitationProbability []int     `json:"precipitation_probability"`
+		Precipitation            []float64 `json:"precipitation"`
+		WindSpeed                []float64 `json:"wind_speed_10m"`
+		WindDirection            []int     `json:"wind_direction_10m"`
+	} `json:"hourly"`
+}
+
+// Open-Meteo geocoding API response structure (simplified)
+type OpenMeteoGeocodeAPIResponse struct {
+	Results []struct {
+		Name      string  `json:"name"`
+		Latitude  float64 `json:"latitude"`
+		Longitude float64 `json:"longitude"`
+	} `json:"results"`
+}
+
+// weatherCondition is the description and OpenWeatherMap icon code (without
+// the day/night suffix) for a provider's weather code
+type weatherCondition struct {
+	description string
+	icon        string
+}
+
+// WMO weather interpretation codes used by Open-Meteo
+var wmoWeatherCodes = map[int]weatherCondition{
+	0:  {"clear sky", "01"},
+	1:  {"mainly clear", "02"},
+	2:  {"partly cloudy", "03"},
+	3:  {"overcast", "04"},
+	45: {"fog", "50"},
+	48: {"depositing rime fog", "50"},
+	51: {"light drizzle", "09"},
+	53: {"moderate drizzle", "09"},
+	55: {"dense drizzle", "09"},
+	56: {"light freezing drizzle", "09"},
+	57: {"dense freezing drizzle", "09"},
+	61: {"slight rain", "10"},
+	63: {"moderate rain", "10"},
+	65: {"heavy rain", "10"},
+	66: {"light freezing rain", "13"},
+	67: {"heavy freezing rain", "13"},
+	71: {"slight snow fall", "13"},
+	73: {"moderate snow fall", "13"},
+	75: {"heavy snow fall", "13"},
+	77: {"snow grains", "13"},
+	80: {"slight rain showers", "09"},
+	81: {"moderate rain showers", "09"},
+	82: {"violent rain showers", "09"},
+	85: {"slight snow showers", "13"},
+	86: {"heavy snow showers", "13"},
+	95: {"thunderstorm", "11"},
+	96: {"thunderstorm with slight hail", "11"},
+	99: {"thunderstorm with heavy hail", "11"},
+}
+
+// wmoWeather returns the description and OpenWeatherMap icon code for a WMO weather code
+func wmoWeather(code int, isDay bool) (string, string) {
+	weather, ok := wmoWeatherCodes[code]
+	if !ok {
+		return "clear", defaultIcon
+	}
+	if isDay {
+		return weather.description, weather.icon + "d"
+	}
+	return weather.description, weather.icon + "n"
+}
+
+// openMeteoProvider serves current conditions and daily forecasts from the Open-Meteo API
+type openMeteoProvider struct {
+	s *Server
+}
+
+func (p openMeteoProvider) Name() string {
+	return "open-meteo"
+}
+
+func (p openMeteoProvider) Current(ctx context.Context, loc Location, opts Options) (*WeatherResponse, error) {
+	coords, err := p.s.openMeteoCoordinates(ctx, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	params := openMeteoParams(coords, opts)
+	params.Add("current", openMeteoCurrentVariables)
+
+	var apiResp OpenMeteoAPIResponse
+	if err := p.s.fetchOpenMeteo(ctx, params, &apiResp); err != nil {
+		return nil, err
+	}
+	current := apiResp.Current
+
+	// Observation time is UTC; local time uses the location's UTC offset
+	zone := time.FixedZone("local", apiResp.UTCOffsetSeconds)
+
+	description, icon := wmoWeather(current.WeatherCode, current.IsDay == 1)
+	return &WeatherResponse{
+		ZipCode:       loc.ZipCode,
+		Location:      coords.Name,
+		Units:         opts.units(),
+		Temperature:   openMeteoTemperature(current.Temperature, opts.units()),
+		FeelsLike:     openMeteoTemperature(current.ApparentTemperature, opts.units()),
+		DewPoint:      openMeteoTemperature(current.DewPoint, opts.units()),
+		Description:   description,
+		Icon:          icon,
+		IconURL:       p.s.iconURL(icon),
+		Humidity:      current.RelativeHumidity,
+		Pressure:      int(math.Round(current.PressureMSL)),
+		Visibility:    int(math.Round(current.Visibility)),
+		CloudCover:    current.CloudCover,
+		WindSpeed:     current.WindSpeed,
+		WindDirection: current.WindDirection,
+		WindGust:      current.WindGusts,
+		ObservedAt:    time.Unix(current.Time, 0).UTC().Format(time.RFC3339),
+		LocalTime:     time.Now().In(zone).Format(time.RFC3339),
+	}, nil
+}
+
+func (p openMeteoProvider) Forecast(ctx context.Context, loc Location, opts Options) (*ForecastResponse, error) {
+	coords, err := p.s.openMeteoCoordinates(ctx, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	params := openMeteoParams(coords, opts)
+	params.Add("daily", openMeteoDailyVariables)
+	params.Add("forecast_days", strconv.Itoa(forecastDays))
+
+	var apiResp OpenMeteoAPIResponse
+	if err := p.s.fetchOpenMeteo(ctx, params, &apiResp); err != nil {
+		return nil, err
+	}
+	daily := apiResp.Daily
+
+	// Daily times are local midnight
+	zone := time.FixedZone("local", apiResp.UTCOffsetSeconds)
+	days := make([]DailyForecast, 0, len(daily.Time))
+	for i, dt := range daily.Time {
+		if i >= len(daily.WeatherCode) || i >= len(daily.TemperatureMax) || i >= len(daily.TemperatureMin) {
+			break
+		}
+
+		description, icon := wmoWeather(daily.WeatherCode[i], true)
+		day := DailyForecast{
+			Date:        time.Unix(dt, 0).In(zone).Format("2006-01-02"),
+			High:        openMeteoTemperature(daily.TemperatureMax[i], opts.units()),
+			Low:         openMeteoTemperature(daily.TemperatureMin[i], opts.units()),
+			Description: description,
+			Icon:        icon,
+			IconURL:     p.s.iconURL(icon),
+		}
+		if i < len(daily.PrecipitationProbabilityMax) {
+			day.PrecipitationChance = daily.PrecipitationProbabilityMax[i]
+		}
+		days = append(days, day)
+	}
+
+	return &ForecastResponse{
+		ZipCode:  loc.ZipCode,
+		Location: coords.Name,
+		Units:    opts.units(),
+		Days:     days,
+	}, nil
+}
+
+func (p openMeteoProvider) HourlyForecast(ctx context.Context, loc Location, hours int, opts Options) (*HourlyForecastResponse, error) {
+	coords, err := p.s.openMeteoCoordinates(ctx, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	params := openMeteoParams(coords, opts)
+	params.Add("hourly", openMeteoHourlyVariables)
+	params.Add("forecast_hours", strconv.Itoa(hours))
+
+	var apiResp OpenMeteoAPIResponse
+	if err := p.s.fetchOpenMeteo(ctx, params, &apiResp); err != nil {
+		return nil, err
+	}
+	hourly := apiResp.Hourly
+
+	zone := time.FixedZone("local", apiResp.UTCOffsetSeconds)
+	periods := make([]HourlyForecast, 0, len(hourly.Time))
+	for i, dt := range hourly.Time {
+		if i >= len(hourly.Temperature) || i >= len(hourly.WeatherCode) {
+			break
+		}
+
+		isDay := i < len(hourly.IsDay) && hourly.IsDay[i] == 1
+		description, icon := wmoWeather(hourly.WeatherCode[i], isDay)
+		period := HourlyForecast{
+			Time:        time.Unix(dt, 0).In(zone).Format(time.RFC3339),
+			Temperature: openMeteoTemperature(hourly.Temperature[i], opts.units()),
+			Description: description,
+			Icon:        icon,
+			IconURL:     p.s.iconURL(icon),
+		}
+		if i < len(hourly.PrecipitationProbability) {
+			period.PrecipitationChance = hourly.PrecipitationProbability[i]
+		}
+		if i < len(hourly.Precipitation) {
+			period.Precipitation = convertPrecipitation(hourly.Precipitation[i], opts.units())
+		}
+		if i < len(hourly.WindSpeed) && i < len(hourly.WindDirection) {
+			period.WindSpeed = hourly.WindSpeed[i]
+			period.WindDirection = hourly.WindDirection[i]
+		}
+		periods = append(periods, period)
+	}
+
+	return &HourlyForecastResponse{
+		ZipCode:       loc.ZipCode,
+		Location:      coords.Name,
+		Units:         opts.units(),
+		IntervalHours: 1,
+		Hours:         periods,
+	}, nil
+}
+
+// openMeteoParams builds the query shared by current and forecast requests.
+// Temperatures come back in Fahrenheit or Celsius (converted to Kelvin for
+// standard units) and wind speeds in mph or m/s.
+func openMeteoParams(coords *Coordinates, opts Options) url.Values {
+	params := url.Values{}
+	params.Add("latitude", strconv.FormatFloat(coords.Lat, 'f', 4, 64))
+	params.Add("longitude", strconv.FormatFloat(coords.Lon, 'f', 4, 64))
+	params.Add("timezone", "auto")
+	params.Add("timeformat", "unixtime")
+	if opts.units() == unitsImperial {
+		params.Add("temperature_unit", "fahrenheit")
+		params.Add("wind_speed_unit", "mph")
+	} else {
+		params.Add("temperature_unit", "celsius")
+		params.Add("wind_speed_unit", "ms")
+	}
+	return params
+}
+
+// fetchOpenMeteo calls the Open-Meteo forecast API
+func (s *Server) fetchOpenMeteo(ctx context.Context, params url.Values, v interface{}) error {
+	fullURL := fmt.Sprintf("%s/v1/forecast?%s", openMeteoBaseURL, params.Encode())
+	return s.fetchJSON(ctx, fullURL, "Open-Meteo", v)
+}
+
+// openMeteoTemperature converts an Open-Meteo temperature (Fahrenheit for
+// imperial, otherwise Celsius) to the requested unit system
+func openMeteoTemperature(temp float64, units string) float64 {
+	if units == unitsStandard {
+		return round1(temp + 273.15)
+	}
+	return temp
+}
+
+// openMeteoCoordinates resolves a location to coordinates without an API key
+func (s *Server) openMeteoCoordinates(ctx context.Context, loc Location) (*Coordinates, error) {
+	switch {
+	case loc.Coords != nil:
+		coords := *loc.Coords
+		if coords.Name == "" {
+			coords.Name = fmt.Sprintf("%.4f, %.4f", coords.Lat, coords.Lon)
+		}
+		return &coords, nil
+	case loc.ZipCode != "":
+		return s.geocodeZipCodeKeyless(ctx, loc)
+	default:
+		return s.geocodeCityOpenMeteo(ctx, loc)
+	}
+}
+
+// geocodeCityOpenMeteo resolves a "City" or "City,ST" query to coordinates
+// using the Open-Meteo geocoding API, which searches by name within a country
+func (s *Server) geocodeCityOpenMeteo(ctx context.Context, loc Location) (*Coordinates, error) {
+	params := url.Values{}
+	params.Add("name", strings.TrimSpace(strings.Split(loc.City, ",")[0]))
+	params.Add("countryCode", loc.country())
+	params.Add("count", "1")
+
+	var apiResp OpenMeteoGeocodeAPIResponse
+	fullURL := fmt.Sprintf("%s/v1/search?%s", openMeteoGeocodingBaseURL, params.Encode())
+	if err := s.fetchJSON(ctx, fullURL, "geocoding", &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to geocode city: %w", err)
+	}
+
+	if len(apiResp.Results) == 0 {
+		return nil, withKind(ErrNotFound, fmt.Errorf("no location found for city %q", loc.City))
+	}
+
+	return &Coordinates{
+		Name: apiResp.Results[0].Name,
+		Lat:  apiResp.Results[0].Latitude,
+		Lon:  apiResp.Results[0].Longitude,
+	}, nil
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// OpenWeatherMap API base URL used when OPENWEATHER_BASE_URL isn't set.
+// OPENWEATHER_BASE_URL can point at a mirror, proxy, or local test server with
+// the same API paths.
+const defaultOpenWeatherBaseURL = "https://api.openweathermap.org"
+
+// fetchOpenWeather calls an OpenWeatherMap API path and decodes the JSON response into v
+func (s *Server) fetchOpenWeather(ctx context.Context, path string, params url.Values, v interface{}) error {
+	return s.fetchOpenWeatherFrom(ctx, s.config().OpenWeatherBaseURL, path, params, v)
+}
+
+// fetchOpenWeatherFrom calls an OpenWeatherMap API path on the given base URL
+func (s *Server) fetchOpenWeatherFrom(ctx context.Context, baseURL, path string, params url.Values, v interface{}) error {
+	if !s.config().openWeatherMapAvailable() {
+		return withKind(ErrNotImplemented, errors.New("this data comes from OpenWeatherMap, which needs OPENWEATHER_API_KEY to be set"))
+	}
+	fullURL := fmt.Sprintf("%s%s?%s", baseURL, path, params.Encode())
+	return s.fetchJSON(ctx, fullURL, "weather", v)
+}
+
+// OpenWeatherMap API response structure (simplified)
+type OpenWeatherAPIResponse struct {
+	Name string `json:"name"`
+	Main struct {
+		Temp      float64 `json:"temp"`
+		FeelsLike float64 `json:"feels_like"`
+		Humidity  int     `json:"humidity"`
+		Pressure  int     `json:"pressure"`
+	} `json:"main"`
+	Weather []struct {
+		Description string `json:"description"`
+		Icon        string `json:"icon"`
+	} `json:"weather"`
+	Visibility int `json:"visibility"`
+	Clouds     struct {
+		All int `json:"all"`
+	} `json:"clouds"`
+	Wind struct {
+		Speed float64 `json:"speed"`
+		Deg   int     `json:"deg"`
+		Gust  float64 `json:"gust"`
+	} `json:"wind"`
+	Sys struct {
+		Sunrise int64 `json:"sunrise"`
+		Sunset  int64 `json:"sunset"`
+	} `json:"sys"`
+	Dt       int64 `json:"dt"`
+	Timezone int   `json:"timezone"`
+}
+
+// fetchCurrentWeather retrieves the raw current conditions for a location from
+// the OpenWeatherMap API at baseURL
+func (s *Server) fetchCurrentWeather(ctx context.Context, baseURL string, loc Location, opts Options, apiKey string) (*OpenWeatherAPIResponse, error) {
+	// Build API request from the zip code or geocoded city
+	params, err := s.locationParams(ctx, loc, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	params.Add("appid", apiKey)
+	params.Add("units", opts.units())
+	params.Add("lang", opts.lang())
+
+	var apiResp OpenWeatherAPIResponse
+	if err := s.fetchOpenWeatherFrom(ctx, baseURL, "/data/2.5/weather", params, &apiResp); err != nil {
+		return nil, err
+	}
+	return &apiResp, nil
+}
+
+// openWeatherMapProvider serves current conditions from the OpenWeatherMap API
+type openWeatherMapProvider struct {
+	s       *Server
+	apiKey  string
+	baseURL string
+}
+
+func (p openWeatherMapProvider) Name() string {
+	return "openweathermap"
+}
+
+func (p openWeatherMapProvider) Current(ctx context.Context, loc Location, opts Options) (*WeatherResponse, error) {
+	apiResp, err := p.s.fetchCurrentWeather(ctx, p.baseURL, loc, opts, p.apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert to our response format
+	description, icon := "clear", defaultIcon
+	if len(apiResp.Weather) > 0 {
+		description = apiResp.Weather[0].Description
+		icon = apiResp.Weather[0].Icon
+	}
+
+	// Observation time is UTC; local time uses the location's UTC offset
+	zone := time.FixedZone("local", apiResp.Timezone)
+
+	return &WeatherResponse{
+		ZipCode:       loc.ZipCode,
+		Location:      apiResp.Name,
+		Units:         opts.units(),
+		Temperature:   apiResp.Main.Temp,
+		FeelsLike:     apiResp.Main.FeelsLike,
+		DewPoint:      dewPoint(apiResp.Main.Temp, apiResp.Main.Humidity, opts.units()),
+		Description:   description,
+		Icon:          icon,
+		IconURL:       p.s.iconURL(icon),
+		Humidity:      apiResp.Main.Humidity,
+		Pressure:      apiResp.Main.Pressure,
+		Visibility:    apiResp.Visibility,
+		CloudCover:    apiResp.Clouds.All,
+		WindSpeed:     apiResp.Wind.Speed,
+		WindDirection: apiResp.Wind.Deg,
+		WindGust:      apiResp.Wind.Gust,
+		ObservedAt:    time.Unix(apiResp.Dt, 0).UTC().Format(time.RFC3339),
+		LocalTime:     time.Now().In(zone).Format(time.RFC3339),
+	}, nil
+}
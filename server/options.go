@@ -0,0 +1,294 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Unit systems supported by OpenWeatherMap
+const (
+	unitsImperial = "imperial" // Fahrenheit, mph, inches
+	unitsMetric   = "metric"   // Celsius, m/s, mm
+	unitsStandard = "standard" // Kelvin, m/s, mm
+)
+
+// Millimeters per inch, for converting upstream precipitation amounts
+const mmPerInch = 25.4
+
+// Language used when none is requested
+const defaultLang = "en"
+
+// Language codes supported by OpenWeatherMap for weather descriptions
+var supportedLangs = map[string]bool{
+	"af": true, "al": true, "ar": true, "az": true, "bg": true, "ca": true, "cz": true,
+	"da": true, "de": true, "el": true, "en": true, "es": true, "eu": true, "fa": true,
+	"fi": true, "fr": true, "gl": true, "he": true, "hi": true, "hr": true, "hu": true,
+	"id": true, "it": true, "ja": true, "kr": true, "la": true, "lt": true, "mk": true,
+	"nl": true, "no": true, "pl": true, "pt": true, "pt_br": true, "ro": true, "ru": true,
+	"sk": true, "sl": true, "sr": true, "sv": true, "th": true, "tr": true, "uk": true,
+	"vi": true, "zh_cn": true, "zh_tw": true, "zu": true,
+}
+
+// Standard language tags that OpenWeatherMap spells differently
+var langAliases = map[string]string{
+	"cs": "cz",
+	"ko": "kr",
+	"lv": "la",
+	"nb": "no",
+	"sq": "al",
+	"zh": "zh_cn",
+}
+
+// Options holds per-request settings that are passed through to upstream APIs
+type Options struct {
+	Units    string
+	Lang     string
+	Provider string
+}
+
+// units returns the requested unit system, defaulting to imperial
+func (o Options) units() string {
+	if o.Units == "" {
+		return unitsImperial
+	}
+	return o.Units
+}
+
+// lang returns the requested description language, defaulting to English
+func (o Options) lang() string {
+	if o.Lang == "" {
+		return defaultLang
+	}
+	return o.Lang
+}
+
+// provider returns the requested weather provider, defaulting to c's WEATHER_PROVIDER
+func (o Options) provider(c *Config) string {
+	if o.Provider == "" {
+		return c.WeatherProvider
+	}
+	return o.Provider
+}
+
+// normalizeLang converts a language tag (e.g. "pt-BR") into an OpenWeatherMap
+// language code (e.g. "pt_br"), returning "" if the language isn't supported
+func normalizeLang(tag string) string {
+	code := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(tag)), "-", "_")
+	for _, candidate := range []string{code, strings.SplitN(code, "_", 2)[0]} {
+		if alias, ok := langAliases[candidate]; ok {
+			candidate = alias
+		}
+		if supportedLangs[candidate] {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// langFromAcceptLanguage picks the most preferred supported language from an
+// Accept-Language header, returning "" if none is supported
+func langFromAcceptLanguage(header string) string {
+	type preference struct {
+		lang    string
+		quality float64
+	}
+
+	var preferences []preference
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				quality = parsed
+			}
+		}
+
+		if lang := normalizeLang(tag); lang != "" && quality > 0 {
+			preferences = append(preferences, preference{lang, quality})
+		}
+	}
+
+	if len(preferences) == 0 {
+		return ""
+	}
+	sort.SliceStable(preferences, func(i, j int) bool {
+		return preferences[i].quality > preferences[j].quality
+	})
+	return preferences[0].lang
+}
+
+// Errors for unsupported units and languages
+var (
+	errInvalidUnits = errors.New("units must be one of: metric, imperial, standard")
+	errInvalidLang  = errors.New("lang must be a language supported by OpenWeatherMap (e.g. en, es, fr, pt_br)")
+)
+
+// validateUnits checks that a unit system is supported
+func validateUnits(units string) error {
+	switch units {
+	case unitsImperial, unitsMetric, unitsStandard:
+		return nil
+	}
+	return errInvalidUnits
+}
+
+// validateProvider checks that a weather provider exists and is enabled
+func (s *Server) validateProvider(provider string) error {
+	if !slices.Contains(providerNames, provider) {
+		return errors.New("provider must be one of: " + strings.Join(providerNames, ", "))
+	}
+	if !s.providerEnabled(provider) {
+		return fmt.Errorf("provider %q is not enabled (enabled: %s)", provider, strings.Join(s.enabledProviders(), ", "))
+	}
+	return nil
+}
+
+// optionsFromRequest reads and validates the optional units, lang, and provider
+// query parameters. Parameters that aren't given come from the caller's saved
+// preferences, then the Accept-Language header for the language, then the defaults.
+// On failure it writes a 400 response and returns false.
+func (s *Server) optionsFromRequest(w http.ResponseWriter, r *http.Request) (Options, bool) {
+	query := r.URL.Query()
+	prefs := s.requestPreferences(r)
+
+	// Set when a saved preference is used, since the response then depends on the caller
+	personalized := false
+
+	units := cmp.Or(query.Get("units"), prefs.Units, unitsImperial)
+	personalized = query.Get("units") == "" && prefs.Units != ""
+	if err := validateUnits(units); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return Options{}, false
+	}
+
+	lang := defaultLang
+	if value := query.Get("lang"); value != "" {
+		if lang = normalizeLang(value); lang == "" {
+			writeError(w, http.StatusBadRequest, errInvalidLang.Error())
+			return Options{}, false
+		}
+	} else if prefs.Lang != "" {
+		lang, personalized = prefs.Lang, true
+	} else if preferred := langFromAcceptLanguage(r.Header.Get("Accept-Language")); preferred != "" {
+		lang = preferred
+	}
+
+	provider := query.Get("provider")
+	if provider != "" {
+		if err := s.validateProvider(provider); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return Options{}, false
+		}
+	} else if prefs.Provider != "" && s.validateProvider(prefs.Provider) == nil {
+		// A saved provider that has since been disabled falls back to the default
+		provider, personalized = prefs.Provider, true
+	}
+
+	if cacheControl := w.Header().Get("Cache-Control"); personalized && strings.HasPrefix(cacheControl, "public") {
+		// Shared caches mustn't store a response made for one caller
+		w.Header().Set("Cache-Control", "private"+strings.TrimPrefix(cacheControl, "public"))
+	}
+
+	return Options{Units: units, Lang: lang, Provider: provider}, true
+}
+
+// optionsFromArguments validates units, language, and provider given other
+// than as query parameters, like optionsFromRequest. Empty ones fall back to
+// the saved preferences of the client ctx is authenticated as, then the defaults.
+func (s *Server) optionsFromArguments(ctx context.Context, units, lang, provider string) (Options, error) {
+	prefs := s.clientPreferences(ctx)
+
+	units = cmp.Or(units, prefs.Units, unitsImperial)
+	if err := validateUnits(units); err != nil {
+		return Options{}, err
+	}
+
+	if lang == "" {
+		lang = cmp.Or(prefs.Lang, defaultLang)
+	} else if lang = normalizeLang(lang); lang == "" {
+		return Options{}, errInvalidLang
+	}
+
+	if provider != "" {
+		if err := s.validateProvider(provider); err != nil {
+			return Options{}, err
+		}
+	} else if prefs.Provider != "" && s.validateProvider(prefs.Provider) == nil {
+		provider = prefs.Provider
+	}
+
+	return Options{Units: units, Lang: lang, Provider: provider}, nil
+}
+
+// convertTemperature converts a Fahrenheit temperature to the requested unit system.
+// Used for demo data, which is defined in imperial units.
+func convertTemperature(fahrenheit float64, units string) float64 {
+	switch units {
+	case unitsMetric:
+		return round1((fahrenheit - 32) * 5 / 9)
+	case unitsStandard:
+		return round1((fahrenheit-32)*5/9 + 273.15)
+	default:
+		return fahrenheit
+	}
+}
+
+// convertSpeed converts a speed in mph to the requested unit system (m/s outside imperial).
+// Used for demo data, which is defined in imperial units.
+func convertSpeed(mph float64, units string) float64 {
+	if units == unitsImperial {
+		return mph
+	}
+	return round1(mph * 0.44704)
+}
+
+// convertPrecipitation converts an upstream amount in millimeters to the requested unit system
+func convertPrecipitation(mm float64, units string) float64 {
+	if units == unitsImperial {
+		return math.Round(mm/mmPerInch*100) / 100
+	}
+	return math.Round(mm*10) / 10
+}
+
+// dewPoint estimates the dew point from temperature and relative humidity using the
+// Magnus formula. The current conditions API doesn't report it, so it's derived here.
+func dewPoint(temperature float64, humidity int, units string) float64 {
+	if humidity <= 0 {
+		return 0
+	}
+
+	// Work in Celsius and convert back to the requested units
+	celsius := temperature
+	switch units {
+	case unitsImperial:
+		celsius = (temperature - 32) * 5 / 9
+	case unitsStandard:
+		celsius = temperature - 273.15
+	}
+
+	const b, c = 17.62, 243.12
+	gamma := math.Log(float64(humidity)/100) + b*celsius/(c+celsius)
+	dew := c * gamma / (b - gamma)
+
+	switch units {
+	case unitsImperial:
+		return round1(dew*9/5 + 32)
+	case unitsStandard:
+		return round1(dew + 273.15)
+	default:
+		return round1(dew)
+	}
+}
+
+// round1 rounds to one decimal place
+func round1(value float64) float64 {
+	return math.Round(value*10) / 10
+}
@@ -0,0 +1,366 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pressly/goose/v3"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // registers the "pgx" database/sql driver
+)
+
+// Connection pool defaults for the postgres store
+const (
+	defaultDatabaseMaxConns        = 10
+	defaultDatabaseMaxIdleConns    = 2
+	defaultDatabaseConnMaxLifetime = 30 * time.Minute
+)
+
+// How often servers sharing a PostgreSQL store reread the API keys it keeps,
+// to pick up keys created or revoked through another server
+const storedAPIKeysRefreshInterval = time.Minute
+
+// sqlQuerier runs queries on a connection pool or in a transaction
+type sqlQuerier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// postgresStore keeps client data, API keys, usage, and observations in a
+// PostgreSQL database that several servers can share
+type postgresStore struct {
+	db *sql.DB
+}
+
+// openPostgresStore connects to the database at DATABASE_URL
+func openPostgresStore(c *Config) (*postgresStore, error) {
+	db, err := sql.Open("pgx", c.DatabaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open DATABASE_URL: %v", err)
+	}
+	db.SetMaxOpenConns(c.DatabaseMaxConns)
+	db.SetMaxIdleConns(min(c.DatabaseMaxIdleConns, c.DatabaseMaxConns))
+	db.SetConnMaxLifetime(c.DatabaseConnMaxLifetime)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to DATABASE_URL: %v", err)
+	}
+	return &postgresStore{db: db}, nil
+}
+
+// databaseDescription names the database a DATABASE_URL connects to, without
+// its password
+func databaseDescription(databaseURL string) string {
+	config, err := pgconn.ParseConfig(databaseURL)
+	if err != nil {
+		return "(invalid)"
+	}
+	return fmt.Sprintf("%s@%s/%s", config.User, net.JoinHostPort(config.Host, strconv.Itoa(int(config.Port))), config.Database)
+}
+
+func (s *postgresStore) migrator() (*goose.Provider, error) {
+	return newMigrator(goose.DialectPostgres, s.db, "postgres")
+}
+
+func (s *postgresStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *postgresStore) SavedLocations(client string) ([]SavedLocation, error) {
+	rows, err := s.db.Query(`SELECT zip_code, name FROM saved_locations WHERE client = $1 ORDER BY id`, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read saved locations: %v", err)
+	}
+	defer rows.Close()
+
+	var locations []SavedLocation
+	for rows.Next() {
+		var loc SavedLocation
+		if err := rows.Scan(&loc.ZipCode, &loc.Name); err != nil {
+			return nil, fmt.Errorf("failed to read saved locations: %v", err)
+		}
+		locations = append(locations, loc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read saved locations: %v", err)
+	}
+	return locations, nil
+}
+
+func (s *postgresStore) SaveLocation(client string, loc SavedLocation) (bool, error) {
+	// xmax is 0 for a row this statement inserted rather than updated
+	var created bool
+	err := s.db.QueryRow(`INSERT INTO saved_locations (client, zip_code, name) VALUES ($1, $2, $3)
+		ON CONFLICT (client, zip_code) DO UPDATE SET name = excluded.name
+		RETURNING xmax = 0`,
+		client, loc.ZipCode, loc.Name).Scan(&created)
+	if err != nil {
+		return false, fmt.Errorf("failed to save location: %v", err)
+	}
+	return created, nil
+}
+
+func (s *postgresStore) DeleteSavedLocation(client, zipCode string) (*SavedLocation, error) {
+	deleted := SavedLocation{ZipCode: zipCode}
+	err := s.db.QueryRow(`DELETE FROM saved_locations WHERE client = $1 AND zip_code = $2 RETURNING name`, client, zipCode).Scan(&deleted.Name)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to delete saved location: %v", err)
+	}
+	return &deleted, nil
+}
+
+func (s *postgresStore) Preferences(client string) (Preferences, error) {
+	var prefs Preferences
+	err := s.db.QueryRow(`SELECT units, lang, provider FROM preferences WHERE client = $1`, client).Scan(&prefs.Units, &prefs.Lang, &prefs.Provider)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return Preferences{}, nil
+	case err != nil:
+		return Preferences{}, fmt.Errorf("failed to read preferences: %v", err)
+	}
+	return prefs, nil
+}
+
+func (s *postgresStore) SetPreferences(client string, prefs Preferences) error {
+	_, err := s.db.Exec(`INSERT INTO preferences (client, units, lang, provider) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (client) DO UPDATE SET units = excluded.units, lang = excluded.lang, provider = excluded.provider`,
+		client, prefs.Units, prefs.Lang, prefs.Provider)
+	if err != nil {
+		return fmt.Errorf("failed to save preferences: %v", err)
+	}
+	return nil
+}
+
+// readSubscriptions runs a query for subscriptionColumns and scans the rows
+func (s *postgresStore) readSubscriptions(query string, args ...any) ([]Subscription, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read subscriptions: %v", err)
+	}
+	defer rows.Close()
+
+	var subs []Subscription
+	for rows.Next() {
+		var sub Subscription
+		var createdAt time.Time
+		var lastAlertAt sql.NullTime
+		if err := rows.Scan(&sub.ID, &sub.Client, &sub.ZipCode, &sub.SavedLocations, &sub.Parent, &sub.Condition, &sub.Units, &sub.Channel, &sub.CallbackURL, &sub.Email, &sub.Secret, &createdAt, &sub.Triggered, &lastAlertAt); err != nil {
+			return nil, fmt.Errorf("failed to read subscriptions: %v", err)
+		}
+		sub.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+		if lastAlertAt.Valid {
+			sub.LastAlertAt = lastAlertAt.Time.UTC().Format(time.RFC3339)
+		}
+		subs = append(subs, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read subscriptions: %v", err)
+	}
+	return subs, nil
+}
+
+func (s *postgresStore) Subscriptions(client string) ([]Subscription, error) {
+	return s.readSubscriptions(`SELECT `+subscriptionColumns+` FROM subscriptions WHERE client = $1 ORDER BY created_at, id`, client)
+}
+
+func (s *postgresStore) AllSubscriptions() ([]Subscription, error) {
+	return s.readSubscriptions(`SELECT ` + subscriptionColumns + ` FROM subscriptions`)
+}
+
+func (s *postgresStore) AddSubscription(client string, sub Subscription) error {
+	createdAt, err := time.Parse(time.RFC3339, sub.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save subscription: invalid created_at %q", sub.CreatedAt)
+	}
+	_, err = s.db.Exec(`INSERT INTO subscriptions (id, client, zip_code, saved_locations, parent_id, condition, units, channel, callback_url, email, secret, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+		sub.ID, client, sub.ZipCode, sub.SavedLocations, sub.Parent, sub.Condition, sub.Units, sub.Channel, sub.CallbackURL, sub.Email, sub.Secret, createdAt)
+	if err != nil {
+		return fmt.Errorf("failed to save subscription: %v", err)
+	}
+	return nil
+}
+
+func (s *postgresStore) DeleteSubscription(client, id string) (*Subscription, error) {
+	subs, err := s.readSubscriptions(`DELETE FROM subscriptions WHERE client = $1 AND id = $2 RETURNING `+subscriptionColumns, client, id)
+	if err != nil || len(subs) == 0 {
+		return nil, err
+	}
+	return &subs[0], nil
+}
+
+func (s *postgresStore) SetSubscriptionTriggered(id string, triggered bool, at time.Time) (bool, error) {
+	result, err := s.db.Exec(`UPDATE subscriptions SET triggered = $1, last_alert_at = $2 WHERE id = $3 AND triggered != $1`, triggered, at, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to update subscription: %v", err)
+	}
+	updated, _ := result.RowsAffected()
+	return updated > 0, nil
+}
+
+func (s *postgresStore) APIKeys() ([]apiKeyEntry, error) {
+	rows, err := s.db.Query(`SELECT sha256, name, tier FROM api_keys ORDER BY created_at`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API keys: %v", err)
+	}
+	defer rows.Close()
+
+	var entries []apiKeyEntry
+	for rows.Next() {
+		var entry apiKeyEntry
+		if err := rows.Scan(&entry.SHA256, &entry.Name, &entry.Tier); err != nil {
+			return nil, fmt.Errorf("failed to read API keys: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read API keys: %v", err)
+	}
+	return entries, nil
+}
+
+func (s *postgresStore) AddAPIKey(entry apiKeyEntry) error {
+	if _, err := s.db.Exec(`INSERT INTO api_keys (sha256, name, tier) VALUES ($1, $2, $3)`, entry.hash(), entry.Name, entry.Tier); err != nil {
+		return fmt.Errorf("failed to save API key: %v", err)
+	}
+	return nil
+}
+
+func (s *postgresStore) DeleteAPIKey(hash string) (bool, error) {
+	result, err := s.db.Exec(`DELETE FROM api_keys WHERE sha256 = $1`, hash)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete API key: %v", err)
+	}
+	deleted, _ := result.RowsAffected()
+	return deleted > 0, nil
+}
+
+func (s *postgresStore) LoadUsage() (map[string]map[string]*clientUsage, error) {
+	return s.loadUsage(s.db)
+}
+
+// loadUsage reads the saved usage over the store's pool or a transaction
+func (s *postgresStore) loadUsage(db sqlQuerier) (map[string]map[string]*clientUsage, error) {
+	rows, err := db.Query(`SELECT day, client, tier, requests FROM usage`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage: %v", err)
+	}
+	defer rows.Close()
+
+	days := map[string]map[string]*clientUsage{}
+	for rows.Next() {
+		var day, client string
+		var usage clientUsage
+		if err := rows.Scan(&day, &client, &usage.Tier, &usage.Requests); err != nil {
+			return nil, fmt.Errorf("failed to read usage: %v", err)
+		}
+		if days[day] == nil {
+			days[day] = map[string]*clientUsage{}
+		}
+		days[day][client] = &usage
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read usage: %v", err)
+	}
+	return days, nil
+}
+
+// SaveUsage isn't used for a shared store, since it would replace the
+// requests other servers saved; see AddUsage
+func (s *postgresStore) SaveUsage(days map[string]map[string]*clientUsage, oldest string) error {
+	return errors.New("usage must be added to a shared store")
+}
+
+func (s *postgresStore) AddUsage(requests map[string]map[string]*clientUsage, oldest string) (map[string]map[string]*clientUsage, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to save usage: %v", err)
+	}
+	defer tx.Rollback()
+
+	for day, clients := range requests {
+		for client, usage := range clients {
+			_, err := tx.Exec(`INSERT INTO usage (day, client, tier, requests) VALUES ($1, $2, $3, $4)
+				ON CONFLICT (day, client) DO UPDATE SET tier = excluded.tier, requests = usage.requests + excluded.requests`,
+				day, client, usage.Tier, usage.Requests)
+			if err != nil {
+				return nil, fmt.Errorf("failed to save usage: %v", err)
+			}
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM usage WHERE day < $1`, oldest); err != nil {
+		return nil, fmt.Errorf("failed to save usage: %v", err)
+	}
+	days, err := s.loadUsage(tx)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to save usage: %v", err)
+	}
+	return days, nil
+}
+
+func (s *postgresStore) RecordObservation(zipCode, country string, weather WeatherResponse) error {
+	observedAt, err := time.Parse(time.RFC3339, weather.ObservedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record observation: invalid observed_at %q", weather.ObservedAt)
+	}
+	data, err := json.Marshal(weather)
+	if err != nil {
+		return fmt.Errorf("failed to encode observation: %v", err)
+	}
+	_, err = s.db.Exec(`INSERT INTO observations (zip_code, country, observed_at, source, units, weather) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT DO NOTHING`,
+		zipCode, country, observedAt, weather.Source, weather.Units, string(data))
+	if err != nil {
+		return fmt.Errorf("failed to record observation: %v", err)
+	}
+	return nil
+}
+
+func (s *postgresStore) Observations(zipCode, country string, from, to time.Time) ([]WeatherResponse, error) {
+	rows, err := s.db.Query(`SELECT weather FROM observations
+		WHERE zip_code = $1 AND country = $2 AND observed_at >= $3 AND observed_at < $4
+		ORDER BY observed_at, source, units`,
+		zipCode, country, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read observations: %v", err)
+	}
+	defer rows.Close()
+
+	var observations []WeatherResponse
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read observations: %v", err)
+		}
+		var weather WeatherResponse
+		if err := json.Unmarshal(data, &weather); err != nil {
+			return nil, fmt.Errorf("failed to decode observation: %v", err)
+		}
+		observations = append(observations, weather)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read observations: %v", err)
+	}
+	return observations, nil
+}
+
+func (s *postgresStore) PruneObservations(before time.Time) error {
+	if _, err := s.db.Exec(`DELETE FROM observations WHERE observed_at < $1`, before); err != nil {
+		return fmt.Errorf("failed to prune observations: %v", err)
+	}
+	return nil
+}
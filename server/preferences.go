@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// requestPreferences returns the saved preferences of the client a request was
+// authenticated as, or none for anonymous requests
+func (s *Server) requestPreferences(r *http.Request) Preferences {
+	return s.clientPreferences(r.Context())
+}
+
+// clientPreferences returns the saved preferences of the client a request's
+// context is authenticated as, or none for anonymous requests
+func (s *Server) clientPreferences(ctx context.Context) Preferences {
+	client, ok := requestClient(ctx)
+	if !ok {
+		return Preferences{}
+	}
+	prefs, err := s.dataStore.Preferences(client.Name)
+	if err != nil {
+		warnf("Failed to read preferences of %s, using the defaults: %v", client.Name, err)
+		return Preferences{}
+	}
+	return prefs
+}
+
+// Preferences handler using Chi
+func (s *Server) preferencesHandler(w http.ResponseWriter, r *http.Request) {
+	client, ok := requestClient(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "preferences are only kept for requests with an API key or bearer token")
+		return
+	}
+	prefs, err := s.dataStore.Preferences(client.Name)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(prefs)
+}
+
+// Preferences update handler using Chi. The body replaces every preference,
+// so fields left out go back to the server defaults.
+func (s *Server) setPreferencesHandler(w http.ResponseWriter, r *http.Request) {
+	client, ok := requestClient(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "preferences are only kept for requests with an API key or bearer token")
+		return
+	}
+
+	var prefs Preferences
+	r.Body = http.MaxBytesReader(w, r.Body, 1024)
+	if err := json.NewDecoder(r.Body).Decode(&prefs); err != nil {
+		writeError(w, http.StatusBadRequest, `request body must be a JSON object such as {"units": "metric", "lang": "de", "provider": "openmeteo"}`)
+		return
+	}
+	if prefs.Units != "" {
+		if err := validateUnits(prefs.Units); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if prefs.Lang != "" {
+		lang := normalizeLang(prefs.Lang)
+		if lang == "" {
+			writeError(w, http.StatusBadRequest, errInvalidLang.Error())
+			return
+		}
+		prefs.Lang = lang
+	}
+	if prefs.Provider != "" {
+		if err := s.validateProvider(prefs.Provider); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	if err := s.dataStore.SetPreferences(client.Name, prefs); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(prefs)
+}
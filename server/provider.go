@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// WeatherProvider is an upstream source of current conditions
+type WeatherProvider interface {
+	// Name identifies the provider, e.g. "openweathermap"
+	Name() string
+	Current(ctx context.Context, loc Location, opts Options) (*WeatherResponse, error)
+}
+
+// ForecastProvider is implemented by providers that also supply daily forecasts.
+// Forecasts for other providers come from OpenWeatherMap.
+type ForecastProvider interface {
+	Forecast(ctx context.Context, loc Location, opts Options) (*ForecastResponse, error)
+}
+
+// HourlyForecastProvider is implemented by providers that also supply hourly
+// forecasts. Hourly forecasts aren't available from other providers.
+type HourlyForecastProvider interface {
+	HourlyForecast(ctx context.Context, loc Location, hours int, opts Options) (*HourlyForecastResponse, error)
+}
+
+// Names accepted by WEATHER_PROVIDER and the provider query parameter
+var providerNames = []string{"openweathermap", "nws", "open-meteo", "weatherapi", "tomorrow"}
+
+// weatherProvider returns the named provider (default: OpenWeatherMap).
+// Every provider serves demo data in mock mode.
+func (s *Server) weatherProvider(name string) (WeatherProvider, error) {
+	c := s.config()
+	if err := c.checkProvider(name); err != nil {
+		return nil, err
+	}
+	if c.MockMode {
+		return mockProvider{s}, nil
+	}
+
+	switch name {
+	case "nws":
+		return nwsProvider{s}, nil
+	case "open-meteo":
+		return openMeteoProvider{s}, nil
+	case "weatherapi":
+		return weatherAPIProvider{s: s, apiKey: c.WeatherAPIKey}, nil
+	case "tomorrow":
+		return tomorrowProvider{s: s, apiKey: c.TomorrowAPIKey}, nil
+	default:
+		return openWeatherMapProvider{s: s, apiKey: c.OpenWeatherAPIKey, baseURL: c.OpenWeatherBaseURL}, nil
+	}
+}
+
+// checkProvider reports why the named provider can't be used, if it can't:
+// it must be known and, outside mock mode, have its API key configured
+func (c *Config) checkProvider(name string) error {
+	switch {
+	case name != "" && !slices.Contains(providerNames, name):
+		return fmt.Errorf("provider must be one of: %s", strings.Join(providerNames, ", "))
+	case c.MockMode:
+		return nil
+	case name == "weatherapi" && c.WeatherAPIKey == "":
+		return fmt.Errorf("the weatherapi provider requires WEATHERAPI_KEY to be set")
+	case name == "tomorrow" && c.TomorrowAPIKey == "":
+		return fmt.Errorf("the tomorrow provider requires TOMORROW_API_KEY to be set")
+	}
+	return nil
+}
+
+// openWeatherMapAvailable reports whether OpenWeatherMap can be called: it
+// needs OPENWEATHER_API_KEY, except for demo data and recorded responses
+func (c *Config) openWeatherMapAvailable() bool {
+	return c.MockMode || c.UpstreamMode == "replay" || c.OpenWeatherAPIKey != ""
+}
+
+// usesOpenWeatherMap reports whether OpenWeatherMap is the default provider or
+// one of its fallbacks
+func (c *Config) usesOpenWeatherMap() bool {
+	return c.WeatherProvider == "" || c.WeatherProvider == "openweathermap" ||
+		slices.Contains(c.ProviderFallbacks, "openweathermap")
+}
+
+// providerEnabled reports whether clients may select a provider with the provider
+// parameter: it must be listed in ENABLED_PROVIDERS (default: all) and configured
+func (s *Server) providerEnabled(name string) bool {
+	c := s.config()
+	if err := c.checkProvider(name); err != nil {
+		return false
+	}
+	if name == "openweathermap" && !c.openWeatherMapAvailable() {
+		return false
+	}
+	return c.EnabledProviders == nil || slices.Contains(c.EnabledProviders, name)
+}
+
+// enabledProviders returns the names of the providers clients may select
+func (s *Server) enabledProviders() []string {
+	var names []string
+	for _, name := range providerNames {
+		if s.providerEnabled(name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+// realIP sets RemoteAddr to the client address forwarded by a TRUSTED_PROXIES
+// proxy. Forwarding headers on requests from anywhere else are ignored, so
+// callers can't choose the address that rate limits and geolocation see.
+func (s *Server) realIP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ip, ok := forwardedIP(r, s.config().TrustedProxies); ok {
+			r.RemoteAddr = ip.String()
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// forwardedIP returns the client address of a request relayed by trusted
+// proxies. Each proxy appends the address it got the request from to
+// X-Forwarded-For, so the client is the last address that isn't a trusted
+// proxy's own; anything before it was sent by the client. X-Real-IP is used
+// when there's no X-Forwarded-For.
+func forwardedIP(r *http.Request, trusted []netip.Prefix) (netip.Addr, bool) {
+	isTrusted := func(addr netip.Addr) bool {
+		return slices.ContainsFunc(trusted, func(network netip.Prefix) bool {
+			return network.Contains(addr.Unmap())
+		})
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	remote, err := netip.ParseAddr(host)
+	if err != nil || !isTrusted(remote) {
+		return netip.Addr{}, false
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	if len(hops) == 0 {
+		addr, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP")))
+		return addr.Unmap(), err == nil
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			return netip.Addr{}, false
+		}
+		if !isTrusted(addr) || i == 0 {
+			return addr.Unmap(), true
+		}
+	}
+	return netip.Addr{}, false
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestForwardedIP(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	for _, test := range []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string // empty when RemoteAddr should be kept
+	}{
+		{"untrusted X-Real-IP", "203.0.113.7:4000", map[string]string{"X-Real-IP": "10.9.9.9"}, ""},
+		{"untrusted X-Forwarded-For", "203.0.113.7:4000", map[string]string{"X-Forwarded-For": "198.51.100.1"}, ""},
+		{"trusted X-Real-IP", "10.0.0.2:4000", map[string]string{"X-Real-IP": "198.51.100.1"}, "198.51.100.1"},
+		{"trusted X-Forwarded-For", "10.0.0.2:4000", map[string]string{"X-Forwarded-For": "198.51.100.1"}, "198.51.100.1"},
+		{"spoofed X-Forwarded-For prefix", "10.0.0.2:4000", map[string]string{"X-Forwarded-For": "10.9.9.9, 198.51.100.1, 10.0.0.3"}, "198.51.100.1"},
+		{"all trusted hops", "10.0.0.2:4000", map[string]string{"X-Forwarded-For": "10.0.0.4, 10.0.0.3"}, "10.0.0.4"},
+		{"invalid X-Forwarded-For", "10.0.0.2:4000", map[string]string{"X-Forwarded-For": "unknown"}, ""},
+		{"no headers", "10.0.0.2:4000", nil, ""},
+	} {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = test.remoteAddr
+		for name, value := range test.headers {
+			r.Header.Set(name, value)
+		}
+		ip, ok := forwardedIP(r, trusted)
+		if got := ip.String(); ok != (test.want != "") || (ok && got != test.want) {
+			t.Errorf("%s: forwardedIP = %s, %t; want %q", test.name, got, ok, test.want)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// quotaTracker counts upstream calls per provider for the current UTC day
+type quotaTracker struct {
+	mu    sync.Mutex
+	day   string
+	calls map[string]int64
+}
+
+// quotaBudgetEnvVar returns the environment variable holding a provider's daily
+// call budget, e.g. DAILY_BUDGET_OPENWEATHERMAP or DAILY_BUDGET_OPEN_METEO
+func quotaBudgetEnvVar(provider string) string {
+	return "DAILY_BUDGET_" + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(provider))
+}
+
+// quotaBudget returns a provider's daily call budget, or 0 when it has none
+func (s *Server) quotaBudget(provider string) int64 {
+	return s.config().DailyBudgets[provider]
+}
+
+// today returns the current UTC date, which quotas reset on
+func today() string {
+	return time.Now().UTC().Format("2006-01-02")
+}
+
+// take records a call to a provider, refusing it once the provider's daily
+// budget (0 for none) has been spent
+func (q *quotaTracker) take(provider string, budget int64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if day := today(); q.day != day {
+		q.day = day
+		q.calls = map[string]int64{}
+	}
+	if budget > 0 && q.calls[provider] >= budget {
+		return &quotaExceededError{provider: provider, budget: budget}
+	}
+	q.calls[provider]++
+	return nil
+}
+
+// snapshot returns the current day and each provider's call count
+func (q *quotaTracker) snapshot() (string, map[string]int64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if day := today(); q.day != day {
+		q.day = day
+		q.calls = map[string]int64{}
+	}
+	calls := make(map[string]int64, len(q.calls))
+	for provider, count := range q.calls {
+		calls[provider] = count
+	}
+	return q.day, calls
+}
+
+// quotaExceededError is returned for upstream calls past a provider's daily budget
+type quotaExceededError struct {
+	provider string
+	budget   int64
+}
+
+func (e *quotaExceededError) Error() string {
+	return fmt.Sprintf("daily %s API budget of %d calls is used up", e.provider, e.budget)
+}
+
+func (e *quotaExceededError) Is(target error) bool { return target == ErrUpstreamUnavailable }
+
+// quotaResetTime returns when quotas next reset: the coming UTC midnight
+func quotaResetTime() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+}
+
+// untilQuotaReset returns how long until quotas reset
+func untilQuotaReset() time.Duration {
+	return time.Until(quotaResetTime())
+}
+
+// hostOf returns the host of a base URL
+func hostOf(baseURL string) string {
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Host
+}
+
+// quotaProvider names the provider an upstream URL belongs to; hosts that
+// aren't a weather provider (such as geocoders) are counted by host name
+func (s *Server) quotaProvider(u *url.URL) string {
+	switch u.Host {
+	case hostOf(s.config().OpenWeatherBaseURL):
+		return "openweathermap"
+	case hostOf(nwsBaseURL):
+		return "nws"
+	case hostOf(openMeteoBaseURL), hostOf(openMeteoGeocodingBaseURL):
+		return "open-meteo"
+	case hostOf(weatherAPIBaseURL):
+		return "weatherapi"
+	case hostOf(tomorrowBaseURL):
+		return "tomorrow"
+	default:
+		return u.Host
+	}
+}
+
+// quotaTransport counts upstream calls and enforces daily budgets
+type quotaTransport struct {
+	s    *Server
+	next http.RoundTripper
+}
+
+func (t *quotaTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	provider := t.s.quotaProvider(req.URL)
+	if err := t.s.upstreamQuota.take(provider, t.s.quotaBudget(provider)); err != nil {
+		return nil, err
+	}
+	return t.next.RoundTrip(req)
+}
+
+// ProviderQuota reports one provider's calls today
+type ProviderQuota struct {
+	Provider  string `json:"provider"`
+	Calls     int64  `json:"calls"`
+	Budget    int64  `json:"budget,omitempty"`
+	Remaining *int64 `json:"remaining,omitempty"`
+}
+
+// QuotaResponse represents the upstream call counts we'll return
+type QuotaResponse struct {
+	Date      string          `json:"date"`
+	ResetsAt  string          `json:"resets_at"`
+	Providers []ProviderQuota `json:"providers"`
+}
+
+// Upstream quota handler using Chi
+func (s *Server) quotaHandler(w http.ResponseWriter, r *http.Request) {
+	day, calls := s.upstreamQuota.snapshot()
+
+	// Providers with a budget are listed even before their first call
+	for _, provider := range providerNames {
+		if _, ok := calls[provider]; !ok && s.quotaBudget(provider) > 0 {
+			calls[provider] = 0
+		}
+	}
+
+	response := QuotaResponse{
+		Date:      day,
+		ResetsAt:  quotaResetTime().Format(time.RFC3339),
+		Providers: []ProviderQuota{},
+	}
+	for provider, count := range calls {
+		quota := ProviderQuota{Provider: provider, Calls: count}
+		if budget := s.quotaBudget(provider); budget > 0 {
+			remaining := max(budget-count, 0)
+			quota.Budget, quota.Remaining = budget, &remaining
+		}
+		response.Providers = append(response.Providers, quota)
+	}
+	sort.Slice(response.Providers, func(i, j int) bool {
+		return response.Providers[i].Provider < response.Providers[j].Provider
+	})
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,247 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/netip"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tier of requests without an API key or bearer token, which are limited by IP address
+const anonymousTier = "anonymous"
+
+// How often buckets that have refilled are dropped
+const rateLimitSweepInterval = time.Minute
+
+// rateLimit allows a number of requests per period, in bursts of up to that many
+type rateLimit struct {
+	requests int
+	period   time.Duration
+}
+
+// Shorthand periods accepted in rate limits, and their names
+var (
+	rateLimitPeriods     = map[string]time.Duration{"s": time.Second, "m": time.Minute, "h": time.Hour, "d": 24 * time.Hour}
+	rateLimitPeriodNames = map[time.Duration]string{time.Second: "second", time.Minute: "minute", time.Hour: "hour", 24 * time.Hour: "day"}
+)
+
+// String describes a limit, e.g. "100 requests per minute"
+func (l rateLimit) String() string {
+	if name, ok := rateLimitPeriodNames[l.period]; ok {
+		return fmt.Sprintf("%d requests per %s", l.requests, name)
+	}
+	return fmt.Sprintf("%d requests per %s", l.requests, l.period)
+}
+
+// parseRateLimit parses a limit such as "100/m", "5000/h", or "20/30s"
+func parseRateLimit(value string) (rateLimit, error) {
+	count, per, ok := strings.Cut(value, "/")
+	requests, err := strconv.Atoi(count)
+	if !ok || err != nil || requests < 1 {
+		return rateLimit{}, fmt.Errorf("must be a number of requests per period, such as 100/m")
+	}
+	period, ok := rateLimitPeriods[per]
+	if !ok {
+		if period, err = time.ParseDuration(per); err != nil || period <= 0 {
+			return rateLimit{}, fmt.Errorf("period must be s, m, h, d, or a duration such as 30s")
+		}
+	}
+	return rateLimit{requests: requests, period: period}, nil
+}
+
+// rateLimitsFromEnv reads the RATE_LIMIT_<TIER> limits, by tier
+func rateLimitsFromEnv(env *environment) (map[string]rateLimit, []string) {
+	limits := map[string]rateLimit{}
+	var problems []string
+	forEachTierEnvVar(env, "RATE_LIMIT_", func(name, tier, value string) {
+		limit, err := parseRateLimit(value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("invalid %s %q: %v", name, value, err))
+			return
+		}
+		limits[tier] = limit
+	})
+	return limits, problems
+}
+
+// tokenBucket holds the requests a client may still make right away, and the
+// rate and capacity it last refilled at
+type tokenBucket struct {
+	tokens   float64
+	updated  time.Time
+	rate     float64 // tokens per second
+	capacity float64
+}
+
+// refill adds the tokens earned since the bucket was last updated
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens = math.Min(b.capacity, b.tokens+now.Sub(b.updated).Seconds()*b.rate)
+	b.updated = now
+}
+
+// rateLimiter keeps a token bucket per client
+type rateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	swept   time.Time
+}
+
+// rateDecision is the outcome of taking a token
+type rateDecision struct {
+	allowed    bool
+	remaining  int
+	retryAfter time.Duration // until the next token, when rejected
+	reset      time.Duration // until the bucket is full again
+}
+
+// take takes a token from key's bucket, which refills at limit's rate
+func (l *rateLimiter) take(key string, limit rateLimit, now time.Time) rateDecision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.swept) > rateLimitSweepInterval {
+		l.sweep(now)
+	}
+
+	capacity := float64(limit.requests)
+	rate := capacity / limit.period.Seconds()
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: capacity, updated: now}
+		l.buckets[key] = bucket
+	}
+	// A changed limit (after a reload, or a new tier) applies from now on
+	if ok {
+		bucket.refill(now)
+	}
+	bucket.rate, bucket.capacity = rate, capacity
+	bucket.tokens = math.Min(bucket.tokens, capacity)
+
+	decision := rateDecision{allowed: bucket.tokens >= 1}
+	if decision.allowed {
+		bucket.tokens--
+	} else {
+		decision.retryAfter = seconds((1 - bucket.tokens) / rate)
+	}
+	decision.remaining = int(bucket.tokens)
+	decision.reset = seconds((capacity - bucket.tokens) / rate)
+	return decision
+}
+
+// sweep drops the buckets that have refilled, which start full again anyway
+func (l *rateLimiter) sweep(now time.Time) {
+	for key, bucket := range l.buckets {
+		if bucket.refill(now); bucket.tokens >= bucket.capacity {
+			delete(l.buckets, key)
+		}
+	}
+	l.swept = now
+}
+
+// seconds converts a number of seconds into a duration
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// rateLimitClients limits requests with a token bucket per client: per API key
+// or token subject, or per IP address for anonymous requests, at the rate of
+// the client's tier. Tiers without a RATE_LIMIT_<TIER> aren't limited.
+func (s *Server) rateLimitClients(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tier, key := anonymousTier, "ip:"+r.RemoteAddr
+		if ip := clientIP(r); ip != nil {
+			key = "ip:" + ip.String()
+		}
+		if client, ok := requestClient(r.Context()); ok {
+			tier, key = client.Tier, "client:"+client.Name
+		}
+		limit, ok := s.config().RateLimits[tier]
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		decision := s.clientLimiter.take(key, limit, time.Now())
+		setRateLimitHeaders(w, limit, decision)
+		if !decision.allowed {
+			writeRateLimited(w, limit, decision)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// rateLimitIPs limits every request to IP_RATE_LIMIT per IP address, except
+// from the IP_RATE_LIMIT_ALLOWLIST networks, whatever credentials it carries.
+// Only rejected responses describe the limit, so the X-RateLimit-* headers of
+// a client's own limit aren't overwritten.
+func (s *Server) rateLimitIPs(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := s.config()
+		ip, ok := netip.AddrFromSlice(clientIP(r))
+		if c.IPRateLimit.requests == 0 || !ok || slices.ContainsFunc(c.IPRateLimitAllowlist, func(network netip.Prefix) bool {
+			return network.Contains(ip.Unmap())
+		}) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		decision := s.ipLimiter.take(ip.Unmap().String(), c.IPRateLimit, time.Now())
+		if !decision.allowed {
+			setRateLimitHeaders(w, c.IPRateLimit, decision)
+			writeRateLimited(w, c.IPRateLimit, decision)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// setRateLimitHeaders describes a client's bucket in X-RateLimit-* headers
+func setRateLimitHeaders(w http.ResponseWriter, limit rateLimit, decision rateDecision) {
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limit.requests))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(decision.remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.Itoa(int(math.Ceil(decision.reset.Seconds()))))
+}
+
+// writeRateLimited rejects a request over its limit with 429 and Retry-After
+func writeRateLimited(w http.ResponseWriter, limit rateLimit, decision rateDecision) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(decision.retryAfter.Seconds()))))
+	writeError(w, http.StatusTooManyRequests, fmt.Sprintf("rate limit of %s exceeded", limit))
+}
+
+// networksFromEnv reads a comma-separated list of CIDR networks and IP addresses from an environment variable
+func networksFromEnv(env *environment, name string) ([]netip.Prefix, []string) {
+	var networks []netip.Prefix
+	var problems []string
+	for _, item := range listFromEnv(env, name) {
+		if network, err := netip.ParsePrefix(item); err == nil {
+			networks = append(networks, network.Masked())
+		} else if addr, err := netip.ParseAddr(item); err == nil {
+			networks = append(networks, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+		} else {
+			problems = append(problems, fmt.Sprintf("invalid %s entry %q: must be an IP address or CIDR network such as 10.0.0.0/8", name, item))
+		}
+	}
+	return networks, problems
+}
+
+// describeRateLimits lists the limits by tier for check-config
+func describeRateLimits(limits map[string]rateLimit) string {
+	if len(limits) == 0 {
+		return "none"
+	}
+	var tiers []string
+	for tier, limit := range limits {
+		tiers = append(tiers, tier+": "+limit.String())
+	}
+	sort.Strings(tiers)
+	return strings.Join(tiers, ", ")
+}
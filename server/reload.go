@@ -0,0 +1,174 @@
+package server
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+)
+
+// reloadConfig re-reads the --config file and the environment and swaps in the
+// new settings without restarting. Settings that only take effect at startup
+// (the listener, upstream mode, and cache setup) keep their current values; the
+// variables whose changes were ignored are returned. On error the running
+// configuration is left unchanged.
+func (s *Server) reloadConfig() ([]string, error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	if s.env.filePath != "" {
+		if err := s.env.loadConfigFile(s.env.filePath); err != nil {
+			return nil, err
+		}
+	}
+	c, err := loadConfig(s.env)
+	if err != nil {
+		return nil, err
+	}
+
+	old := s.config()
+	ignored := c.keepStartupSettings(old)
+	s.activeConfig.Store(c)
+	s.setupCircuitBreakers(c)
+	s.setupLocations(c)
+	s.setupCORS(c)
+	// A level set with PUT /admin/loglevel stays until LOG_LEVEL itself changes
+	if c.LogLevel != old.LogLevel {
+		logLevel.Store(c.LogLevel)
+	}
+	return ignored, nil
+}
+
+// keepStartupSettings copies the settings that need a restart to change from
+// old into c, returning the environment variables that differed
+func (c *Config) keepStartupSettings(old *Config) []string {
+	var ignored []string
+	keep := func(name string, changed bool) {
+		if changed {
+			ignored = append(ignored, name)
+		}
+	}
+
+	keep("PORT", c.Port != old.Port)
+	keep("BIND_ADDR", c.BindAddr != old.BindAddr)
+	keep("READ_TIMEOUT", c.ReadTimeout != old.ReadTimeout)
+	keep("WRITE_TIMEOUT", c.WriteTimeout != old.WriteTimeout)
+	keep("IDLE_TIMEOUT", c.IdleTimeout != old.IdleTimeout)
+	keep("SHUTDOWN_TIMEOUT", c.ShutdownTimeout != old.ShutdownTimeout)
+	keep("MAX_HEADER_BYTES", c.MaxHeaderBytes != old.MaxHeaderBytes)
+	keep("HTTP2", c.HTTP2 != old.HTTP2)
+	keep("HTTP2_CLEARTEXT", c.HTTP2Cleartext != old.HTTP2Cleartext)
+	keep("TLS_*", tlsDescription(c.TLS) != tlsDescription(old.TLS))
+	keep("ADMIN_PORT", c.AdminPort != old.AdminPort)
+	keep("ADMIN_CLIENT_CA_FILE", c.AdminClientCA != old.AdminClientCA)
+	keep("GRPC_PORT", c.GRPCPort != old.GRPCPort)
+	keep("UPSTREAM_MODE", c.UpstreamMode != old.UpstreamMode)
+	keep("FIXTURES_DIR", c.FixturesDir != old.FixturesDir)
+	keep("UPSTREAM_TIMEOUT", c.UpstreamTimeout != old.UpstreamTimeout)
+	keep("CACHE_BACKEND", c.CacheBackend != old.CacheBackend)
+	keep("CACHE_TTL", c.CacheTTL != old.CacheTTL)
+	keep("CACHE_STALE_TTL", c.CacheStaleTTL != old.CacheStaleTTL)
+	keep("CACHE_WARM_ZIP_CODES", !slices.Equal(c.CacheWarmZipCodes, old.CacheWarmZipCodes))
+	keep("CACHE_WARM_INTERVAL", c.CacheWarmInterval != old.CacheWarmInterval)
+	keep("CACHE_CONTROL_*", !maps.Equal(c.CacheControl, old.CacheControl))
+	keep("USAGE_FILE", c.UsageFile != old.UsageFile)
+	keep("STORE_BACKEND", c.StoreBackend != old.StoreBackend)
+	keep("STORE_PATH", c.StorePath != old.StorePath)
+	keep("STORE_AUTO_MIGRATE", c.StoreAutoMigrate != old.StoreAutoMigrate)
+	keep("DATABASE_URL", c.DatabaseURL != old.DatabaseURL)
+	keep("DATABASE_MAX_CONNS", c.DatabaseMaxConns != old.DatabaseMaxConns)
+	keep("DATABASE_MAX_IDLE_CONNS", c.DatabaseMaxIdleConns != old.DatabaseMaxIdleConns)
+	keep("DATABASE_CONN_MAX_LIFETIME", c.DatabaseConnMaxLifetime != old.DatabaseConnMaxLifetime)
+	keep("SUBSCRIPTION_POLL_INTERVAL", c.SubscriptionPollInterval != old.SubscriptionPollInterval)
+	keep("SCHEDULE_*", !slices.EqualFunc(c.Schedule, old.Schedule, (*scheduledJob).equal))
+	keep("MQTT_BROKER_URL", c.MQTTBrokerURL != old.MQTTBrokerURL)
+	keep("MQTT_CLIENT_ID", c.MQTTClientID != old.MQTTClientID)
+	keep("MQTT_USERNAME", c.MQTTUsername != old.MQTTUsername)
+	keep("MQTT_PASSWORD", c.MQTTPassword != old.MQTTPassword)
+	keep("MQTT_TOPIC_PREFIX", c.MQTTTopicPrefix != old.MQTTTopicPrefix)
+	keep("EVENT_BUS", c.EventBus != old.EventBus)
+	keep("EVENT_BUS_URL", c.EventBusURL != old.EventBusURL)
+	keep("EVENT_TOPIC", c.EventTopic != old.EventTopic)
+
+	c.Port, c.BindAddr = old.Port, old.BindAddr
+	c.ReadTimeout, c.WriteTimeout, c.IdleTimeout, c.ShutdownTimeout = old.ReadTimeout, old.WriteTimeout, old.IdleTimeout, old.ShutdownTimeout
+	c.MaxHeaderBytes = old.MaxHeaderBytes
+	c.HTTP2, c.HTTP2Cleartext, c.TLS = old.HTTP2, old.HTTP2Cleartext, old.TLS
+	c.AdminPort, c.AdminClientCA, c.AdminClientCAs = old.AdminPort, old.AdminClientCA, old.AdminClientCAs
+	c.GRPCPort = old.GRPCPort
+	c.UpstreamMode, c.FixturesDir, c.UpstreamTimeout = old.UpstreamMode, old.FixturesDir, old.UpstreamTimeout
+	c.CacheBackend, c.CacheTTL, c.CacheStaleTTL = old.CacheBackend, old.CacheTTL, old.CacheStaleTTL
+	c.CacheWarmZipCodes, c.CacheWarmInterval, c.CacheControl = old.CacheWarmZipCodes, old.CacheWarmInterval, old.CacheControl
+	c.UsageFile = old.UsageFile
+	c.StoreBackend, c.StorePath, c.StoreAutoMigrate = old.StoreBackend, old.StorePath, old.StoreAutoMigrate
+	c.DatabaseURL, c.DatabaseMaxConns, c.DatabaseMaxIdleConns, c.DatabaseConnMaxLifetime = old.DatabaseURL, old.DatabaseMaxConns, old.DatabaseMaxIdleConns, old.DatabaseConnMaxLifetime
+	c.SubscriptionPollInterval, c.Schedule = old.SubscriptionPollInterval, old.Schedule
+	c.MQTTBrokerURL, c.MQTTClientID, c.MQTTUsername, c.MQTTPassword = old.MQTTBrokerURL, old.MQTTClientID, old.MQTTUsername, old.MQTTPassword
+	c.MQTTTopicPrefix = old.MQTTTopicPrefix
+	c.EventBus, c.EventBusURL, c.EventTopic = old.EventBus, old.EventBusURL, old.EventTopic
+	return ignored
+}
+
+// tlsDescription summarizes TLS settings for comparison
+func tlsDescription(t *serverTLS) string {
+	switch {
+	case t == nil:
+		return "off"
+	case t.autocert != nil:
+		return "autocert " + strings.Join(t.domains, ",")
+	default:
+		return "certificate " + t.certFile + " " + t.keyFile
+	}
+}
+
+// logReload logs the outcome of a configuration reload
+func logReload(ignored []string, err error) {
+	switch {
+	case err != nil:
+		warnf("Configuration reload failed, keeping the current settings: %v", err)
+	case len(ignored) > 0:
+		warnf("Configuration reloaded; restart to apply changes to %v", ignored)
+	default:
+		infof("Configuration reloaded")
+	}
+}
+
+// reloadOnSIGHUP reloads the configuration whenever the process receives SIGHUP
+func (s *Server) reloadOnSIGHUP() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(signals)
+		for {
+			select {
+			case <-signals:
+				logReload(s.reloadConfig())
+			case <-s.done:
+				return
+			}
+		}
+	}()
+}
+
+// ReloadResponse reports the outcome of a configuration reload
+type ReloadResponse struct {
+	Status          string   `json:"status"`
+	RestartRequired []string `json:"restart_required,omitempty"`
+}
+
+// Configuration reload handler using Chi
+func (s *Server) reloadHandler(w http.ResponseWriter, r *http.Request) {
+	ignored, err := s.reloadConfig()
+	logReload(ignored, err)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ReloadResponse{Status: "reloaded", RestartRequired: ignored})
+}
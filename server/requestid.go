@@ -0,0 +1,30 @@
+package server
+
+import (
+	"net/http"
+	"regexp"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Header carrying the request ID from clients, back to them, and on to upstream providers
+const requestIDHeader = "X-Request-ID"
+
+// Client-supplied request IDs are only kept when they look like an ID, so they
+// can't be used to inject text into logs or upstream requests
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:/+=-]{1,128}$`)
+
+// requestID assigns each request an ID, reusing a valid X-Request-ID from the
+// client, and returns it in the X-Request-ID response header
+func requestID(next http.Handler) http.Handler {
+	withID := middleware.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestIDHeader, middleware.GetReqID(r.Context()))
+		next.ServeHTTP(w, r)
+	}))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := r.Header.Get(requestIDHeader); id != "" && !validRequestID.MatchString(id) {
+			r.Header.Del(requestIDHeader)
+		}
+		withID.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Retry defaults for upstream calls, overridable with UPSTREAM_RETRIES,
+// UPSTREAM_RETRY_BACKOFF, and UPSTREAM_RETRY_DEADLINE
+const (
+	defaultUpstreamRetries       = 2
+	defaultUpstreamRetryBackoff  = 200 * time.Millisecond
+	defaultUpstreamRetryDeadline = 15 * time.Second
+	maxUpstreamRetryBackoff      = 5 * time.Second
+)
+
+// retryPolicy controls how failed upstream calls are retried
+type retryPolicy struct {
+	retries  int           // retries after the first attempt
+	backoff  time.Duration // delay before the first retry, doubled for each one after
+	deadline time.Duration // no retry starts later than this after the first attempt
+}
+
+// delay returns the jittered wait before the given retry (0 for the first):
+// a random duration between half and all of the exponential backoff
+func (p retryPolicy) delay(retry int) time.Duration {
+	backoff := p.backoff << retry
+	if backoff <= 0 || backoff > maxUpstreamRetryBackoff {
+		backoff = maxUpstreamRetryBackoff
+	}
+	half := backoff / 2
+	return half + rand.N(half+1)
+}
+
+// doWithRetry sends an upstream request, retrying idempotent requests that time
+// out, fail to connect, or get a 5xx response. Retries back off exponentially
+// and stop once the next one would start after the policy's deadline or the
+// request context's, whichever is sooner.
+func doWithRetry(client *http.Client, req *http.Request, policy retryPolicy) (*http.Response, error) {
+	deadline := time.Now().Add(policy.deadline)
+	if ctxDeadline, ok := req.Context().Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+
+	for retry := 0; ; retry++ {
+		resp, err := client.Do(req)
+		if retry >= policy.retries || !idempotentMethod(req.Method) || !retryableResponse(resp, err) {
+			return resp, err
+		}
+
+		wait := policy.delay(retry)
+		if time.Now().Add(wait).After(deadline) {
+			return resp, err
+		}
+
+		// Drain the failed response so its connection can be reused
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// idempotentMethod reports whether a request can safely be sent again
+func idempotentMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+// retryableResponse reports whether an upstream failure is likely transient
+func retryableResponse(resp *http.Response, err error) bool {
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return false
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return true
+		}
+		var opErr *net.OpError
+		return errors.As(err, &opErr)
+	}
+	return resp.StatusCode >= http.StatusInternalServerError
+}
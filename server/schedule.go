@@ -0,0 +1,328 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scheduledJob refreshes the weather for a set of zip codes on a schedule,
+// whether or not clients are asking for them. Each refresh updates the
+// cache, records an observation, and checks the zip codes' subscriptions.
+type scheduledJob struct {
+	name     string
+	zipCodes []string
+	units    string
+	every    time.Duration // for interval jobs
+	cron     *cronSchedule // for cron jobs
+	spec     string        // the schedule as configured
+}
+
+// next returns when the job next runs after t
+func (j *scheduledJob) next(t time.Time) time.Time {
+	if j.cron != nil {
+		return j.cron.next(t)
+	}
+	return t.Add(j.every)
+}
+
+// equal reports whether two jobs have the same settings
+func (j *scheduledJob) equal(other *scheduledJob) bool {
+	return j.name == other.name && j.spec == other.spec && j.units == other.units && slices.Equal(j.zipCodes, other.zipCodes)
+}
+
+// String describes the job for check-config
+func (j *scheduledJob) String() string {
+	noun := "zip codes"
+	if len(j.zipCodes) == 1 {
+		noun = "zip code"
+	}
+	return fmt.Sprintf("%s (%s, %d %s)", j.name, j.spec, len(j.zipCodes), noun)
+}
+
+// Settings each scheduled job can have, after SCHEDULE_<JOB>_
+var scheduleSettings = []string{"ZIP_CODES", "EVERY", "CRON", "TIMEZONE", "UNITS"}
+
+// scheduleFromEnv reads the scheduled jobs. A job is configured by the
+// SCHEDULE_<JOB>_* variables, or a schedule.<job> section of the config file:
+// ZIP_CODES, and either EVERY (a duration) or CRON (a cron expression, read in
+// TIMEZONE or the server's local time), and optionally UNITS.
+func scheduleFromEnv(env *environment) ([]*scheduledJob, []string) {
+	settings := map[string]map[string]string{}
+	var names []string
+	for _, env := range env.Environ() {
+		name, value, _ := strings.Cut(env, "=")
+		rest, ok := strings.CutPrefix(name, "SCHEDULE_")
+		if !ok || value == "" {
+			continue
+		}
+		for _, setting := range scheduleSettings {
+			if job, ok := strings.CutSuffix(rest, "_"+setting); ok && job != "" {
+				if settings[job] == nil {
+					settings[job] = map[string]string{}
+					names = append(names, job)
+				}
+				settings[job][setting] = value
+				break
+			}
+		}
+	}
+	slices.Sort(names)
+
+	var jobs []*scheduledJob
+	var problems []string
+	for _, name := range names {
+		job, err := parseScheduledJob(name, settings[name])
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, problems
+}
+
+// parseScheduledJob checks and reads one job's SCHEDULE_<JOB>_* settings
+func parseScheduledJob(name string, settings map[string]string) (*scheduledJob, error) {
+	prefix := "SCHEDULE_" + name + "_"
+	job := &scheduledJob{name: strings.ToLower(strings.ReplaceAll(name, "_", "-")), units: settings["UNITS"]}
+
+	for _, zipCode := range strings.Split(settings["ZIP_CODES"], ",") {
+		if zipCode = strings.TrimSpace(zipCode); zipCode == "" {
+			continue
+		}
+		if err := validateZipCode(zipCode); err != nil {
+			return nil, fmt.Errorf("invalid %sZIP_CODES entry %q: %v", prefix, zipCode, err)
+		}
+		// Requests for ZIP+4 codes are cached by their 5-digit zip code
+		job.zipCodes = append(job.zipCodes, zipCode[:5])
+	}
+	if len(job.zipCodes) == 0 {
+		return nil, fmt.Errorf("scheduled job %s requires %sZIP_CODES", job.name, prefix)
+	}
+	if job.units != "" {
+		if err := validateUnits(job.units); err != nil {
+			return nil, fmt.Errorf("invalid %sUNITS: %v", prefix, err)
+		}
+	}
+
+	every, spec := settings["EVERY"], settings["CRON"]
+	switch {
+	case (every == "") == (spec == ""):
+		return nil, fmt.Errorf("scheduled job %s requires one of %sEVERY or %sCRON", job.name, prefix, prefix)
+	case every != "":
+		d, err := time.ParseDuration(every)
+		if err != nil || d < time.Minute {
+			return nil, fmt.Errorf("invalid %sEVERY %q: must be a duration of at least 1m", prefix, every)
+		}
+		job.every, job.spec = d, "every "+every
+		if settings["TIMEZONE"] != "" {
+			return nil, fmt.Errorf("%sTIMEZONE only applies to %sCRON", prefix, prefix)
+		}
+	default:
+		zone := time.Local
+		if tz := settings["TIMEZONE"]; tz != "" {
+			var err error
+			if zone, err = time.LoadLocation(tz); err != nil {
+				return nil, fmt.Errorf("invalid %sTIMEZONE %q: %v", prefix, tz, err)
+			}
+		}
+		cron, err := parseCronSchedule(spec, zone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %sCRON %q: %v", prefix, spec, err)
+		}
+		job.cron, job.spec = cron, "cron "+spec
+		if settings["TIMEZONE"] != "" {
+			job.spec += " " + settings["TIMEZONE"]
+		}
+	}
+	return job, nil
+}
+
+// startScheduler runs each scheduled job: interval jobs right away and then
+// every interval, cron jobs at each matching minute. A run that overlaps the
+// next one's time delays it rather than running twice at once.
+func (s *Server) startScheduler(c *Config) {
+	for _, job := range c.Schedule {
+		go func() {
+			if job.cron == nil {
+				s.runScheduledJob(job)
+			}
+			for {
+				next := job.next(time.Now())
+				if next.IsZero() {
+					warnf("Scheduled job %s will never run again", job.name)
+					return
+				}
+				if !s.sleep(time.Until(next)) {
+					return
+				}
+				s.runScheduledJob(job)
+			}
+		}()
+	}
+}
+
+// runScheduledJob refreshes the weather for a job's zip codes upstream, which
+// caches it and records it as an observation, then checks the subscriptions
+// for those zip codes against it
+func (s *Server) runScheduledJob(job *scheduledJob) {
+	start := time.Now()
+	opts := Options{Units: job.units}
+	failed := 0
+	for _, zipCode := range job.zipCodes {
+		loc := Location{ZipCode: zipCode}
+		if _, err := s.fetchWeather(context.Background(), s.cacheKey(loc, opts), loc, opts); err != nil {
+			warnf("Scheduled job %s failed to refresh %s: %v", job.name, zipCode, err)
+			failed++
+		}
+	}
+	s.checkSubscriptions(job.zipCodes)
+	debugf("Scheduled job %s refreshed %d of %d zip codes in %s", job.name, len(job.zipCodes)-failed, len(job.zipCodes), time.Since(start).Round(time.Millisecond))
+}
+
+// cronSchedule is a standard five-field cron expression: minute, hour, day of
+// month, month, and day of week, in a time zone
+type cronSchedule struct {
+	minutes, hours, days, months, weekdays uint64 // bit n is set when value n matches
+	anyDay, anyWeekday                     bool
+	zone                                   *time.Location
+}
+
+// Shorthands for common cron expressions
+var cronShorthands = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+	"@yearly":  "0 0 1 1 *",
+}
+
+// parseCronSchedule parses a cron expression. Each field is *, a number, a
+// range such as 1-5, any of those followed by a step such as */15, or a
+// comma-separated list of them; day of week 0 and 7 are both Sunday.
+func parseCronSchedule(spec string, zone *time.Location) (*cronSchedule, error) {
+	if expanded, ok := cronShorthands[spec]; ok {
+		spec = expanded
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("must have 5 fields (minute hour day-of-month month day-of-week) or be one of @hourly, @daily, @weekly, @monthly, @yearly")
+	}
+
+	s := &cronSchedule{zone: zone, anyDay: fields[2] == "*", anyWeekday: fields[4] == "*"}
+	var err error
+	for _, f := range []struct {
+		bits     *uint64
+		field    string
+		min, max int
+		name     string
+	}{
+		{&s.minutes, fields[0], 0, 59, "minute"},
+		{&s.hours, fields[1], 0, 23, "hour"},
+		{&s.days, fields[2], 1, 31, "day of month"},
+		{&s.months, fields[3], 1, 12, "month"},
+		{&s.weekdays, fields[4], 0, 7, "day of week"},
+	} {
+		if *f.bits, err = parseCronField(f.field, f.min, f.max); err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %v", f.name, f.field, err)
+		}
+	}
+	if s.weekdays&(1<<7) != 0 {
+		s.weekdays |= 1 // 7 is Sunday too
+	}
+	return s, nil
+}
+
+// parseCronField parses one field of a cron expression into a bit set
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step < 1 {
+				return 0, fmt.Errorf("step must be a positive number")
+			}
+		}
+
+		low, high := min, max
+		if rangePart != "*" {
+			first, last, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = strconv.Atoi(first); err != nil {
+				return 0, fmt.Errorf("must be *, a number, or a range")
+			}
+			high = low
+			if isRange {
+				if high, err = strconv.Atoi(last); err != nil {
+					return 0, fmt.Errorf("must be *, a number, or a range")
+				}
+			} else if hasStep {
+				high = max
+			}
+			if low < min || high > max || low > high {
+				return 0, fmt.Errorf("must be between %d and %d", min, max)
+			}
+		}
+		for v := low; v <= high; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// dayMatches reports whether a day is scheduled. As in cron, when both the day
+// of month and day of week are restricted, a day matching either one is.
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	day := s.days&(1<<t.Day()) != 0
+	weekday := s.weekdays&(1<<t.Weekday()) != 0
+	switch {
+	case s.anyDay && s.anyWeekday:
+		return true
+	case s.anyDay:
+		return weekday
+	case s.anyWeekday:
+		return day
+	default:
+		return day || weekday
+	}
+}
+
+// next returns the first matching minute after t, or the zero time if there
+// is none in the next five years (such as on February 30th)
+func (s *cronSchedule) next(t time.Time) time.Time {
+	t = t.In(s.zone).Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case s.months&(1<<t.Month()) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.zone)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.zone)
+		case s.hours&(1<<t.Hour()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.zone)
+		case s.minutes&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// describeSchedule summarizes the scheduled jobs for check-config
+func describeSchedule(jobs []*scheduledJob) string {
+	if len(jobs) == 0 {
+		return "none"
+	}
+	descriptions := make([]string, len(jobs))
+	for i, job := range jobs {
+		descriptions[i] = job.String()
+	}
+	return strings.Join(descriptions, ", ")
+}
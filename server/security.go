@@ -0,0 +1,64 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Security header defaults, for an API whose responses are JSON: nothing is
+// loaded or framed from them, and the dashboard only loads from this server
+const (
+	defaultHSTS           = "max-age=31536000; includeSubDomains"
+	defaultReferrerPolicy = "no-referrer"
+	defaultAPICSP         = "default-src 'none'; frame-ancestors 'none'"
+	// Icons may come from ICON_BASE_URL
+	defaultUICSP = "default-src 'self'; img-src *; object-src 'none'; base-uri 'none'; form-action 'self'; frame-ancestors 'none'"
+)
+
+// Values a Referrer-Policy header may have
+var referrerPolicies = []string{
+	"no-referrer", "no-referrer-when-downgrade", "origin", "origin-when-cross-origin",
+	"same-origin", "strict-origin", "strict-origin-when-cross-origin", "unsafe-url",
+}
+
+// securityHeaders sets the configured security headers on every response.
+// Strict-Transport-Security is only sent over HTTPS, directly or through a
+// proxy that sets X-Forwarded-Proto, since browsers ignore it otherwise.
+// Handlers serving other content, such as the dashboard, replace the
+// Content-Security-Policy with their own.
+func (s *Server) securityHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := s.config()
+		if c.ContentTypeNosniff {
+			w.Header().Set("X-Content-Type-Options", "nosniff")
+		}
+		if c.ReferrerPolicy != "" {
+			w.Header().Set("Referrer-Policy", c.ReferrerPolicy)
+		}
+		if c.ContentSecurityPolicy != "" {
+			w.Header().Set("Content-Security-Policy", c.ContentSecurityPolicy)
+		}
+		if c.HSTS != "" && (r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https") {
+			w.Header().Set("Strict-Transport-Security", c.HSTS)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// validateHSTS checks a Strict-Transport-Security value
+func validateHSTS(value string) error {
+	if value != "" && !strings.HasPrefix(value, "max-age=") {
+		return fmt.Errorf("invalid HSTS %q: must be off or a policy such as max-age=31536000; includeSubDomains", value)
+	}
+	return nil
+}
+
+// validateReferrerPolicy checks a Referrer-Policy value
+func validateReferrerPolicy(value string) error {
+	if value != "" && !slices.Contains(referrerPolicies, value) {
+		return fmt.Errorf("invalid REFERRER_POLICY %q: must be off or one of: %s", value, strings.Join(referrerPolicies, ", "))
+	}
+	return nil
+}
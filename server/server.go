@@ -0,0 +1,390 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"os/signal"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/cors"
+	"github.com/gorilla/websocket"
+	"github.com/graph-gophers/graphql-go"
+	"golang.org/x/sync/singleflight"
+)
+
+// How long in-flight requests may take to finish after a shutdown signal,
+// when SHUTDOWN_TIMEOUT isn't set
+const defaultShutdownTimeout = 30 * time.Second
+
+// Server timeouts used when READ_TIMEOUT, WRITE_TIMEOUT, and IDLE_TIMEOUT
+// aren't set. The write timeout leaves room for slow upstreams and failover.
+const (
+	defaultReadTimeout  = 15 * time.Second
+	defaultWriteTimeout = 60 * time.Second
+	defaultIdleTimeout  = 120 * time.Second
+)
+
+// Server is the weather API: its configuration, routes, listeners, and the
+// state its handlers and background jobs share, such as the cache, store, rate
+// limits, and usage counts. Servers don't share state, so a process can run
+// several, e.g. in tests; only the log level is process-wide.
+type Server struct {
+	env          *environment           // where the configuration is read from
+	activeConfig atomic.Pointer[Config] // setup loads it and reloadConfig replaces it
+	reloadMu     sync.Mutex             // serializes configuration reloads
+
+	weather        WeatherService  // looks up weather for the handlers
+	graphQL        *graphql.Schema // the /graphql schema, resolved with weather
+	graphQLCounter *graphql.Schema // the /graphql schema, counting the lookups of queries
+	router         chi.Router
+	admin          chi.Router // nil when the admin endpoints are on router
+
+	// Upstream calls and the providers they go to. The client is shared by
+	// all upstream calls, so connections are pooled across requests;
+	// setupUpstream configures its transport and timeout.
+	upstreamClient   *http.Client
+	upstreamQuota    *quotaTracker
+	upstreamHealth   *dependencyTracker
+	providerBreakers *circuitBreakers // setupCircuitBreakers loads their settings
+	zipLocations     *locationStore
+
+	// Cached current conditions, set up by setupCache
+	weatherCache           Cache
+	weatherCacheRefreshing sync.Map           // keys with a background refresh in progress
+	weatherLookups         singleflight.Group // coalesces concurrent upstream lookups for the same cache key
+	sharedLookupsMu        sync.Mutex
+	sharedLookups          map[string]*sharedLookup // in-flight upstream lookups by cache key
+
+	// Clients and their data
+	activeCORS       atomic.Pointer[cors.Cors] // setupCORS builds it from the configuration
+	clientLimiter    *rateLimiter              // token buckets by client, for the weather endpoints
+	jwksClient       *http.Client              // fetches JWT_JWKS_URL
+	ipLimiter        *rateLimiter              // token buckets by IP address, for every endpoint
+	requestUsage     *usageTracker
+	locationRequests *locationRequestTracker
+	dataStore        Store                             // set up by setupStore
+	storedAPIKeys    atomic.Pointer[map[string]apiKey] // API keys kept in the store, by hash; updated through /admin/keys
+	apiKeysFileMu    sync.Mutex                        // serializes changes to API_KEYS_FILE made through /admin/keys
+
+	// Where weather and alerts are delivered
+	notifiers            map[string]notifier // the channels alerts can be delivered over
+	webhookClient        *http.Client
+	wsUpgrader           *websocket.Upgrader
+	observationListeners *observationHub
+	mqttClient           mqtt.Client   // nil when MQTT_BROKER_URL isn't set
+	eventBus             eventProducer // nil without an EVENT_BUS
+
+	done      chan struct{} // closed by Close to stop the background jobs
+	closeOnce sync.Once
+}
+
+// Option configures a Server
+type Option func(*Server) error
+
+// WithConfigFile loads a YAML or TOML configuration file, like --config.
+// Environment variables take precedence over its settings.
+func WithConfigFile(path string) Option {
+	return func(s *Server) error {
+		return s.env.loadConfigFile(path)
+	}
+}
+
+// WithSettings sets configuration variables, overriding the environment and
+// the config file, e.g. to turn on mock mode or point providers at a test upstream:
+//
+//	s, err := server.NewServer(server.WithSettings(map[string]string{"MOCK_MODE": "true"}))
+//
+// They only apply to this server, and are kept on reload.
+func WithSettings(settings map[string]string) Option {
+	return func(s *Server) error {
+		maps.Copy(s.env.settings, settings)
+		return nil
+	}
+}
+
+// WithWeatherService looks up weather with svc instead of the configured
+// providers, e.g. to serve canned responses in tests
+func WithWeatherService(svc WeatherService) Option {
+	return func(s *Server) error {
+		s.weather = svc
+		return nil
+	}
+}
+
+// NewServer loads and validates the configuration, opens the store, and
+// creates the routes. It doesn't listen or start background jobs until Run,
+// so Router can be served with httptest on its own; Close releases what it
+// opened.
+func NewServer(opts ...Option) (*Server, error) {
+	s, err := newServer(opts...)
+	if err != nil {
+		return nil, err
+	}
+	c := s.config()
+	if err := s.setupStore(c); err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	if s.weather == nil {
+		s.weather = providerWeatherService{s}
+	}
+	s.graphQL = newGraphQLSchema(s)
+	s.graphQLCounter = s.graphQL.MustClone(&graphQLResolver{s: s, counting: true})
+	s.router, s.admin = s.newRouter(c)
+	return s, nil
+}
+
+// newServer creates a server with the options applied and the configuration
+// loaded, without opening the store, for commands that only need the settings
+func newServer(opts ...Option) (*Server, error) {
+	s := &Server{
+		env:                  &environment{settings: map[string]string{}},
+		upstreamClient:       &http.Client{Timeout: defaultUpstreamTimeout},
+		upstreamQuota:        &quotaTracker{calls: map[string]int64{}},
+		upstreamHealth:       &dependencyTracker{statuses: map[string]*dependencyStatus{}},
+		providerBreakers:     &circuitBreakers{threshold: defaultBreakerThreshold, cooldown: defaultBreakerCooldown, statuses: map[string]*breakerStatus{}},
+		zipLocations:         &locationStore{},
+		weatherCache:         noopCache{},
+		sharedLookups:        map[string]*sharedLookup{},
+		clientLimiter:        &rateLimiter{buckets: map[string]*tokenBucket{}},
+		ipLimiter:            &rateLimiter{buckets: map[string]*tokenBucket{}},
+		jwksClient:           &http.Client{Timeout: jwksTimeout},
+		requestUsage:         &usageTracker{days: map[string]map[string]*clientUsage{}, unsaved: map[string]map[string]*clientUsage{}},
+		locationRequests:     &locationRequestTracker{hours: map[time.Time]map[locationKey]int64{}},
+		dataStore:            newMemoryStore(""),
+		observationListeners: &observationHub{listeners: map[string]map[chan WeatherResponse]bool{}, done: make(chan struct{})},
+		done:                 make(chan struct{}),
+	}
+	s.notifiers = s.newNotifiers()
+	s.webhookClient = s.newWebhookClient()
+	s.wsUpgrader = s.newWSUpgrader()
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := s.setup(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Router returns the handler for the API. It includes the admin endpoints
+// unless ADMIN_PORT is set.
+func (s *Server) Router() http.Handler {
+	return s.router
+}
+
+// AdminRouter returns the handler for the admin endpoints when ADMIN_PORT is
+// set, and nil otherwise
+func (s *Server) AdminRouter() http.Handler {
+	if s.admin == nil {
+		return nil
+	}
+	return s.admin
+}
+
+// Run starts the background jobs (cache warming, subscription polling,
+// scheduled refreshes, MQTT, and the event bus) and serves the API, the admin
+// endpoints, and gRPC on their ports until the process receives SIGINT or
+// SIGTERM. It then shuts down gracefully, saves usage, and closes the store.
+func (s *Server) Run() error {
+	c := s.config()
+	s.startCacheWarmer(c)
+	if err := s.startUsagePersistence(c); err != nil {
+		s.Close()
+		return err
+	}
+	s.startSubscriptionPoller(c)
+	s.startMQTT(c)
+	if err := s.startEventBus(c); err != nil {
+		s.Close()
+		return err
+	}
+	s.startScheduler(c)
+	s.reloadOnSIGHUP()
+
+	servers := []*http.Server{newHTTPServer(c, s.router)}
+	if s.admin != nil {
+		servers = append(servers, newAdminServer(c, s.admin))
+	}
+
+	scheme, grpcScheme := "HTTP", "h2c"
+	if c.TLS != nil {
+		scheme, grpcScheme = "HTTPS", "TLS"
+	}
+	fmt.Printf("Starting weather server with Chi router on %s (%s)...\n", servers[0].Addr, scheme)
+	if c.AdminPort != "" {
+		fmt.Printf("Admin endpoints on %s (%s)\n", servers[1].Addr, scheme)
+	}
+
+	// The gRPC WeatherService, on its own listener when GRPC_PORT is set
+	if c.GRPCPort != "" {
+		grpcServer := s.newGRPCServer(c)
+		servers = append(servers, grpcServer)
+		fmt.Printf("gRPC WeatherService on %s (%s)\n", grpcServer.Addr, grpcScheme)
+	}
+	printEndpoints()
+
+	err := s.serve(c, servers...)
+	s.saveUsage(c)
+	s.stopMQTT()
+	s.stopEventBus()
+	s.Close()
+	return err
+}
+
+// Close stops the server's background jobs and closes its cache and store, e.g.
+// after tests. Run closes the server when it stops.
+func (s *Server) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.weatherCache.Close()
+		s.closeStore()
+	})
+}
+
+// sleep waits for d, returning false if the server is closed first
+func (s *Server) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-s.done:
+		return false
+	}
+}
+
+// newHTTPServer creates the HTTP server for handler. It listens on PORT at
+// BIND_ADDR (default: all interfaces), and limits how long reading a request,
+// writing a response, and keeping an idle connection open may take with
+// READ_TIMEOUT, WRITE_TIMEOUT, and IDLE_TIMEOUT (0 means no limit).
+func newHTTPServer(c *Config, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:           net.JoinHostPort(c.BindAddr, c.Port),
+		Handler:        handler,
+		ReadTimeout:    c.ReadTimeout,
+		WriteTimeout:   c.WriteTimeout,
+		IdleTimeout:    c.IdleTimeout,
+		MaxHeaderBytes: c.MaxHeaderBytes,
+		Protocols:      serverProtocols(c),
+	}
+}
+
+// newAdminServer creates the server for the admin endpoints on ADMIN_PORT. It
+// uses the same TLS settings as the main server and, with ADMIN_CLIENT_CA_FILE,
+// only accepts connections with a client certificate signed by its CAs.
+func newAdminServer(c *Config, handler http.Handler) *http.Server {
+	srv := newHTTPServer(c, handler)
+	srv.Addr = net.JoinHostPort(c.BindAddr, c.AdminPort)
+	if c.TLS != nil {
+		srv.TLSConfig = c.TLS.config()
+		if c.AdminClientCAs != nil {
+			srv.TLSConfig.ClientCAs = c.AdminClientCAs
+			srv.TLSConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+	return srv
+}
+
+// serverProtocols returns the protocols to serve. HTTP/2 is offered over TLS
+// unless HTTP2=false; without TLS, HTTP2_CLEARTEXT=true accepts HTTP/2 with
+// prior knowledge (h2c), for load balancers and meshes that speak it to backends.
+func serverProtocols(c *Config) *http.Protocols {
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	switch {
+	case c.HTTP2Cleartext:
+		protocols.SetUnencryptedHTTP2(true)
+	case c.TLS != nil:
+		protocols.SetHTTP2(c.HTTP2)
+	}
+	return &protocols
+}
+
+// boolFromEnv reads a boolean ("true", "false", "1", "0", ...) from an environment variable
+func boolFromEnv(env *environment, name string, fallback bool) (bool, error) {
+	value := env.Getenv(name)
+	if value == "" {
+		return fallback, nil
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q: must be true or false", name, value)
+	}
+	return parsed, nil
+}
+
+// serve runs the HTTP servers, over TLS when it is configured, until one fails
+// or the process receives SIGINT or SIGTERM. It then stops accepting new
+// connections and waits up to SHUTDOWN_TIMEOUT for in-flight requests to
+// finish. A second signal stops the servers immediately.
+func (s *Server) serve(c *Config, servers ...*http.Server) error {
+	tlsSettings, timeout := c.TLS, c.ShutdownTimeout
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	errs := make(chan error, len(servers))
+	for _, srv := range servers {
+		srv.RegisterOnShutdown(s.observationListeners.shutdown)
+		go func() {
+			if tlsSettings == nil {
+				errs <- srv.ListenAndServe()
+				return
+			}
+			if srv.TLSConfig == nil {
+				srv.TLSConfig = tlsSettings.config()
+			}
+			errs <- srv.ListenAndServeTLS(tlsSettings.certFile, tlsSettings.keyFile)
+		}()
+	}
+
+	select {
+	case err := <-errs:
+		for _, srv := range servers {
+			srv.Close()
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	// Restore default signal handling so a second signal kills the process
+	stop()
+	infof("Shutting down, waiting up to %s for in-flight requests", timeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	for _, srv := range servers {
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			for _, srv := range servers {
+				srv.Close()
+			}
+			return fmt.Errorf("graceful shutdown did not finish: %v", err)
+		}
+	}
+	s.observationListeners.wait(shutdownCtx)
+
+	for range servers {
+		if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+	}
+	infof("Server stopped")
+	return nil
+}
@@ -0,0 +1,34 @@
+package server
+
+import "context"
+
+// WeatherService looks up weather for the handlers, GraphQL, gRPC, streams,
+// and subscriptions. The default looks it up with the configured providers,
+// through the cache; tests and embedders can supply their own with
+// WithWeatherService, e.g. a fake that records calls.
+type WeatherService interface {
+	// CurrentWeather returns the current conditions at a location
+	CurrentWeather(ctx context.Context, loc Location, opts Options) (*WeatherResponse, error)
+	// Forecast returns the 5-day forecast at a location
+	Forecast(ctx context.Context, loc Location, opts Options) (*ForecastResponse, error)
+	// HourlyForecast returns the forecast at a location for the next hours
+	HourlyForecast(ctx context.Context, loc Location, hours int, opts Options) (*HourlyForecastResponse, error)
+}
+
+// providerWeatherService is the default WeatherService, backed by the
+// configured providers and the weather cache
+type providerWeatherService struct {
+	s *Server
+}
+
+func (w providerWeatherService) CurrentWeather(ctx context.Context, loc Location, opts Options) (*WeatherResponse, error) {
+	return w.s.getCachedWeather(ctx, loc, opts)
+}
+
+func (w providerWeatherService) Forecast(ctx context.Context, loc Location, opts Options) (*ForecastResponse, error) {
+	return w.s.getForecast(ctx, loc, opts)
+}
+
+func (w providerWeatherService) HourlyForecast(ctx context.Context, loc Location, hours int, opts Options) (*HourlyForecastResponse, error) {
+	return w.s.getHourlyForecast(ctx, loc, hours, opts)
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+// fakeWeatherService answers every lookup with the temperature it was made
+// with, and records the zip codes it was asked for
+type fakeWeatherService struct {
+	temperature float64
+	zipCodes    []string
+}
+
+func (f *fakeWeatherService) CurrentWeather(ctx context.Context, loc Location, opts Options) (*WeatherResponse, error) {
+	f.zipCodes = append(f.zipCodes, loc.ZipCode)
+	return &WeatherResponse{ZipCode: loc.ZipCode, Temperature: f.temperature}, nil
+}
+
+func (f *fakeWeatherService) Forecast(ctx context.Context, loc Location, opts Options) (*ForecastResponse, error) {
+	return &ForecastResponse{ZipCode: loc.ZipCode}, nil
+}
+
+func (f *fakeWeatherService) HourlyForecast(ctx context.Context, loc Location, hours int, opts Options) (*HourlyForecastResponse, error) {
+	return &HourlyForecastResponse{ZipCode: loc.ZipCode}, nil
+}
+
+func TestServersUseTheirOwnWeatherService(t *testing.T) {
+	first, second := &fakeWeatherService{temperature: 10}, &fakeWeatherService{temperature: 20}
+	for _, fake := range []*fakeWeatherService{first, second} {
+		s := newTestServer(t, WithWeatherService(fake))
+		w := httptest.NewRecorder()
+		s.Router().ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/weather?zip_code=10001", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET /api/v1/weather returned %d: %s", w.Code, w.Body)
+		}
+		var weather WeatherResponse
+		if err := json.NewDecoder(w.Body).Decode(&weather); err != nil {
+			t.Fatal(err)
+		}
+		if weather.Temperature != fake.temperature {
+			t.Errorf("got temperature %v, want %v from the server's own service", weather.Temperature, fake.temperature)
+		}
+	}
+	if len(first.zipCodes) != 1 || len(second.zipCodes) != 1 {
+		t.Errorf("services looked up %v and %v, want one lookup each", first.zipCodes, second.zipCodes)
+	}
+}
+
+func TestServersKeepTheirOwnSettings(t *testing.T) {
+	nws := newTestServer(t, WithSettings(map[string]string{"WEATHER_PROVIDER": "nws"}))
+	openMeteo := newTestServer(t, WithSettings(map[string]string{"WEATHER_PROVIDER": "open-meteo"}))
+	if got := nws.config().WeatherProvider; got != "nws" {
+		t.Errorf("first server's provider is %q, want nws", got)
+	}
+	if got := openMeteo.config().WeatherProvider; got != "open-meteo" {
+		t.Errorf("second server's provider is %q, want open-meteo", got)
+	}
+	if value, ok := os.LookupEnv("WEATHER_PROVIDER"); ok {
+		t.Errorf("settings were copied to the environment: WEATHER_PROVIDER=%s", value)
+	}
+}
+
+func TestCloseStopsCacheSweeper(t *testing.T) {
+	s := newTestServer(t, WithSettings(map[string]string{"CACHE_BACKEND": "memory"}))
+	cache, ok := s.weatherCache.(*memoryCache)
+	if !ok {
+		t.Fatalf("cache is a %T, want a memory cache", s.weatherCache)
+	}
+	s.Close()
+	select {
+	case <-cache.done:
+	default:
+		t.Error("the cache sweeper is still running after Close")
+	}
+	select {
+	case <-s.done:
+	default:
+		t.Error("background jobs are still running after Close")
+	}
+}
@@ -0,0 +1,308 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/pressly/goose/v3"
+	_ "modernc.org/sqlite" // registers the pure-Go "sqlite" driver
+)
+
+// sqliteStore keeps client data, API keys, usage, and observations in an
+// SQLite database file
+type sqliteStore struct {
+	db *sql.DB
+}
+
+// openSQLiteStore opens the database at path, creating the file if needed
+func openSQLiteStore(path string) (*sqliteStore, error) {
+	// WAL lets lookups run while a change is written; waiting on a busy
+	// database beats failing the request
+	dsn := "file:" + path + "?" + url.Values{"_pragma": {"journal_mode(WAL)", "busy_timeout(5000)"}}.Encode()
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open STORE_PATH %s: %v", path, err)
+	}
+	// SQLite allows one writer at a time
+	db.SetMaxOpenConns(1)
+	return &sqliteStore{db: db}, nil
+}
+
+func (s *sqliteStore) migrator() (*goose.Provider, error) {
+	return newMigrator(goose.DialectSQLite3, s.db, "sqlite")
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *sqliteStore) SavedLocations(client string) ([]SavedLocation, error) {
+	// Replacing a location keeps its rowid, so rowid order is the order saved
+	rows, err := s.db.Query(`SELECT zip_code, name FROM saved_locations WHERE client = ? ORDER BY rowid`, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read saved locations: %v", err)
+	}
+	defer rows.Close()
+
+	var locations []SavedLocation
+	for rows.Next() {
+		var loc SavedLocation
+		if err := rows.Scan(&loc.ZipCode, &loc.Name); err != nil {
+			return nil, fmt.Errorf("failed to read saved locations: %v", err)
+		}
+		locations = append(locations, loc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read saved locations: %v", err)
+	}
+	return locations, nil
+}
+
+func (s *sqliteStore) SaveLocation(client string, loc SavedLocation) (bool, error) {
+	result, err := s.db.Exec(`UPDATE saved_locations SET name = ? WHERE client = ? AND zip_code = ?`, loc.Name, client, loc.ZipCode)
+	if err != nil {
+		return false, fmt.Errorf("failed to save location: %v", err)
+	}
+	if updated, _ := result.RowsAffected(); updated > 0 {
+		return false, nil
+	}
+	if _, err := s.db.Exec(`INSERT INTO saved_locations (client, zip_code, name) VALUES (?, ?, ?)`, client, loc.ZipCode, loc.Name); err != nil {
+		return false, fmt.Errorf("failed to save location: %v", err)
+	}
+	return true, nil
+}
+
+func (s *sqliteStore) DeleteSavedLocation(client, zipCode string) (*SavedLocation, error) {
+	deleted := SavedLocation{ZipCode: zipCode}
+	err := s.db.QueryRow(`DELETE FROM saved_locations WHERE client = ? AND zip_code = ? RETURNING name`, client, zipCode).Scan(&deleted.Name)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to delete saved location: %v", err)
+	}
+	return &deleted, nil
+}
+
+func (s *sqliteStore) Preferences(client string) (Preferences, error) {
+	var prefs Preferences
+	err := s.db.QueryRow(`SELECT units, lang, provider FROM preferences WHERE client = ?`, client).Scan(&prefs.Units, &prefs.Lang, &prefs.Provider)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return Preferences{}, nil
+	case err != nil:
+		return Preferences{}, fmt.Errorf("failed to read preferences: %v", err)
+	}
+	return prefs, nil
+}
+
+func (s *sqliteStore) SetPreferences(client string, prefs Preferences) error {
+	_, err := s.db.Exec(`INSERT INTO preferences (client, units, lang, provider) VALUES (?, ?, ?, ?)
+		ON CONFLICT (client) DO UPDATE SET units = excluded.units, lang = excluded.lang, provider = excluded.provider`,
+		client, prefs.Units, prefs.Lang, prefs.Provider)
+	if err != nil {
+		return fmt.Errorf("failed to save preferences: %v", err)
+	}
+	return nil
+}
+
+// readSubscriptions runs a query for subscriptionColumns and scans the rows
+func (s *sqliteStore) readSubscriptions(query string, args ...any) ([]Subscription, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read subscriptions: %v", err)
+	}
+	defer rows.Close()
+
+	var subs []Subscription
+	for rows.Next() {
+		var sub Subscription
+		if err := rows.Scan(&sub.ID, &sub.Client, &sub.ZipCode, &sub.SavedLocations, &sub.Parent, &sub.Condition, &sub.Units, &sub.Channel, &sub.CallbackURL, &sub.Email, &sub.Secret, &sub.CreatedAt, &sub.Triggered, &sub.LastAlertAt); err != nil {
+			return nil, fmt.Errorf("failed to read subscriptions: %v", err)
+		}
+		subs = append(subs, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read subscriptions: %v", err)
+	}
+	return subs, nil
+}
+
+func (s *sqliteStore) Subscriptions(client string) ([]Subscription, error) {
+	return s.readSubscriptions(`SELECT `+subscriptionColumns+` FROM subscriptions WHERE client = ? ORDER BY created_at, rowid`, client)
+}
+
+func (s *sqliteStore) AllSubscriptions() ([]Subscription, error) {
+	return s.readSubscriptions(`SELECT ` + subscriptionColumns + ` FROM subscriptions`)
+}
+
+func (s *sqliteStore) AddSubscription(client string, sub Subscription) error {
+	_, err := s.db.Exec(`INSERT INTO subscriptions (id, client, zip_code, saved_locations, parent_id, condition, units, channel, callback_url, email, secret, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		sub.ID, client, sub.ZipCode, sub.SavedLocations, sub.Parent, sub.Condition, sub.Units, sub.Channel, sub.CallbackURL, sub.Email, sub.Secret, sub.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save subscription: %v", err)
+	}
+	return nil
+}
+
+func (s *sqliteStore) DeleteSubscription(client, id string) (*Subscription, error) {
+	subs, err := s.readSubscriptions(`DELETE FROM subscriptions WHERE client = ? AND id = ? RETURNING `+subscriptionColumns, client, id)
+	if err != nil || len(subs) == 0 {
+		return nil, err
+	}
+	return &subs[0], nil
+}
+
+func (s *sqliteStore) SetSubscriptionTriggered(id string, triggered bool, at time.Time) (bool, error) {
+	result, err := s.db.Exec(`UPDATE subscriptions SET triggered = ?, last_alert_at = ? WHERE id = ? AND triggered != ?`,
+		triggered, at.UTC().Format(time.RFC3339), id, triggered)
+	if err != nil {
+		return false, fmt.Errorf("failed to update subscription: %v", err)
+	}
+	updated, _ := result.RowsAffected()
+	return updated > 0, nil
+}
+
+func (s *sqliteStore) APIKeys() ([]apiKeyEntry, error) {
+	rows, err := s.db.Query(`SELECT sha256, name, tier FROM api_keys ORDER BY created_at`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API keys: %v", err)
+	}
+	defer rows.Close()
+
+	var entries []apiKeyEntry
+	for rows.Next() {
+		var entry apiKeyEntry
+		if err := rows.Scan(&entry.SHA256, &entry.Name, &entry.Tier); err != nil {
+			return nil, fmt.Errorf("failed to read API keys: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read API keys: %v", err)
+	}
+	return entries, nil
+}
+
+func (s *sqliteStore) AddAPIKey(entry apiKeyEntry) error {
+	_, err := s.db.Exec(`INSERT INTO api_keys (sha256, name, tier, created_at) VALUES (?, ?, ?, ?)`,
+		entry.hash(), entry.Name, entry.Tier, time.Now().UTC().Format(time.RFC3339Nano))
+	if err != nil {
+		return fmt.Errorf("failed to save API key: %v", err)
+	}
+	return nil
+}
+
+func (s *sqliteStore) DeleteAPIKey(hash string) (bool, error) {
+	result, err := s.db.Exec(`DELETE FROM api_keys WHERE sha256 = ?`, hash)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete API key: %v", err)
+	}
+	deleted, _ := result.RowsAffected()
+	return deleted > 0, nil
+}
+
+func (s *sqliteStore) LoadUsage() (map[string]map[string]*clientUsage, error) {
+	rows, err := s.db.Query(`SELECT day, client, tier, requests FROM usage`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage: %v", err)
+	}
+	defer rows.Close()
+
+	days := map[string]map[string]*clientUsage{}
+	for rows.Next() {
+		var day, client string
+		var usage clientUsage
+		if err := rows.Scan(&day, &client, &usage.Tier, &usage.Requests); err != nil {
+			return nil, fmt.Errorf("failed to read usage: %v", err)
+		}
+		if days[day] == nil {
+			days[day] = map[string]*clientUsage{}
+		}
+		days[day][client] = &usage
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read usage: %v", err)
+	}
+	return days, nil
+}
+
+func (s *sqliteStore) SaveUsage(days map[string]map[string]*clientUsage, oldest string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to save usage: %v", err)
+	}
+	defer tx.Rollback()
+
+	for day, clients := range days {
+		for client, usage := range clients {
+			_, err := tx.Exec(`INSERT INTO usage (day, client, tier, requests) VALUES (?, ?, ?, ?)
+				ON CONFLICT (day, client) DO UPDATE SET tier = excluded.tier, requests = excluded.requests`,
+				day, client, usage.Tier, usage.Requests)
+			if err != nil {
+				return fmt.Errorf("failed to save usage: %v", err)
+			}
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM usage WHERE day < ?`, oldest); err != nil {
+		return fmt.Errorf("failed to save usage: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save usage: %v", err)
+	}
+	return nil
+}
+
+func (s *sqliteStore) RecordObservation(zipCode, country string, weather WeatherResponse) error {
+	data, err := json.Marshal(weather)
+	if err != nil {
+		return fmt.Errorf("failed to encode observation: %v", err)
+	}
+	_, err = s.db.Exec(`INSERT INTO observations (zip_code, country, observed_at, source, units, weather) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT DO NOTHING`,
+		zipCode, country, weather.ObservedAt, weather.Source, weather.Units, string(data))
+	if err != nil {
+		return fmt.Errorf("failed to record observation: %v", err)
+	}
+	return nil
+}
+
+func (s *sqliteStore) Observations(zipCode, country string, from, to time.Time) ([]WeatherResponse, error) {
+	// observed_at is RFC 3339 in UTC, so it sorts and compares as text
+	rows, err := s.db.Query(`SELECT weather FROM observations
+		WHERE zip_code = ? AND country = ? AND observed_at >= ? AND observed_at < ?
+		ORDER BY observed_at, source, units`,
+		zipCode, country, from.UTC().Format(time.RFC3339), to.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read observations: %v", err)
+	}
+	defer rows.Close()
+
+	var observations []WeatherResponse
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read observations: %v", err)
+		}
+		var weather WeatherResponse
+		if err := json.Unmarshal([]byte(data), &weather); err != nil {
+			return nil, fmt.Errorf("failed to decode observation: %v", err)
+		}
+		observations = append(observations, weather)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read observations: %v", err)
+	}
+	return observations, nil
+}
+
+func (s *sqliteStore) PruneObservations(before time.Time) error {
+	if _, err := s.db.Exec(`DELETE FROM observations WHERE observed_at < ?`, before.UTC().Format(time.RFC3339)); err != nil {
+		return fmt.Errorf("failed to prune observations: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,361 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Store persists the data kept for each client, by client name.
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// SavedLocations returns a client's saved locations, in the order they were saved
+	SavedLocations(client string) ([]SavedLocation, error)
+	// SaveLocation saves a location for a client, replacing the one with the
+	// same zip code; it reports whether the zip code wasn't saved before
+	SaveLocation(client string, loc SavedLocation) (bool, error)
+	// DeleteSavedLocation removes a client's saved zip code and returns it, or
+	// nil if it wasn't saved
+	DeleteSavedLocation(client, zipCode string) (*SavedLocation, error)
+	// Preferences returns a client's preferences, which are empty until set
+	Preferences(client string) (Preferences, error)
+	// SetPreferences replaces a client's preferences
+	SetPreferences(client string, prefs Preferences) error
+	// Subscriptions returns a client's weather alert subscriptions, oldest first
+	Subscriptions(client string) ([]Subscription, error)
+	// AllSubscriptions returns every client's subscriptions, for the poller
+	AllSubscriptions() ([]Subscription, error)
+	// AddSubscription saves a new subscription for a client
+	AddSubscription(client string, sub Subscription) error
+	// DeleteSubscription removes a client's subscription and returns it, or
+	// nil if it doesn't exist
+	DeleteSubscription(client, id string) (*Subscription, error)
+	// SetSubscriptionTriggered records whether a subscription's condition is
+	// met, and reports whether that changed. Of several servers polling one
+	// store, only the one whose call changed it sends the alert.
+	SetSubscriptionTriggered(id string, triggered bool, at time.Time) (bool, error)
+	// Close releases the store's resources
+	Close() error
+}
+
+// usageStore is implemented by stores that keep request usage, in place of USAGE_FILE
+type usageStore interface {
+	// LoadUsage returns the saved usage by date, then client name
+	LoadUsage() (map[string]map[string]*clientUsage, error)
+	// SaveUsage saves usage by date, then client name, and forgets the days
+	// before oldest
+	SaveUsage(days map[string]map[string]*clientUsage, oldest string) error
+}
+
+// sharedUsageStore is implemented by stores that several servers share, which
+// add up the requests each server saves rather than replacing the counts
+type sharedUsageStore interface {
+	usageStore
+	// AddUsage adds requests by date, then client name, to the saved usage,
+	// forgets the days before oldest, and returns all the saved usage
+	AddUsage(requests map[string]map[string]*clientUsage, oldest string) (map[string]map[string]*clientUsage, error)
+}
+
+// apiKeyStore is implemented by stores that keep API keys managed through
+// /admin/keys, alongside API_KEYS and API_KEYS_FILE. Keys are kept by hash.
+type apiKeyStore interface {
+	// APIKeys returns the stored keys
+	APIKeys() ([]apiKeyEntry, error)
+	// AddAPIKey stores a key
+	AddAPIKey(entry apiKeyEntry) error
+	// DeleteAPIKey removes the key with a hash and reports whether it was stored
+	DeleteAPIKey(hash string) (bool, error)
+}
+
+// observationStore is implemented by stores that record current conditions
+// looked up for zip codes
+type observationStore interface {
+	// RecordObservation records a lookup; looking up the same observation
+	// twice records it once
+	RecordObservation(zipCode, country string, weather WeatherResponse) error
+	// Observations returns the observations recorded for a zip code between
+	// from and to, oldest first
+	Observations(zipCode, country string, from, to time.Time) ([]WeatherResponse, error)
+	// PruneObservations forgets the observations made before a time
+	PruneObservations(before time.Time) error
+}
+
+// SavedLocation is a zip code a client saved, with an optional label
+type SavedLocation struct {
+	ZipCode string `json:"zip_code"`
+	Name    string `json:"name,omitempty"`
+}
+
+// Preferences are a client's defaults for the optional weather parameters;
+// empty fields use the server defaults
+type Preferences struct {
+	Units    string `json:"units,omitempty"`
+	Lang     string `json:"lang,omitempty"`
+	Provider string `json:"provider,omitempty"`
+}
+
+// clientData is everything stored for one client
+type clientData struct {
+	Locations     []SavedLocation `json:"locations,omitempty"`
+	Preferences   Preferences     `json:"preferences,omitzero"`
+	Subscriptions []Subscription  `json:"subscriptions,omitempty"`
+}
+
+// openStore opens the store for the configured STORE_BACKEND
+func openStore(c *Config) (Store, error) {
+	switch c.StoreBackend {
+	case "postgres":
+		return openPostgresStore(c)
+	case "sqlite":
+		return openSQLiteStore(c.StorePath)
+	case "file":
+		store := newMemoryStore(c.StorePath)
+		if err := store.load(); err != nil {
+			return nil, err
+		}
+		return store, nil
+	}
+	return newMemoryStore(""), nil
+}
+
+// setupStore opens the store, makes sure its schema is up to date, and loads
+// the API keys it keeps
+func (s *Server) setupStore(c *Config) error {
+	store, err := openStore(c)
+	if err != nil {
+		return err
+	}
+	if err := prepareSchema(store, c.StoreAutoMigrate); err != nil {
+		store.Close()
+		return err
+	}
+	s.dataStore = store
+	if err := s.loadStoredAPIKeys(); err != nil {
+		return err
+	}
+
+	if c.StoreBackend == "postgres" {
+		go func() {
+			for s.sleep(storedAPIKeysRefreshInterval) {
+				if err := s.loadStoredAPIKeys(); err != nil {
+					warnf("Failed to refresh stored API keys: %v", err)
+				}
+			}
+		}()
+	}
+	return nil
+}
+
+// closeStore closes the store before the server exits
+func (s *Server) closeStore() {
+	if err := s.dataStore.Close(); err != nil {
+		warnf("Failed to close the store: %v", err)
+	}
+}
+
+// memoryStore keeps client data in memory and, for the file backend, writes
+// all of it to a JSON file after every change
+type memoryStore struct {
+	mu      sync.Mutex
+	clients map[string]*clientData
+	path    string // empty keeps data in memory only
+}
+
+func newMemoryStore(path string) *memoryStore {
+	return &memoryStore{clients: map[string]*clientData{}, path: path}
+}
+
+// load reads the data saved to the store's file, if it exists
+func (s *memoryStore) load() error {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read STORE_PATH: %v", err)
+	}
+
+	clients := map[string]*clientData{}
+	if err := json.Unmarshal(data, &clients); err != nil {
+		return fmt.Errorf("failed to parse STORE_PATH %s: %v", s.path, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clients = clients
+	return nil
+}
+
+// update applies change to a client's data and saves the store, leaving the
+// data unchanged if saving fails. The caller holds mu.
+func (s *memoryStore) update(client string, change func(data *clientData)) error {
+	data, ok := s.clients[client]
+	if !ok {
+		data = &clientData{}
+	}
+	updated := *data
+	updated.Locations = slices.Clone(data.Locations)
+	updated.Subscriptions = slices.Clone(data.Subscriptions)
+	change(&updated)
+
+	s.clients[client] = &updated
+	if err := s.save(); err != nil {
+		if ok {
+			s.clients[client] = data
+		} else {
+			delete(s.clients, client)
+		}
+		return err
+	}
+	return nil
+}
+
+// save writes the store to its file, if it has one. The caller holds mu.
+func (s *memoryStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(s.clients)
+	if err != nil {
+		return fmt.Errorf("failed to encode client data: %v", err)
+	}
+	if err := writeFileAtomic(s.path, data); err != nil {
+		return fmt.Errorf("failed to save client data to %s: %v", s.path, err)
+	}
+	return nil
+}
+
+func (s *memoryStore) SavedLocations(client string) ([]SavedLocation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if data, ok := s.clients[client]; ok {
+		return slices.Clone(data.Locations), nil
+	}
+	return nil, nil
+}
+
+func (s *memoryStore) SaveLocation(client string, loc SavedLocation) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	created := false
+	err := s.update(client, func(data *clientData) {
+		i := slices.IndexFunc(data.Locations, func(saved SavedLocation) bool { return saved.ZipCode == loc.ZipCode })
+		if i >= 0 {
+			data.Locations[i] = loc
+			return
+		}
+		data.Locations = append(data.Locations, loc)
+		created = true
+	})
+	return created, err
+}
+
+func (s *memoryStore) DeleteSavedLocation(client, zipCode string) (*SavedLocation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted *SavedLocation
+	err := s.update(client, func(data *clientData) {
+		data.Locations = slices.DeleteFunc(data.Locations, func(saved SavedLocation) bool {
+			if saved.ZipCode != zipCode {
+				return false
+			}
+			deleted = &saved
+			return true
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+func (s *memoryStore) Preferences(client string) (Preferences, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if data, ok := s.clients[client]; ok {
+		return data.Preferences, nil
+	}
+	return Preferences{}, nil
+}
+
+func (s *memoryStore) SetPreferences(client string, prefs Preferences) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.update(client, func(data *clientData) { data.Preferences = prefs })
+}
+
+func (s *memoryStore) Subscriptions(client string) ([]Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if data, ok := s.clients[client]; ok {
+		return slices.Clone(data.Subscriptions), nil
+	}
+	return nil, nil
+}
+
+func (s *memoryStore) AllSubscriptions() ([]Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var subs []Subscription
+	for client, data := range s.clients {
+		for _, sub := range data.Subscriptions {
+			sub.Client = client
+			subs = append(subs, sub)
+		}
+	}
+	return subs, nil
+}
+
+func (s *memoryStore) AddSubscription(client string, sub Subscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.update(client, func(data *clientData) { data.Subscriptions = append(data.Subscriptions, sub) })
+}
+
+func (s *memoryStore) DeleteSubscription(client, id string) (*Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted *Subscription
+	err := s.update(client, func(data *clientData) {
+		data.Subscriptions = slices.DeleteFunc(data.Subscriptions, func(sub Subscription) bool {
+			if sub.ID != id {
+				return false
+			}
+			deleted = &sub
+			return true
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+func (s *memoryStore) SetSubscriptionTriggered(id string, triggered bool, at time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for client, data := range s.clients {
+		i := slices.IndexFunc(data.Subscriptions, func(sub Subscription) bool { return sub.ID == id })
+		if i < 0 {
+			continue
+		}
+		if data.Subscriptions[i].Triggered == triggered {
+			return false, nil
+		}
+		err := s.update(client, func(data *clientData) {
+			data.Subscriptions[i].Triggered = triggered
+			data.Subscriptions[i].LastAlertAt = at.UTC().Format(time.RFC3339)
+		})
+		return err == nil, err
+	}
+	return false, nil
+}
+
+func (s *memoryStore) Close() error { return nil }
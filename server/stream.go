@@ -0,0 +1,175 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// How often an idle stream sends a comment so proxies keep it open
+const streamKeepAlive = 30 * time.Second
+
+// observationHub hands refreshed current conditions to the streams waiting
+// for them, by cache key
+type observationHub struct {
+	mu        sync.Mutex
+	listeners map[string]map[chan WeatherResponse]bool
+	done      chan struct{} // closed when the server shuts down
+	closeOnce sync.Once
+
+	// Connections taken over from the servers, such as WebSockets, which
+	// their Shutdown doesn't wait for
+	hijacked sync.WaitGroup
+}
+
+// listen returns a channel receiving each refresh of the weather cached under
+// key, and a function that stops it. A listener that falls behind only gets
+// the latest refresh.
+func (h *observationHub) listen(key string) (<-chan WeatherResponse, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	updates := make(chan WeatherResponse, 1)
+	if h.listeners[key] == nil {
+		h.listeners[key] = map[chan WeatherResponse]bool{}
+	}
+	h.listeners[key][updates] = true
+	return updates, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.listeners[key], updates)
+		if len(h.listeners[key]) == 0 {
+			delete(h.listeners, key)
+		}
+	}
+}
+
+// broadcast hands a refresh of the weather cached under key to its listeners
+func (h *observationHub) broadcast(key string, weather WeatherResponse) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for updates := range h.listeners[key] {
+		// Replace an update the listener hasn't taken yet
+		select {
+		case <-updates:
+		default:
+		}
+		updates <- weather
+	}
+}
+
+// shutdown ends every stream, so they don't hold up a graceful shutdown
+func (h *observationHub) shutdown() {
+	h.closeOnce.Do(func() { close(h.done) })
+}
+
+// wait waits for the hijacked connections to close, or ctx to end
+func (h *observationHub) wait(ctx context.Context) {
+	closed := make(chan struct{})
+	go func() {
+		h.hijacked.Wait()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-ctx.Done():
+	}
+}
+
+// Weather stream handler using Chi. It sends the current conditions right away
+// and again whenever the cached conditions are refreshed with a new
+// observation, keeping them refreshed while the stream is open.
+func (s *Server) weatherStreamHandler(w http.ResponseWriter, r *http.Request) {
+	loc, ok := s.zipCodeFromRequest(w, r)
+	if !ok {
+		return
+	}
+	opts, ok := s.optionsFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	// Listen before the first lookup so a refresh right after it isn't missed
+	updates, stop := s.observationListeners.listen(s.cacheKey(loc, opts))
+	defer stop()
+
+	weather, err := s.weather.CurrentWeather(r.Context(), loc, opts)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	// Streams outlive WRITE_TIMEOUT
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", (5 * time.Second).Milliseconds())
+
+	last := *weather
+	if err := writeWeatherEvent(w, rc, last); err != nil {
+		return
+	}
+
+	refresh := time.NewTicker(s.liveRefreshInterval())
+	defer refresh.Stop()
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case weather := <-updates:
+			if weather.ObservedAt == last.ObservedAt && weather.Source == last.Source {
+				continue // refreshed, but with the same observation
+			}
+			last = weather
+			if err := writeWeatherEvent(w, rc, weather); err != nil {
+				return
+			}
+			keepAlive.Reset(streamKeepAlive)
+		case <-refresh.C:
+			s.weather.CurrentWeather(r.Context(), loc, opts)
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		case <-s.observationListeners.done:
+			return
+		}
+	}
+}
+
+// liveRefreshInterval is how often live weather is looked up again. Looking
+// it up once it may have expired refreshes it, which listeners then hear about
+// like any other refresh.
+func (s *Server) liveRefreshInterval() time.Duration {
+	if every := s.config().CacheTTL / 2; every > 0 {
+		return every
+	}
+	return time.Minute
+}
+
+// liveWeather prepares current conditions for sending to a listener
+func liveWeather(weather WeatherResponse) WeatherResponse {
+	weather.Cache, weather.Stale = "", false
+	refreshLocalTime(&weather)
+	return weather
+}
+
+// writeWeatherEvent sends current conditions as a "weather" event
+func writeWeatherEvent(w http.ResponseWriter, rc *http.ResponseController, weather WeatherResponse) error {
+	data, err := json.Marshal(liveWeather(weather))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "event: weather\ndata: %s\n\n", data)
+	return rc.Flush()
+}
@@ -0,0 +1,546 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Subscription defaults and limits
+const (
+	maxSubscriptions                = 20
+	defaultSubscriptionPollInterval = 5 * time.Minute
+)
+
+// Alert deliveries that fail to connect or are refused temporarily are
+// retried with backoff
+var alertRetry = retryPolicy{retries: 2, backoff: time.Second, deadline: time.Minute}
+
+// A notifier delivers a subscription's alerts over one channel
+type notifier interface {
+	// available reports why the channel can't be used, if it can't
+	available() error
+	// validate checks, and may normalize, a new subscription's destination
+	validate(sub *Subscription) error
+	// notify delivers one alert and reports whether a failed delivery is worth retrying
+	notify(sub Subscription, alert SubscriptionAlert) (bool, error)
+}
+
+// newNotifiers returns the channels alerts can be delivered over
+func (s *Server) newNotifiers() map[string]notifier {
+	return map[string]notifier{
+		"webhook": webhookNotifier{s},
+		"email":   emailNotifier{s},
+		"slack":   slackNotifier{s},
+		"teams":   teamsNotifier{s},
+	}
+}
+
+// Subscription is a client's rule to be alerted, at a callback URL, by email,
+// or in a Slack or Teams channel, when the current weather at a zip code meets
+// a condition. A saved-locations subscription has no zip code of its own:
+// it stands for a subscription per saved location, kept as locations are
+// saved and deleted.
+type Subscription struct {
+	ID             string `json:"id"`
+	Client         string `json:"-"`
+	ZipCode        string `json:"zip_code,omitempty"`
+	SavedLocations bool   `json:"saved_locations,omitempty"`
+	// Parent is the saved-locations subscription this one was made from
+	Parent      string `json:"parent_id,omitempty"`
+	Condition   string `json:"condition"`
+	Units       string `json:"units"`
+	Channel     string `json:"channel"`
+	CallbackURL string `json:"callback_url,omitempty"`
+	Email       string `json:"email,omitempty"`
+	// Secret signs webhook alerts; it is only shown when the subscription is created
+	Secret      string `json:"secret,omitempty"`
+	CreatedAt   string `json:"created_at"`
+	Triggered   bool   `json:"triggered"`
+	LastAlertAt string `json:"last_alert_at,omitempty"`
+}
+
+// subscriptionColumns are the columns the database stores read subscriptions from
+const subscriptionColumns = `id, client, zip_code, saved_locations, parent_id, condition, units, channel, callback_url, email, secret, created_at, triggered, last_alert_at`
+
+// SubscriptionRequest is the body of POST /subscriptions
+type SubscriptionRequest struct {
+	ZipCode        string `json:"zip_code"`
+	SavedLocations bool   `json:"saved_locations"`
+	Condition      string `json:"condition"`
+	Units          string `json:"units"`
+	Channel        string `json:"channel"`
+	CallbackURL    string `json:"callback_url"`
+	Email          string `json:"email"`
+}
+
+// SubscriptionAlert is sent when a subscription's condition starts or stops
+// being met; it is the body POSTed to webhook subscriptions' callback URLs
+type SubscriptionAlert struct {
+	Event          string           `json:"event"` // "triggered" or "cleared"
+	SubscriptionID string           `json:"subscription_id"`
+	ZipCode        string           `json:"zip_code"`
+	Condition      string           `json:"condition"`
+	At             string           `json:"at"`
+	Weather        *WeatherResponse `json:"weather"`
+	SevereAlerts   []SevereAlert    `json:"severe_alerts,omitempty"`
+}
+
+// subscriptionsClient returns the client whose subscriptions a request is for.
+// Without one it writes a 401 response and returns false.
+func subscriptionsClient(w http.ResponseWriter, r *http.Request) (apiClient, bool) {
+	client, ok := requestClient(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "subscriptions are only kept for requests with an API key or bearer token")
+	}
+	return client, ok
+}
+
+// Subscription creation handler using Chi. The new subscription's secret is
+// only ever shown in this response.
+func (s *Server) createSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	client, ok := subscriptionsClient(w, r)
+	if !ok {
+		return
+	}
+
+	var body SubscriptionRequest
+	r.Body = http.MaxBytesReader(w, r.Body, 4096)
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || (body.ZipCode == "") == !body.SavedLocations {
+		writeError(w, http.StatusBadRequest, `request body must be a JSON object with a zip_code or "saved_locations": true, such as {"zip_code": "10001", "condition": "temperature < 32", "callback_url": "https://example.com/hooks/weather"}`)
+		return
+	}
+	var zipCode string
+	if body.ZipCode != "" {
+		var err error
+		if zipCode, err = s.normalizeZipCode(body.ZipCode); err != nil {
+			writeServiceError(w, err)
+			return
+		}
+	}
+	cond, err := parseCondition(body.Condition)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// Thresholds are in the subscription's units, which default to the caller's preference
+	units := body.Units
+	if units == "" {
+		units = Options{Units: s.requestPreferences(r).Units}.units()
+	}
+	if err := validateUnits(units); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// The channel can be left out when an email address is given instead of a callback URL
+	channel := body.Channel
+	if channel == "" {
+		channel = "webhook"
+		if body.Email != "" && body.CallbackURL == "" {
+			channel = "email"
+		}
+	}
+	notify, ok := s.notifiers[channel]
+	if !ok {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid channel %q: must be one of: %s", channel, strings.Join(s.notifierNames(), ", ")))
+		return
+	}
+	if err := notify.available(); err != nil {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	sub := Subscription{
+		ZipCode:        zipCode,
+		SavedLocations: body.SavedLocations,
+		Condition:      cond.String(),
+		Units:          units,
+		Channel:        channel,
+		CreatedAt:      time.Now().UTC().Format(time.RFC3339),
+	}
+	if channel == "email" {
+		sub.Email = body.Email
+	} else {
+		sub.CallbackURL = body.CallbackURL
+	}
+	if err := notify.validate(&sub); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	existing, err := s.dataStore.Subscriptions(client.Name)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	// Those made for saved locations don't count
+	if len(slices.DeleteFunc(existing, func(sub Subscription) bool { return sub.Parent != "" })) >= maxSubscriptions {
+		writeError(w, http.StatusConflict, fmt.Sprintf("at most %d subscriptions can be kept", maxSubscriptions))
+		return
+	}
+
+	if sub.ID, err = randomHex(8); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to generate subscription ID")
+		return
+	}
+	if channel == "webhook" {
+		if sub.Secret, err = randomHex(32); err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to generate subscription secret")
+			return
+		}
+	}
+	if err := s.dataStore.AddSubscription(client.Name, sub); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if sub.SavedLocations {
+		saved, err := s.dataStore.SavedLocations(client.Name)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		for _, loc := range saved {
+			if err := s.addLocationSubscription(client.Name, sub, loc.ZipCode); err != nil {
+				writeError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+		}
+		infof("Created subscription %s for %s: %s at saved locations", sub.ID, client.Name, sub.Condition)
+	} else {
+		infof("Created subscription %s for %s: %s at %s", sub.ID, client.Name, sub.Condition, sub.ZipCode)
+	}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(sub)
+}
+
+// Subscriptions handler using Chi
+func (s *Server) subscriptionsHandler(w http.ResponseWriter, r *http.Request) {
+	client, ok := subscriptionsClient(w, r)
+	if !ok {
+		return
+	}
+	subs, err := s.dataStore.Subscriptions(client.Name)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	response := []Subscription{}
+	for _, sub := range subs {
+		sub.Secret = ""
+		response = append(response, sub)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// Subscription handler using Chi
+func (s *Server) subscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	client, ok := subscriptionsClient(w, r)
+	if !ok {
+		return
+	}
+	subs, err := s.dataStore.Subscriptions(client.Name)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	id := chi.URLParam(r, "id")
+	for _, sub := range subs {
+		if sub.ID == id {
+			sub.Secret = ""
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(sub)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "no subscription with id "+id)
+}
+
+// Delete subscription handler using Chi. Deleting a saved-locations
+// subscription deletes the subscriptions made from it too.
+func (s *Server) deleteSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	client, ok := subscriptionsClient(w, r)
+	if !ok {
+		return
+	}
+	id := chi.URLParam(r, "id")
+	deleted, err := s.dataStore.DeleteSubscription(client.Name, id)
+	switch {
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	case deleted == nil:
+		writeError(w, http.StatusNotFound, "no subscription with id "+id)
+		return
+	}
+	if deleted.SavedLocations {
+		err := s.deleteLocationSubscriptions(client.Name, func(sub Subscription) bool { return sub.Parent == id })
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	infof("Deleted subscription %s for %s", id, client.Name)
+	deleted.Secret = ""
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(deleted)
+}
+
+// addLocationSubscription adds the subscription a saved-locations subscription
+// makes for one of the client's saved zip codes
+func (s *Server) addLocationSubscription(client string, parent Subscription, zipCode string) error {
+	sub := parent
+	sub.ZipCode, sub.SavedLocations, sub.Parent = zipCode, false, parent.ID
+	sub.Triggered, sub.LastAlertAt = false, ""
+	var err error
+	if sub.ID, err = randomHex(8); err != nil {
+		return fmt.Errorf("failed to generate subscription ID: %v", err)
+	}
+	return s.dataStore.AddSubscription(client, sub)
+}
+
+// deleteLocationSubscriptions deletes the client's subscriptions made for
+// saved locations that match
+func (s *Server) deleteLocationSubscriptions(client string, match func(Subscription) bool) error {
+	subs, err := s.dataStore.Subscriptions(client)
+	if err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		if sub.Parent != "" && match(sub) {
+			if _, err := s.dataStore.DeleteSubscription(client, sub.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// subscribeSavedLocation adds a subscription at a newly saved zip code for
+// each of the client's saved-locations subscriptions
+func (s *Server) subscribeSavedLocation(client, zipCode string) error {
+	subs, err := s.dataStore.Subscriptions(client)
+	if err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		if sub.SavedLocations {
+			if err := s.addLocationSubscription(client, sub, zipCode); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// unsubscribeSavedLocation deletes the subscriptions made for a zip code the
+// client no longer has saved
+func (s *Server) unsubscribeSavedLocation(client, zipCode string) error {
+	return s.deleteLocationSubscriptions(client, func(sub Subscription) bool { return sub.ZipCode == zipCode })
+}
+
+// randomHex returns n random bytes in hex
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// startSubscriptionPoller checks every subscription's condition right away and
+// then every SUBSCRIPTION_POLL_INTERVAL
+func (s *Server) startSubscriptionPoller(c *Config) {
+	if c.SubscriptionPollInterval <= 0 {
+		return
+	}
+	go func() {
+		for {
+			s.checkSubscriptions(nil)
+			if !s.sleep(c.SubscriptionPollInterval) {
+				return
+			}
+		}
+	}()
+}
+
+// checkSubscriptions looks up the current weather for the subscriptions to
+// some zip codes, or to all of them when zipCodes is nil, once per zip code
+// and unit system, and the severe alerts for those that ask for them, once per
+// zip code, and alerts those whose condition started or stopped being met
+func (s *Server) checkSubscriptions(zipCodes []string) {
+	subs, err := s.dataStore.AllSubscriptions()
+	if err != nil {
+		warnf("Failed to read subscriptions: %v", err)
+		return
+	}
+	// Saved-locations subscriptions are checked through those made from them
+	subs = slices.DeleteFunc(subs, func(sub Subscription) bool {
+		return sub.SavedLocations || (zipCodes != nil && !slices.Contains(zipCodes, sub.ZipCode))
+	})
+
+	type lookup struct{ zipCode, units string }
+	weather := map[lookup]*WeatherResponse{}
+	severe := map[string][]SevereAlert{}
+	severeFailed := map[string]bool{}
+	for _, sub := range subs {
+		cond, err := parseCondition(sub.Condition)
+		if err != nil {
+			warnf("Skipping subscription %s: %v", sub.ID, err)
+			continue
+		}
+
+		key := lookup{sub.ZipCode, sub.Units}
+		current, looked := weather[key]
+		if !looked {
+			current, err = s.weather.CurrentWeather(context.Background(), Location{ZipCode: sub.ZipCode}, Options{Units: sub.Units})
+			if err != nil {
+				warnf("Failed to check subscriptions for %s: %v", sub.ZipCode, err)
+			}
+			weather[key] = current
+		}
+		if current == nil {
+			continue
+		}
+		readings := &conditionReadings{weather: current}
+		if cond.uses(severeAlertsField) {
+			alerts, looked := severe[sub.ZipCode]
+			if !looked && !severeFailed[sub.ZipCode] {
+				alerts, err = s.getSevereAlerts(context.Background(), Location{ZipCode: sub.ZipCode})
+				if err != nil {
+					warnf("Failed to check severe alerts for %s: %v", sub.ZipCode, err)
+					severeFailed[sub.ZipCode] = true
+				}
+				severe[sub.ZipCode] = alerts
+			}
+			if severeFailed[sub.ZipCode] {
+				continue
+			}
+			readings.severeAlerts = alerts
+		}
+
+		met := cond.matches(readings)
+		if met == sub.Triggered {
+			continue
+		}
+		now := time.Now()
+		changed, err := s.dataStore.SetSubscriptionTriggered(sub.ID, met, now)
+		if err != nil {
+			warnf("%v", err)
+			continue
+		}
+		if !changed {
+			continue // another server got there first
+		}
+
+		observed := *current
+		observed.Cache, observed.Stale = "", false
+		alert := SubscriptionAlert{
+			Event:          "cleared",
+			SubscriptionID: sub.ID,
+			ZipCode:        sub.ZipCode,
+			Condition:      sub.Condition,
+			At:             now.UTC().Format(time.RFC3339),
+			Weather:        &observed,
+			SevereAlerts:   readings.severeAlerts,
+		}
+		if met {
+			alert.Event = "triggered"
+		}
+		go s.deliverAlert(sub, alert)
+		go s.emitAlertEvent(alert)
+	}
+}
+
+// deliverAlert sends an alert over a subscription's channel, retrying failed
+// deliveries
+func (s *Server) deliverAlert(sub Subscription, alert SubscriptionAlert) {
+	notify, ok := s.notifiers[sub.channel()]
+	if !ok {
+		warnf("Failed to deliver %s alert for subscription %s: unknown channel %q", alert.Event, sub.ID, sub.Channel)
+		return
+	}
+
+	start := time.Now()
+	for retry := 0; ; retry++ {
+		retryable, err := notify.notify(sub, alert)
+		if err == nil {
+			infof("Delivered %s alert for subscription %s by %s", alert.Event, sub.ID, sub.channel())
+			return
+		}
+
+		wait := alertRetry.delay(retry)
+		if retry >= alertRetry.retries || !retryable || time.Since(start)+wait > alertRetry.deadline {
+			warnf("Failed to deliver %s alert for subscription %s: %v", alert.Event, sub.ID, err)
+			return
+		}
+		time.Sleep(wait)
+	}
+}
+
+// channel returns the subscription's channel; subscriptions kept before
+// there were channels are webhooks
+func (sub Subscription) channel() string {
+	if sub.Channel == "" {
+		return "webhook"
+	}
+	return sub.Channel
+}
+
+// notifierNames lists the channels alerts can be delivered over, sorted
+func (s *Server) notifierNames() []string {
+	names := make([]string, 0, len(s.notifiers))
+	for name := range s.notifiers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// alertHeading writes a short title for an alert sent to people
+func alertHeading(alert SubscriptionAlert) string {
+	if alert.Event == "triggered" {
+		return "Weather alert for " + alert.ZipCode
+	}
+	return "Weather alert cleared for " + alert.ZipCode
+}
+
+// alertSummary writes a sentence saying what changed
+func alertSummary(alert SubscriptionAlert) string {
+	if alert.Event == "triggered" {
+		return fmt.Sprintf("The weather at %s now meets your alert condition: %s", alert.ZipCode, alert.Condition)
+	}
+	return fmt.Sprintf("The weather at %s no longer meets your alert condition: %s", alert.ZipCode, alert.Condition)
+}
+
+// weatherLines describes the current conditions of an alert in a few lines
+func weatherLines(w *WeatherResponse) []string {
+	temp, speed := unitSymbols(w.Units)
+	return []string{
+		fmt.Sprintf("%s, %.1f%s (feels like %.1f%s)", w.Description, w.Temperature, temp, w.FeelsLike, temp),
+		fmt.Sprintf("Humidity %d%%, wind %.1f %s gusting to %.1f %s", w.Humidity, w.WindSpeed, speed, w.WindGust, speed),
+	}
+}
+
+// unitSymbols returns the temperature and wind speed units of a unit system
+func unitSymbols(units string) (temperature, speed string) {
+	switch units {
+	case unitsMetric:
+		return "°C", "m/s"
+	case unitsStandard:
+		return "K", "m/s"
+	default:
+		return "°F", "mph"
+	}
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// newTestServer creates a server with mock data and an empty in-memory store,
+// closed at the end of the test
+func newTestServer(t *testing.T, opts ...Option) *Server {
+	t.Helper()
+	s, err := NewServer(append([]Option{WithSettings(map[string]string{"MOCK_MODE": "true", "STORE_BACKEND": "memory"})}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+	return s
+}
+
+// serveAs sends a request to a handler as a client, and checks its status
+func serveAs(t *testing.T, client string, handler http.HandlerFunc, method, path, body string, params map[string]string, status int) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	route := chi.NewRouteContext()
+	for name, value := range params {
+		route.URLParams.Add(name, value)
+	}
+	ctx := context.WithValue(r.Context(), chi.RouteCtxKey, route)
+	ctx = context.WithValue(ctx, apiClientContextKey{}, apiClient{Name: client})
+	w := httptest.NewRecorder()
+	handler(w, r.WithContext(ctx))
+	if w.Code != status {
+		t.Fatalf("%s %s returned %d, want %d: %s", method, path, w.Code, status, w.Body)
+	}
+	return w
+}
+
+// subscribedZipCodes returns the zip codes of a client's subscriptions made from parent
+func subscribedZipCodes(t *testing.T, s *Server, client, parent string) []string {
+	t.Helper()
+	subs, err := s.dataStore.Subscriptions(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var zipCodes []string
+	for _, sub := range subs {
+		if sub.Parent == parent {
+			zipCodes = append(zipCodes, sub.ZipCode)
+		}
+	}
+	slices.Sort(zipCodes)
+	return zipCodes
+}
+
+func TestSavedLocationSubscriptions(t *testing.T) {
+	s := newTestServer(t)
+	serveAs(t, "app", s.saveLocationHandler, "POST", "/me/locations", `{"zip_code": "10001"}`, nil, http.StatusCreated)
+
+	w := serveAs(t, "app", s.createSubscriptionHandler, "POST", "/subscriptions",
+		`{"saved_locations": true, "condition": "temperature < 32", "callback_url": "https://example.com/hook"}`, nil, http.StatusCreated)
+	var parent Subscription
+	if err := json.NewDecoder(w.Body).Decode(&parent); err != nil {
+		t.Fatal(err)
+	}
+	if !parent.SavedLocations || parent.ZipCode != "" {
+		t.Fatalf("created %+v, want a saved-locations subscription", parent)
+	}
+	if got := subscribedZipCodes(t, s, "app", parent.ID); !slices.Equal(got, []string{"10001"}) {
+		t.Errorf("subscribed to %v for the saved location, want [10001]", got)
+	}
+
+	// Saving and deleting locations keeps the subscriptions in step
+	serveAs(t, "app", s.saveLocationHandler, "POST", "/me/locations", `{"zip_code": "94102"}`, nil, http.StatusCreated)
+	serveAs(t, "app", s.saveLocationHandler, "POST", "/me/locations", `{"zip_code": "94102", "name": "Home"}`, nil, http.StatusOK)
+	if got := subscribedZipCodes(t, s, "app", parent.ID); !slices.Equal(got, []string{"10001", "94102"}) {
+		t.Errorf("subscribed to %v after saving 94102, want [10001 94102]", got)
+	}
+	serveAs(t, "app", s.deleteSavedLocationHandler, "DELETE", "/me/locations/10001", "", map[string]string{"zip": "10001"}, http.StatusOK)
+	if got := subscribedZipCodes(t, s, "app", parent.ID); !slices.Equal(got, []string{"94102"}) {
+		t.Errorf("subscribed to %v after deleting 10001, want [94102]", got)
+	}
+
+	// Other clients' locations aren't followed
+	serveAs(t, "other", s.saveLocationHandler, "POST", "/me/locations", `{"zip_code": "60601"}`, nil, http.StatusCreated)
+	if got := subscribedZipCodes(t, s, "other", parent.ID); len(got) != 0 {
+		t.Errorf("another client's location was subscribed to: %v", got)
+	}
+
+	serveAs(t, "app", s.deleteSubscriptionHandler, "DELETE", "/subscriptions/"+parent.ID, "", map[string]string{"id": parent.ID}, http.StatusOK)
+	if subs, _ := s.dataStore.Subscriptions("app"); len(subs) != 0 {
+		t.Errorf("%d subscriptions left after deleting the saved-locations subscription", len(subs))
+	}
+}
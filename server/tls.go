@@ -0,0 +1,86 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// Directory Let's Encrypt certificates are cached in when TLS_AUTOCERT_CACHE_DIR isn't set
+const defaultAutocertCacheDir = "certs"
+
+// serverTLS describes how the server terminates TLS: with a certificate and
+// key from disk, or with certificates obtained from Let's Encrypt
+type serverTLS struct {
+	certFile string
+	keyFile  string
+	domains  []string
+	autocert *autocert.Manager
+}
+
+// tlsFromEnv reads the TLS settings. It returns nil when TLS is off, which is
+// the default: set TLS_CERT_FILE and TLS_KEY_FILE to serve a certificate from
+// disk, or TLS_AUTOCERT_DOMAINS to obtain certificates from Let's Encrypt.
+func tlsFromEnv(env *environment) (*serverTLS, error) {
+	certFile, keyFile := env.Getenv("TLS_CERT_FILE"), env.Getenv("TLS_KEY_FILE")
+	var domains []string
+	for _, domain := range strings.Split(env.Getenv("TLS_AUTOCERT_DOMAINS"), ",") {
+		if domain = strings.TrimSpace(domain); domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+
+	switch {
+	case (certFile != "" || keyFile != "") && len(domains) > 0:
+		return nil, fmt.Errorf("TLS_CERT_FILE/TLS_KEY_FILE and TLS_AUTOCERT_DOMAINS can't be used together")
+	case certFile != "" || keyFile != "":
+		if certFile == "" || keyFile == "" {
+			return nil, fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+		}
+		if _, err := tls.LoadX509KeyPair(certFile, keyFile); err != nil {
+			return nil, fmt.Errorf("invalid TLS certificate: %v", err)
+		}
+		return &serverTLS{certFile: certFile, keyFile: keyFile}, nil
+	case len(domains) > 0:
+		cacheDir := env.Getenv("TLS_AUTOCERT_CACHE_DIR")
+		if cacheDir == "" {
+			cacheDir = defaultAutocertCacheDir
+		}
+		return &serverTLS{domains: domains, autocert: &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(domains...),
+			Cache:      autocert.DirCache(cacheDir),
+			Email:      env.Getenv("TLS_AUTOCERT_EMAIL"),
+		}}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// loadCertPool reads the PEM certificates in a file
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%s has no PEM certificates", path)
+	}
+	return pool, nil
+}
+
+// config returns the server's TLS configuration
+func (t *serverTLS) config() *tls.Config {
+	if t.autocert != nil {
+		// Also answers Let's Encrypt's TLS-ALPN-01 challenges on the TLS port
+		config := t.autocert.TLSConfig()
+		config.MinVersion = tls.VersionTLS12
+		return config
+	}
+	return &tls.Config{MinVersion: tls.VersionTLS12}
+}
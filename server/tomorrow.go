@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Tomorrow.io API base URL
+const tomorrowBaseURL = "https://api.tomorrow.io"
+
+// Conversion factors for Tomorrow.io imperial values
+const (
+	metersPerMile       = 1609.344
+	hectopascalsPerInHg = 33.8639
+)
+
+// Tomorrow.io realtime weather response structure (simplified). Values are in
+// °F, mph, miles, and inHg for imperial units; °C, m/s, km, and hPa for metric.
+type TomorrowAPIResponse struct {
+	Data struct {
+		Time   string `json:"time"`
+		Values struct {
+			Temperature          float64 `json:"temperature"`
+			TemperatureApparent  float64 `json:"temperatureApparent"`
+			DewPoint             float64 `json:"dewPoint"`
+			Humidity             float64 `json:"humidity"`
+			WeatherCode          int     `json:"weatherCode"`
+			PressureSeaLevel     float64 `json:"pressureSeaLevel"`
+			PressureSurfaceLevel float64 `json:"pressureSurfaceLevel"`
+			Visibility           float64 `json:"visibility"`
+			CloudCover           float64 `json:"cloudCover"`
+			WindSpeed            float64 `json:"windSpeed"`
+			WindDirection        float64 `json:"windDirection"`
+			WindGust             float64 `json:"windGust"`
+		} `json:"values"`
+	} `json:"data"`
+	Location struct {
+		Name string `json:"name"`
+	} `json:"location"`
+}
+
+// Descriptions and OpenWeatherMap icon codes for Tomorrow.io weather codes
+var tomorrowWeatherCodes = map[int]weatherCondition{
+	1000: {"clear", "01"},
+	1100: {"mostly clear", "02"},
+	1101: {"partly cloudy", "03"},
+	1102: {"mostly cloudy", "04"},
+	1001: {"cloudy", "04"},
+	2000: {"fog", "50"},
+	2100: {"light fog", "50"},
+	4000: {"drizzle", "09"},
+	4001: {"rain", "10"},
+	4200: {"light rain", "10"},
+	4201: {"heavy rain", "10"},
+	5000: {"snow", "13"},
+	5001: {"flurries", "13"},
+	5100: {"light snow", "13"},
+	5101: {"heavy snow", "13"},
+	6000: {"freezing drizzle", "13"},
+	6001: {"freezing rain", "13"},
+	6200: {"light freezing rain", "13"},
+	6201: {"heavy freezing rain", "13"},
+	7000: {"ice pellets", "13"},
+	7101: {"heavy ice pellets", "13"},
+	7102: {"light ice pellets", "13"},
+	8000: {"thunderstorm", "11"},
+}
+
+// tomorrowProvider serves current conditions from the Tomorrow.io API
+type tomorrowProvider struct {
+	s      *Server
+	apiKey string
+}
+
+func (p tomorrowProvider) Name() string {
+	return "tomorrow"
+}
+
+func (p tomorrowProvider) Current(ctx context.Context, loc Location, opts Options) (*WeatherResponse, error) {
+	// Tomorrow.io has imperial and metric units; Kelvin is derived from Celsius
+	units := "metric"
+	if opts.units() == unitsImperial {
+		units = "imperial"
+	}
+
+	params := url.Values{}
+	params.Add("location", tomorrowLocation(loc))
+	params.Add("units", units)
+	params.Add("apikey", p.apiKey)
+
+	var apiResp TomorrowAPIResponse
+	fullURL := fmt.Sprintf("%s/v4/weather/realtime?%s", tomorrowBaseURL, params.Encode())
+	if err := p.s.fetchJSON(ctx, fullURL, "Tomorrow.io", &apiResp); err != nil {
+		return nil, err
+	}
+	values := apiResp.Data.Values
+
+	// Normalize pressure to hPa and visibility to meters
+	pressure := values.PressureSeaLevel
+	if pressure == 0 {
+		pressure = values.PressureSurfaceLevel
+	}
+	visibility := values.Visibility * 1000
+	if units == "imperial" {
+		pressure *= hectopascalsPerInHg
+		visibility = values.Visibility * metersPerMile
+	}
+
+	temperature, feelsLike, dew := values.Temperature, values.TemperatureApparent, values.DewPoint
+	if opts.units() == unitsStandard {
+		temperature, feelsLike, dew = round1(temperature+273.15), round1(feelsLike+273.15), round1(dew+273.15)
+	}
+
+	// Tomorrow.io names places "City, County, State, Country"; keep the city.
+	// Realtime data has no time zone or day/night flag, so local time is UTC
+	// and the daytime icon is used.
+	now := time.Now().UTC()
+	observedAt := now
+	if parsed, err := time.Parse(time.RFC3339, apiResp.Data.Time); err == nil {
+		observedAt = parsed.UTC()
+	}
+
+	description, icon := "clear", defaultIcon
+	if weather, ok := tomorrowWeatherCodes[values.WeatherCode]; ok {
+		description, icon = weather.description, weather.icon+"d"
+	}
+
+	return &WeatherResponse{
+		ZipCode:       loc.ZipCode,
+		Location:      strings.TrimSpace(strings.Split(apiResp.Location.Name, ",")[0]),
+		Units:         opts.units(),
+		Temperature:   temperature,
+		FeelsLike:     feelsLike,
+		DewPoint:      dew,
+		Description:   description,
+		Icon:          icon,
+		IconURL:       p.s.iconURL(icon),
+		Humidity:      int(math.Round(values.Humidity)),
+		Pressure:      int(math.Round(pressure)),
+		Visibility:    int(math.Round(visibility)),
+		CloudCover:    int(math.Round(values.CloudCover)),
+		WindSpeed:     values.WindSpeed,
+		WindDirection: int(math.Round(values.WindDirection)),
+		WindGust:      values.WindGust,
+		ObservedAt:    observedAt.Format(time.RFC3339),
+		LocalTime:     now.Format(time.RFC3339),
+	}, nil
+}
+
+// tomorrowLocation builds the location parameter, which accepts "lat,lon",
+// "postal code country", or a place name
+func tomorrowLocation(loc Location) string {
+	switch {
+	case loc.Coords != nil:
+		return fmt.Sprintf("%.4f,%.4f", loc.Coords.Lat, loc.Coords.Lon)
+	case loc.City != "":
+		return loc.City + ", " + loc.country()
+	case loc.country() == defaultCountry:
+		return loc.ZipCode[:5] + " " + defaultCountry
+	default:
+		return loc.ZipCode + " " + loc.country()
+	}
+}
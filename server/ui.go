@@ -0,0 +1,35 @@
+package server
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// The /ui dashboard's pages, scripts, and styles
+//
+//go:embed ui
+var uiFiles embed.FS
+
+// uiHandler serves the dashboard at /ui/. It's static, and calls the JSON API
+// from the browser with the API key entered on the page, so it's served
+// without client authentication.
+func (s *Server) uiHandler() http.Handler {
+	files, err := fs.Sub(uiFiles, "ui")
+	if err != nil {
+		panic(err)
+	}
+	fileServer := http.StripPrefix("/ui", http.FileServerFS(files))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The file server sets each file's type, so drop the JSON default
+		w.Header().Del("Content-Type")
+		// By default, scripts, styles, and API calls only from this server
+		if csp := s.config().UIContentSecurityPolicy; csp != "" {
+			w.Header().Set("Content-Security-Policy", csp)
+		} else {
+			w.Header().Del("Content-Security-Policy")
+		}
+		fileServer.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,134 @@
+// Dashboard for the weather API: looks up a zip code's current conditions and
+// 5-day forecast with the same JSON endpoints API clients use.
+"use strict";
+
+const $ = (id) => document.getElementById(id);
+
+const unitLabels = {
+  imperial: { temperature: "°F", speed: "mph" },
+  metric: { temperature: "°C", speed: "m/s" },
+};
+
+// The API key is kept in the browser, so it needn't be entered on every visit
+const apiKey = $("api-key");
+apiKey.value = localStorage.getItem("apiKey") || "";
+apiKey.addEventListener("change", () => localStorage.setItem("apiKey", apiKey.value));
+
+// getJSON calls an API endpoint, throwing the API's error message on failure
+async function getJSON(path, params) {
+  const headers = { Accept: "application/json" };
+  if (apiKey.value) {
+    headers["X-API-Key"] = apiKey.value;
+  }
+  const response = await fetch(path + "?" + new URLSearchParams(params), { headers });
+  const body = await response.json().catch(() => ({}));
+  if (!response.ok) {
+    throw new Error(body.error || `${path} returned ${response.status}`);
+  }
+  return body;
+}
+
+function showError(message) {
+  $("error").textContent = message;
+  $("error").hidden = !message;
+}
+
+function showCurrent(weather, labels) {
+  $("location").textContent = weather.location;
+  $("description").textContent = weather.description;
+  $("icon").src = weather.icon_url;
+  $("temperature").textContent = `${Math.round(weather.temperature)}${labels.temperature}`;
+  $("feels-like").textContent = `${Math.round(weather.feels_like)}${labels.temperature}`;
+  $("humidity").textContent = `${weather.humidity}%`;
+  $("wind").textContent = `${weather.wind_speed} ${labels.speed}`;
+  $("pressure").textContent = `${weather.pressure} hPa`;
+  const observed = new Date(weather.observed_at);
+  $("observed").textContent = `Observed ${observed.toLocaleString()} · ${weather.source}`;
+  $("current").hidden = false;
+}
+
+// svgElement creates an SVG element with the given attributes
+function svgElement(name, attributes, text) {
+  const element = document.createElementNS("http://www.w3.org/2000/svg", name);
+  for (const [key, value] of Object.entries(attributes)) {
+    element.setAttribute(key, value);
+  }
+  if (text !== undefined) {
+    element.textContent = text;
+  }
+  return element;
+}
+
+// drawChart plots each day's high and low as a line chart
+function drawChart(days, labels) {
+  const chart = $("chart");
+  chart.replaceChildren();
+
+  const width = 600, height = 240, left = 30, right = 30, top = 30, bottom = 40;
+  const temperatures = days.flatMap((day) => [day.high, day.low]);
+  const min = Math.min(...temperatures), max = Math.max(...temperatures);
+  const range = max - min || 1;
+  const x = (i) => left + (i * (width - left - right)) / Math.max(days.length - 1, 1);
+  const y = (t) => top + ((max - t) * (height - top - bottom)) / range;
+
+  for (const [series, key] of [["high", "high"], ["low", "low"]]) {
+    const points = days.map((day, i) => `${x(i)},${y(day[key])}`).join(" ");
+    chart.append(svgElement("polyline", { class: series, points }));
+    days.forEach((day, i) => {
+      chart.append(svgElement("circle", { class: series, cx: x(i), cy: y(day[key]), r: 4 }));
+      const offset = series === "high" ? -10 : 20;
+      chart.append(svgElement("text", { class: series, x: x(i), y: y(day[key]) + offset, "text-anchor": "middle" },
+        `${Math.round(day[key])}${labels.temperature}`));
+    });
+  }
+  days.forEach((day, i) => {
+    const weekday = new Date(day.date + "T12:00:00").toLocaleDateString(undefined, { weekday: "short" });
+    chart.append(svgElement("text", { class: "axis", x: x(i), y: height - 10, "text-anchor": "middle" }, weekday));
+  });
+}
+
+function showForecast(forecast, labels) {
+  drawChart(forecast.days, labels);
+  $("days").replaceChildren(...forecast.days.map((day) => {
+    const item = document.createElement("li");
+    const icon = document.createElement("img");
+    icon.src = day.icon_url;
+    icon.alt = day.description;
+    const chance = document.createElement("div");
+    chance.textContent = `${day.precipitation_chance}% precip.`;
+    item.append(icon, chance);
+    return item;
+  }));
+  $("forecast").hidden = false;
+}
+
+async function search(zip, units) {
+  const labels = unitLabels[units];
+  showError("");
+  try {
+    const [weather, forecast] = await Promise.all([
+      getJSON("/weather", { zip_code: zip, units }),
+      getJSON("/forecast", { zip_code: zip, units }),
+    ]);
+    showCurrent(weather, labels);
+    showForecast(forecast, labels);
+    history.replaceState(null, "", `?zip=${encodeURIComponent(zip)}&units=${units}`);
+  } catch (error) {
+    $("current").hidden = true;
+    $("forecast").hidden = true;
+    showError(error.message);
+  }
+}
+
+$("search").addEventListener("submit", (event) => {
+  event.preventDefault();
+  search($("zip").value.trim(), $("units").value);
+});
+
+// Look up the zip code in the page's URL, so searches can be bookmarked
+const params = new URLSearchParams(location.search);
+if (params.get("zip")) {
+  $("zip").value = params.get("zip");
+  $("units").value = unitLabels[params.get("units")] ? params.get("units") : "imperial";
+  search($("zip").value, $("units").value);
+}
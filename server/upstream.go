@@ -0,0 +1,153 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Directory fixtures are recorded to and replayed from when FIXTURES_DIR isn't set
+const defaultFixturesDir = "fixtures"
+
+// Query parameters holding API keys, which are left out of fixtures
+var secretParams = []string{"appid", "key", "apikey", "token"}
+
+// Per-request timeout for upstream API calls when UPSTREAM_TIMEOUT isn't set
+const defaultUpstreamTimeout = 10 * time.Second
+
+// newUpstreamTransport returns the pooled, TLS 1.2+ transport used for live upstream calls
+func newUpstreamTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	transport.MaxIdleConns = 100
+	transport.MaxIdleConnsPerHost = 10
+	transport.IdleConnTimeout = 90 * time.Second
+	transport.TLSHandshakeTimeout = 5 * time.Second
+	return transport
+}
+
+// setupUpstream configures the upstream client's UPSTREAM_TIMEOUT and
+// UPSTREAM_MODE: "live", "record", or "replay". In record mode upstream responses
+// are saved to FIXTURES_DIR; in replay mode they are served from there without
+// network access.
+func (s *Server) setupUpstream(c *Config) {
+	s.upstreamClient.Timeout = c.UpstreamTimeout
+
+	// Calls refused by a daily budget never reach the dependency, so they
+	// aren't counted against its health
+	switch c.UpstreamMode {
+	case "record":
+		s.upstreamClient.Transport = &recordingTransport{dir: c.FixturesDir, next: &quotaTransport{s: s, next: &healthTransport{s: s, next: newUpstreamTransport()}}}
+	case "replay":
+		s.upstreamClient.Transport = &healthTransport{s: s, next: &replayTransport{dir: c.FixturesDir}}
+	default:
+		s.upstreamClient.Transport = &quotaTransport{s: s, next: &healthTransport{s: s, next: newUpstreamTransport()}}
+	}
+}
+
+// fixture is a recorded upstream response
+type fixture struct {
+	Method      string `json:"method"`
+	URL         string `json:"url"`
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type"`
+	Body        string `json:"body"`
+}
+
+// redactedURL returns the request URL without API keys
+func redactedURL(u *url.URL) string {
+	redacted := *u
+	query := redacted.Query()
+	for _, param := range secretParams {
+		query.Del(param)
+	}
+	redacted.RawQuery = query.Encode()
+	return redacted.String()
+}
+
+// fixturePath returns where the fixture for a request is stored: one file per
+// method and redacted URL, grouped by host
+func fixturePath(dir string, req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.Method + " " + redactedURL(req.URL)))
+	return filepath.Join(dir, req.URL.Host, hex.EncodeToString(sum[:8])+".json")
+}
+
+// recordingTransport passes requests upstream and saves each response as a fixture
+type recordingTransport struct {
+	dir  string
+	next http.RoundTripper
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	data, err := json.MarshalIndent(fixture{
+		Method:      req.Method,
+		URL:         redactedURL(req.URL),
+		Status:      resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        string(body),
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	path := fixturePath(t.dir, req)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to record fixture: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return nil, fmt.Errorf("failed to record fixture: %v", err)
+	}
+	return resp, nil
+}
+
+// replayTransport serves recorded fixtures and never touches the network
+type replayTransport struct {
+	dir string
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	data, err := os.ReadFile(fixturePath(t.dir, req))
+	if err != nil {
+		return nil, fmt.Errorf("no recorded fixture for %s %s", req.Method, redactedURL(req.URL))
+	}
+
+	var recorded fixture
+	if err := json.Unmarshal(data, &recorded); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture for %s %s: %v", req.Method, redactedURL(req.URL), err)
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", recorded.ContentType)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.Status, http.StatusText(recorded.Status)),
+		StatusCode:    recorded.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(recorded.Body))),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,356 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Days of usage kept, including today
+const usageRetentionDays = 31
+
+// How often usage is saved to USAGE_FILE
+const usageSaveInterval = time.Minute
+
+// clientUsage is one client's requests on one day
+type clientUsage struct {
+	Tier     string `json:"tier"`
+	Requests int64  `json:"requests"`
+}
+
+// usageTracker counts the requests each authenticated client makes per UTC day
+type usageTracker struct {
+	mu      sync.Mutex
+	days    map[string]map[string]*clientUsage // by date, then client name
+	unsaved map[string]map[string]*clientUsage // requests since last saved, in the same layout
+}
+
+// addUsage adds the requests in from to into, taking the tiers from from
+func addUsage(into, from map[string]map[string]*clientUsage) {
+	for day, clients := range from {
+		if into[day] == nil {
+			into[day] = map[string]*clientUsage{}
+		}
+		for name, usage := range clients {
+			total, ok := into[day][name]
+			if !ok {
+				total = &clientUsage{}
+				into[day][name] = total
+			}
+			total.Tier = usage.Tier
+			total.Requests += usage.Requests
+		}
+	}
+}
+
+// take records a request by client, refusing it once the client has made
+// quota requests today. A quota of 0 is unlimited.
+func (u *usageTracker) take(client apiClient, quota int64) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	day := today()
+	clients, ok := u.days[day]
+	if !ok {
+		clients = map[string]*clientUsage{}
+		u.days[day] = clients
+		u.prune()
+	}
+	usage, ok := clients[client.Name]
+	if !ok {
+		usage = &clientUsage{}
+		clients[client.Name] = usage
+	}
+	if quota > 0 && usage.Requests >= quota {
+		return false
+	}
+	usage.Tier = client.Tier
+	usage.Requests++
+	addUsage(u.unsaved, map[string]map[string]*clientUsage{day: {client.Name: {Tier: client.Tier, Requests: 1}}})
+	return true
+}
+
+// oldestUsageDay is the first date whose usage is kept
+func oldestUsageDay() string {
+	return time.Now().UTC().AddDate(0, 0, 1-usageRetentionDays).Format("2006-01-02")
+}
+
+// prune drops the days past usageRetentionDays
+func (u *usageTracker) prune() {
+	oldest := oldestUsageDay()
+	for day := range u.days {
+		if day < oldest {
+			delete(u.days, day)
+		}
+	}
+}
+
+// day returns a copy of every client's usage on a date
+func (u *usageTracker) day(date string) map[string]clientUsage {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	usage := map[string]clientUsage{}
+	for name, client := range u.days[date] {
+		usage[name] = *client
+	}
+	return usage
+}
+
+// history returns one client's requests per day, newest first
+func (u *usageTracker) history(name string) []DailyUsage {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	history := []DailyUsage{}
+	for day, clients := range u.days {
+		if usage, ok := clients[name]; ok {
+			history = append(history, DailyUsage{Date: day, Requests: usage.Requests})
+		}
+	}
+	sort.Slice(history, func(i, j int) bool { return history[i].Date > history[j].Date })
+	return history
+}
+
+// usageFile saves usage to a JSON file, for USAGE_FILE
+type usageFile string
+
+// LoadUsage reads the usage saved to the file, if it exists
+func (path usageFile) LoadUsage() (map[string]map[string]*clientUsage, error) {
+	data, err := os.ReadFile(string(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read USAGE_FILE: %v", err)
+	}
+
+	days := map[string]map[string]*clientUsage{}
+	if err := json.Unmarshal(data, &days); err != nil {
+		return nil, fmt.Errorf("failed to parse USAGE_FILE %s: %v", path, err)
+	}
+	return days, nil
+}
+
+// SaveUsage rewrites the file; days before oldest were already pruned
+func (path usageFile) SaveUsage(days map[string]map[string]*clientUsage, oldest string) error {
+	data, err := json.Marshal(days)
+	if err != nil {
+		return fmt.Errorf("failed to encode usage: %v", err)
+	}
+	if err := writeFileAtomic(string(path), data); err != nil {
+		return fmt.Errorf("failed to save usage to %s: %v", path, err)
+	}
+	return nil
+}
+
+// load reads the usage saved to store
+func (u *usageTracker) load(store usageStore) error {
+	days, err := store.LoadUsage()
+	if err != nil {
+		return err
+	}
+	if days == nil {
+		return nil
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.days = days
+	u.prune()
+	return nil
+}
+
+// save writes usage to store if it changed since it was last saved. Stores
+// shared with other servers get the requests made since then added to their
+// counts, and the tracker takes on the combined counts.
+func (u *usageTracker) save(store usageStore) error {
+	u.mu.Lock()
+	if len(u.unsaved) == 0 {
+		u.mu.Unlock()
+		return nil
+	}
+	unsaved := u.unsaved
+	u.unsaved = map[string]map[string]*clientUsage{}
+	days := map[string]map[string]*clientUsage{}
+	addUsage(days, u.days)
+	u.mu.Unlock()
+
+	var err error
+	if shared, ok := store.(sharedUsageStore); ok {
+		days, err = shared.AddUsage(unsaved, oldestUsageDay())
+	} else {
+		err = store.SaveUsage(days, oldestUsageDay())
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if err != nil {
+		addUsage(u.unsaved, unsaved)
+		return err
+	}
+	if _, ok := store.(sharedUsageStore); ok {
+		// Requests taken while saving aren't in the combined counts yet
+		addUsage(days, u.unsaved)
+		u.days = days
+		u.prune()
+	}
+	return nil
+}
+
+// usagePersistence returns where usage is saved: the store, if it keeps
+// usage, or else USAGE_FILE. Without either, usage is only kept in memory.
+func (s *Server) usagePersistence(c *Config) (usageStore, bool) {
+	if store, ok := s.dataStore.(usageStore); ok {
+		return store, true
+	}
+	if c.UsageFile != "" {
+		return usageFile(c.UsageFile), true
+	}
+	return nil, false
+}
+
+// startUsagePersistence loads the saved usage and saves it every
+// usageSaveInterval from then on. It runs after setupStore.
+func (s *Server) startUsagePersistence(c *Config) error {
+	store, ok := s.usagePersistence(c)
+	if !ok {
+		return nil
+	}
+	if err := s.requestUsage.load(store); err != nil {
+		return err
+	}
+
+	go func() {
+		for s.sleep(usageSaveInterval) {
+			if err := s.requestUsage.save(store); err != nil {
+				warnf("%v", err)
+			}
+		}
+	}()
+	return nil
+}
+
+// saveUsage saves usage, if it is persisted, before the server exits
+func (s *Server) saveUsage(c *Config) {
+	store, ok := s.usagePersistence(c)
+	if !ok {
+		return
+	}
+	if err := s.requestUsage.save(store); err != nil {
+		warnf("%v", err)
+	}
+}
+
+// trackUsage counts requests per authenticated client, rejecting those past
+// the DAILY_QUOTA_<TIER> of the client's tier until quotas reset at UTC midnight
+func (s *Server) trackUsage(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client, ok := requestClient(r.Context())
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		quota := s.config().DailyQuotas[client.Tier]
+		if !s.requestUsage.take(client, quota) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(untilQuotaReset().Seconds()))))
+			writeError(w, http.StatusTooManyRequests, fmt.Sprintf("daily quota of %d requests is used up", quota))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// DailyUsage reports a client's requests on one day
+type DailyUsage struct {
+	Date     string `json:"date"`
+	Requests int64  `json:"requests"`
+}
+
+// ClientUsageReport reports one client's requests on a day, against its tier's quota
+type ClientUsageReport struct {
+	Client    string `json:"client"`
+	Tier      string `json:"tier"`
+	Requests  int64  `json:"requests"`
+	Quota     int64  `json:"quota,omitempty"`
+	Remaining *int64 `json:"remaining,omitempty"`
+}
+
+// report describes usage against the current quota of the client's tier
+func (u clientUsage) report(name string, c *Config) ClientUsageReport {
+	report := ClientUsageReport{Client: name, Tier: u.Tier, Requests: u.Requests}
+	if quota := c.DailyQuotas[u.Tier]; quota > 0 {
+		remaining := max(quota-u.Requests, 0)
+		report.Quota, report.Remaining = quota, &remaining
+	}
+	return report
+}
+
+// UsageResponse represents the per-client usage we'll return from /admin/usage
+type UsageResponse struct {
+	Date     string              `json:"date"`
+	ResetsAt string              `json:"resets_at"`
+	Clients  []ClientUsageReport `json:"clients"`
+}
+
+// Usage handler using Chi
+func (s *Server) usageHandler(w http.ResponseWriter, r *http.Request) {
+	date := r.URL.Query().Get("date")
+	if date == "" {
+		date = today()
+	} else if _, err := time.Parse("2006-01-02", date); err != nil {
+		writeError(w, http.StatusBadRequest, "date must be in YYYY-MM-DD format")
+		return
+	}
+
+	response := UsageResponse{Date: date, ResetsAt: quotaResetTime().Format(time.RFC3339), Clients: []ClientUsageReport{}}
+	for name, usage := range s.requestUsage.day(date) {
+		response.Clients = append(response.Clients, usage.report(name, s.config()))
+	}
+	sort.Slice(response.Clients, func(i, j int) bool {
+		return response.Clients[i].Client < response.Clients[j].Client
+	})
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// MyUsageResponse represents the caller's own usage we'll return from /me/usage
+type MyUsageResponse struct {
+	ClientUsageReport
+	Date     string       `json:"date"`
+	ResetsAt string       `json:"resets_at"`
+	History  []DailyUsage `json:"history"`
+}
+
+// Caller usage handler using Chi
+func (s *Server) myUsageHandler(w http.ResponseWriter, r *http.Request) {
+	client, ok := requestClient(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "usage is only tracked for requests with an API key or bearer token")
+		return
+	}
+
+	usage, ok := s.requestUsage.day(today())[client.Name]
+	if !ok {
+		usage = clientUsage{Tier: client.Tier}
+	}
+	usage.Tier = client.Tier
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(MyUsageResponse{
+		ClientUsageReport: usage.report(client.Name, s.config()),
+		Date:              today(),
+		ResetsAt:          quotaResetTime().Format(time.RFC3339),
+		History:           s.requestUsage.history(client.Name),
+	})
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// UVResponse represents the UV index data we'll return
+type UVResponse struct {
+	ZipCode  string  `json:"zip_code"`
+	Location string  `json:"location"`
+	UVIndex  float64 `json:"uv_index"`
+	Risk     string  `json:"risk"`
+}
+
+// OpenWeatherMap One Call API response structure (simplified)
+type OpenWeatherOneCallAPIResponse struct {
+	Current struct {
+		UVI float64 `json:"uvi"`
+	} `json:"current"`
+}
+
+// uvRisk returns the WHO exposure category for a UV index
+func uvRisk(index float64) string {
+	switch {
+	case index < 3:
+		return "Low"
+	case index < 6:
+		return "Moderate"
+	case index < 8:
+		return "High"
+	case index < 11:
+		return "Very High"
+	default:
+		return "Extreme"
+	}
+}
+
+func (s *Server) getUV(ctx context.Context, loc Location) (*UVResponse, error) {
+	// Get API key from environment variable
+	apiKey := s.config().OpenWeatherAPIKey
+	if s.config().MockMode {
+		// In mock mode, return mock data instead of calling the API
+		return &UVResponse{
+			ZipCode:  loc.ZipCode,
+			Location: s.mockName(loc),
+			UVIndex:  5.4,
+			Risk:     uvRisk(5.4),
+		}, nil
+	}
+
+	// The One Call API only accepts coordinates
+	coords, err := s.geocodeZipCode(ctx, loc, apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Add("lat", fmt.Sprintf("%f", coords.Lat))
+	params.Add("lon", fmt.Sprintf("%f", coords.Lon))
+	params.Add("exclude", "minutely,hourly,daily,alerts") // Only current conditions are needed
+	params.Add("appid", apiKey)
+
+	var apiResp OpenWeatherOneCallAPIResponse
+	if err := s.fetchOpenWeather(ctx, "/data/3.0/onecall", params, &apiResp); err != nil {
+		return nil, err
+	}
+
+	return &UVResponse{
+		ZipCode:  loc.ZipCode,
+		Location: coords.Name,
+		UVIndex:  apiResp.Current.UVI,
+		Risk:     uvRisk(apiResp.Current.UVI),
+	}, nil
+}
+
+// UV index handler using Chi
+func (s *Server) uvHandler(w http.ResponseWriter, r *http.Request) {
+	// Get zip code and country from query parameters
+	loc, ok := s.zipCodeFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	// Get UV index data
+	uv, err := s.getUV(r.Context(), loc)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	// Return UV index data as JSON
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(uv)
+}
@@ -0,0 +1,290 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// The unversioned /weather route is deprecated in favor of /api/v2/weather.
+// Its Deprecation header (RFC 9745) gives when that happened, and its Sunset
+// header (RFC 8594) UNVERSIONED_SUNSET, when it will be removed.
+var (
+	unversionedDeprecated    = time.Date(2026, time.October, 14, 0, 0, 0, 0, time.UTC)
+	defaultUnversionedSunset = time.Date(2027, time.April, 14, 0, 0, 0, 0, time.UTC)
+)
+
+// WeatherV2 is the current weather in the /api/v2 schema: quantities grouped
+// with their units, timestamps in RFC 3339, and where the data came from
+type WeatherV2 struct {
+	Location    LocationV2    `json:"location"`
+	Temperature TemperatureV2 `json:"temperature"`
+	Wind        WindV2        `json:"wind"`
+	Conditions  ConditionsV2  `json:"conditions"`
+	Humidity    int           `json:"humidity"`    // percent
+	Pressure    int           `json:"pressure"`    // hPa
+	Visibility  int           `json:"visibility"`  // meters
+	CloudCover  int           `json:"cloud_cover"` // percent
+	ObservedAt  string        `json:"observed_at"`
+	Provenance  ProvenanceV2  `json:"provenance"`
+}
+
+// LocationV2 is where a response is for
+type LocationV2 struct {
+	ZipCode   string `json:"zip_code,omitempty"`
+	Name      string `json:"name"`
+	Country   string `json:"country"`
+	LocalTime string `json:"local_time,omitempty"` // with the location's UTC offset
+}
+
+// TemperatureV2 is a set of temperatures in one unit: celsius, fahrenheit, or kelvin
+type TemperatureV2 struct {
+	Current   *float64 `json:"current,omitempty"`
+	FeelsLike *float64 `json:"feels_like,omitempty"`
+	DewPoint  *float64 `json:"dew_point,omitempty"`
+	High      *float64 `json:"high,omitempty"`
+	Low       *float64 `json:"low,omitempty"`
+	Unit      string   `json:"unit"`
+}
+
+// WindV2 is wind speed and direction
+type WindV2 struct {
+	Speed     float64  `json:"speed"`
+	Gust      *float64 `json:"gust,omitempty"`
+	Direction int      `json:"direction"` // degrees clockwise from north
+	Unit      string   `json:"unit"`      // of speed and gust: mph or m/s
+}
+
+// ConditionsV2 describes the sky
+type ConditionsV2 struct {
+	Description string `json:"description"`
+	Icon        string `json:"icon"`
+	IconURL     string `json:"icon_url"`
+}
+
+// PrecipitationV2 is the chance and amount of precipitation
+type PrecipitationV2 struct {
+	Chance int      `json:"chance"`           // percent
+	Amount *float64 `json:"amount,omitempty"` // mm, hourly forecasts only
+}
+
+// ProvenanceV2 says where a response's data came from
+type ProvenanceV2 struct {
+	Provider    string `json:"provider,omitempty"` // empty for forecasts
+	Cache       string `json:"cache,omitempty"`    // hit or miss
+	Stale       bool   `json:"stale"`
+	GeneratedAt string `json:"generated_at"`
+	APIVersion  string `json:"api_version"`
+}
+
+// ForecastV2 is the 5-day forecast in the /api/v2 schema
+type ForecastV2 struct {
+	Location   LocationV2      `json:"location"`
+	Days       []DayForecastV2 `json:"days"`
+	Provenance ProvenanceV2    `json:"provenance"`
+}
+
+// DayForecastV2 is the forecast for a single day
+type DayForecastV2 struct {
+	Date          string          `json:"date"` // YYYY-MM-DD
+	Temperature   TemperatureV2   `json:"temperature"`
+	Conditions    ConditionsV2    `json:"conditions"`
+	Precipitation PrecipitationV2 `json:"precipitation"`
+}
+
+// HourlyForecastV2 is the hour-by-hour forecast in the /api/v2 schema
+type HourlyForecastV2 struct {
+	Location      LocationV2       `json:"location"`
+	IntervalHours int              `json:"interval_hours"`
+	Hours         []HourForecastV2 `json:"hours"`
+	Provenance    ProvenanceV2     `json:"provenance"`
+}
+
+// HourForecastV2 is the forecast for a single forecast period
+type HourForecastV2 struct {
+	Time          string          `json:"time"`
+	Temperature   TemperatureV2   `json:"temperature"`
+	Wind          WindV2          `json:"wind"`
+	Conditions    ConditionsV2    `json:"conditions"`
+	Precipitation PrecipitationV2 `json:"precipitation"`
+}
+
+// v2Routes adds the /api/v2 endpoints. They take the same parameters and
+// credentials as v1, but only respond with JSON.
+func (s *Server) v2Routes(r chi.Router) {
+	r.Use(s.requestTimeout)
+	r.Use(s.requireClientAuth)
+	r.Use(s.rateLimitClients)
+	r.Use(s.trackUsage)
+	r.Use(selectFields)
+	r.With(s.cacheControl("weather")).Get("/weather", s.weatherV2Handler)
+	r.With(s.cacheControl("forecast")).Get("/forecast", s.forecastV2Handler)
+	r.With(s.cacheControl("forecast/hourly")).Get("/forecast/hourly", s.hourlyForecastV2Handler)
+}
+
+// deprecateUnversioned marks responses of the unversioned /weather route as
+// deprecated, pointing at its /api/v2 successor
+func (s *Server) deprecateUnversioned(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/weather" {
+			w.Header().Set("Deprecation", fmt.Sprintf("@%d", unversionedDeprecated.Unix()))
+			w.Header().Set("Sunset", s.config().UnversionedSunset.Format(http.TimeFormat))
+			w.Header().Set("Link", `</api/v2/weather>; rel="successor-version"`)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Current weather handler for /api/v2
+func (s *Server) weatherV2Handler(w http.ResponseWriter, r *http.Request) {
+	loc, ok := s.locationFromRequest(w, r)
+	if !ok {
+		return
+	}
+	opts, ok := s.optionsFromRequest(w, r)
+	if !ok {
+		return
+	}
+	weather, err := s.weather.CurrentWeather(r.Context(), loc, opts)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	setCacheStatusHeader(w, weather)
+	json.NewEncoder(w).Encode(weatherV2(loc, weather))
+}
+
+// Forecast handler for /api/v2
+func (s *Server) forecastV2Handler(w http.ResponseWriter, r *http.Request) {
+	loc, ok := s.zipCodeFromRequest(w, r)
+	if !ok {
+		return
+	}
+	opts, ok := s.optionsFromRequest(w, r)
+	if !ok {
+		return
+	}
+	forecast, err := s.weather.Forecast(r.Context(), loc, opts)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(forecastV2(loc, forecast))
+}
+
+// Hourly forecast handler for /api/v2
+func (s *Server) hourlyForecastV2Handler(w http.ResponseWriter, r *http.Request) {
+	loc, ok := s.zipCodeFromRequest(w, r)
+	if !ok {
+		return
+	}
+	hours, ok := hoursFromRequest(w, r)
+	if !ok {
+		return
+	}
+	opts, ok := s.optionsFromRequest(w, r)
+	if !ok {
+		return
+	}
+	forecast, err := s.weather.HourlyForecast(r.Context(), loc, hours, opts)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(hourlyForecastV2(loc, forecast))
+}
+
+// weatherV2 converts current weather to the /api/v2 schema
+func weatherV2(loc Location, weather *WeatherResponse) WeatherV2 {
+	temperature, speed := v2UnitNames(weather.Units)
+	v2 := WeatherV2{
+		Location: LocationV2{ZipCode: weather.ZipCode, Name: weather.Location, Country: loc.country(), LocalTime: weather.LocalTime},
+		Temperature: TemperatureV2{
+			Current:   &weather.Temperature,
+			FeelsLike: &weather.FeelsLike,
+			DewPoint:  &weather.DewPoint,
+			Unit:      temperature,
+		},
+		Wind:       WindV2{Speed: weather.WindSpeed, Direction: weather.WindDirection, Unit: speed},
+		Conditions: ConditionsV2{Description: weather.Description, Icon: weather.Icon, IconURL: weather.IconURL},
+		Humidity:   weather.Humidity,
+		Pressure:   weather.Pressure,
+		Visibility: weather.Visibility,
+		CloudCover: weather.CloudCover,
+		ObservedAt: weather.ObservedAt,
+		Provenance: provenanceV2(weather.Source, weather.Cache, weather.Stale),
+	}
+	if weather.WindGust > 0 {
+		v2.Wind.Gust = &weather.WindGust
+	}
+	return v2
+}
+
+// forecastV2 converts a 5-day forecast to the /api/v2 schema
+func forecastV2(loc Location, forecast *ForecastResponse) ForecastV2 {
+	temperature, _ := v2UnitNames(forecast.Units)
+	v2 := ForecastV2{
+		Location:   LocationV2{ZipCode: forecast.ZipCode, Name: forecast.Location, Country: loc.country()},
+		Days:       make([]DayForecastV2, len(forecast.Days)),
+		Provenance: provenanceV2("", "", false),
+	}
+	for i := range forecast.Days {
+		day := &forecast.Days[i]
+		v2.Days[i] = DayForecastV2{
+			Date:          day.Date,
+			Temperature:   TemperatureV2{High: &day.High, Low: &day.Low, Unit: temperature},
+			Conditions:    ConditionsV2{Description: day.Description, Icon: day.Icon, IconURL: day.IconURL},
+			Precipitation: PrecipitationV2{Chance: day.PrecipitationChance},
+		}
+	}
+	return v2
+}
+
+// hourlyForecastV2 converts an hourly forecast to the /api/v2 schema
+func hourlyForecastV2(loc Location, forecast *HourlyForecastResponse) HourlyForecastV2 {
+	temperature, speed := v2UnitNames(forecast.Units)
+	v2 := HourlyForecastV2{
+		Location:      LocationV2{ZipCode: forecast.ZipCode, Name: forecast.Location, Country: loc.country()},
+		IntervalHours: forecast.IntervalHours,
+		Hours:         make([]HourForecastV2, len(forecast.Hours)),
+		Provenance:    provenanceV2("", "", false),
+	}
+	for i := range forecast.Hours {
+		hour := &forecast.Hours[i]
+		v2.Hours[i] = HourForecastV2{
+			Time:          hour.Time,
+			Temperature:   TemperatureV2{Current: &hour.Temperature, Unit: temperature},
+			Wind:          WindV2{Speed: hour.WindSpeed, Direction: hour.WindDirection, Unit: speed},
+			Conditions:    ConditionsV2{Description: hour.Description, Icon: hour.Icon, IconURL: hour.IconURL},
+			Precipitation: PrecipitationV2{Chance: hour.PrecipitationChance, Amount: &hour.Precipitation},
+		}
+	}
+	return v2
+}
+
+// provenanceV2 describes a response generated now from the given provider
+func provenanceV2(provider, cache string, stale bool) ProvenanceV2 {
+	return ProvenanceV2{
+		Provider:    provider,
+		Cache:       cache,
+		Stale:       stale,
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		APIVersion:  "v2",
+	}
+}
+
+// v2UnitNames returns the names of a unit system's temperature and speed units
+func v2UnitNames(units string) (temperature, speed string) {
+	switch units {
+	case unitsMetric:
+		return "celsius", "m/s"
+	case unitsStandard:
+		return "kelvin", "m/s"
+	default:
+		return "fahrenheit", "mph"
+	}
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+)
+
+// Build details, set at build time with -ldflags, e.g.
+// -X github.com/dekkagaijin/go-container-test/server.version=v1.2.3, and
+// likewise server.commit and server.buildDate.
+// Without them, the commit and its time are taken from what Go recorded about
+// the git checkout the binary was built in.
+var (
+	version   = "dev"
+	commit    = ""
+	buildDate = ""
+)
+
+// VersionResponse describes the running build
+type VersionResponse struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Modified  bool   `json:"modified,omitempty"` // built from a checkout with uncommitted changes
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+}
+
+// buildInfo returns the running build's details
+func buildInfo() VersionResponse {
+	info := VersionResponse{Version: version, Commit: commit, BuildDate: buildDate, GoVersion: runtime.Version()}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildDate == "" {
+					info.BuildDate = setting.Value
+				}
+			case "vcs.modified":
+				info.Modified = commit == "" && setting.Value == "true"
+			}
+		}
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.BuildDate == "" {
+		info.BuildDate = "unknown"
+	}
+	return info
+}
+
+// versionString describes this build for the version command
+func versionString() string {
+	info := buildInfo()
+	if info.Modified {
+		info.Commit += " (modified)"
+	}
+	return fmt.Sprintf("weather-server %s (commit %s, built %s, %s)", info.Version, info.Commit, info.BuildDate, info.GoVersion)
+}
+
+// Version handler using Chi
+func versionHandler(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(buildInfo())
+}
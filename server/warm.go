@@ -0,0 +1,39 @@
+package server
+
+import (
+	"context"
+)
+
+// startCacheWarmer pre-fetches the zip codes listed in CACHE_WARM_ZIP_CODES
+// and keeps refreshing them every CACHE_WARM_INTERVAL (default: half the
+// cache TTL), so popular lookups are always served from the cache
+func (s *Server) startCacheWarmer(c *Config) {
+	if len(c.CacheWarmZipCodes) == 0 {
+		return
+	}
+	if c.CacheBackend == "none" {
+		warnf("caching is disabled; ignoring CACHE_WARM_ZIP_CODES")
+		return
+	}
+
+	go func() {
+		for {
+			s.warmCache(c.CacheWarmZipCodes)
+			if !s.sleep(c.CacheWarmInterval) {
+				return
+			}
+		}
+	}()
+}
+
+// warmCache refreshes the cached weather for each zip code using the default
+// units and language
+func (s *Server) warmCache(zipCodes []string) {
+	var opts Options
+	for _, zipCode := range zipCodes {
+		loc := Location{ZipCode: zipCode}
+		if _, err := s.fetchWeather(context.Background(), s.cacheKey(loc, opts), loc, opts); err != nil {
+			warnf("cache warming for %s failed: %v", zipCode, err)
+		}
+	}
+}
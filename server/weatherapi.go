@@ -0,0 +1,172 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// WeatherAPI.com base URL
+const weatherAPIBaseURL = "https://api.weatherapi.com"
+
+// WeatherAPI.com current weather response structure (simplified)
+type WeatherAPIResponse struct {
+	Location struct {
+		Name string `json:"name"`
+		TzID string `json:"tz_id"`
+	} `json:"location"`
+	Current struct {
+		LastUpdatedEpoch int64   `json:"last_updated_epoch"`
+		TempC            float64 `json:"temp_c"`
+		TempF            float64 `json:"temp_f"`
+		FeelsLikeC       float64 `json:"feelslike_c"`
+		FeelsLikeF       float64 `json:"feelslike_f"`
+		DewPointC        float64 `json:"dewpoint_c"`
+		DewPointF        float64 `json:"dewpoint_f"`
+		IsDay            int     `json:"is_day"`
+		Condition        struct {
+			Text string `json:"text"`
+			Code int    `json:"code"`
+		} `json:"condition"`
+		WindMph    float64 `json:"wind_mph"`
+		WindKph    float64 `json:"wind_kph"`
+		WindDegree int     `json:"wind_degree"`
+		GustMph    float64 `json:"gust_mph"`
+		GustKph    float64 `json:"gust_kph"`
+		PressureMb float64 `json:"pressure_mb"`
+		Humidity   int     `json:"humidity"`
+		Cloud      int     `json:"cloud"`
+		VisKm      float64 `json:"vis_km"`
+	} `json:"current"`
+}
+
+// WeatherAPI.com language codes that differ from OpenWeatherMap's
+var weatherAPILangs = map[string]string{
+	"cz":    "cs",
+	"kr":    "ko",
+	"pt_br": "pt",
+	"zh_cn": "zh",
+}
+
+// weatherAPIProvider serves current conditions from the WeatherAPI.com API
+type weatherAPIProvider struct {
+	s      *Server
+	apiKey string
+}
+
+func (p weatherAPIProvider) Name() string {
+	return "weatherapi"
+}
+
+func (p weatherAPIProvider) Current(ctx context.Context, loc Location, opts Options) (*WeatherResponse, error) {
+	params := url.Values{}
+	params.Add("key", p.apiKey)
+	params.Add("q", weatherAPIQuery(loc))
+	if lang := opts.lang(); lang != defaultLang {
+		if alias, ok := weatherAPILangs[lang]; ok {
+			lang = alias
+		}
+		params.Add("lang", lang)
+	}
+
+	var apiResp WeatherAPIResponse
+	fullURL := fmt.Sprintf("%s/v1/current.json?%s", weatherAPIBaseURL, params.Encode())
+	if err := p.s.fetchJSON(ctx, fullURL, "WeatherAPI.com", &apiResp); err != nil {
+		return nil, err
+	}
+	current := apiResp.Current
+
+	// WeatherAPI.com reports both unit systems; Kelvin is derived from Celsius
+	temperature, feelsLike, dew := current.TempF, current.FeelsLikeF, current.DewPointF
+	windSpeed, windGust := current.WindMph, current.GustMph
+	if opts.units() != unitsImperial {
+		temperature, feelsLike, dew = current.TempC, current.FeelsLikeC, current.DewPointC
+		if opts.units() == unitsStandard {
+			temperature, feelsLike, dew = round1(temperature+273.15), round1(feelsLike+273.15), round1(dew+273.15)
+		}
+		windSpeed, windGust = round1(current.WindKph/3.6), round1(current.GustKph/3.6)
+	}
+
+	// Observation time is UTC; local time uses the location's time zone
+	zone, err := time.LoadLocation(apiResp.Location.TzID)
+	if err != nil {
+		zone = time.UTC
+	}
+
+	icon := weatherAPIIcon(current.Condition.Code, current.IsDay == 1)
+	return &WeatherResponse{
+		ZipCode:       loc.ZipCode,
+		Location:      apiResp.Location.Name,
+		Units:         opts.units(),
+		Temperature:   temperature,
+		FeelsLike:     feelsLike,
+		DewPoint:      dew,
+		Description:   strings.ToLower(current.Condition.Text),
+		Icon:          icon,
+		IconURL:       p.s.iconURL(icon),
+		Humidity:      current.Humidity,
+		Pressure:      int(math.Round(current.PressureMb)),
+		Visibility:    int(math.Round(current.VisKm * 1000)),
+		CloudCover:    current.Cloud,
+		WindSpeed:     windSpeed,
+		WindDirection: current.WindDegree,
+		WindGust:      windGust,
+		ObservedAt:    time.Unix(current.LastUpdatedEpoch, 0).UTC().Format(time.RFC3339),
+		LocalTime:     time.Now().In(zone).Format(time.RFC3339),
+	}, nil
+}
+
+// weatherAPIQuery builds the q parameter, which accepts postal codes, city names, or "lat,lon"
+func weatherAPIQuery(loc Location) string {
+	switch {
+	case loc.Coords != nil:
+		return fmt.Sprintf("%.4f,%.4f", loc.Coords.Lat, loc.Coords.Lon)
+	case loc.City != "":
+		if loc.country() != defaultCountry {
+			return loc.City + "," + loc.country()
+		}
+		return loc.City
+	case loc.country() == defaultCountry:
+		// WeatherAPI.com only knows the 5-digit portion of ZIP+4 codes
+		return loc.ZipCode[:5]
+	default:
+		return loc.ZipCode
+	}
+}
+
+// weatherAPIIcon converts a WeatherAPI.com condition code into the equivalent
+// OpenWeatherMap icon code
+func weatherAPIIcon(code int, isDay bool) string {
+	var icon string
+	switch {
+	case code == 1000:
+		icon = "01"
+	case code == 1003:
+		icon = "02"
+	case code == 1006:
+		icon = "03"
+	case code == 1009:
+		icon = "04"
+	case code == 1030 || code == 1135 || code == 1147:
+		icon = "50"
+	case code == 1087 || (code >= 1273 && code <= 1282):
+		icon = "11"
+	case code == 1063 || (code >= 1180 && code <= 1195):
+		icon = "10"
+	case (code >= 1150 && code <= 1171) || (code >= 1240 && code <= 1246):
+		icon = "09"
+	case code >= 1066 && code <= 1264:
+		// Snow, sleet, freezing rain, and ice pellets
+		icon = "13"
+	default:
+		return defaultIcon
+	}
+
+	if isDay {
+		return icon + "d"
+	}
+	return icon + "n"
+}
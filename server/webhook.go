@@ -0,0 +1,133 @@
+package server
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// How long a webhook delivery may take
+const webhookTimeout = 10 * time.Second
+
+// privateAddress reports whether an IP address is on a loopback, private,
+// link-local, or otherwise non-public network
+func privateAddress(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified()
+}
+
+// validateCallbackURL checks that a callback URL is an absolute http or https
+// URL which, unless WEBHOOK_ALLOW_PRIVATE_NETWORKS is set, doesn't name a
+// private address. Host names are checked again when alerts are delivered.
+func (s *Server) validateCallbackURL(callbackURL string) error {
+	parsed, err := url.Parse(callbackURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return errors.New("callback_url must be an http or https URL")
+	}
+	if s.config().WebhookAllowPrivateNetworks {
+		return nil
+	}
+	host := parsed.Hostname()
+	if ip := net.ParseIP(host); (ip != nil && privateAddress(ip)) || strings.EqualFold(host, "localhost") {
+		return errors.New("callback_url must not be on a private network unless WEBHOOK_ALLOW_PRIVATE_NETWORKS=true")
+	}
+	return nil
+}
+
+// newWebhookClient creates the client webhooks are delivered with. Deliveries
+// don't follow redirects or use a proxy, and refuse to connect to private
+// addresses unless WEBHOOK_ALLOW_PRIVATE_NETWORKS is set.
+func (s *Server) newWebhookClient() *http.Client {
+	return &http.Client{
+		Timeout:       webhookTimeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		Transport: &http.Transport{
+			DialContext: (&net.Dialer{
+				Timeout: webhookTimeout,
+				Control: func(network, address string, _ syscall.RawConn) error {
+					host, _, err := net.SplitHostPort(address)
+					if err != nil {
+						return err
+					}
+					if ip := net.ParseIP(host); ip != nil && privateAddress(ip) && !s.config().WebhookAllowPrivateNetworks {
+						return fmt.Errorf("refusing to deliver a webhook to private address %s", host)
+					}
+					return nil
+				},
+			}).DialContext,
+			TLSHandshakeTimeout: webhookTimeout,
+			MaxIdleConnsPerHost: 2,
+		},
+	}
+}
+
+// webhookNotifier POSTs alerts as JSON to a subscription's callback URL,
+// signed with the subscription's secret
+type webhookNotifier struct {
+	s *Server
+}
+
+func (n webhookNotifier) available() error { return nil }
+
+func (n webhookNotifier) validate(sub *Subscription) error {
+	return n.s.validateCallbackURL(sub.CallbackURL)
+}
+
+func (n webhookNotifier) notify(sub Subscription, alert SubscriptionAlert) (bool, error) {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return false, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	return n.s.postWebhook(sub.CallbackURL, body, http.Header{
+		"User-Agent":          {"weather-server/" + buildInfo().Version},
+		"X-Webhook-Event":     {alert.Event},
+		"X-Webhook-Timestamp": {timestamp},
+		"X-Webhook-Signature": {signWebhook(sub.Secret, timestamp, body)},
+	})
+}
+
+// signWebhook returns the X-Webhook-Signature of a delivery: the hex HMAC-SHA256,
+// keyed by the subscription's secret, of the timestamp, a period, and the body
+func signWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// postWebhook sends one webhook delivery, for which any 2xx response is a
+// success, and reports whether a failed one is worth retrying
+func (s *Server) postWebhook(callbackURL string, body []byte, headers http.Header) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header = headers
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.webhookClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("callback returned %s", resp.Status)
+	default:
+		return false, fmt.Errorf("callback returned %s", resp.Status)
+	}
+}
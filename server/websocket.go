@@ -0,0 +1,286 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// WebSocket connection limits and keepalive
+const (
+	defaultWebSocketMaxSubscriptions = 20
+	wsMaxMessageSize                 = 4 << 10 // 4 KiB
+	wsPingInterval                   = 30 * time.Second
+	wsPongWait                       = 60 * time.Second // a connection silent this long is dropped
+	wsWriteWait                      = 10 * time.Second
+)
+
+// newWSUpgrader creates the upgrader for /ws requests, which accepts pages on
+// the server's own origin or one the CORS policy allows. Requests without an
+// Origin header don't come from browsers.
+func (s *Server) newWSUpgrader() *websocket.Upgrader {
+	return &websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				return true
+			}
+			if parsed, err := url.Parse(origin); err == nil && strings.EqualFold(parsed.Host, r.Host) {
+				return true
+			}
+			return corsOriginAllowed(s.config(), origin)
+		},
+		Error: func(w http.ResponseWriter, r *http.Request, status int, reason error) {
+			writeError(w, status, reason.Error())
+		},
+	}
+}
+
+// WebSocketRequest is a message from a /ws client
+type WebSocketRequest struct {
+	Action  string `json:"action"` // subscribe or unsubscribe
+	ZipCode string `json:"zip_code"`
+}
+
+// WebSocketMessage is a message to a /ws client. Type is "subscribed",
+// "unsubscribed", "weather", or "error".
+type WebSocketMessage struct {
+	Type          string           `json:"type"`
+	ZipCode       string           `json:"zip_code,omitempty"`
+	Weather       *WeatherResponse `json:"weather,omitempty"`
+	Subscriptions []string         `json:"subscriptions,omitempty"`
+	Error         string           `json:"error,omitempty"`
+}
+
+// wsConnection is one /ws client and the zip codes it subscribes to
+type wsConnection struct {
+	s      *Server
+	conn   *websocket.Conn
+	opts   Options
+	ctx    context.Context // canceled when the connection ends
+	cancel context.CancelFunc
+	out    chan WebSocketMessage
+
+	// Each subscription's stop function, by zip code. Only the read loop uses it.
+	subscriptions map[string]context.CancelFunc
+}
+
+// WebSocket handler using Chi. Clients subscribe and unsubscribe to zip codes,
+// and get each subscription's current conditions right away and again
+// whenever they're refreshed with a new observation.
+func (s *Server) webSocketHandler(w http.ResponseWriter, r *http.Request) {
+	// Every subscription on a connection uses the same units, language, and provider
+	opts, ok := s.optionsFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	// Counted before the upgrade, while the server still waits for the request
+	s.observationListeners.hijacked.Add(1)
+	defer s.observationListeners.hijacked.Done()
+	conn, err := s.wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // the upgrader has responded
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &wsConnection{
+		s:             s,
+		conn:          conn,
+		opts:          opts,
+		ctx:           ctx,
+		cancel:        cancel,
+		out:           make(chan WebSocketMessage, 16),
+		subscriptions: map[string]context.CancelFunc{},
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.writeLoop()
+	}()
+	c.readLoop()
+	cancel()
+	wg.Wait()
+}
+
+// readLoop handles the client's messages until the connection ends
+func (c *wsConnection) readLoop() {
+	c.conn.SetReadLimit(wsMaxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseNoStatusReceived) {
+				debugf("websocket closed: %v", err)
+			}
+			return
+		}
+		c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+
+		var request WebSocketRequest
+		if err := json.Unmarshal(data, &request); err != nil {
+			c.send(WebSocketMessage{Type: "error", Error: "messages must be JSON objects such as {\"action\": \"subscribe\", \"zip_code\": \"10001\"}"})
+			continue
+		}
+		switch request.Action {
+		case "subscribe":
+			c.subscribe(request.ZipCode)
+		case "unsubscribe":
+			c.unsubscribe(request.ZipCode)
+		default:
+			c.send(WebSocketMessage{Type: "error", ZipCode: request.ZipCode, Error: "action must be subscribe or unsubscribe"})
+		}
+	}
+}
+
+// writeLoop sends queued messages and pings until the connection ends, and
+// closes it when the server shuts down
+func (c *wsConnection) writeLoop() {
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case message := <-c.out:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := c.conn.WriteJSON(message); err != nil {
+				c.conn.Close() // ends the read loop
+				return
+			}
+		case <-ping.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				c.conn.Close()
+				return
+			}
+		case <-c.s.observationListeners.done:
+			c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"), time.Now().Add(wsWriteWait))
+			c.conn.Close()
+			return
+		case <-c.ctx.Done():
+			c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(wsWriteWait))
+			return
+		}
+	}
+}
+
+// send queues a message for the client, giving up if the connection ends first
+func (c *wsConnection) send(message WebSocketMessage) {
+	select {
+	case c.out <- message:
+	case <-c.ctx.Done():
+	}
+}
+
+// subscribedZipCodes lists the zip codes the client subscribes to, sorted
+func (c *wsConnection) subscribedZipCodes() []string {
+	zipCodes := make([]string, 0, len(c.subscriptions))
+	for zipCode := range c.subscriptions {
+		zipCodes = append(zipCodes, zipCode)
+	}
+	slices.Sort(zipCodes)
+	return zipCodes
+}
+
+// subscribe starts sending the weather at a zip code. Subscribing again to
+// the same zip code only confirms the subscription.
+func (c *wsConnection) subscribe(zipCode string) {
+	normalized, err := c.s.normalizeZipCode(zipCode)
+	if err != nil {
+		c.send(WebSocketMessage{Type: "error", ZipCode: zipCode, Error: err.Error()})
+		return
+	}
+	if _, ok := c.subscriptions[normalized]; ok {
+		c.send(WebSocketMessage{Type: "subscribed", ZipCode: normalized, Subscriptions: c.subscribedZipCodes()})
+		return
+	}
+	if limit := c.s.config().WebSocketMaxSubscriptions; len(c.subscriptions) >= limit {
+		c.send(WebSocketMessage{Type: "error", ZipCode: normalized, Error: fmt.Sprintf("at most %d subscriptions are allowed per connection", limit)})
+		return
+	}
+
+	loc := Location{ZipCode: normalized}
+	c.s.locationRequests.record(loc)
+	ctx, stop := context.WithCancel(c.ctx)
+	c.subscriptions[normalized] = stop
+	// Confirm before the forwarder can send any weather
+	c.send(WebSocketMessage{Type: "subscribed", ZipCode: normalized, Subscriptions: c.subscribedZipCodes()})
+	go c.forward(ctx, loc)
+}
+
+// unsubscribe stops sending the weather at a zip code
+func (c *wsConnection) unsubscribe(zipCode string) {
+	normalized, err := c.s.normalizeZipCode(zipCode)
+	if err != nil {
+		c.send(WebSocketMessage{Type: "error", ZipCode: zipCode, Error: err.Error()})
+		return
+	}
+	stop, ok := c.subscriptions[normalized]
+	if !ok {
+		c.send(WebSocketMessage{Type: "error", ZipCode: normalized, Error: "not subscribed to " + normalized})
+		return
+	}
+	stop()
+	delete(c.subscriptions, normalized)
+	c.send(WebSocketMessage{Type: "unsubscribed", ZipCode: normalized, Subscriptions: c.subscribedZipCodes()})
+}
+
+// forward sends the weather at a subscription's zip code now and whenever it
+// refreshes with a new observation, keeping it refreshed, until ctx ends
+func (c *wsConnection) forward(ctx context.Context, loc Location) {
+	// Listen before the first lookup so a refresh right after it isn't missed
+	updates, stop := c.s.observationListeners.listen(c.s.cacheKey(loc, c.opts))
+	defer stop()
+
+	// Sends are dropped once the client unsubscribes
+	send := func(message WebSocketMessage) {
+		select {
+		case c.out <- message:
+		case <-ctx.Done():
+		}
+	}
+
+	// A failed lookup is reported, and the subscription kept for when the
+	// weather can be looked up again
+	var last WeatherResponse
+	if current, err := c.s.weather.CurrentWeather(ctx, loc, c.opts); err != nil {
+		send(WebSocketMessage{Type: "error", ZipCode: loc.ZipCode, Error: err.Error()})
+	} else {
+		weather := liveWeather(*current)
+		last = weather
+		send(WebSocketMessage{Type: "weather", ZipCode: loc.ZipCode, Weather: &weather})
+	}
+
+	refresh := time.NewTicker(c.s.liveRefreshInterval())
+	defer refresh.Stop()
+
+	for {
+		select {
+		case weather := <-updates:
+			if weather.ObservedAt == last.ObservedAt && weather.Source == last.Source {
+				continue // refreshed, but with the same observation
+			}
+			weather = liveWeather(weather)
+			last = weather
+			send(WebSocketMessage{Type: "weather", ZipCode: loc.ZipCode, Weather: &weather})
+		case <-refresh.C:
+			c.s.weather.CurrentWeather(ctx, loc, c.opts)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
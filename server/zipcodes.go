@@ -0,0 +1,389 @@
+package server
+
+import (
+	"bytes"
+	"cmp"
+	_ "embed"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// Built-in zip code database, used unless ZIP_CODE_DATABASE_FILE replaces it.
+// It has the same columns as the files ZIP_CODE_DATABASE_FILE accepts, and is
+// generated from the GeoNames US postal codes.
+//
+//go:generate go run ./internal/zipcodegen -o zipcodes.csv
+//go:embed zipcodes.csv
+var embeddedZipCodes []byte
+
+// zipCodeRecord is a zip code's place in the zip code database
+type zipCodeRecord struct {
+	City  string // "City,ST,US"
+	Point Coordinates
+}
+
+// Column names accepted in a zip code database header, by field
+var zipCodeColumns = map[string][]string{
+	"zip":   {"zip", "zip_code", "zipcode", "postal_code"},
+	"city":  {"city", "primary_city", "place_name"},
+	"state": {"state", "state_id", "state_code"},
+	"lat":   {"lat", "latitude"},
+	"lon":   {"lon", "lng", "longitude"},
+}
+
+// The built-in database, parsed on first use
+var builtinZipCodes = sync.OnceValues(func() (map[string]zipCodeRecord, error) {
+	return parseZipCodeDB(bytes.NewReader(embeddedZipCodes))
+})
+
+// loadZipCodeDB reads the zip code database from the CSV file at path,
+// or the built-in one when path is empty
+func loadZipCodeDB(path string) (map[string]zipCodeRecord, error) {
+	if path == "" {
+		return builtinZipCodes()
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ZIP_CODE_DATABASE_FILE: %v", err)
+	}
+	defer f.Close()
+	records, err := parseZipCodeDB(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ZIP_CODE_DATABASE_FILE %s: %v", path, err)
+	}
+	return records, nil
+}
+
+// parseZipCodeDB parses a CSV file with a header row naming its zip,
+// city, state, lat, and lon columns, in any order. Other columns are ignored.
+func parseZipCodeDB(r io.Reader) (map[string]zipCodeRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %v", err)
+	}
+
+	columns := map[string]int{}
+	for _, field := range []string{"zip", "city", "state", "lat", "lon"} {
+		names := zipCodeColumns[field]
+		for i, column := range header {
+			if slices.Contains(names, strings.ToLower(strings.TrimSpace(column))) {
+				columns[field] = i
+				break
+			}
+		}
+		if _, ok := columns[field]; !ok {
+			return nil, fmt.Errorf("header has no %s column (one of %s)", field, strings.Join(names, ", "))
+		}
+	}
+
+	records := map[string]zipCodeRecord{}
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			if i := columns[name]; i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		zipCode, city, state := field("zip"), field("city"), field("state")
+		// Some sources drop the leading zeros of New England zip codes
+		if len(zipCode) < 5 && strings.Trim(zipCode, "0123456789") == "" {
+			zipCode = strings.Repeat("0", 5-len(zipCode)) + zipCode
+		}
+		if err := validateZipCode(zipCode); err != nil || len(zipCode) != 5 {
+			return nil, fmt.Errorf("line %d: invalid zip code %q", line, zipCode)
+		}
+		lat, latErr := strconv.ParseFloat(field("lat"), 64)
+		lon, lonErr := strconv.ParseFloat(field("lon"), 64)
+		if city == "" || state == "" || latErr != nil || lonErr != nil {
+			return nil, fmt.Errorf("line %d: zip code %s needs a city, state, lat, and lon", line, zipCode)
+		}
+		records[zipCode] = zipCodeRecord{
+			City:  city + "," + state + "," + defaultCountry,
+			Point: Coordinates{Name: city, Lat: lat, Lon: lon},
+		}
+	}
+}
+
+// zipCodeCities returns the "City,ST,US" of each zip code in a database
+func zipCodeCities(records map[string]zipCodeRecord) map[string]string {
+	cities := make(map[string]string, len(records))
+	for zipCode, record := range records {
+		cities[zipCode] = record.City
+	}
+	return cities
+}
+
+// lookupZipCode returns the coordinates of a US zip code from the zip code
+// database, so it can be geocoded without calling an upstream API
+func (s *Server) lookupZipCode(loc Location) (*Coordinates, bool) {
+	if loc.country() != defaultCountry || len(loc.ZipCode) < 5 {
+		return nil, false
+	}
+	record, ok := s.config().ZipCodeDB[loc.ZipCode[:5]]
+	if !ok {
+		return nil, false
+	}
+	point := record.Point
+	return &point, true
+}
+
+// locationStore holds the "City,ST,CC" of each known zip code, which can be
+// changed at runtime through /admin/locations
+type locationStore struct {
+	mu     sync.RWMutex
+	cities map[string]string
+	file   string // ZIP_CODE_CITIES_FILE changes are saved to, if any
+	source string // file the cities were loaded from
+}
+
+// setupLocations loads the zip code mapping from the configuration. Changes
+// made at runtime are kept across reloads until the server restarts, unless
+// the mapping comes from ZIP_CODE_CITIES_FILE, which holds those changes and
+// is read again, or the zip code database changes.
+func (s *Server) setupLocations(c *Config) {
+	s.zipLocations.mu.Lock()
+	defer s.zipLocations.mu.Unlock()
+	source := cmp.Or(c.ZipCodeCitiesFile, c.ZipCodeDBFile)
+	if s.zipLocations.cities != nil && c.ZipCodeCitiesFile == "" && source == s.zipLocations.source {
+		return
+	}
+	s.zipLocations.cities = maps.Clone(c.ZipCodeCities)
+	s.zipLocations.file, s.zipLocations.source = c.ZipCodeCitiesFile, source
+}
+
+// city returns the "City,ST,CC" of a zip code
+func (s *locationStore) city(zipCode string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	city, ok := s.cities[zipCode]
+	return city, ok
+}
+
+// count returns the number of zip codes in the mapping
+func (s *locationStore) count() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.cities)
+}
+
+// all returns a copy of the whole mapping
+func (s *locationStore) all() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return maps.Clone(s.cities)
+}
+
+// update applies change to the mapping, saving it to ZIP_CODE_CITIES_FILE when
+// one is set. On error the mapping is left unchanged.
+func (s *locationStore) update(change func(cities map[string]string)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cities := maps.Clone(s.cities)
+	change(cities)
+	if s.file != "" {
+		var data []byte
+		var err error
+		if strings.EqualFold(filepath.Ext(s.file), ".json") {
+			data, err = json.MarshalIndent(cities, "", "  ")
+		} else {
+			data, err = yaml.Marshal(cities)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to encode zip codes: %v", err)
+		}
+		if err := writeFileAtomic(s.file, data); err != nil {
+			return fmt.Errorf("failed to write ZIP_CODE_CITIES_FILE: %v", err)
+		}
+	}
+	s.cities = cities
+	return nil
+}
+
+// mockLocationName returns the city name for a sample zip code, used by demo data
+func (s *Server) mockLocationName(zipCode string) string {
+	city, exists := s.zipLocations.city(zipCode)
+	if !exists {
+		return "Unknown Location"
+	}
+	return strings.Split(city, ",")[0]
+}
+
+// LocationEntry represents a zip code's city we'll return from /admin/locations
+type LocationEntry struct {
+	ZipCode string `json:"zip_code"`
+	City    string `json:"city"`
+}
+
+// LocationRequest describes the city to set with PUT /admin/locations/{zip}
+type LocationRequest struct {
+	City string `json:"city"`
+}
+
+// zipCodeFromPath reads and validates the {zip} route parameter, dropping the
+// +4 of ZIP+4 codes. On failure it writes a 400 response and returns false.
+func zipCodeFromPath(w http.ResponseWriter, r *http.Request) (string, bool) {
+	zipCode := chi.URLParam(r, "zip")
+	if err := validateZipCode(zipCode); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return "", false
+	}
+	return zipCode[:5], true
+}
+
+// Location list handler using Chi
+func (s *Server) locationsHandler(w http.ResponseWriter, r *http.Request) {
+	locations := []LocationEntry{}
+	for zipCode, city := range s.zipLocations.all() {
+		locations = append(locations, LocationEntry{ZipCode: zipCode, City: city})
+	}
+	sort.Slice(locations, func(i, j int) bool { return locations[i].ZipCode < locations[j].ZipCode })
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(locations)
+}
+
+// Location lookup handler using Chi
+func (s *Server) locationHandler(w http.ResponseWriter, r *http.Request) {
+	zipCode, ok := zipCodeFromPath(w, r)
+	if !ok {
+		return
+	}
+	city, ok := s.zipLocations.city(zipCode)
+	if !ok {
+		writeError(w, http.StatusNotFound, "no city for zip code "+zipCode)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(LocationEntry{ZipCode: zipCode, City: city})
+}
+
+// Location update handler using Chi. Cached weather for the zip code is
+// dropped so demo data picks up the new name.
+func (s *Server) setLocationHandler(w http.ResponseWriter, r *http.Request) {
+	zipCode, ok := zipCodeFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var body LocationRequest
+	r.Body = http.MaxBytesReader(w, r.Body, 1024)
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || strings.TrimSpace(body.City) == "" {
+		writeError(w, http.StatusBadRequest, `request body must be a JSON object such as {"city": "New York,NY,US"}`)
+		return
+	}
+
+	created := false
+	err := s.zipLocations.update(func(cities map[string]string) {
+		_, exists := cities[zipCode]
+		created = !exists
+		cities[zipCode] = body.City
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.weatherCache.DeletePrefix(cacheKeyPrefix(Location{ZipCode: zipCode, Country: defaultCountry}))
+
+	infof("Set zip code %s to %s", zipCode, body.City)
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	json.NewEncoder(w).Encode(LocationEntry{ZipCode: zipCode, City: body.City})
+}
+
+// Location removal handler using Chi
+func (s *Server) deleteLocationHandler(w http.ResponseWriter, r *http.Request) {
+	zipCode, ok := zipCodeFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	city, ok := s.zipLocations.city(zipCode)
+	if !ok {
+		writeError(w, http.StatusNotFound, "no city for zip code "+zipCode)
+		return
+	}
+	err := s.zipLocations.update(func(cities map[string]string) { delete(cities, zipCode) })
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.weatherCache.DeletePrefix(cacheKeyPrefix(Location{ZipCode: zipCode, Country: defaultCountry}))
+
+	infof("Removed zip code %s", zipCode)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(LocationEntry{ZipCode: zipCode, City: city})
+}
+
+// ZipCodeResponse represents a normalized zip code we'll return from /zip-code
+type ZipCodeResponse struct {
+	ZipCode string  `json:"zip_code"`
+	Plus4   string  `json:"plus4,omitempty"`
+	City    string  `json:"city"`
+	State   string  `json:"state"`
+	Country string  `json:"country"`
+	Lat     float64 `json:"lat"`
+	Lon     float64 `json:"lon"`
+}
+
+// Zip code lookup handler using Chi. Zip codes must be in the zip code
+// database, whether or not ZIP_CODE_STRICT is on.
+func (s *Server) zipCodeHandler(w http.ResponseWriter, r *http.Request) {
+	given := r.URL.Query().Get("zip_code")
+	if given == "" {
+		writeError(w, http.StatusBadRequest, "zip_code parameter is required")
+		return
+	}
+	zipCode, err := s.normalizeZipCode(given)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	record, ok := s.config().ZipCodeDB[zipCode]
+	if !ok {
+		writeServiceError(w, &unknownZipCodeError{zipCode: zipCode})
+		return
+	}
+
+	_, plus4, _ := strings.Cut(given, "-")
+	place := strings.Split(record.City, ",")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ZipCodeResponse{
+		ZipCode: zipCode,
+		Plus4:   plus4,
+		City:    place[0],
+		State:   place[1],
+		Country: defaultCountry,
+		Lat:     record.Point.Lat,
+		Lon:     record.Point.Lon,
+	})
+}
@@ -0,0 +1,1264 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: weatherpb/weather.proto
+
+// Weather service for internal clients, served on GRPC_PORT. Messages mirror
+// the JSON API's responses, and are also the bodies of its
+// application/x-protobuf responses.
+
+package weatherpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Options are the settings every request takes, as in the JSON API's query
+// parameters. Empty fields use the caller's saved preferences, then the defaults.
+type Options struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Units         string                 `protobuf:"bytes,1,opt,name=units,proto3" json:"units,omitempty"`       // imperial, metric, or standard
+	Lang          string                 `protobuf:"bytes,2,opt,name=lang,proto3" json:"lang,omitempty"`         // e.g. en or es
+	Provider      string                 `protobuf:"bytes,3,opt,name=provider,proto3" json:"provider,omitempty"` // one of ENABLED_PROVIDERS
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Options) Reset() {
+	*x = Options{}
+	mi := &file_weatherpb_weather_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Options) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Options) ProtoMessage() {}
+
+func (x *Options) ProtoReflect() protoreflect.Message {
+	mi := &file_weatherpb_weather_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Options.ProtoReflect.Descriptor instead.
+func (*Options) Descriptor() ([]byte, []int) {
+	return file_weatherpb_weather_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Options) GetUnits() string {
+	if x != nil {
+		return x.Units
+	}
+	return ""
+}
+
+func (x *Options) GetLang() string {
+	if x != nil {
+		return x.Lang
+	}
+	return ""
+}
+
+func (x *Options) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+type GetCurrentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ZipCode       string                 `protobuf:"bytes,1,opt,name=zip_code,json=zipCode,proto3" json:"zip_code,omitempty"`
+	Country       string                 `protobuf:"bytes,2,opt,name=country,proto3" json:"country,omitempty"` // ISO 3166 code (default: US)
+	Options       *Options               `protobuf:"bytes,3,opt,name=options,proto3" json:"options,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCurrentRequest) Reset() {
+	*x = GetCurrentRequest{}
+	mi := &file_weatherpb_weather_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCurrentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCurrentRequest) ProtoMessage() {}
+
+func (x *GetCurrentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weatherpb_weather_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCurrentRequest.ProtoReflect.Descriptor instead.
+func (*GetCurrentRequest) Descriptor() ([]byte, []int) {
+	return file_weatherpb_weather_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetCurrentRequest) GetZipCode() string {
+	if x != nil {
+		return x.ZipCode
+	}
+	return ""
+}
+
+func (x *GetCurrentRequest) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *GetCurrentRequest) GetOptions() *Options {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+type GetForecastRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ZipCode       string                 `protobuf:"bytes,1,opt,name=zip_code,json=zipCode,proto3" json:"zip_code,omitempty"`
+	Country       string                 `protobuf:"bytes,2,opt,name=country,proto3" json:"country,omitempty"`
+	Options       *Options               `protobuf:"bytes,3,opt,name=options,proto3" json:"options,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetForecastRequest) Reset() {
+	*x = GetForecastRequest{}
+	mi := &file_weatherpb_weather_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetForecastRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetForecastRequest) ProtoMessage() {}
+
+func (x *GetForecastRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weatherpb_weather_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetForecastRequest.ProtoReflect.Descriptor instead.
+func (*GetForecastRequest) Descriptor() ([]byte, []int) {
+	return file_weatherpb_weather_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetForecastRequest) GetZipCode() string {
+	if x != nil {
+		return x.ZipCode
+	}
+	return ""
+}
+
+func (x *GetForecastRequest) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *GetForecastRequest) GetOptions() *Options {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+type StreamUpdatesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ZipCode       string                 `protobuf:"bytes,1,opt,name=zip_code,json=zipCode,proto3" json:"zip_code,omitempty"`
+	Country       string                 `protobuf:"bytes,2,opt,name=country,proto3" json:"country,omitempty"`
+	Options       *Options               `protobuf:"bytes,3,opt,name=options,proto3" json:"options,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamUpdatesRequest) Reset() {
+	*x = StreamUpdatesRequest{}
+	mi := &file_weatherpb_weather_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamUpdatesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamUpdatesRequest) ProtoMessage() {}
+
+func (x *StreamUpdatesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weatherpb_weather_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamUpdatesRequest.ProtoReflect.Descriptor instead.
+func (*StreamUpdatesRequest) Descriptor() ([]byte, []int) {
+	return file_weatherpb_weather_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *StreamUpdatesRequest) GetZipCode() string {
+	if x != nil {
+		return x.ZipCode
+	}
+	return ""
+}
+
+func (x *StreamUpdatesRequest) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *StreamUpdatesRequest) GetOptions() *Options {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+type Weather struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ZipCode       string                 `protobuf:"bytes,1,opt,name=zip_code,json=zipCode,proto3" json:"zip_code,omitempty"`
+	Location      string                 `protobuf:"bytes,2,opt,name=location,proto3" json:"location,omitempty"`
+	Units         string                 `protobuf:"bytes,3,opt,name=units,proto3" json:"units,omitempty"`
+	Temperature   float64                `protobuf:"fixed64,4,opt,name=temperature,proto3" json:"temperature,omitempty"`
+	FeelsLike     float64                `protobuf:"fixed64,5,opt,name=feels_like,json=feelsLike,proto3" json:"feels_like,omitempty"`
+	DewPoint      float64                `protobuf:"fixed64,6,opt,name=dew_point,json=dewPoint,proto3" json:"dew_point,omitempty"`
+	Description   string                 `protobuf:"bytes,7,opt,name=description,proto3" json:"description,omitempty"`
+	Icon          string                 `protobuf:"bytes,8,opt,name=icon,proto3" json:"icon,omitempty"`
+	IconUrl       string                 `protobuf:"bytes,9,opt,name=icon_url,json=iconUrl,proto3" json:"icon_url,omitempty"`
+	Humidity      int32                  `protobuf:"varint,10,opt,name=humidity,proto3" json:"humidity,omitempty"`
+	Pressure      int32                  `protobuf:"varint,11,opt,name=pressure,proto3" json:"pressure,omitempty"`
+	Visibility    int32                  `protobuf:"varint,12,opt,name=visibility,proto3" json:"visibility,omitempty"`
+	CloudCover    int32                  `protobuf:"varint,13,opt,name=cloud_cover,json=cloudCover,proto3" json:"cloud_cover,omitempty"`
+	WindSpeed     float64                `protobuf:"fixed64,14,opt,name=wind_speed,json=windSpeed,proto3" json:"wind_speed,omitempty"`
+	WindDirection int32                  `protobuf:"varint,15,opt,name=wind_direction,json=windDirection,proto3" json:"wind_direction,omitempty"`
+	WindGust      float64                `protobuf:"fixed64,16,opt,name=wind_gust,json=windGust,proto3" json:"wind_gust,omitempty"`
+	ObservedAt    string                 `protobuf:"bytes,17,opt,name=observed_at,json=observedAt,proto3" json:"observed_at,omitempty"` // RFC 3339
+	LocalTime     string                 `protobuf:"bytes,18,opt,name=local_time,json=localTime,proto3" json:"local_time,omitempty"`    // RFC 3339, in the location's time zone
+	Source        string                 `protobuf:"bytes,19,opt,name=source,proto3" json:"source,omitempty"`                           // the provider the observation came from
+	Cache         string                 `protobuf:"bytes,20,opt,name=cache,proto3" json:"cache,omitempty"`                             // hit or miss; empty in streamed updates
+	Stale         bool                   `protobuf:"varint,21,opt,name=stale,proto3" json:"stale,omitempty"`                            // an expired cache entry, served while it is refreshed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Weather) Reset() {
+	*x = Weather{}
+	mi := &file_weatherpb_weather_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Weather) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Weather) ProtoMessage() {}
+
+func (x *Weather) ProtoReflect() protoreflect.Message {
+	mi := &file_weatherpb_weather_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Weather.ProtoReflect.Descriptor instead.
+func (*Weather) Descriptor() ([]byte, []int) {
+	return file_weatherpb_weather_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Weather) GetZipCode() string {
+	if x != nil {
+		return x.ZipCode
+	}
+	return ""
+}
+
+func (x *Weather) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *Weather) GetUnits() string {
+	if x != nil {
+		return x.Units
+	}
+	return ""
+}
+
+func (x *Weather) GetTemperature() float64 {
+	if x != nil {
+		return x.Temperature
+	}
+	return 0
+}
+
+func (x *Weather) GetFeelsLike() float64 {
+	if x != nil {
+		return x.FeelsLike
+	}
+	return 0
+}
+
+func (x *Weather) GetDewPoint() float64 {
+	if x != nil {
+		return x.DewPoint
+	}
+	return 0
+}
+
+func (x *Weather) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Weather) GetIcon() string {
+	if x != nil {
+		return x.Icon
+	}
+	return ""
+}
+
+func (x *Weather) GetIconUrl() string {
+	if x != nil {
+		return x.IconUrl
+	}
+	return ""
+}
+
+func (x *Weather) GetHumidity() int32 {
+	if x != nil {
+		return x.Humidity
+	}
+	return 0
+}
+
+func (x *Weather) GetPressure() int32 {
+	if x != nil {
+		return x.Pressure
+	}
+	return 0
+}
+
+func (x *Weather) GetVisibility() int32 {
+	if x != nil {
+		return x.Visibility
+	}
+	return 0
+}
+
+func (x *Weather) GetCloudCover() int32 {
+	if x != nil {
+		return x.CloudCover
+	}
+	return 0
+}
+
+func (x *Weather) GetWindSpeed() float64 {
+	if x != nil {
+		return x.WindSpeed
+	}
+	return 0
+}
+
+func (x *Weather) GetWindDirection() int32 {
+	if x != nil {
+		return x.WindDirection
+	}
+	return 0
+}
+
+func (x *Weather) GetWindGust() float64 {
+	if x != nil {
+		return x.WindGust
+	}
+	return 0
+}
+
+func (x *Weather) GetObservedAt() string {
+	if x != nil {
+		return x.ObservedAt
+	}
+	return ""
+}
+
+func (x *Weather) GetLocalTime() string {
+	if x != nil {
+		return x.LocalTime
+	}
+	return ""
+}
+
+func (x *Weather) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Weather) GetCache() string {
+	if x != nil {
+		return x.Cache
+	}
+	return ""
+}
+
+func (x *Weather) GetStale() bool {
+	if x != nil {
+		return x.Stale
+	}
+	return false
+}
+
+type Forecast struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ZipCode       string                 `protobuf:"bytes,1,opt,name=zip_code,json=zipCode,proto3" json:"zip_code,omitempty"`
+	Location      string                 `protobuf:"bytes,2,opt,name=location,proto3" json:"location,omitempty"`
+	Units         string                 `protobuf:"bytes,3,opt,name=units,proto3" json:"units,omitempty"`
+	Days          []*DailyForecast       `protobuf:"bytes,4,rep,name=days,proto3" json:"days,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Forecast) Reset() {
+	*x = Forecast{}
+	mi := &file_weatherpb_weather_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Forecast) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Forecast) ProtoMessage() {}
+
+func (x *Forecast) ProtoReflect() protoreflect.Message {
+	mi := &file_weatherpb_weather_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Forecast.ProtoReflect.Descriptor instead.
+func (*Forecast) Descriptor() ([]byte, []int) {
+	return file_weatherpb_weather_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Forecast) GetZipCode() string {
+	if x != nil {
+		return x.ZipCode
+	}
+	return ""
+}
+
+func (x *Forecast) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *Forecast) GetUnits() string {
+	if x != nil {
+		return x.Units
+	}
+	return ""
+}
+
+func (x *Forecast) GetDays() []*DailyForecast {
+	if x != nil {
+		return x.Days
+	}
+	return nil
+}
+
+type DailyForecast struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Date                string                 `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"` // YYYY-MM-DD
+	High                float64                `protobuf:"fixed64,2,opt,name=high,proto3" json:"high,omitempty"`
+	Low                 float64                `protobuf:"fixed64,3,opt,name=low,proto3" json:"low,omitempty"`
+	Description         string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	Icon                string                 `protobuf:"bytes,5,opt,name=icon,proto3" json:"icon,omitempty"`
+	IconUrl             string                 `protobuf:"bytes,6,opt,name=icon_url,json=iconUrl,proto3" json:"icon_url,omitempty"`
+	PrecipitationChance int32                  `protobuf:"varint,7,opt,name=precipitation_chance,json=precipitationChance,proto3" json:"precipitation_chance,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *DailyForecast) Reset() {
+	*x = DailyForecast{}
+	mi := &file_weatherpb_weather_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DailyForecast) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DailyForecast) ProtoMessage() {}
+
+func (x *DailyForecast) ProtoReflect() protoreflect.Message {
+	mi := &file_weatherpb_weather_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DailyForecast.ProtoReflect.Descriptor instead.
+func (*DailyForecast) Descriptor() ([]byte, []int) {
+	return file_weatherpb_weather_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DailyForecast) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *DailyForecast) GetHigh() float64 {
+	if x != nil {
+		return x.High
+	}
+	return 0
+}
+
+func (x *DailyForecast) GetLow() float64 {
+	if x != nil {
+		return x.Low
+	}
+	return 0
+}
+
+func (x *DailyForecast) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *DailyForecast) GetIcon() string {
+	if x != nil {
+		return x.Icon
+	}
+	return ""
+}
+
+func (x *DailyForecast) GetIconUrl() string {
+	if x != nil {
+		return x.IconUrl
+	}
+	return ""
+}
+
+func (x *DailyForecast) GetPrecipitationChance() int32 {
+	if x != nil {
+		return x.PrecipitationChance
+	}
+	return 0
+}
+
+type HourlyForecast struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ZipCode       string                 `protobuf:"bytes,1,opt,name=zip_code,json=zipCode,proto3" json:"zip_code,omitempty"`
+	Location      string                 `protobuf:"bytes,2,opt,name=location,proto3" json:"location,omitempty"`
+	Units         string                 `protobuf:"bytes,3,opt,name=units,proto3" json:"units,omitempty"`
+	IntervalHours int32                  `protobuf:"varint,4,opt,name=interval_hours,json=intervalHours,proto3" json:"interval_hours,omitempty"`
+	Hours         []*HourlyPeriod        `protobuf:"bytes,5,rep,name=hours,proto3" json:"hours,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HourlyForecast) Reset() {
+	*x = HourlyForecast{}
+	mi := &file_weatherpb_weather_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HourlyForecast) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HourlyForecast) ProtoMessage() {}
+
+func (x *HourlyForecast) ProtoReflect() protoreflect.Message {
+	mi := &file_weatherpb_weather_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HourlyForecast.ProtoReflect.Descriptor instead.
+func (*HourlyForecast) Descriptor() ([]byte, []int) {
+	return file_weatherpb_weather_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *HourlyForecast) GetZipCode() string {
+	if x != nil {
+		return x.ZipCode
+	}
+	return ""
+}
+
+func (x *HourlyForecast) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *HourlyForecast) GetUnits() string {
+	if x != nil {
+		return x.Units
+	}
+	return ""
+}
+
+func (x *HourlyForecast) GetIntervalHours() int32 {
+	if x != nil {
+		return x.IntervalHours
+	}
+	return 0
+}
+
+func (x *HourlyForecast) GetHours() []*HourlyPeriod {
+	if x != nil {
+		return x.Hours
+	}
+	return nil
+}
+
+type HourlyPeriod struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Time                string                 `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"` // RFC 3339
+	Temperature         float64                `protobuf:"fixed64,2,opt,name=temperature,proto3" json:"temperature,omitempty"`
+	Description         string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Icon                string                 `protobuf:"bytes,4,opt,name=icon,proto3" json:"icon,omitempty"`
+	IconUrl             string                 `protobuf:"bytes,5,opt,name=icon_url,json=iconUrl,proto3" json:"icon_url,omitempty"`
+	PrecipitationChance int32                  `protobuf:"varint,6,opt,name=precipitation_chance,json=precipitationChance,proto3" json:"precipitation_chance,omitempty"`
+	Precipitation       float64                `protobuf:"fixed64,7,opt,name=precipitation,proto3" json:"precipitation,omitempty"`
+	WindSpeed           float64                `protobuf:"fixed64,8,opt,name=wind_speed,json=windSpeed,proto3" json:"wind_speed,omitempty"`
+	WindDirection       int32                  `protobuf:"varint,9,opt,name=wind_direction,json=windDirection,proto3" json:"wind_direction,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *HourlyPeriod) Reset() {
+	*x = HourlyPeriod{}
+	mi := &file_weatherpb_weather_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HourlyPeriod) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HourlyPeriod) ProtoMessage() {}
+
+func (x *HourlyPeriod) ProtoReflect() protoreflect.Message {
+	mi := &file_weatherpb_weather_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HourlyPeriod.ProtoReflect.Descriptor instead.
+func (*HourlyPeriod) Descriptor() ([]byte, []int) {
+	return file_weatherpb_weather_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *HourlyPeriod) GetTime() string {
+	if x != nil {
+		return x.Time
+	}
+	return ""
+}
+
+func (x *HourlyPeriod) GetTemperature() float64 {
+	if x != nil {
+		return x.Temperature
+	}
+	return 0
+}
+
+func (x *HourlyPeriod) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *HourlyPeriod) GetIcon() string {
+	if x != nil {
+		return x.Icon
+	}
+	return ""
+}
+
+func (x *HourlyPeriod) GetIconUrl() string {
+	if x != nil {
+		return x.IconUrl
+	}
+	return ""
+}
+
+func (x *HourlyPeriod) GetPrecipitationChance() int32 {
+	if x != nil {
+		return x.PrecipitationChance
+	}
+	return 0
+}
+
+func (x *HourlyPeriod) GetPrecipitation() float64 {
+	if x != nil {
+		return x.Precipitation
+	}
+	return 0
+}
+
+func (x *HourlyPeriod) GetWindSpeed() float64 {
+	if x != nil {
+		return x.WindSpeed
+	}
+	return 0
+}
+
+func (x *HourlyPeriod) GetWindDirection() int32 {
+	if x != nil {
+		return x.WindDirection
+	}
+	return 0
+}
+
+// BatchWeather is the result of POST /weather/batch, in request order
+type BatchWeather struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*BatchResult         `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchWeather) Reset() {
+	*x = BatchWeather{}
+	mi := &file_weatherpb_weather_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchWeather) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchWeather) ProtoMessage() {}
+
+func (x *BatchWeather) ProtoReflect() protoreflect.Message {
+	mi := &file_weatherpb_weather_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchWeather.ProtoReflect.Descriptor instead.
+func (*BatchWeather) Descriptor() ([]byte, []int) {
+	return file_weatherpb_weather_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *BatchWeather) GetResults() []*BatchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type BatchResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ZipCode       string                 `protobuf:"bytes,1,opt,name=zip_code,json=zipCode,proto3" json:"zip_code,omitempty"`
+	Weather       *Weather               `protobuf:"bytes,2,opt,name=weather,proto3" json:"weather,omitempty"` // unset when the lookup failed
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchResult) Reset() {
+	*x = BatchResult{}
+	mi := &file_weatherpb_weather_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchResult) ProtoMessage() {}
+
+func (x *BatchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_weatherpb_weather_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchResult.ProtoReflect.Descriptor instead.
+func (*BatchResult) Descriptor() ([]byte, []int) {
+	return file_weatherpb_weather_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *BatchResult) GetZipCode() string {
+	if x != nil {
+		return x.ZipCode
+	}
+	return ""
+}
+
+func (x *BatchResult) GetWeather() *Weather {
+	if x != nil {
+		return x.Weather
+	}
+	return nil
+}
+
+func (x *BatchResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Consensus is the result of GET /weather?mode=consensus
+type Consensus struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	ZipCode           string                 `protobuf:"bytes,1,opt,name=zip_code,json=zipCode,proto3" json:"zip_code,omitempty"`
+	Location          string                 `protobuf:"bytes,2,opt,name=location,proto3" json:"location,omitempty"`
+	Units             string                 `protobuf:"bytes,3,opt,name=units,proto3" json:"units,omitempty"`
+	Temperature       float64                `protobuf:"fixed64,4,opt,name=temperature,proto3" json:"temperature,omitempty"`
+	Humidity          int32                  `protobuf:"varint,5,opt,name=humidity,proto3" json:"humidity,omitempty"`
+	TemperatureSpread float64                `protobuf:"fixed64,6,opt,name=temperature_spread,json=temperatureSpread,proto3" json:"temperature_spread,omitempty"`
+	ProviderCount     int32                  `protobuf:"varint,7,opt,name=provider_count,json=providerCount,proto3" json:"provider_count,omitempty"`
+	Providers         []*ProviderReading     `protobuf:"bytes,8,rep,name=providers,proto3" json:"providers,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Consensus) Reset() {
+	*x = Consensus{}
+	mi := &file_weatherpb_weather_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Consensus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Consensus) ProtoMessage() {}
+
+func (x *Consensus) ProtoReflect() protoreflect.Message {
+	mi := &file_weatherpb_weather_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Consensus.ProtoReflect.Descriptor instead.
+func (*Consensus) Descriptor() ([]byte, []int) {
+	return file_weatherpb_weather_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Consensus) GetZipCode() string {
+	if x != nil {
+		return x.ZipCode
+	}
+	return ""
+}
+
+func (x *Consensus) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *Consensus) GetUnits() string {
+	if x != nil {
+		return x.Units
+	}
+	return ""
+}
+
+func (x *Consensus) GetTemperature() float64 {
+	if x != nil {
+		return x.Temperature
+	}
+	return 0
+}
+
+func (x *Consensus) GetHumidity() int32 {
+	if x != nil {
+		return x.Humidity
+	}
+	return 0
+}
+
+func (x *Consensus) GetTemperatureSpread() float64 {
+	if x != nil {
+		return x.TemperatureSpread
+	}
+	return 0
+}
+
+func (x *Consensus) GetProviderCount() int32 {
+	if x != nil {
+		return x.ProviderCount
+	}
+	return 0
+}
+
+func (x *Consensus) GetProviders() []*ProviderReading {
+	if x != nil {
+		return x.Providers
+	}
+	return nil
+}
+
+type ProviderReading struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Provider      string                 `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	Weather       *Weather               `protobuf:"bytes,2,opt,name=weather,proto3" json:"weather,omitempty"` // unset when the provider failed
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProviderReading) Reset() {
+	*x = ProviderReading{}
+	mi := &file_weatherpb_weather_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProviderReading) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProviderReading) ProtoMessage() {}
+
+func (x *ProviderReading) ProtoReflect() protoreflect.Message {
+	mi := &file_weatherpb_weather_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProviderReading.ProtoReflect.Descriptor instead.
+func (*ProviderReading) Descriptor() ([]byte, []int) {
+	return file_weatherpb_weather_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ProviderReading) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *ProviderReading) GetWeather() *Weather {
+	if x != nil {
+		return x.Weather
+	}
+	return nil
+}
+
+func (x *ProviderReading) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+var File_weatherpb_weather_proto protoreflect.FileDescriptor
+
+const file_weatherpb_weather_proto_rawDesc = "" +
+	"\n" +
+	"\x17weatherpb/weather.proto\x12\n" +
+	"weather.v1\"O\n" +
+	"\aOptions\x12\x14\n" +
+	"\x05units\x18\x01 \x01(\tR\x05units\x12\x12\n" +
+	"\x04lang\x18\x02 \x01(\tR\x04lang\x12\x1a\n" +
+	"\bprovider\x18\x03 \x01(\tR\bprovider\"w\n" +
+	"\x11GetCurrentRequest\x12\x19\n" +
+	"\bzip_code\x18\x01 \x01(\tR\azipCode\x12\x18\n" +
+	"\acountry\x18\x02 \x01(\tR\acountry\x12-\n" +
+	"\aoptions\x18\x03 \x01(\v2\x13.weather.v1.OptionsR\aoptions\"x\n" +
+	"\x12GetForecastRequest\x12\x19\n" +
+	"\bzip_code\x18\x01 \x01(\tR\azipCode\x12\x18\n" +
+	"\acountry\x18\x02 \x01(\tR\acountry\x12-\n" +
+	"\aoptions\x18\x03 \x01(\v2\x13.weather.v1.OptionsR\aoptions\"z\n" +
+	"\x14StreamUpdatesRequest\x12\x19\n" +
+	"\bzip_code\x18\x01 \x01(\tR\azipCode\x12\x18\n" +
+	"\acountry\x18\x02 \x01(\tR\acountry\x12-\n" +
+	"\aoptions\x18\x03 \x01(\v2\x13.weather.v1.OptionsR\aoptions\"\xe5\x04\n" +
+	"\aWeather\x12\x19\n" +
+	"\bzip_code\x18\x01 \x01(\tR\azipCode\x12\x1a\n" +
+	"\blocation\x18\x02 \x01(\tR\blocation\x12\x14\n" +
+	"\x05units\x18\x03 \x01(\tR\x05units\x12 \n" +
+	"\vtemperature\x18\x04 \x01(\x01R\vtemperature\x12\x1d\n" +
+	"\n" +
+	"feels_like\x18\x05 \x01(\x01R\tfeelsLike\x12\x1b\n" +
+	"\tdew_point\x18\x06 \x01(\x01R\bdewPoint\x12 \n" +
+	"\vdescription\x18\a \x01(\tR\vdescription\x12\x12\n" +
+	"\x04icon\x18\b \x01(\tR\x04icon\x12\x19\n" +
+	"\bicon_url\x18\t \x01(\tR\aiconUrl\x12\x1a\n" +
+	"\bhumidity\x18\n" +
+	" \x01(\x05R\bhumidity\x12\x1a\n" +
+	"\bpressure\x18\v \x01(\x05R\bpressure\x12\x1e\n" +
+	"\n" +
+	"visibility\x18\f \x01(\x05R\n" +
+	"visibility\x12\x1f\n" +
+	"\vcloud_cover\x18\r \x01(\x05R\n" +
+	"cloudCover\x12\x1d\n" +
+	"\n" +
+	"wind_speed\x18\x0e \x01(\x01R\twindSpeed\x12%\n" +
+	"\x0ewind_direction\x18\x0f \x01(\x05R\rwindDirection\x12\x1b\n" +
+	"\twind_gust\x18\x10 \x01(\x01R\bwindGust\x12\x1f\n" +
+	"\vobserved_at\x18\x11 \x01(\tR\n" +
+	"observedAt\x12\x1d\n" +
+	"\n" +
+	"local_time\x18\x12 \x01(\tR\tlocalTime\x12\x16\n" +
+	"\x06source\x18\x13 \x01(\tR\x06source\x12\x14\n" +
+	"\x05cache\x18\x14 \x01(\tR\x05cache\x12\x14\n" +
+	"\x05stale\x18\x15 \x01(\bR\x05stale\"\x86\x01\n" +
+	"\bForecast\x12\x19\n" +
+	"\bzip_code\x18\x01 \x01(\tR\azipCode\x12\x1a\n" +
+	"\blocation\x18\x02 \x01(\tR\blocation\x12\x14\n" +
+	"\x05units\x18\x03 \x01(\tR\x05units\x12-\n" +
+	"\x04days\x18\x04 \x03(\v2\x19.weather.v1.DailyForecastR\x04days\"\xcd\x01\n" +
+	"\rDailyForecast\x12\x12\n" +
+	"\x04date\x18\x01 \x01(\tR\x04date\x12\x12\n" +
+	"\x04high\x18\x02 \x01(\x01R\x04high\x12\x10\n" +
+	"\x03low\x18\x03 \x01(\x01R\x03low\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12\x12\n" +
+	"\x04icon\x18\x05 \x01(\tR\x04icon\x12\x19\n" +
+	"\bicon_url\x18\x06 \x01(\tR\aiconUrl\x121\n" +
+	"\x14precipitation_chance\x18\a \x01(\x05R\x13precipitationChance\"\xb4\x01\n" +
+	"\x0eHourlyForecast\x12\x19\n" +
+	"\bzip_code\x18\x01 \x01(\tR\azipCode\x12\x1a\n" +
+	"\blocation\x18\x02 \x01(\tR\blocation\x12\x14\n" +
+	"\x05units\x18\x03 \x01(\tR\x05units\x12%\n" +
+	"\x0einterval_hours\x18\x04 \x01(\x05R\rintervalHours\x12.\n" +
+	"\x05hours\x18\x05 \x03(\v2\x18.weather.v1.HourlyPeriodR\x05hours\"\xb4\x02\n" +
+	"\fHourlyPeriod\x12\x12\n" +
+	"\x04time\x18\x01 \x01(\tR\x04time\x12 \n" +
+	"\vtemperature\x18\x02 \x01(\x01R\vtemperature\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x12\n" +
+	"\x04icon\x18\x04 \x01(\tR\x04icon\x12\x19\n" +
+	"\bicon_url\x18\x05 \x01(\tR\aiconUrl\x121\n" +
+	"\x14precipitation_chance\x18\x06 \x01(\x05R\x13precipitationChance\x12$\n" +
+	"\rprecipitation\x18\a \x01(\x01R\rprecipitation\x12\x1d\n" +
+	"\n" +
+	"wind_speed\x18\b \x01(\x01R\twindSpeed\x12%\n" +
+	"\x0ewind_direction\x18\t \x01(\x05R\rwindDirection\"A\n" +
+	"\fBatchWeather\x121\n" +
+	"\aresults\x18\x01 \x03(\v2\x17.weather.v1.BatchResultR\aresults\"m\n" +
+	"\vBatchResult\x12\x19\n" +
+	"\bzip_code\x18\x01 \x01(\tR\azipCode\x12-\n" +
+	"\aweather\x18\x02 \x01(\v2\x13.weather.v1.WeatherR\aweather\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"\xa7\x02\n" +
+	"\tConsensus\x12\x19\n" +
+	"\bzip_code\x18\x01 \x01(\tR\azipCode\x12\x1a\n" +
+	"\blocation\x18\x02 \x01(\tR\blocation\x12\x14\n" +
+	"\x05units\x18\x03 \x01(\tR\x05units\x12 \n" +
+	"\vtemperature\x18\x04 \x01(\x01R\vtemperature\x12\x1a\n" +
+	"\bhumidity\x18\x05 \x01(\x05R\bhumidity\x12-\n" +
+	"\x12temperature_spread\x18\x06 \x01(\x01R\x11temperatureSpread\x12%\n" +
+	"\x0eprovider_count\x18\a \x01(\x05R\rproviderCount\x129\n" +
+	"\tproviders\x18\b \x03(\v2\x1b.weather.v1.ProviderReadingR\tproviders\"r\n" +
+	"\x0fProviderReading\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12-\n" +
+	"\aweather\x18\x02 \x01(\v2\x13.weather.v1.WeatherR\aweather\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error2\xe1\x01\n" +
+	"\x0eWeatherService\x12@\n" +
+	"\n" +
+	"GetCurrent\x12\x1d.weather.v1.GetCurrentRequest\x1a\x13.weather.v1.Weather\x12C\n" +
+	"\vGetForecast\x12\x1e.weather.v1.GetForecastRequest\x1a\x14.weather.v1.Forecast\x12H\n" +
+	"\rStreamUpdates\x12 .weather.v1.StreamUpdatesRequest\x1a\x13.weather.v1.Weather0\x01B4Z2github.com/dekkagaijin/go-container-test/weatherpbb\x06proto3"
+
+var (
+	file_weatherpb_weather_proto_rawDescOnce sync.Once
+	file_weatherpb_weather_proto_rawDescData []byte
+)
+
+func file_weatherpb_weather_proto_rawDescGZIP() []byte {
+	file_weatherpb_weather_proto_rawDescOnce.Do(func() {
+		file_weatherpb_weather_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_weatherpb_weather_proto_rawDesc), len(file_weatherpb_weather_proto_rawDesc)))
+	})
+	return file_weatherpb_weather_proto_rawDescData
+}
+
+var file_weatherpb_weather_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_weatherpb_weather_proto_goTypes = []any{
+	(*Options)(nil),              // 0: weather.v1.Options
+	(*GetCurrentRequest)(nil),    // 1: weather.v1.GetCurrentRequest
+	(*GetForecastRequest)(nil),   // 2: weather.v1.GetForecastRequest
+	(*StreamUpdatesRequest)(nil), // 3: weather.v1.StreamUpdatesRequest
+	(*Weather)(nil),              // 4: weather.v1.Weather
+	(*Forecast)(nil),             // 5: weather.v1.Forecast
+	(*DailyForecast)(nil),        // 6: weather.v1.DailyForecast
+	(*HourlyForecast)(nil),       // 7: weather.v1.HourlyForecast
+	(*HourlyPeriod)(nil),         // 8: weather.v1.HourlyPeriod
+	(*BatchWeather)(nil),         // 9: weather.v1.BatchWeather
+	(*BatchResult)(nil),          // 10: weather.v1.BatchResult
+	(*Consensus)(nil),            // 11: weather.v1.Consensus
+	(*ProviderReading)(nil),      // 12: weather.v1.ProviderReading
+}
+var file_weatherpb_weather_proto_depIdxs = []int32{
+	0,  // 0: weather.v1.GetCurrentRequest.options:type_name -> weather.v1.Options
+	0,  // 1: weather.v1.GetForecastRequest.options:type_name -> weather.v1.Options
+	0,  // 2: weather.v1.StreamUpdatesRequest.options:type_name -> weather.v1.Options
+	6,  // 3: weather.v1.Forecast.days:type_name -> weather.v1.DailyForecast
+	8,  // 4: weather.v1.HourlyForecast.hours:type_name -> weather.v1.HourlyPeriod
+	10, // 5: weather.v1.BatchWeather.results:type_name -> weather.v1.BatchResult
+	4,  // 6: weather.v1.BatchResult.weather:type_name -> weather.v1.Weather
+	12, // 7: weather.v1.Consensus.providers:type_name -> weather.v1.ProviderReading
+	4,  // 8: weather.v1.ProviderReading.weather:type_name -> weather.v1.Weather
+	1,  // 9: weather.v1.WeatherService.GetCurrent:input_type -> weather.v1.GetCurrentRequest
+	2,  // 10: weather.v1.WeatherService.GetForecast:input_type -> weather.v1.GetForecastRequest
+	3,  // 11: weather.v1.WeatherService.StreamUpdates:input_type -> weather.v1.StreamUpdatesRequest
+	4,  // 12: weather.v1.WeatherService.GetCurrent:output_type -> weather.v1.Weather
+	5,  // 13: weather.v1.WeatherService.GetForecast:output_type -> weather.v1.Forecast
+	4,  // 14: weather.v1.WeatherService.StreamUpdates:output_type -> weather.v1.Weather
+	12, // [12:15] is the sub-list for method output_type
+	9,  // [9:12] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_weatherpb_weather_proto_init() }
+func file_weatherpb_weather_proto_init() {
+	if File_weatherpb_weather_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_weatherpb_weather_proto_rawDesc), len(file_weatherpb_weather_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   13,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_weatherpb_weather_proto_goTypes,
+		DependencyIndexes: file_weatherpb_weather_proto_depIdxs,
+		MessageInfos:      file_weatherpb_weather_proto_msgTypes,
+	}.Build()
+	File_weatherpb_weather_proto = out.File
+	file_weatherpb_weather_proto_goTypes = nil
+	file_weatherpb_weather_proto_depIdxs = nil
+}